@@ -0,0 +1,540 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/project-gamma/ai-resolver/internal/adapter"
+	"github.com/project-gamma/ai-resolver/internal/eip712"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+)
+
+// marketView is the JSON rendering of a market
+type marketView struct {
+	MarketID        string `json:"marketId"`
+	Status          string `json:"status"`
+	Category        string `json:"category"`
+	CloseTime       int64  `json:"closeTime"`
+	Creator         string `json:"creator"`
+	AMM             string `json:"amm"`
+	CollateralToken string `json:"collateralToken"`
+	MetadataURI     string `json:"metadataUri"`
+	CreatorStake    string `json:"creatorStake"`
+	StakeRefunded   bool   `json:"stakeRefunded"`
+}
+
+func newMarketView(m *adapter.MarketInfo) marketView {
+	return marketView{
+		MarketID:        m.ID.String(),
+		Status:          marketStatusName(m.Status),
+		Category:        m.Category,
+		CloseTime:       m.CloseTime.Int64(),
+		Creator:         m.Creator.Hex(),
+		AMM:             m.AMM.Hex(),
+		CollateralToken: m.CollateralToken.Hex(),
+		MetadataURI:     m.MetadataURI,
+		CreatorStake:    m.CreatorStake.String(),
+		StakeRefunded:   m.StakeRefunded,
+	}
+}
+
+// runMarkets handles "markets list" and "markets show"
+func (c *cli) runMarkets(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: markets list|show")
+	}
+
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("markets list", flag.ExitOnError)
+		offset := fs.Uint64("offset", 0, "pagination offset")
+		limit := fs.Uint64("limit", 50, "maximum number of markets")
+		if _, err := parseArgs(fs, args[1:]); err != nil {
+			return err
+		}
+
+		client, _, err := c.dial(ctx)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		markets, err := client.ListMarkets(ctx, *offset, *limit)
+		if err != nil {
+			return err
+		}
+
+		views := make([]marketView, 0, len(markets))
+		t := table{headers: []string{"ID", "STATUS", "CATEGORY", "CLOSE TIME", "METADATA"}}
+		for _, m := range markets {
+			v := newMarketView(m)
+			views = append(views, v)
+			t.rows = append(t.rows, []string{v.MarketID, v.Status, v.Category, formatTime(v.CloseTime), v.MetadataURI})
+		}
+		return c.print(views, t)
+
+	case "show":
+		fs := flag.NewFlagSet("markets show", flag.ExitOnError)
+		pos, err := parseArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		marketID, err := marketIDArg(pos)
+		if err != nil {
+			return err
+		}
+
+		client, _, err := c.dial(ctx)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		market, err := client.GetMarket(ctx, marketID)
+		if err != nil {
+			return err
+		}
+
+		v := newMarketView(market)
+		return c.print(v, fields(
+			"Market ID", v.MarketID,
+			"Status", v.Status,
+			"Category", v.Category,
+			"Close Time", formatTime(v.CloseTime),
+			"Creator", v.Creator,
+			"AMM", v.AMM,
+			"Collateral", v.CollateralToken,
+			"Metadata URI", v.MetadataURI,
+			"Creator Stake", v.CreatorStake,
+			"Stake Refunded", fmt.Sprint(v.StakeRefunded),
+		))
+
+	default:
+		return fmt.Errorf("unknown markets subcommand %q", args[0])
+	}
+}
+
+// runAnalyze calls the server's analyze or propose endpoint
+func (c *cli) runAnalyze(ctx context.Context, args []string, propose bool) error {
+	name := "analyze"
+	if propose {
+		name = "propose"
+	}
+
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	question := fs.String("question", "", "market question to resolve (required)")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	marketID, err := marketIDArg(pos)
+	if err != nil {
+		return err
+	}
+	if *question == "" {
+		return fmt.Errorf("-question is required")
+	}
+
+	body := map[string]any{
+		"marketId": marketID.Uint64(),
+		"question": *question,
+	}
+
+	if !propose {
+		var resp struct {
+			ChainID  int64         `json:"chainId"`
+			MarketID uint64        `json:"marketId"`
+			RunID    string        `json:"runId"`
+			Decision *llm.Decision `json:"decision"`
+		}
+		if err := c.postJSON(ctx, "analyze", body, &resp); err != nil {
+			return err
+		}
+		if resp.Decision == nil {
+			return fmt.Errorf("server returned no decision")
+		}
+
+		t := fields(
+			"Market ID", fmt.Sprint(resp.MarketID),
+			"Run ID", resp.RunID,
+			"Outcome", fmt.Sprint(resp.Decision.OutcomeID),
+			"Confidence", fmt.Sprintf("%.2f", resp.Decision.Confidence),
+			"Facts", fmt.Sprint(len(resp.Decision.Facts)),
+			"Reasoning", resp.Decision.Reasoning,
+		)
+		for i, citation := range resp.Decision.Citations {
+			t.rows = append(t.rows, []string{fmt.Sprintf("Citation %d", i+1), fmt.Sprintf("%s (%.2f)", citation.URL, citation.Weight)})
+		}
+		return c.print(resp, t)
+	}
+
+	var resp map[string]any
+	if err := c.postJSON(ctx, "propose", body, &resp); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(resp))
+	for k := range resp {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	t := table{headers: []string{"FIELD", "VALUE"}}
+	for _, k := range keys {
+		t.rows = append(t.rows, []string{k, fmt.Sprint(resp[k])})
+	}
+	return c.print(resp, t)
+}
+
+// runState shows a market's resolution record and dispute window
+func (c *cli) runState(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("state", flag.ExitOnError)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	marketID, err := marketIDArg(pos)
+	if err != nil {
+		return err
+	}
+
+	client, _, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	res, err := client.GetResolution(ctx, marketID)
+	if err != nil {
+		return err
+	}
+	remaining, err := client.GetDisputeTimeRemaining(ctx, marketID)
+	if err != nil {
+		return err
+	}
+	canFinalize, err := client.CanFinalize(ctx, marketID)
+	if err != nil {
+		return err
+	}
+
+	view := map[string]any{
+		"marketId":             marketID.String(),
+		"state":                res.State.String(),
+		"proposedOutcome":      res.ProposedOutcome.String(),
+		"proposalTime":         res.ProposalTime.Int64(),
+		"proposer":             res.Proposer.Hex(),
+		"proposerBond":         res.ProposerBond.String(),
+		"disputer":             res.Disputer.Hex(),
+		"disputerBond":         res.DisputerBond.String(),
+		"evidenceUri":          res.EvidenceURI,
+		"disputeTimeRemaining": remaining.Int64(),
+		"canFinalize":          canFinalize,
+	}
+
+	return c.print(view, fields(
+		"Market ID", marketID.String(),
+		"State", res.State.String(),
+		"Proposed Outcome", res.ProposedOutcome.String(),
+		"Proposal Time", formatTime(res.ProposalTime.Int64()),
+		"Proposer", res.Proposer.Hex(),
+		"Proposer Bond", res.ProposerBond.String(),
+		"Disputer", res.Disputer.Hex(),
+		"Disputer Bond", res.DisputerBond.String(),
+		"Evidence URI", res.EvidenceURI,
+		"Dispute Time Left", (time.Duration(remaining.Int64())*time.Second).String(),
+		"Can Finalize", fmt.Sprint(canFinalize),
+	))
+}
+
+// runFinalize finalizes an undisputed proposal
+func (c *cli) runFinalize(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("finalize", flag.ExitOnError)
+	wait := fs.Bool("wait", false, "wait for the transaction to be mined")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	marketID, err := marketIDArg(pos)
+	if err != nil {
+		return err
+	}
+
+	client, _, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	tx, err := client.Finalize(ctx, marketID)
+	if err != nil {
+		return err
+	}
+	return c.printTx(ctx, client, tx, *wait)
+}
+
+// runApprove approves the adapter to spend bond tokens
+func (c *cli) runApprove(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("approve", flag.ExitOnError)
+	wait := fs.Bool("wait", false, "wait for the transaction to be mined")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("usage: approve <amountWei>")
+	}
+	amount, ok := new(big.Int).SetString(pos[0], 10)
+	if !ok || amount.Sign() < 0 {
+		return fmt.Errorf("invalid amount %q", pos[0])
+	}
+
+	client, _, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	tx, err := client.ApproveBond(ctx, amount)
+	if err != nil {
+		return err
+	}
+	return c.printTx(ctx, client, tx, *wait)
+}
+
+// runSigners adds, removes or checks AIOracleAdapter signers
+func (c *cli) runSigners(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: signers add|remove|check <address>")
+	}
+
+	fs := flag.NewFlagSet("signers "+args[0], flag.ExitOnError)
+	wait := fs.Bool("wait", false, "wait for the transaction to be mined")
+	pos, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 || !common.IsHexAddress(pos[0]) {
+		return fmt.Errorf("usage: signers %s <address>", args[0])
+	}
+	signer := common.HexToAddress(pos[0])
+
+	client, _, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	switch args[0] {
+	case "add":
+		tx, err := client.AddSigner(ctx, signer)
+		if err != nil {
+			return err
+		}
+		return c.printTx(ctx, client, tx, *wait)
+	case "remove":
+		tx, err := client.RemoveSigner(ctx, signer)
+		if err != nil {
+			return err
+		}
+		return c.printTx(ctx, client, tx, *wait)
+	case "check":
+		allowed, err := client.IsAllowedSigner(ctx, signer)
+		if err != nil {
+			return err
+		}
+		return c.print(map[string]any{"signer": signer.Hex(), "allowed": allowed},
+			fields("Signer", signer.Hex(), "Allowed", fmt.Sprint(allowed)))
+	default:
+		return fmt.Errorf("unknown signers subcommand %q", args[0])
+	}
+}
+
+// runVerify checks an EIP-712 proposal signature against the chain's adapter domain
+func (c *cli) runVerify(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	market := fs.String("market", "", "market ID")
+	outcome := fs.String("outcome", "", "outcome ID")
+	closeTime := fs.String("close-time", "", "market close time (unix seconds)")
+	evidenceHash := fs.String("evidence-hash", "", "evidence hash (32-byte hex)")
+	notBefore := fs.String("not-before", "", "proposal notBefore (unix seconds)")
+	deadline := fs.String("deadline", "", "proposal deadline (unix seconds)")
+	signature := fs.String("signature", "", "65-byte signature (hex)")
+	signerFlag := fs.String("signer", "", "expected signer address")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	proposal := eip712.ProposedOutcome{}
+	for _, field := range []struct {
+		name  string
+		value string
+		dst   **big.Int
+	}{
+		{"market", *market, &proposal.MarketID},
+		{"outcome", *outcome, &proposal.OutcomeID},
+		{"close-time", *closeTime, &proposal.CloseTime},
+		{"not-before", *notBefore, &proposal.NotBefore},
+		{"deadline", *deadline, &proposal.Deadline},
+	} {
+		v, ok := new(big.Int).SetString(field.value, 10)
+		if !ok {
+			return fmt.Errorf("-%s must be a decimal integer", field.name)
+		}
+		*field.dst = v
+	}
+
+	hashBytes, err := decodeHex(*evidenceHash)
+	if err != nil || len(hashBytes) != 32 {
+		return fmt.Errorf("-evidence-hash must be 32 bytes of hex")
+	}
+	copy(proposal.EvidenceHash[:], hashBytes)
+
+	sig, err := decodeHex(*signature)
+	if err != nil {
+		return fmt.Errorf("-signature must be hex: %w", err)
+	}
+	if !common.IsHexAddress(*signerFlag) {
+		return fmt.Errorf("-signer must be an address")
+	}
+	expected := common.HexToAddress(*signerFlag)
+
+	profile, err := c.profile()
+	if err != nil {
+		return err
+	}
+
+	signer := eip712.NewSigner(big.NewInt(profile.ChainID), common.HexToAddress(profile.AIOracleAdapterAddr))
+	valid, err := signer.VerifySignature(proposal, sig, expected)
+	if err != nil {
+		return err
+	}
+
+	return c.print(map[string]any{"valid": valid, "signer": expected.Hex(), "chainId": profile.ChainID},
+		fields("Valid", fmt.Sprint(valid), "Signer", expected.Hex(), "Chain ID", fmt.Sprint(profile.ChainID)))
+}
+
+// printTx prints a submitted transaction and optionally waits for its receipt
+func (c *cli) printTx(ctx context.Context, client *adapter.Client, tx *types.Transaction, wait bool) error {
+	view := map[string]any{"txHash": tx.Hash().Hex()}
+	t := fields("Tx Hash", tx.Hash().Hex())
+
+	if wait {
+		receipt, err := client.WaitForTransaction(ctx, tx)
+		if err != nil {
+			return err
+		}
+		view["blockNumber"] = receipt.BlockNumber.Uint64()
+		view["gasUsed"] = receipt.GasUsed
+		t.rows = append(t.rows,
+			[]string{"Block", receipt.BlockNumber.String()},
+			[]string{"Gas Used", fmt.Sprint(receipt.GasUsed)},
+		)
+	}
+
+	return c.print(view, t)
+}
+
+// postJSON posts body to the resolver API, chain-scoped when -chain is set
+func (c *cli) postJSON(ctx context.Context, endpoint string, body any, out any) error {
+	path := "/v1/" + endpoint
+	if c.chainID != 0 {
+		path = fmt.Sprintf("/v1/%d/%s", c.chainID, endpoint)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.serverURL, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// parseArgs parses flags that may appear before or after positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// marketIDArg parses the single positional market ID
+func marketIDArg(pos []string) (*big.Int, error) {
+	if len(pos) != 1 {
+		return nil, fmt.Errorf("expected exactly one market ID")
+	}
+	id, ok := new(big.Int).SetString(pos[0], 10)
+	if !ok || id.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid market ID %q", pos[0])
+	}
+	return id, nil
+}
+
+// marketStatusName returns the MarketFactory.MarketStatus name
+func marketStatusName(status uint8) string {
+	switch status {
+	case adapter.MarketStatusActive:
+		return "Active"
+	case adapter.MarketStatusClosed:
+		return "Closed"
+	case adapter.MarketStatusResolved:
+		return "Resolved"
+	case adapter.MarketStatusInvalid:
+		return "Invalid"
+	default:
+		return fmt.Sprintf("Unknown(%d)", status)
+	}
+}
+
+func formatTime(unix int64) string {
+	if unix == 0 {
+		return "-"
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
+
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
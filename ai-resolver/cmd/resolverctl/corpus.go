@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/project-gamma/ai-resolver/internal/config"
+	"github.com/project-gamma/ai-resolver/internal/corpus"
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+)
+
+// openCorpus opens the corpus configured by CORPUS_FILE, embedding with
+// CORPUS_EMBEDDING_MODEL when it is set
+func openCorpus() (*corpus.Index, *config.Config, error) {
+	cfg, err := config.LoadOperatorConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.CorpusFile == "" {
+		return nil, nil, fmt.Errorf("CORPUS_FILE is not set")
+	}
+	index, err := corpus.Open(cfg.CorpusFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.CorpusEmbeddingModel != "" {
+		if cfg.OpenAIAPIKey == "" {
+			return nil, nil, fmt.Errorf("CORPUS_EMBEDDING_MODEL needs OPENAI_API_KEY")
+		}
+		index.SetEmbedder(corpus.NewOpenAIEmbedder(cfg.OpenAIAPIKey, cfg.CorpusEmbeddingModel))
+	}
+	return index, cfg, nil
+}
+
+// runCorpus manages the local document corpus
+func (c *cli) runCorpus(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: corpus ingest|search|list|remove")
+	}
+
+	fs := flag.NewFlagSet("corpus "+args[0], flag.ExitOnError)
+	published := fs.String("url", "", "URL a local file was published at; its passages are cited by it")
+	limit := fs.Int("limit", 5, "passages to return")
+	pos, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	index, cfg, err := openCorpus()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "ingest":
+		if len(pos) == 0 {
+			return fmt.Errorf("usage: corpus ingest <url|file>... [-url U]")
+		}
+		if *published != "" && len(pos) > 1 {
+			return fmt.Errorf("-url names one file's URL, got %d sources", len(pos))
+		}
+		ingester := corpus.NewIngester(index, fetch.New(fetch.Options{Timeout: cfg.FetchTimeout, MaxBytes: cfg.FetchMaxBytes}))
+		t := table{headers: []string{"DOCUMENT", "PASSAGES", "TITLE", "URL"}}
+		docs := make([]corpus.Document, 0, len(pos))
+		for _, source := range pos {
+			var doc corpus.Document
+			if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+				doc, err = ingester.IngestURL(ctx, source)
+			} else {
+				doc, err = ingester.IngestFile(ctx, source, *published)
+			}
+			if err != nil {
+				return err
+			}
+			docs = append(docs, doc)
+			t.rows = append(t.rows, []string{doc.ID, fmt.Sprint(doc.Passages), doc.Title, doc.URL})
+		}
+		if err := index.Save(); err != nil {
+			return err
+		}
+		return c.print(docs, t)
+
+	case "search":
+		if len(pos) == 0 {
+			return fmt.Errorf("usage: corpus search <query> [-limit N]")
+		}
+		hits, err := index.Search(ctx, strings.Join(pos, " "), *limit)
+		if err != nil {
+			return err
+		}
+		t := table{headers: []string{"PASSAGE", "SCORE", "URL", "TEXT"}}
+		for _, hit := range hits {
+			t.rows = append(t.rows, []string{hit.PassageID, fmt.Sprintf("%.3f", hit.Score), hit.URL, excerpt(hit.Text, 80)})
+		}
+		return c.print(hits, t)
+
+	case "list":
+		docs := index.Documents()
+		t := table{headers: []string{"DOCUMENT", "PASSAGES", "TYPE", "INGESTED", "TITLE", "URL"}}
+		for _, doc := range docs {
+			t.rows = append(t.rows, []string{doc.ID, fmt.Sprint(doc.Passages), doc.ContentType,
+				doc.IngestedAt.Format("2006-01-02 15:04"), doc.Title, doc.URL})
+		}
+		return c.print(docs, t)
+
+	case "remove":
+		if len(pos) != 1 {
+			return fmt.Errorf("usage: corpus remove <documentId|url>")
+		}
+		if !index.Remove(pos[0]) {
+			return fmt.Errorf("document %s is not in the corpus", pos[0])
+		}
+		if err := index.Save(); err != nil {
+			return err
+		}
+		return c.print(map[string]any{"removed": pos[0]}, fields("REMOVED", pos[0]))
+
+	default:
+		return fmt.Errorf("unknown corpus command %q (use ingest, search, list or remove)", args[0])
+	}
+}
+
+// excerpt shortens text to one line of at most n characters
+func excerpt(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if r := []rune(text); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,147 @@
+// Command resolverctl is the operator CLI for the AI resolver. It talks to the
+// contracts through adapter.Client and to a running resolver through its HTTP API.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/adapter"
+	"github.com/project-gamma/ai-resolver/internal/config"
+)
+
+const usage = `Usage: resolverctl [global flags] <command> [flags] [args]
+
+Commands:
+  markets list [-offset N] [-limit N]    List markets from the factory
+  markets show <marketId>                Show market details
+  analyze <marketId> -question Q         Run the LLM pipeline via the server (no proposal)
+  propose <marketId> -question Q         Analyze, sign and submit a proposal via the server
+  state <marketId>                       Show resolution state and dispute time remaining
+  finalize <marketId> [-wait]            Finalize an undisputed proposal
+  approve <amountWei> [-wait]            Approve the adapter to spend bond tokens
+  signers add|remove|check <address>     Manage AIOracleAdapter signers
+  verify [flags]                         Verify an EIP-712 proposal signature
+  runs <marketId>                        List recorded analyses of a market (AUDIT_DIR)
+  replay <runId> [-live] [-save]         Re-run a recorded analysis and diff the decision
+  corpus ingest <url|file>... [-url U]   Add documents to the corpus (CORPUS_FILE)
+  corpus search <query> [-limit N]       Search the corpus as search_documents does
+  corpus list                            List the documents in the corpus
+  corpus remove <documentId|url>         Remove a document from the corpus
+
+Global flags:
+`
+
+// cli holds global options shared by all commands
+type cli struct {
+	chainID   int64
+	serverURL string
+	output    string
+	timeout   time.Duration
+}
+
+func main() {
+	c := &cli{}
+
+	global := flag.NewFlagSet("resolverctl", flag.ExitOnError)
+	global.Int64Var(&c.chainID, "chain", 0, "chain ID to operate on (default: DEFAULT_CHAIN_ID or first profile)")
+	global.StringVar(&c.serverURL, "server", getEnv("RESOLVER_URL", "http://localhost:8080"), "resolver server base URL")
+	global.StringVar(&c.output, "o", "table", "output format: table or json")
+	global.DurationVar(&c.timeout, "timeout", 10*time.Minute, "overall command timeout")
+	global.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		global.PrintDefaults()
+	}
+	global.Parse(os.Args[1:])
+
+	if c.output != "table" && c.output != "json" {
+		fatalf("unknown output format %q (use table or json)", c.output)
+	}
+
+	args := global.Args()
+	if len(args) == 0 {
+		global.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var err error
+	switch args[0] {
+	case "markets":
+		err = c.runMarkets(ctx, args[1:])
+	case "analyze":
+		err = c.runAnalyze(ctx, args[1:], false)
+	case "propose":
+		err = c.runAnalyze(ctx, args[1:], true)
+	case "state":
+		err = c.runState(ctx, args[1:])
+	case "finalize":
+		err = c.runFinalize(ctx, args[1:])
+	case "approve":
+		err = c.runApprove(ctx, args[1:])
+	case "signers":
+		err = c.runSigners(ctx, args[1:])
+	case "verify":
+		err = c.runVerify(ctx, args[1:])
+	case "runs":
+		err = c.runRuns(ctx, args[1:])
+	case "replay":
+		err = c.runReplay(ctx, args[1:])
+	case "corpus":
+		err = c.runCorpus(ctx, args[1:])
+	case "help":
+		global.Usage()
+	default:
+		global.Usage()
+		err = fmt.Errorf("unknown command %q", args[0])
+	}
+
+	if err != nil {
+		fatalf("%v", err)
+	}
+}
+
+// profile loads the chain profile selected by -chain
+func (c *cli) profile() (config.ChainProfile, error) {
+	return config.LoadChainProfile(c.chainID)
+}
+
+// dial connects to the selected chain. The client is read-only unless
+// SIGNER_PRIVATE_KEY (or the profile's signerPrivateKey) is set.
+func (c *cli) dial(ctx context.Context) (*adapter.Client, config.ChainProfile, error) {
+	profile, err := c.profile()
+	if err != nil {
+		return nil, profile, err
+	}
+
+	client, err := adapter.NewClient(ctx, adapter.Config{
+		RPCURL:            profile.RPCEndpoint,
+		ChainID:           profile.ChainID,
+		SignerPrivateKey:  profile.SignerPrivateKey,
+		AdapterAddress:    profile.AIOracleAdapterAddr,
+		FactoryAddress:    profile.MarketFactoryAddr,
+		ResolutionAddress: profile.ResolutionModuleAddr,
+		TokenAddress:      profile.TokenAddr,
+	})
+	if err != nil {
+		return nil, profile, err
+	}
+	return client, profile, nil
+}
+
+func getEnv(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "resolverctl: "+format+"\n", args...)
+	os.Exit(1)
+}
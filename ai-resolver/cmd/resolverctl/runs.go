@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/config"
+	"github.com/project-gamma/ai-resolver/internal/replay"
+)
+
+// runView is the summary of one audit run
+type runView struct {
+	RunID      string `json:"runId"`
+	ChainID    int64  `json:"chainId"`
+	MarketID   uint64 `json:"marketId"`
+	Model      string `json:"model"`
+	DryRun     bool   `json:"dryRun"`
+	ReplayOf   string `json:"replayOf,omitempty"`
+	StartedAt  string `json:"startedAt"`
+	LLMCalls   int    `json:"llmCalls"`
+	ToolCalls  int    `json:"toolCalls"`
+	TxHash     string `json:"txHash,omitempty"`
+	Error      string `json:"error,omitempty"`
+	HasOutcome bool   `json:"hasOutcome"`
+}
+
+func newRunView(run *audit.Run) runView {
+	v := runView{
+		RunID:      run.ID,
+		ChainID:    run.ChainID,
+		MarketID:   run.MarketID,
+		Model:      run.Model,
+		DryRun:     run.DryRun,
+		ReplayOf:   run.ReplayOf,
+		StartedAt:  run.StartedAt.Format("2006-01-02 15:04:05 MST"),
+		LLMCalls:   len(run.EventsOf(audit.EventLLMCall)),
+		ToolCalls:  len(run.EventsOf(audit.EventToolCall)),
+		Error:      run.Error,
+		HasOutcome: len(run.Decision) > 0,
+	}
+	if run.Proposal != nil {
+		v.TxHash = run.Proposal.TxHash
+	}
+	return v
+}
+
+// auditStore opens the audit directory configured by AUDIT_DIR
+func auditStore() (*audit.FileStore, *config.Config, error) {
+	cfg, err := config.LoadOperatorConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	store, err := audit.NewFileStore(cfg.AuditDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, cfg, nil
+}
+
+// runRuns lists the recorded analyses of a market
+func (c *cli) runRuns(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("runs", flag.ExitOnError)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	marketID, err := marketIDArg(pos)
+	if err != nil {
+		return err
+	}
+
+	store, cfg, err := auditStore()
+	if err != nil {
+		return err
+	}
+	chainID := c.chainID
+	if chainID == 0 {
+		chainID = cfg.DefaultChainID
+	}
+
+	runs, err := store.List(chainID, marketID.Uint64())
+	if err != nil {
+		return err
+	}
+
+	views := make([]runView, 0, len(runs))
+	t := table{headers: []string{"RUN", "STARTED", "MODEL", "MODE", "LLM", "TOOLS", "RESULT"}}
+	for _, run := range runs {
+		v := newRunView(run)
+		views = append(views, v)
+
+		mode := "propose"
+		switch {
+		case v.ReplayOf != "":
+			mode = "replay"
+		case v.DryRun:
+			mode = "analyze"
+		}
+		result := v.TxHash
+		switch {
+		case v.Error != "":
+			result = "error: " + v.Error
+		case result == "" && v.HasOutcome:
+			result = "decided"
+		}
+		t.rows = append(t.rows, []string{v.RunID, v.StartedAt, v.Model, mode, fmt.Sprint(v.LLMCalls), fmt.Sprint(v.ToolCalls), result})
+	}
+	return c.print(views, t)
+}
+
+// runReplay re-runs a recorded analysis and diffs the decision
+func (c *cli) runReplay(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	live := fs.Bool("live", false, "query the model again instead of serving recorded responses")
+	model := fs.String("model", "", "model for live replays (default: the recorded model)")
+	save := fs.Bool("save", false, "store the replay as a new audit run")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("expected exactly one run ID")
+	}
+
+	store, cfg, err := auditStore()
+	if err != nil {
+		return err
+	}
+	original, err := store.Load(pos[0])
+	if err != nil {
+		return err
+	}
+
+	opts := replay.Options{Live: *live, Model: *model}
+	if *live {
+		if cfg.OpenAIAPIKey == "" {
+			return fmt.Errorf("OPENAI_API_KEY is required for live replays")
+		}
+		opts.APIKey = cfg.OpenAIAPIKey
+	}
+
+	result, err := replay.Replay(ctx, original, opts)
+	if err != nil {
+		return err
+	}
+	if *save {
+		if err := store.Save(result.Run); err != nil {
+			return err
+		}
+	}
+
+	view := map[string]any{
+		"runId":    original.ID,
+		"replayId": result.Run.ID,
+		"live":     *live,
+		"match":    len(result.Diffs) == 0,
+		"diffs":    result.Diffs,
+	}
+	if result.Replayed != nil {
+		view["decision"] = result.Replayed
+	}
+
+	t := fields(
+		"Run ID", original.ID,
+		"Replay ID", result.Run.ID,
+		"Live", fmt.Sprint(*live),
+		"Match", fmt.Sprint(len(result.Diffs) == 0),
+	)
+	for _, d := range result.Diffs {
+		t.rows = append(t.rows,
+			[]string{"- " + d.Field, oneLine(d.Original)},
+			[]string{"+ " + d.Field, oneLine(d.Replayed)},
+		)
+	}
+	return c.print(view, t)
+}
+
+// oneLine flattens multi-line values for table output
+func oneLine(s string) string {
+	return strings.ReplaceAll(s, "\n", " | ")
+}
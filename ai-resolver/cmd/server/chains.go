@@ -0,0 +1,407 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/project-gamma/ai-resolver/internal/adapter"
+	"github.com/project-gamma/ai-resolver/internal/config"
+	"github.com/project-gamma/ai-resolver/internal/corpus"
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+	"github.com/project-gamma/ai-resolver/internal/dependent"
+	"github.com/project-gamma/ai-resolver/internal/eip712"
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+	"github.com/project-gamma/ai-resolver/internal/httprec"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+	"github.com/project-gamma/ai-resolver/internal/metadata"
+	"github.com/project-gamma/ai-resolver/internal/pricefeed"
+	"github.com/project-gamma/ai-resolver/internal/tools"
+	"github.com/project-gamma/ai-resolver/internal/verify"
+)
+
+// chainInstance holds the client, signer, bond settings and watcher for one
+// deployment profile. A resolver process runs one instance per configured chain.
+type chainInstance struct {
+	profile    config.ChainProfile
+	client     *adapter.Client
+	llm        llm.Pipeline
+	prompts    interface{ SetPrompts(*llm.PromptSet) } // nil when the pipeline's prompts are fixed
+	signer     *eip712.Signer
+	privateKey *ecdsa.PrivateKey
+	bondAmount *big.Int
+	watcher    *marketWatcher // nil when watching is disabled
+}
+
+// newChainInstance connects to a chain and wires up its pipeline and signer.
+// transport, when non-nil, carries the pipeline's and tools' HTTP traffic.
+// documents, when non-nil, is the corpus search_documents searches.
+func newChainInstance(ctx context.Context, cfg *config.Config, profile config.ChainProfile, transport http.RoundTripper, prompts *llm.PromptSet, router *llm.Router, documents *corpus.Index) (*chainInstance, error) {
+	// Initialize blockchain client
+	client, err := adapter.NewClient(ctx, adapter.Config{
+		RPCURL:            profile.RPCEndpoint,
+		ChainID:           profile.ChainID,
+		SignerPrivateKey:  profile.SignerPrivateKey,
+		AdapterAddress:    profile.AIOracleAdapterAddr,
+		FactoryAddress:    profile.MarketFactoryAddr,
+		ResolutionAddress: profile.ResolutionModuleAddr,
+		TokenAddress:      profile.TokenAddr,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize client: %w", err)
+	}
+
+	instance, err := newChainInstanceWithClient(cfg, profile, client, transport, prompts, router, documents)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return instance, nil
+}
+
+// newChainInstanceWithClient wires up a chain's pipeline and signer around an
+// existing client, such as one on a simulated backend
+func newChainInstanceWithClient(cfg *config.Config, profile config.ChainProfile, client *adapter.Client, transport http.RoundTripper, prompts *llm.PromptSet, router *llm.Router, documents *corpus.Index) (*chainInstance, error) {
+	// Tools that read chain state are bound to this chain's client, so each
+	// chain gets its own pipeline and tool registry
+	llmPipeline, err := newPipeline(cfg, client, transport, router, documents)
+	if err != nil {
+		return nil, err
+	}
+	llmPipeline.SetPrompts(prompts)
+
+	// Parse private key for signing
+	privateKey, err := crypto.HexToECDSA(profile.SignerPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	bondAmount, ok := new(big.Int).SetString(profile.DefaultBondAmount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid bond amount %q", profile.DefaultBondAmount)
+	}
+
+	instance := &chainInstance{
+		profile:    profile,
+		client:     client,
+		llm:        llmPipeline,
+		prompts:    llmPipeline,
+		signer:     eip712.NewSigner(big.NewInt(profile.ChainID), common.HexToAddress(profile.AIOracleAdapterAddr)),
+		privateKey: privateKey,
+		bondAmount: bondAmount,
+	}
+
+	if profile.WatchInterval > 0 {
+		instance.watcher = newMarketWatcher(profile.Name, client, time.Duration(profile.WatchInterval))
+	}
+
+	return instance, nil
+}
+
+// newPriceResolver returns the on-chain price resolver for a chain, with the
+// feeds from PRICE_FEEDS_FILE or the built-in ones
+func newPriceResolver(cfg *config.Config, client *adapter.Client, chainID int64) (*pricefeed.Resolver, error) {
+	feeds := pricefeed.DefaultFeeds()
+	if cfg.PriceFeedsFile != "" {
+		var err error
+		if feeds, err = pricefeed.LoadFeeds(cfg.PriceFeedsFile); err != nil {
+			return nil, err
+		}
+	}
+	backend := (&pancakeswapClientAdapter{client: client}).GetETHClient()
+	return pricefeed.NewResolver(backend, chainID, feeds), nil
+}
+
+// newCassette returns the record/replay transport configured by HTTP_CASSETTE,
+// or nil when HTTP traffic goes straight to the network
+func newCassette(cfg *config.Config) (http.RoundTripper, error) {
+	if cfg.HTTPCassette == "" {
+		return nil, nil
+	}
+
+	mode, err := httprec.ParseMode(cfg.HTTPCassetteMode)
+	if err != nil {
+		return nil, err
+	}
+	recorder, err := httprec.New(cfg.HTTPCassette, mode, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+
+	log.Printf("HTTP cassette %s (%s)", cfg.HTTPCassette, mode)
+	return recorder, nil
+}
+
+// newPageFetcher creates the fetcher behind fetch_url and citation
+// verification. Pages are fetched directly, not through the HTTP cassette,
+// which only holds API traffic; documents in the corpus are read from it, and
+// fetched pages are archived so that verification checks the page the model
+// read.
+func newPageFetcher(cfg *config.Config, documents *corpus.Index) fetch.PageFetcher {
+	var pages fetch.PageFetcher = fetch.NewArchive(fetch.New(fetch.Options{
+		Timeout:      cfg.FetchTimeout,
+		MaxBytes:     cfg.FetchMaxBytes,
+		AllowDomains: cfg.FetchAllowedDomains,
+		Robots:       cfg.FetchRobots,
+	}), cfg.FetchArchiveTTL)
+	if documents != nil {
+		pages = corpus.NewFetcher(documents, pages)
+	}
+	return pages
+}
+
+// newVerifier creates the citation verifier
+func newVerifier(cfg *config.Config, pages fetch.PageFetcher) *verify.Verifier {
+	verifier := verify.New(pages)
+	if cfg.VerifyMinScore > 0 {
+		verifier.SetMinScore(cfg.VerifyMinScore)
+	}
+	return verifier
+}
+
+// openCorpus opens the document corpus configured by CORPUS_FILE, or returns
+// nil when there is none. Queries are embedded when the corpus has an
+// embedding model.
+func openCorpus(cfg *config.Config, transport http.RoundTripper) (*corpus.Index, error) {
+	if cfg.CorpusFile == "" {
+		return nil, nil
+	}
+	documents, err := corpus.Open(cfg.CorpusFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.CorpusEmbeddingModel != "" {
+		embedder := corpus.NewOpenAIEmbedder(cfg.OpenAIAPIKey, cfg.CorpusEmbeddingModel)
+		if transport != nil {
+			embedder.SetTransport(transport)
+		}
+		documents.SetEmbedder(embedder)
+	}
+	log.Printf("Loaded %d documents from corpus %s", len(documents.Documents()), cfg.CorpusFile)
+	return documents, nil
+}
+
+// setStages registers the stages beyond the built-in ones and applies the
+// stage layout from PIPELINE_FILE. Without one, the market metadata is
+// fetched, the parent of conditional markets checked and price markets
+// answered from the chain first, then the built-in stages run.
+func setStages(cfg *config.Config, llmPipeline *llm.OpenAIPipeline, client *adapter.Client) error {
+	fetcher := fetch.New(fetch.Options{Timeout: cfg.FetchTimeout, MaxBytes: cfg.FetchMaxBytes})
+	if err := llmPipeline.RegisterStage(metadata.NewStage(fetcher, cfg.IPFSGateway)); err != nil {
+		return fmt.Errorf("failed to register metadata stage: %w", err)
+	}
+	parent := dependent.NewStage(client, client.GetChainID().Int64(), client.GetResolutionAddress())
+	parent.SetWait(cfg.ParentWait, cfg.ParentPollInterval)
+	if err := llmPipeline.RegisterStage(parent); err != nil {
+		return fmt.Errorf("failed to register parent stage: %w", err)
+	}
+	prices, err := newPriceResolver(cfg, client, client.GetChainID().Int64())
+	if err != nil {
+		return err
+	}
+	if err := llmPipeline.RegisterStage(pricefeed.NewStage(prices)); err != nil {
+		return fmt.Errorf("failed to register price stage: %w", err)
+	}
+	if cfg.PipelineFile == "" {
+		layout := llm.DefaultPipelineConfig()
+		layout.Stages = append([]llm.StageConfig{{Name: metadata.StageName}, {Name: dependent.StageName}, {Name: pricefeed.StageName}}, layout.Stages...)
+		return llmPipeline.SetStages(layout)
+	}
+	layout, err := llm.LoadPipelineConfig(cfg.PipelineFile)
+	if err != nil {
+		return err
+	}
+	if err := llmPipeline.SetStages(layout); err != nil {
+		return fmt.Errorf("failed to apply %s: %w", cfg.PipelineFile, err)
+	}
+	log.Printf("Loaded stage layout from %s", cfg.PipelineFile)
+	return nil
+}
+
+// loadPrompts returns the prompt templates from PROMPTS_DIR, or the built-in
+// set when it is unset
+func loadPrompts(cfg *config.Config) (*llm.PromptSet, error) {
+	if cfg.PromptsDir == "" {
+		return llm.DefaultPrompts(), nil
+	}
+	prompts, err := llm.LoadPrompts(os.DirFS(cfg.PromptsDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts from %s: %w", cfg.PromptsDir, err)
+	}
+	return prompts, nil
+}
+
+// reloadPrompts re-reads PROMPTS_DIR and swaps the templates of every chain's
+// pipeline. Analyses already running keep the set they started with; on error
+// the current set stays in place.
+func reloadPrompts(cfg *config.Config, chains map[int64]*chainInstance) {
+	prompts, err := loadPrompts(cfg)
+	if err != nil {
+		log.Printf("Warning: prompt reload failed, keeping current prompts: %v", err)
+		return
+	}
+	for _, chain := range chains {
+		if chain.prompts != nil {
+			chain.prompts.SetPrompts(prompts)
+		}
+	}
+	log.Printf("Loaded prompt set version %s", prompts.Version())
+}
+
+// Close releases the chain's RPC connection
+func (c *chainInstance) Close() {
+	c.client.Close()
+}
+
+// newRouter creates the LLM router shared by every chain's pipeline, so that
+// rate limits, concurrency and circuit state are tracked per API, not per chain
+func newRouter(cfg *config.Config) (*llm.Router, error) {
+	var fallbacks []llm.Endpoint
+	if cfg.LLMFallbacksFile != "" {
+		var err error
+		if fallbacks, err = llm.LoadFallbacks(cfg.LLMFallbacksFile); err != nil {
+			return nil, err
+		}
+	}
+	return llm.NewRouter(fallbacks, llm.Resilience{
+		MaxAttempts:      cfg.LLMMaxAttempts,
+		BaseDelay:        cfg.LLMRetryBaseDelay,
+		MaxDelay:         cfg.LLMRetryMaxDelay,
+		MaxConcurrent:    cfg.LLMMaxConcurrent,
+		BreakerThreshold: cfg.LLMBreakerThreshold,
+		BreakerCooldown:  cfg.LLMBreakerCooldown,
+	}), nil
+}
+
+// newPipeline creates the LLM pipeline and registers the built-in tools
+func newPipeline(cfg *config.Config, client *adapter.Client, transport http.RoundTripper, router *llm.Router, documents *corpus.Index) (*llm.OpenAIPipeline, error) {
+	// Initialize LLM pipeline with integrated web search
+	llmPipeline := llm.NewOpenAIPipeline(cfg.OpenAIAPIKey, cfg.OpenAIModel)
+	llmPipeline.SetRouter(router)
+	llmPipeline.SetChallenge(cfg.ChallengeDecisions)
+	llmPipeline.SetHostedSearch(cfg.HostedWebSearch)
+	pages := newPageFetcher(cfg, documents)
+	if cfg.VerifyCitations {
+		llmPipeline.SetVerifier(newVerifier(cfg, pages))
+	}
+	if transport != nil {
+		llmPipeline.SetTransport(transport)
+	}
+	if cfg.StrategiesFile != "" {
+		strategies, err := llm.LoadStrategies(cfg.StrategiesFile)
+		if err != nil {
+			return nil, err
+		}
+		llmPipeline.SetStrategies(strategies)
+	}
+	if cfg.SourceTiersFile != "" {
+		model, err := credibility.LoadModel(cfg.SourceTiersFile)
+		if err != nil {
+			return nil, err
+		}
+		llmPipeline.SetCredibility(model)
+	}
+	if err := setStages(cfg, llmPipeline, client); err != nil {
+		return nil, err
+	}
+
+	// Initialize tool registry and register built-in tools
+	toolRegistry := tools.NewRegistry()
+
+	// NOTE: web_search is added manually in the LLM pipeline (openai.go)
+	// to ensure compatibility with the Responses API when mixing with custom function tools
+	// We use "web_search" type instead of "web_search_preview" for this purpose.
+	// HOSTED_WEB_SEARCH=false leaves it out; SEARCH_PROVIDER adds the
+	// provider-neutral search_web tool below.
+
+	// Create adapter for market data client
+	marketDataAdapter := &marketDataClientAdapter{client: client}
+
+	// Register market data tool
+	marketDataTool := tools.NewMarketDataTool(marketDataAdapter)
+	if err := toolRegistry.Register(marketDataTool); err != nil {
+		return nil, fmt.Errorf("failed to register market data tool: %w", err)
+	}
+
+	// Register calculator tool
+	calculatorTool := tools.NewCalculatorTool()
+	if err := toolRegistry.Register(calculatorTool); err != nil {
+		return nil, fmt.Errorf("failed to register calculator tool: %w", err)
+	}
+
+	// Register datetime tool
+	datetimeTool := tools.NewDateTimeTool()
+	if err := toolRegistry.Register(datetimeTool); err != nil {
+		return nil, fmt.Errorf("failed to register datetime tool: %w", err)
+	}
+
+	// Register web search tool (if a search provider is configured)
+	if cfg.SearchProvider != "" {
+		provider, err := tools.NewSearchProvider(cfg.SearchProvider, cfg.SearchURL, cfg.SearchAPIKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SEARCH_PROVIDER: %w", err)
+		}
+		if transport != nil {
+			provider.SetTransport(transport)
+		}
+		webSearchTool := tools.NewWebSearchTool(provider)
+		webSearchTool.SetDefaultLimit(cfg.MaxSearchResults)
+		if err := toolRegistry.Register(webSearchTool); err != nil {
+			return nil, fmt.Errorf("failed to register web search tool: %w", err)
+		}
+	}
+
+	// Register fetch tool
+	fetchTool := tools.NewFetchTool(pages)
+	if err := toolRegistry.Register(fetchTool); err != nil {
+		return nil, fmt.Errorf("failed to register fetch tool: %w", err)
+	}
+
+	// Register BSCScan tool (if API key provided)
+	if cfg.BSCScanAPIKey != "" {
+		bscscanTool := tools.NewBSCScanTool(cfg.BSCScanAPIKey)
+		if transport != nil {
+			bscscanTool.SetTransport(transport)
+		}
+		if err := toolRegistry.Register(bscscanTool); err != nil {
+			return nil, fmt.Errorf("failed to register bscscan tool: %w", err)
+		}
+	}
+
+	// Register PancakeSwap tool (adapter implements PancakeSwapClient interface)
+	pancakeswapAdapter := &pancakeswapClientAdapter{client: client}
+	pancakeswapTool := tools.NewPancakeSwapTool(pancakeswapAdapter)
+	if transport != nil {
+		pancakeswapTool.SetTransport(transport)
+	}
+	if err := toolRegistry.Register(pancakeswapTool); err != nil {
+		return nil, fmt.Errorf("failed to register pancakeswap tool: %w", err)
+	}
+
+	// Register document search tool (if a corpus is configured)
+	if documents != nil {
+		if err := toolRegistry.Register(tools.NewDocumentSearchTool(documents)); err != nil {
+			return nil, fmt.Errorf("failed to register document search tool: %w", err)
+		}
+	}
+
+	// Set the tool registry on the pipeline
+	llmPipeline.SetToolRegistry(&toolRegistryAdapter{registry: toolRegistry})
+
+	log.Printf("Registered %d tools: %v", toolRegistry.Count(), func() []string {
+		names := make([]string, 0)
+		for _, tool := range toolRegistry.List() {
+			names = append(names, tool.Name())
+		}
+		return names
+	}())
+
+	return llmPipeline, nil
+}
@@ -2,26 +2,32 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/project-gamma/ai-resolver/internal/adapter"
+	"github.com/project-gamma/ai-resolver/internal/audit"
 	"github.com/project-gamma/ai-resolver/internal/config"
+	"github.com/project-gamma/ai-resolver/internal/dependent"
 	"github.com/project-gamma/ai-resolver/internal/eip712"
+	"github.com/project-gamma/ai-resolver/internal/lint"
 	"github.com/project-gamma/ai-resolver/internal/llm"
+	"github.com/project-gamma/ai-resolver/internal/metadata"
+	"github.com/project-gamma/ai-resolver/internal/pricefeed"
+	"github.com/project-gamma/ai-resolver/internal/progress"
 	"github.com/project-gamma/ai-resolver/internal/tools"
+	"github.com/project-gamma/ai-resolver/internal/usage"
 	"github.com/project-gamma/ai-resolver/pkg/abi"
 )
 
@@ -33,99 +39,74 @@ func main() {
 	}
 
 	// Initialize components
-	ctx := context.Background()
-
-	// Initialize blockchain client
-	client, err := adapter.NewClient(ctx, adapter.Config{
-		RPCURL:            cfg.RPCEndpoint,
-		ChainID:           cfg.ChainID,
-		SignerPrivateKey:  cfg.SignerPrivateKey,
-		AdapterAddress:    cfg.AIOracleAdapterAddr,
-		FactoryAddress:    cfg.MarketFactoryAddr,
-		ResolutionAddress: cfg.ResolutionModuleAddr,
-		TokenAddress:      cfg.TokenAddr,
-	})
+	ctx, cancelWatchers := context.WithCancel(context.Background())
+	defer cancelWatchers()
+
+	// Optionally record or replay LLM and tool HTTP traffic
+	transport, err := newCassette(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize client: %v", err)
+		log.Fatalf("Failed to initialize HTTP cassette: %v", err)
 	}
-	defer client.Close()
-
-	// Initialize LLM pipeline with integrated web search
-	llmPipeline := llm.NewOpenAIPipeline(cfg.OpenAIAPIKey, cfg.OpenAIModel)
-
-	// Initialize tool registry and register built-in tools
-	toolRegistry := tools.NewRegistry()
-
-	// NOTE: web_search is added manually in the LLM pipeline (openai.go)
-	// to ensure compatibility with the Responses API when mixing with custom function tools
-	// We use "web_search" type instead of "web_search_preview" for this purpose
 
-	// Create adapter for market data client
-	marketDataAdapter := &marketDataClientAdapter{client: client}
-
-	// Register market data tool
-	marketDataTool := tools.NewMarketDataTool(marketDataAdapter)
-	if err := toolRegistry.Register(marketDataTool); err != nil {
-		log.Fatalf("Failed to register market data tool: %v", err)
+	// Every analysis is recorded for later explanation and replay
+	auditStore, err := audit.NewFileStore(cfg.AuditDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize audit store: %v", err)
 	}
 
-	// Register calculator tool
-	calculatorTool := tools.NewCalculatorTool()
-	if err := toolRegistry.Register(calculatorTool); err != nil {
-		log.Fatalf("Failed to register calculator tool: %v", err)
+	// LLM spending is accounted across restarts and capped by the budgets
+	prices, err := usage.LoadPriceTable(cfg.PriceTableFile)
+	if err != nil {
+		log.Fatalf("Failed to load price table: %v", err)
 	}
-
-	// Register datetime tool
-	datetimeTool := tools.NewDateTimeTool()
-	if err := toolRegistry.Register(datetimeTool); err != nil {
-		log.Fatalf("Failed to register datetime tool: %v", err)
+	ledger, err := usage.NewLedger(prices, usage.Budget{
+		PerMarketUSD: cfg.BudgetPerMarketUSD,
+		PerDayUSD:    cfg.BudgetPerDayUSD,
+	}, cfg.UsageFile)
+	if err != nil {
+		log.Fatalf("Failed to initialize usage ledger: %v", err)
 	}
 
-	// Register BSCScan tool (if API key provided)
-	if cfg.BSCScanAPIKey != "" {
-		bscscanTool := tools.NewBSCScanTool(cfg.BSCScanAPIKey)
-		if err := toolRegistry.Register(bscscanTool); err != nil {
-			log.Fatalf("Failed to register bscscan tool: %v", err)
-		}
-		log.Printf("BSCScan tool registered")
-	} else {
-		log.Printf("BSCScan API key not provided, skipping BSCScan tool registration")
+	// Prompt templates are reloaded from PROMPTS_DIR on SIGHUP
+	prompts, err := loadPrompts(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load prompts: %v", err)
 	}
+	log.Printf("Loaded prompt set version %s", prompts.Version())
 
-	// Register PancakeSwap tool (adapter implements PancakeSwapClient interface)
-	pancakeswapAdapter := &pancakeswapClientAdapter{client: client}
-	pancakeswapTool := tools.NewPancakeSwapTool(pancakeswapAdapter)
-	if err := toolRegistry.Register(pancakeswapTool); err != nil {
-		log.Fatalf("Failed to register pancakeswap tool: %v", err)
+	// LLM calls from every chain share retries, limits and fallbacks
+	router, err := newRouter(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize LLM router: %v", err)
 	}
 
-	// Set the tool registry on the pipeline
-	llmPipeline.SetToolRegistry(&toolRegistryAdapter{registry: toolRegistry})
+	// The local document corpus, if any, is searched by every chain
+	documents, err := openCorpus(cfg, transport)
+	if err != nil {
+		log.Fatalf("Failed to open document corpus: %v", err)
+	}
 
-	log.Printf("Registered %d tools: %v", toolRegistry.Count(), func() []string {
-		names := make([]string, 0)
-		for _, tool := range toolRegistry.List() {
-			names = append(names, tool.Name())
+	// Initialize one resolver instance per chain profile
+	chains := make(map[int64]*chainInstance, len(cfg.Chains))
+	for _, profile := range cfg.Chains {
+		instance, err := newChainInstance(ctx, cfg, profile, transport, prompts, router, documents)
+		if err != nil {
+			log.Fatalf("Failed to initialize chain %s (%d): %v", profile.Name, profile.ChainID, err)
 		}
-		return names
-	}())
+		defer instance.Close()
+		chains[profile.ChainID] = instance
 
-	// Parse private key for signing
-	privateKey, err := crypto.HexToECDSA(cfg.SignerPrivateKey)
-	if err != nil {
-		log.Fatalf("Failed to parse private key: %v", err)
+		if instance.watcher != nil {
+			go instance.watcher.Run(ctx)
+		}
 	}
 
-	// Initialize EIP-712 signer
-	eip712Signer := eip712.NewSigner(big.NewInt(cfg.ChainID), common.HexToAddress(cfg.AIOracleAdapterAddr))
-
 	// Initialize server
 	srv := &Server{
-		config:     cfg,
-		client:     client,
-		llm:        llmPipeline,
-		signer:     eip712Signer,
-		privateKey: privateKey,
+		config: cfg,
+		chains: chains,
+		audit:  auditStore,
+		usage:  ledger,
 	}
 
 	// Create HTTP server
@@ -140,18 +121,30 @@ func main() {
 	// Start server in a goroutine
 	go func() {
 		log.Printf("Starting AI Resolver server on %s", httpServer.Addr)
-		log.Printf("Chain ID: %d, Signer: %s", cfg.ChainID, client.GetSignerAddress().Hex())
+		for _, chain := range cfg.Chains {
+			log.Printf("Chain %s (%d), Signer: %s", chain.Name, chain.ChainID, chains[chain.ChainID].client.GetSignerAddress().Hex())
+		}
 		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Server failed: %v", err)
 		}
 	}()
 
+	// Reload prompt templates without a restart
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reloadPrompts(cfg, chains)
+		}
+	}()
+
 	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("Shutting down server...")
+	cancelWatchers()
 
 	// Graceful shutdown with timeout
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -166,11 +159,10 @@ func main() {
 
 // Server holds the application state
 type Server struct {
-	config     *config.Config
-	client     *adapter.Client
-	llm        llm.Pipeline
-	signer     *eip712.Signer
-	privateKey *ecdsa.PrivateKey
+	config *config.Config
+	chains map[int64]*chainInstance
+	audit  audit.Store   // Optional; runs are not persisted when nil
+	usage  *usage.Ledger // Optional; LLM usage is not metered when nil
 }
 
 // routes sets up the HTTP routes
@@ -181,14 +173,71 @@ func (s *Server) routes() http.Handler {
 	mux.HandleFunc("/healthz", s.handleHealth)
 	mux.HandleFunc("/v1/healthz", s.handleHealth)
 
-	// API endpoints
+	// API endpoints (default chain)
 	mux.HandleFunc("/v1/propose", s.handlePropose)
+	mux.HandleFunc("/v1/analyze", s.handleAnalyze)
 	mux.HandleFunc("/v1/markets", s.handleMarkets)
+	mux.HandleFunc("/v1/runs", s.handleRuns)
+	mux.HandleFunc("/v1/usage", s.handleUsage)
+	mux.HandleFunc("/v1/questions/lint", s.handleLintQuestion)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+
+	// Chain-scoped API endpoints
+	mux.HandleFunc("/v1/{chainId}/healthz", s.handleHealth)
+	mux.HandleFunc("/v1/{chainId}/propose", s.handlePropose)
+	mux.HandleFunc("/v1/{chainId}/analyze", s.handleAnalyze)
+	mux.HandleFunc("/v1/{chainId}/markets", s.handleMarkets)
+	mux.HandleFunc("/v1/{chainId}/runs", s.handleRuns)
+	mux.HandleFunc("/v1/{chainId}/usage", s.handleUsage)
+	mux.HandleFunc("/v1/{chainId}/questions/lint", s.handleLintQuestion)
 
 	// Wrap with middleware
 	return s.corsMiddleware(s.loggingMiddleware(mux))
 }
 
+// chainFor resolves the chain addressed by the request path, falling back to
+// the default chain for unscoped routes
+func (s *Server) chainFor(r *http.Request) (*chainInstance, error) {
+	chainID := s.config.DefaultChainID
+	if raw := r.PathValue("chainId"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chainId %q", raw)
+		}
+		chainID = parsed
+	}
+
+	chain, ok := s.chains[chainID]
+	if !ok {
+		return nil, fmt.Errorf("chain %d is not configured", chainID)
+	}
+	return chain, nil
+}
+
+// recordChainFor resolves the chain whose records a request asks for: the
+// chain in the path, else the chainId query parameter, else the default
+// chain. Records may outlive a chain's profile, so an unconfigured chainId in
+// the query is accepted. On failure it writes the error response.
+func (s *Server) recordChainFor(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	if r.PathValue("chainId") != "" {
+		chain, err := s.chainFor(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return 0, false
+		}
+		return chain.profile.ChainID, true
+	}
+	if raw := r.URL.Query().Get("chainId"); raw != "" {
+		chainID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid chainId %q", raw), http.StatusBadRequest)
+			return 0, false
+		}
+		return chainID, true
+	}
+	return s.config.DefaultChainID, true
+}
+
 // handleHealth returns the health status
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -196,12 +245,31 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	chain, err := s.chainFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
 	response := map[string]any{
 		"status":  "healthy",
 		"version": "1.0.0",
 		"time":    time.Now().Unix(),
-		"signer":  s.client.GetSignerAddress().Hex(),
-		"chainId": s.client.GetChainID().Int64(),
+		"signer":  chain.client.GetSignerAddress().Hex(),
+		"chainId": chain.client.GetChainID().Int64(),
+	}
+
+	// Unscoped health checks also list every configured chain
+	if r.PathValue("chainId") == "" {
+		chains := make([]map[string]any, 0, len(s.config.Chains))
+		for _, profile := range s.config.Chains {
+			chains = append(chains, map[string]any{
+				"name":    profile.Name,
+				"chainId": profile.ChainID,
+				"signer":  s.chains[profile.ChainID].client.GetSignerAddress().Hex(),
+			})
+		}
+		response["chains"] = chains
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -223,6 +291,12 @@ func (s *Server) handlePropose(w http.ResponseWriter, r *http.Request) {
 		Metadata      string   `json:"metadata"`
 	}
 
+	chain, err := s.chainFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
@@ -242,53 +316,86 @@ func (s *Server) handlePropose(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	// Execute proposal pipeline
-	result, err := s.processProposal(ctx, req.MarketID, req.Question)
+	ctx, run := s.startRun(ctx, chain, req.MarketID, false)
+	if progress.Accepts(r) {
+		s.streamProposal(ctx, w, chain, run, req.MarketID, req.Question)
+		return
+	}
+	result, err := s.processProposal(ctx, chain, req.MarketID, req.Question)
+	s.finishRun(ctx, run, err)
 	if err != nil {
-		log.Printf("Failed to process proposal for market %d: %v", req.MarketID, err)
-		http.Error(w, fmt.Sprintf("Failed to process proposal: %v", err), http.StatusInternalServerError)
+		log.Printf("[%s] Failed to process proposal for market %d (run %s): %v", chain.profile.Name, req.MarketID, run.ID, err)
+		http.Error(w, fmt.Sprintf("Failed to process proposal (run %s): %v", run.ID, err), errorStatus(err))
 		return
 	}
+	result["runId"] = run.ID
+	result["usage"] = run.Usage
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(result)
 }
 
-// processProposal executes the full AI resolution pipeline
-// Updated: 2025-10-28 to accept question parameter
-func (s *Server) processProposal(ctx context.Context, marketID uint64, question string) (map[string]any, error) {
-	marketIDBig := big.NewInt(int64(marketID))
+// sseKeepAlive is how often a streamed proposal sends a keep-alive comment
+var sseKeepAlive = 15 * time.Second
+
+// streamProposal runs a proposal while streaming its progress as server-sent
+// events. The response is committed before the pipeline starts, so failures
+// arrive as an error event carrying the status the JSON endpoint would return.
+func (s *Server) streamProposal(ctx context.Context, w http.ResponseWriter, chain *chainInstance, run *audit.Run, marketID uint64, question string) {
+	sse := progress.NewSSEWriter(w)
+	reporter := progress.NewReporter(func(e progress.Event) {
+		if err := sse.Write(e); err != nil {
+			log.Printf("Warning: failed to stream %s event for run %s: %v", e.Type, run.ID, err)
+		}
+	})
+	ctx = progress.WithReporter(ctx, reporter)
 
-	// Step 1: Fetch market details
-	log.Printf("Fetching market %d details...", marketID)
-	market, err := s.client.GetMarket(ctx, marketIDBig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch market: %w", err)
-	}
+	// The keep-alive writer must stop before the handler returns, since the
+	// ResponseWriter may not be used after that
+	keepAliveCtx, stopKeepAlive := context.WithCancel(ctx)
+	keepAliveDone := make(chan struct{})
+	go func() {
+		defer close(keepAliveDone)
+		sse.KeepAlive(keepAliveCtx, sseKeepAlive)
+	}()
+	defer func() {
+		stopKeepAlive()
+		<-keepAliveDone
+	}()
 
-	// Log the closeTime from the contract
-	log.Printf("Market closeTime from contract: %s (%d)", market.CloseTime.String(), market.CloseTime.Int64())
-	log.Printf("Current time: %d", time.Now().Unix())
+	reporter.Emit(progress.Event{Type: progress.TypeRun, Status: progress.StatusStarted, Data: map[string]any{
+		"runId":    run.ID,
+		"chainId":  chain.profile.ChainID,
+		"marketId": marketID,
+	}})
 
-	// Build market info for LLM
-	marketInfo := llm.MarketInfo{
-		MarketID:     marketID,
-		Question:     question, // Use the question from the request
-		Description:  "",
-		Category:     market.Category,
-		CloseTime:    market.CloseTime.Int64(),
-		MetadataURI:  market.MetadataURI,
-		OutcomeCount: 2,
+	result, err := s.processProposal(ctx, chain, marketID, question)
+	s.finishRun(ctx, run, err)
+	if err != nil {
+		log.Printf("[%s] Failed to process proposal for market %d (run %s): %v", chain.profile.Name, marketID, run.ID, err)
+		reporter.Emit(progress.Event{Type: progress.TypeError, Status: progress.StatusFailed, Data: map[string]any{
+			"runId":   run.ID,
+			"status":  errorStatus(err),
+			"message": fmt.Sprintf("Failed to process proposal (run %s): %v", run.ID, err),
+		}})
+		return
 	}
-	log.Printf("Market: %s (Category: %s)", marketInfo.Question, marketInfo.Category)
+	result["runId"] = run.ID
+	result["usage"] = run.Usage
+	reporter.Emit(progress.Event{Type: progress.TypeResult, Status: progress.StatusCompleted, Data: result})
+}
 
-	// Step 2: Run LLM analysis with integrated web search
-	log.Printf("Running LLM multi-pass analysis with web search...")
-	decision, err := s.llm.AnalyzeMarket(ctx, marketInfo)
+// processProposal executes the full AI resolution pipeline
+// Updated: 2025-10-28 to accept question parameter
+func (s *Server) processProposal(ctx context.Context, chain *chainInstance, marketID uint64, question string) (map[string]any, error) {
+	marketIDBig := big.NewInt(int64(marketID))
+
+	// Steps 1-2: Fetch market details and run LLM analysis
+	market, decision, err := s.analyzeMarket(ctx, chain, marketID, question)
 	if err != nil {
-		return nil, fmt.Errorf("failed to analyze: %w", err)
+		return nil, err
 	}
-	log.Printf("LLM decision: outcomeId=%d, confidence=%.2f", decision.OutcomeID, decision.Confidence)
 
 	// Step 3: Prepare evidence hash and URIs
 	evidenceURIs := make([]string, 0, len(decision.Citations))
@@ -301,7 +408,7 @@ func (s *Server) processProposal(ctx context.Context, marketID uint64, question
 
 	// Step 4: Create proposal and sign
 	// IMPORTANT: Use blockchain timestamp instead of system time
-	blockchainTime, err := s.client.GetCurrentBlockTimestamp(ctx)
+	blockchainTime, err := chain.client.GetCurrentBlockTimestamp(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get blockchain timestamp: %w", err)
 	}
@@ -316,38 +423,81 @@ func (s *Server) processProposal(ctx context.Context, marketID uint64, question
 		CloseTime:    market.CloseTime,
 		EvidenceHash: evidenceHash,
 		NotBefore:    big.NewInt(now),
-		Deadline:     big.NewInt(now + 7200), // 2 hour validity to account for LLM processing time
+		Deadline:     big.NewInt(now + int64(time.Duration(chain.profile.ProposalValidity).Seconds())),
 	}
 
-	signature, err := s.signer.SignProposal(proposal, s.privateKey)
+	signature, err := chain.signer.SignProposal(proposal, chain.privateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign proposal: %w", err)
 	}
 	log.Printf("Signature: %x", signature)
+	progress.Emit(ctx, progress.Event{Type: progress.TypeSignature, Status: progress.StatusCompleted, Data: map[string]any{
+		"evidenceHash": "0x" + hex.EncodeToString(evidenceHash[:]),
+		"signature":    "0x" + hex.EncodeToString(signature),
+		"notBefore":    proposal.NotBefore.Int64(),
+		"deadline":     proposal.Deadline.Int64(),
+	}})
+
+	// Record what was signed before anything reaches the chain
+	record := audit.Proposal{
+		OutcomeID:    decision.OutcomeID,
+		CloseTime:    proposal.CloseTime.Int64(),
+		NotBefore:    proposal.NotBefore.Int64(),
+		Deadline:     proposal.Deadline.Int64(),
+		EvidenceURIs: evidenceURIs,
+		EvidenceHash: "0x" + hex.EncodeToString(evidenceHash[:]),
+		Signer:       chain.client.GetSignerAddress().Hex(),
+		Signature:    "0x" + hex.EncodeToString(signature),
+		BondAmount:   chain.bondAmount.String(),
+	}
+	if decision.Prompt != nil {
+		record.PromptVersion = decision.Prompt.Version
+		record.PromptHash = decision.Prompt.Hash
+	}
+	audit.FromContext(ctx).SetProposal(record)
+	if err := s.saveRun(ctx); err != nil {
+		return nil, fmt.Errorf("failed to save audit run before submitting: %w", err)
+	}
 
 	// Step 5: Check allowance and approve if needed
-	bondAmountBig := new(big.Int)
-	bondAmountBig.SetString(s.config.DefaultBondAmount, 10)
+	bondAmountBig := chain.bondAmount
 
-	allowance, err := s.client.CheckAllowance(ctx)
+	allowance, err := chain.client.CheckAllowance(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check allowance: %w", err)
 	}
 
 	if allowance.Cmp(bondAmountBig) < 0 {
 		log.Printf("Approving bond amount: %s", bondAmountBig.String())
-		approveTx, err := s.client.ApproveBond(ctx, bondAmountBig)
+		approveTx, err := chain.client.ApproveBond(ctx, bondAmountBig)
 		if err != nil {
 			return nil, fmt.Errorf("failed to approve bond: %w", err)
 		}
 		log.Printf("Approve tx: %s", approveTx.Hash().Hex())
+		progress.Emit(ctx, progress.Event{Type: progress.TypeApproval, Status: progress.StatusBroadcast, Data: map[string]any{
+			"txHash": approveTx.Hash().Hex(),
+			"amount": bondAmountBig.String(),
+		}})
 
 		// Wait for approval
-		_, err = s.client.WaitForTransaction(ctx, approveTx)
+		approveReceipt, err := chain.client.WaitForTransaction(ctx, approveTx)
 		if err != nil {
+			progress.Emit(ctx, progress.Event{Type: progress.TypeApproval, Status: progress.StatusFailed, Data: map[string]any{
+				"txHash": approveTx.Hash().Hex(),
+				"error":  err.Error(),
+			}})
 			return nil, fmt.Errorf("approval transaction failed: %w", err)
 		}
 		log.Printf("Approval confirmed")
+		progress.Emit(ctx, progress.Event{Type: progress.TypeApproval, Status: progress.StatusConfirmed, Data: map[string]any{
+			"txHash": approveTx.Hash().Hex(),
+			"block":  approveReceipt.BlockNumber.Uint64(),
+		}})
+	} else {
+		progress.Emit(ctx, progress.Event{Type: progress.TypeApproval, Status: progress.StatusSkipped, Data: map[string]any{
+			"allowance": allowance.String(),
+			"amount":    bondAmountBig.String(),
+		}})
 	}
 
 	// Step 6: Submit proposal
@@ -376,24 +526,42 @@ func (s *Server) processProposal(ctx context.Context, marketID uint64, question
 	log.Printf("EvidenceURIs: %v", evidenceURIs)
 	log.Printf("=========================================\n")
 
-	tx, err := s.client.ProposeOutcome(ctx, abiProposal, signature, bondAmountBig, evidenceURIs)
+	tx, err := chain.client.ProposeOutcome(ctx, abiProposal, signature, bondAmountBig, evidenceURIs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to submit proposal: %w", err)
 	}
 	log.Printf("Proposal tx: %s", tx.Hash().Hex())
+	progress.Emit(ctx, progress.Event{Type: progress.TypeTransaction, Status: progress.StatusBroadcast, Data: map[string]any{
+		"txHash": tx.Hash().Hex(),
+	}})
+
+	record.TxHash = tx.Hash().Hex()
+	audit.FromContext(ctx).SetProposal(record)
+	if err := s.saveRun(ctx); err != nil {
+		log.Printf("Warning: failed to save audit run with tx %s: %v", record.TxHash, err)
+	}
 
 	// Wait for confirmation (optional - could be async)
-	receipt, err := s.client.WaitForTransaction(ctx, tx)
+	receipt, err := chain.client.WaitForTransaction(ctx, tx)
 	if err != nil {
 		log.Printf("Warning: failed to wait for transaction: %v", err)
 		// Continue anyway - tx might still succeed
+		progress.Emit(ctx, progress.Event{Type: progress.TypeTransaction, Status: progress.StatusFailed, Data: map[string]any{
+			"txHash": tx.Hash().Hex(),
+			"error":  err.Error(),
+		}})
 	} else {
 		log.Printf("Proposal confirmed in block %d", receipt.BlockNumber.Uint64())
+		progress.Emit(ctx, progress.Event{Type: progress.TypeTransaction, Status: progress.StatusConfirmed, Data: map[string]any{
+			"txHash": tx.Hash().Hex(),
+			"block":  receipt.BlockNumber.Uint64(),
+		}})
 	}
 
 	// Return result
 	return map[string]any{
 		"status":       "submitted",
+		"chainId":      chain.profile.ChainID,
 		"marketId":     marketID,
 		"outcomeId":    decision.OutcomeID,
 		"confidence":   decision.Confidence,
@@ -402,9 +570,355 @@ func (s *Server) processProposal(ctx context.Context, marketID uint64, question
 		"evidenceHash": hex.EncodeToString(evidenceHash[:]),
 		"citations":    len(decision.Citations),
 		"facts":        len(decision.Facts),
+		"prompt":       decision.Prompt,
 	}, nil
 }
 
+// analyzeMarket fetches a market and runs the LLM pipeline on it without
+// signing or submitting anything
+func (s *Server) analyzeMarket(ctx context.Context, chain *chainInstance, marketID uint64, question string) (*adapter.MarketInfo, *llm.Decision, error) {
+	// Step 1: Fetch market details
+	log.Printf("[%s] Fetching market %d details...", chain.profile.Name, marketID)
+	market, err := chain.client.GetMarket(ctx, big.NewInt(int64(marketID)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch market: %w", err)
+	}
+
+	// Log the closeTime from the contract
+	log.Printf("Market closeTime from contract: %s (%d)", market.CloseTime.String(), market.CloseTime.Int64())
+	log.Printf("Current time: %d", time.Now().Unix())
+
+	// Build market info for LLM
+	marketInfo := llm.MarketInfo{
+		MarketID:     marketID,
+		Question:     question, // Use the question from the request
+		Description:  "",
+		Category:     market.Category,
+		CloseTime:    market.CloseTime.Int64(),
+		MetadataURI:  market.MetadataURI,
+		OutcomeCount: int(market.OutcomeCount),
+	}
+	if marketInfo.OutcomeCount == 0 {
+		marketInfo.OutcomeCount = 2
+	}
+	if window := lint.EventWindow(marketInfo.Question, marketInfo.Description); window != nil {
+		marketInfo.EventWindow = &llm.EventWindow{Start: window.Start, End: window.End}
+	}
+	if blockTime, err := chain.client.GetCurrentBlockTimestamp(ctx); err == nil {
+		marketInfo.BlockTime = blockTime
+	} else {
+		log.Printf("Warning: failed to read block time, using the local clock: %v", err)
+	}
+	log.Printf("Market: %s (Category: %s)", marketInfo.Question, marketInfo.Category)
+	audit.FromContext(ctx).SetMarket(marketInfo)
+	progress.Emit(ctx, progress.Event{Type: progress.TypeMarket, Status: progress.StatusCompleted, Data: map[string]any{"market": marketInfo}})
+
+	// Step 2: Run the analysis: price markets are answered from the chain,
+	// the rest by the LLM with integrated web search
+	log.Printf("Running LLM multi-pass analysis with web search...")
+	decision, err := chain.llm.AnalyzeMarket(ctx, marketInfo)
+	if err != nil {
+		// A decision the strategy's policy rejected is still recorded for review
+		var policyErr *llm.PolicyError
+		if errors.As(err, &policyErr) {
+			audit.FromContext(ctx).SetDecision(policyErr.Decision)
+		}
+		return nil, nil, fmt.Errorf("failed to analyze: %w", err)
+	}
+	log.Printf("LLM decision: outcomeId=%d, confidence=%.2f", decision.OutcomeID, decision.Confidence)
+	audit.FromContext(ctx).SetDecision(decision)
+	emitDecision(ctx, decision)
+
+	return market, decision, nil
+}
+
+// emitDecision reports the decision a proposal will be built from
+func emitDecision(ctx context.Context, decision *llm.Decision) {
+	progress.Emit(ctx, progress.Event{Type: progress.TypeDecision, Status: progress.StatusCompleted, Data: map[string]any{
+		"outcomeId":  decision.OutcomeID,
+		"confidence": decision.Confidence,
+		"reasoning":  decision.Reasoning,
+		"citations":  len(decision.Citations),
+		"facts":      len(decision.Facts),
+	}})
+}
+
+// handleAnalyze runs the analysis pipeline for a market without proposing
+func (s *Server) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		MarketID uint64 `json:"marketId"`
+		Question string `json:"question"`
+	}
+
+	chain, err := s.chainFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.MarketID == 0 {
+		http.Error(w, "marketId is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Question == "" {
+		http.Error(w, "question is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.ProposalTimeout)
+	defer cancel()
+
+	ctx, run := s.startRun(ctx, chain, req.MarketID, true)
+	_, decision, err := s.analyzeMarket(ctx, chain, req.MarketID, req.Question)
+	s.finishRun(ctx, run, err)
+
+	// A rejected decision is the answer to a dry run: it would not be proposed
+	var policyErr *llm.PolicyError
+	if errors.As(err, &policyErr) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"chainId":    chain.profile.ChainID,
+			"marketId":   req.MarketID,
+			"runId":      run.ID,
+			"decision":   policyErr.Decision,
+			"proposable": false,
+			"rejection":  policyErr.Reason,
+			"usage":      run.Usage,
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("[%s] Failed to analyze market %d (run %s): %v", chain.profile.Name, req.MarketID, run.ID, err)
+		http.Error(w, fmt.Sprintf("Failed to analyze market (run %s): %v", run.ID, err), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"chainId":    chain.profile.ChainID,
+		"marketId":   req.MarketID,
+		"runId":      run.ID,
+		"decision":   decision,
+		"proposable": true,
+		"usage":      run.Usage,
+	})
+}
+
+// startRun attaches a new audit run and a usage meter to ctx. dryRun marks
+// analyses that do not propose on-chain.
+func (s *Server) startRun(ctx context.Context, chain *chainInstance, marketID uint64, dryRun bool) (context.Context, *audit.Run) {
+	run := audit.NewRun(chain.profile.ChainID, marketID, s.config.OpenAIModel)
+	run.DryRun = dryRun
+	if s.usage != nil {
+		ctx = usage.WithMeter(ctx, s.usage.Meter(chain.profile.ChainID, marketID))
+	}
+	return audit.WithRun(ctx, run), run
+}
+
+// finishRun completes and persists a run with its usage. A failure to persist
+// is logged rather than returned, since the proposal may already be on-chain.
+func (s *Server) finishRun(ctx context.Context, run *audit.Run, err error) {
+	run.SetUsage(usage.FromContext(ctx).Summary())
+	run.Finish(err)
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.Save(run); err != nil {
+		log.Printf("Warning: failed to save audit run %s: %v", run.ID, err)
+	}
+}
+
+// saveRun persists the context's run while it is in progress, so that a
+// crash after signing or broadcasting still leaves its record
+func (s *Server) saveRun(ctx context.Context) error {
+	run := audit.FromContext(ctx)
+	if s.audit == nil || run == nil {
+		return nil
+	}
+	run.SetUsage(usage.FromContext(ctx).Summary())
+	return s.audit.Save(run)
+}
+
+// handleRuns returns audit records: one run by ?runId=, or every run of a
+// market by ?marketId= (and optional ?chainId=)
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.audit == nil {
+		http.Error(w, "Audit trail is disabled", http.StatusNotFound)
+		return
+	}
+
+	chainID, ok := s.recordChainFor(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	var response any
+	if runID := query.Get("runId"); runID != "" {
+		run, err := s.audit.Load(runID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		// Run IDs are unique across chains, but a chain-scoped route only
+		// serves its own chain's runs
+		if r.PathValue("chainId") != "" && run.ChainID != chainID {
+			http.Error(w, fmt.Sprintf("run %s is not on chain %d", runID, chainID), http.StatusNotFound)
+			return
+		}
+		response = run
+	} else {
+		marketID, err := strconv.ParseUint(query.Get("marketId"), 10, 64)
+		if err != nil {
+			http.Error(w, "runId or marketId is required", http.StatusBadRequest)
+			return
+		}
+		runs, err := s.audit.List(chainID, marketID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response = map[string]any{"chainId": chainID, "marketId": marketID, "runs": runs, "count": len(runs)}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// errorStatus maps a pipeline error to an HTTP status
+func errorStatus(err error) int {
+	if errors.Is(err, usage.ErrBudgetExceeded) {
+		return http.StatusTooManyRequests
+	}
+	if errors.Is(err, llm.ErrPolicyRejected) {
+		return http.StatusUnprocessableEntity
+	}
+	if errors.Is(err, pricefeed.ErrNotObservable) || errors.Is(err, llm.ErrEventNotEnded) || errors.Is(err, dependent.ErrParentPending) {
+		return http.StatusTooEarly
+	}
+	if errors.Is(err, llm.ErrUnavailable) || errors.Is(err, metadata.ErrUnavailable) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// handleUsage returns LLM usage and cost: the whole ledger, or one market's
+// totals by ?marketId= (and optional ?chainId=)
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.usage == nil {
+		http.Error(w, "Usage accounting is disabled", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	var response any
+	if raw := query.Get("marketId"); raw != "" {
+		marketID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid marketId %q", raw), http.StatusBadRequest)
+			return
+		}
+		chainID, ok := s.recordChainFor(w, r)
+		if !ok {
+			return
+		}
+		response = map[string]any{
+			"chainId":  chainID,
+			"marketId": marketID,
+			"budget":   s.usage.Budget(),
+			"usage":    s.usage.Market(chainID, marketID),
+		}
+	} else {
+		top := 20
+		if raw := query.Get("top"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				http.Error(w, fmt.Sprintf("invalid top %q", raw), http.StatusBadRequest)
+				return
+			}
+			top = n
+		}
+		response = s.usage.Report(top)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleMetrics serves LLM usage metrics in the Prometheus text format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if s.usage != nil {
+		s.usage.WritePrometheus(w)
+	}
+}
+
+// handleLintQuestion checks a draft question before market creation and
+// returns its issues with the resolution spec for the metadata document.
+// The model review runs on the default chain's pipeline unless "review" is
+// false.
+func (s *Server) handleLintQuestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := struct {
+		llm.QuestionDraft
+		Review *bool `json:"review"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	chain, err := s.chainFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	reviewer, _ := chain.llm.(lint.Reviewer)
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.ProposalTimeout)
+	defer cancel()
+	if s.usage != nil {
+		// Questions have no market yet; their usage is booked to market 0
+		ctx = usage.WithMeter(ctx, s.usage.Meter(chain.profile.ChainID, 0))
+	}
+
+	result, err := lint.NewLinter(reviewer).Lint(ctx, req.QuestionDraft, req.Review == nil || *req.Review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
 // handleMarkets returns pending markets
 func (s *Server) handleMarkets(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -412,12 +926,36 @@ func (s *Server) handleMarkets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Query pending markets from blockchain
-	// This would require additional contract methods to list markets by status
+	chain, err := s.chainFor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if chain.watcher == nil {
+		response := map[string]any{
+			"chainId": chain.profile.ChainID,
+			"markets": []any{},
+			"count":   0,
+			"message": "Market watcher is disabled for this chain (set watchInterval)",
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	pending, lastScan, scanErr := chain.watcher.Snapshot()
+	if pending == nil {
+		pending = []pendingMarket{}
+	}
 	response := map[string]any{
-		"markets": []any{},
-		"count":   0,
-		"message": "Market listing not yet implemented",
+		"chainId":  chain.profile.ChainID,
+		"markets":  pending,
+		"count":    len(pending),
+		"lastScan": lastScan.Unix(),
+	}
+	if scanErr != nil {
+		response["error"] = scanErr.Error()
 	}
 
 	w.Header().Set("Content-Type", "application/json")
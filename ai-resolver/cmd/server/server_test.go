@@ -0,0 +1,643 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/project-gamma/ai-resolver/internal/adapter"
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/config"
+	"github.com/project-gamma/ai-resolver/internal/jsontime"
+	"github.com/project-gamma/ai-resolver/internal/lint"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+	"github.com/project-gamma/ai-resolver/internal/llm/llmtest"
+	"github.com/project-gamma/ai-resolver/internal/progress"
+	"github.com/project-gamma/ai-resolver/internal/simchain"
+	"github.com/project-gamma/ai-resolver/internal/usage"
+	"github.com/project-gamma/ai-resolver/pkg/abi"
+)
+
+var (
+	testBond          = new(big.Int).Mul(big.NewInt(1_000), big.NewInt(1e18))
+	testCreatorStake  = new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+	testDisputeWindow = 2 * time.Hour
+)
+
+// testEnv is a resolver server running against a simulated chain and the
+// scripted OpenAI stand-in
+type testEnv struct {
+	chain      *simchain.Chain
+	deployment *simchain.Deployment
+	llm        *llmtest.Server
+	client     *adapter.Client
+	server     *Server
+	http       *httptest.Server
+}
+
+// newTestServer wires a Server to the given deployment on the simulated chain.
+// The chain instance is built the way the server builds it, with the OpenAI
+// API served by the scripted stand-in.
+func newTestServer(t *testing.T, chain *simchain.Chain, d *simchain.Deployment) *testEnv {
+	t.Helper()
+
+	llmServer := llmtest.NewServer()
+	t.Cleanup(llmServer.Close)
+
+	client, err := chain.NewAdapterClient(d, chain.Resolver)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	profile := config.ChainProfile{
+		Name:                 "sim",
+		ChainID:              chain.ChainID.Int64(),
+		SignerPrivateKey:     chain.Resolver.KeyHex(),
+		AIOracleAdapterAddr:  d.AIOracleAdapter.Hex(),
+		MarketFactoryAddr:    d.MarketFactory.Hex(),
+		ResolutionModuleAddr: d.ResolutionModule.Hex(),
+		TokenAddr:            d.Token.Hex(),
+		DefaultBondAmount:    testBond.String(),
+		ProposalValidity:     jsontime.Duration(time.Hour),
+	}
+	cfg := &config.Config{
+		OpenAIAPIKey:    "test-key",
+		OpenAIModel:     "test-model",
+		DefaultChainID:  profile.ChainID,
+		ProposalTimeout: time.Minute,
+		Chains:          []config.ChainProfile{profile},
+		// Loopback addresses are refused by the fetcher, so metadata
+		// lookups fail at once instead of reaching the network
+		IPFSGateway:     "http://127.0.0.1:1",
+		FetchTimeout:    time.Second,
+		FetchMaxBytes:   1 << 20,
+		FetchArchiveTTL: time.Minute,
+	}
+
+	prompts, err := loadPrompts(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance, err := newChainInstanceWithClient(cfg, profile, client, llmServer.Transport(nil), prompts, llm.NewRouter(nil, llmtest.Resilience), nil)
+	if err != nil {
+		t.Fatalf("failed to create chain instance: %v", err)
+	}
+
+	store, err := audit.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ledger, err := usage.NewLedger(usage.DefaultPrices(), usage.Budget{PerMarketUSD: 1}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Server{
+		config: cfg,
+		audit:  store,
+		usage:  ledger,
+		chains: map[int64]*chainInstance{profile.ChainID: instance},
+	}
+
+	httpServer := httptest.NewServer(s.routes())
+	t.Cleanup(httpServer.Close)
+
+	return &testEnv{chain: chain, deployment: d, llm: llmServer, client: client, server: s, http: httpServer}
+}
+
+// newProtocolEnv deploys the full protocol, skipping when bytecode is
+// unavailable unless SIMCHAIN_REQUIRED is set, as it is in CI
+func newProtocolEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	chain, err := simchain.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { chain.Close() })
+
+	d, err := chain.DeployProtocol(simchain.ProtocolOptions{
+		MinBond:         testBond,
+		MinCreatorStake: testCreatorStake,
+		DisputeWindow:   testDisputeWindow,
+	})
+	if errors.Is(err, simchain.ErrNoBytecode) && os.Getenv("SIMCHAIN_REQUIRED") == "" {
+		t.Skipf("protocol contracts unavailable: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("failed to deploy protocol: %v", err)
+	}
+
+	funding := new(big.Int).Mul(big.NewInt(10_000), big.NewInt(1e18))
+	if err := chain.Fund(d.TokenContract, funding, chain.Resolver.Address, chain.Disputer.Address, chain.Creator.Address); err != nil {
+		t.Fatal(err)
+	}
+
+	return newTestServer(t, chain, d)
+}
+
+// createClosedMarket creates a market and moves the chain past its close time
+func (e *testEnv) createClosedMarket(t *testing.T) uint64 {
+	t.Helper()
+
+	auth, err := e.chain.Transactor(e.chain.Creator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.chain.Mined(e.deployment.TokenContract.Approve(auth, e.deployment.MarketFactory, testCreatorStake)); err != nil {
+		t.Fatalf("failed to approve creator stake: %v", err)
+	}
+
+	marketID, err := e.deployment.FactoryContract.NextMarketId(&bind.CallOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now, err := e.chain.BlockTime(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeTime := new(big.Int).SetUint64(now + 3600)
+
+	if err := e.chain.Mined(e.deployment.FactoryContract.CreateMarket(auth, abi.MarketFactoryMarketParams{
+		MarketType:         0, // Binary
+		CollateralToken:    e.deployment.Token,
+		CloseTime:          closeTime,
+		Category:           "crypto",
+		MetadataURI:        "ipfs://market",
+		CreatorStake:       testCreatorStake,
+		OutcomeCount:       2,
+		LiquidityParameter: big.NewInt(0),
+	})); err != nil {
+		t.Fatalf("failed to create market: %v", err)
+	}
+
+	if err := e.chain.AdvanceTime(2 * time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.chain.Mined(e.deployment.FactoryContract.UpdateMarketStatus(auth, marketID)); err != nil {
+		t.Fatalf("failed to close market: %v", err)
+	}
+
+	return marketID.Uint64()
+}
+
+// scriptDecision queues a decision for outcomeID. Test markets are in the
+// crypto category, so the evidence comes from two annotated sources the
+// crypto-price strategy accepts.
+func (e *testEnv) scriptDecision(outcomeID uint64) {
+	sources := []llm.WebSource{
+		{URL: "https://www.coingecko.com/en/coins/bnb", Title: "BNB price"},
+		{URL: "https://coinmarketcap.com/currencies/bnb/", Title: "BNB"},
+	}
+	script := llmtest.Script(llm.Decision{
+		OutcomeID:  outcomeID,
+		Confidence: 0.9,
+		Reasoning:  "scripted",
+		Facts: []llm.Fact{
+			{Statement: "scripted fact", Sources: []string{sources[0].URL}, Confidence: 0.9},
+			{Statement: "scripted confirmation", Sources: []string{sources[1].URL}, Confidence: 0.9},
+		},
+	}, sources)
+	e.llm.QueueCited(script[0],
+		llmtest.Cite(script[0], "scripted fact", sources[0].URL, sources[0].Title),
+		llmtest.Cite(script[0], "scripted confirmation", sources[1].URL, sources[1].Title))
+	e.llm.Queue(script[1:]...)
+}
+
+// propose scripts the LLM and calls POST /v1/propose
+func (e *testEnv) propose(t *testing.T, marketID uint64, outcomeID uint64) map[string]any {
+	t.Helper()
+
+	e.scriptDecision(outcomeID)
+	body, _ := json.Marshal(map[string]any{"marketId": marketID, "question": "Will the scripted event happen?"})
+	resp, err := http.Post(e.http.URL+"/v1/propose", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]any
+	json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("propose returned %d", resp.StatusCode)
+	}
+	if prompt, _ := result["prompt"].(map[string]any); prompt["category"] != "crypto-price" {
+		t.Fatalf("expected the crypto-price strategy to resolve the market, got prompt %v", result["prompt"])
+	}
+	return result
+}
+
+func (e *testEnv) requireState(t *testing.T, marketID uint64, want adapter.ResolutionState) {
+	t.Helper()
+	state, err := e.client.GetResolutionState(context.Background(), new(big.Int).SetUint64(marketID))
+	if err != nil {
+		t.Fatalf("failed to get resolution state: %v", err)
+	}
+	if state != want {
+		t.Fatalf("expected state %s, got %s", want, state)
+	}
+}
+
+// TestHealthOnSimulatedChain tests default and chain-scoped routing
+func TestHealthOnSimulatedChain(t *testing.T) {
+	chain, err := simchain.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Close()
+
+	tokenAddr, _, err := chain.DeployToken(big.NewInt(1_000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := newTestServer(t, chain, &simchain.Deployment{Token: tokenAddr})
+
+	resp, err := http.Get(env.http.URL + "/v1/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var health map[string]any
+	json.NewDecoder(resp.Body).Decode(&health)
+	resp.Body.Close()
+
+	if health["signer"] != chain.Resolver.Address.Hex() {
+		t.Errorf("expected signer %s, got %v", chain.Resolver.Address.Hex(), health["signer"])
+	}
+	if int64(health["chainId"].(float64)) != chain.ChainID.Int64() {
+		t.Errorf("expected chain %s, got %v", chain.ChainID, health["chainId"])
+	}
+
+	resp, err = http.Get(env.http.URL + "/v1/999/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for unconfigured chain, got %d", resp.StatusCode)
+	}
+}
+
+// TestProposeAndFinalize tests create → close → propose → finalize
+func TestProposeAndFinalize(t *testing.T) {
+	env := newProtocolEnv(t)
+	ctx := context.Background()
+
+	marketID := env.createClosedMarket(t)
+	result := env.propose(t, marketID, 1)
+	if result["status"] != "submitted" {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	env.requireState(t, marketID, adapter.ResolutionProposed)
+
+	// The run is recorded with the submitted proposal
+	run, err := env.server.audit.Load(result["runId"].(string))
+	if err != nil {
+		t.Fatalf("failed to load audit run: %v", err)
+	}
+	if run.Proposal == nil || run.Proposal.TxHash != result["txHash"] || len(run.EventsOf(audit.EventLLMCall)) != 3 {
+		t.Errorf("unexpected audit run: %+v", run)
+	}
+
+	canFinalize, err := env.client.CanFinalize(ctx, new(big.Int).SetUint64(marketID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canFinalize {
+		t.Error("expected proposal to be in its dispute window")
+	}
+
+	if err := env.chain.AdvanceTime(testDisputeWindow + time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := env.client.Finalize(ctx, new(big.Int).SetUint64(marketID))
+	if err != nil {
+		t.Fatalf("failed to finalize: %v", err)
+	}
+	if _, err := env.client.WaitForTransaction(ctx, tx); err != nil {
+		t.Fatalf("finalize failed: %v", err)
+	}
+	env.requireState(t, marketID, adapter.ResolutionFinalized)
+
+	if env.llm.Remaining() != 0 {
+		t.Errorf("expected all scripted replies to be consumed, %d left", env.llm.Remaining())
+	}
+}
+
+// TestProposeAndDispute tests create → close → propose → dispute → arbitration
+func TestProposeAndDispute(t *testing.T) {
+	env := newProtocolEnv(t)
+
+	marketID := env.createClosedMarket(t)
+	env.propose(t, marketID, 1)
+	env.requireState(t, marketID, adapter.ResolutionProposed)
+
+	auth, err := env.chain.Transactor(env.chain.Disputer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.chain.Mined(env.deployment.TokenContract.Approve(auth, env.deployment.ResolutionModule, testBond)); err != nil {
+		t.Fatalf("failed to approve dispute bond: %v", err)
+	}
+	if err := env.chain.Mined(env.deployment.ResolutionContract.Dispute(auth, new(big.Int).SetUint64(marketID), testBond, "wrong outcome")); err != nil {
+		t.Fatalf("failed to dispute: %v", err)
+	}
+	env.requireState(t, marketID, adapter.ResolutionDisputed)
+
+	// The owner is the arbitrator and overturns the proposal
+	arbitrator, err := env.chain.Transactor(env.chain.Owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.chain.Mined(env.deployment.ResolutionContract.FinalizeDisputed(arbitrator, new(big.Int).SetUint64(marketID), big.NewInt(0), true)); err != nil {
+		t.Fatalf("failed to finalize dispute: %v", err)
+	}
+	env.requireState(t, marketID, adapter.ResolutionFinalized)
+}
+
+// TestProposeStream tests streaming a proposal's progress as server-sent events
+func TestProposeStream(t *testing.T) {
+	env := newProtocolEnv(t)
+	marketID := env.createClosedMarket(t)
+
+	env.scriptDecision(1)
+
+	body, _ := json.Marshal(map[string]any{"marketId": marketID, "question": "Will the scripted event happen?"})
+	req, _ := http.NewRequest(http.MethodPost, env.http.URL+"/v1/propose", bytes.NewReader(body))
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	var events []progress.Event
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			var e progress.Event
+			if err := json.Unmarshal([]byte(data), &e); err != nil {
+				t.Fatalf("invalid event %s: %v", data, err)
+			}
+			events = append(events, e)
+		}
+	}
+
+	var got []string
+	for i, e := range events {
+		if e.Seq != i+1 {
+			t.Errorf("expected event %d to have seq %d, got %d", i, i+1, e.Seq)
+		}
+		label := string(e.Type) + ":" + string(e.Status)
+		if e.Step != "" {
+			label += ":" + e.Step
+		}
+		got = append(got, label)
+	}
+	want := []string{
+		"run:started",
+		"market:completed",
+		"step:started:extract_facts",
+		"step:completed:extract_facts",
+		"step:started:check_contradictions",
+		"step:completed:check_contradictions",
+		"step:started:decide_outcome",
+		"step:completed:decide_outcome",
+		"step:completed:build_citations",
+		"decision:completed",
+		"signature:completed",
+		"approval:skipped",
+		"transaction:broadcast",
+		"transaction:confirmed",
+		"result:completed",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("unexpected events:\n got %v\nwant %v", got, want)
+	}
+
+	result := events[len(events)-1].Data
+	if result["status"] != "submitted" || result["runId"] != events[0].Data["runId"] {
+		t.Errorf("unexpected result %v", result)
+	}
+	env.requireState(t, marketID, adapter.ResolutionProposed)
+}
+
+// TestProposeStreamFailure tests that a failed streamed proposal ends with an
+// error event and that keep-alives stop before the handler returns. It needs
+// no protocol bytecode: the market lookup fails without a factory.
+func TestProposeStreamFailure(t *testing.T) {
+	chain, err := simchain.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Close()
+
+	interval := sseKeepAlive
+	sseKeepAlive = time.Millisecond
+	t.Cleanup(func() { sseKeepAlive = interval })
+
+	env := newTestServer(t, chain, &simchain.Deployment{})
+	instance := env.server.chains[chain.ChainID.Int64()]
+	ctx, run := env.server.startRun(context.Background(), instance, 1, false)
+
+	w := httptest.NewRecorder()
+	env.server.streamProposal(ctx, w, instance, run, 1, "Will the scripted event happen?")
+	stream := w.Body.String()
+
+	var events []progress.Event
+	for _, line := range strings.Split(stream, "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			var e progress.Event
+			if err := json.Unmarshal([]byte(data), &e); err != nil {
+				t.Fatalf("invalid event %s: %v", data, err)
+			}
+			events = append(events, e)
+		}
+	}
+	if len(events) != 2 || events[0].Type != progress.TypeRun || events[1].Type != progress.TypeError {
+		t.Fatalf("expected a run and an error event, got %+v", events)
+	}
+	if status, _ := events[1].Data["status"].(float64); status != http.StatusInternalServerError {
+		t.Errorf("expected the error event to carry status 500, got %v", events[1].Data["status"])
+	}
+
+	time.Sleep(20 * sseKeepAlive)
+	if w.Body.String() != stream {
+		t.Errorf("expected no writes after the handler returned, got:\n%s", strings.TrimPrefix(w.Body.String(), stream))
+	}
+}
+
+// TestRunsEndpoint tests looking up audit runs by ID and by market
+func TestRunsEndpoint(t *testing.T) {
+	chain, err := simchain.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Close()
+
+	env := newTestServer(t, chain, &simchain.Deployment{})
+	run := audit.NewRun(chain.ChainID.Int64(), 42, "test-model")
+	run.Finish(nil)
+	if err := env.server.audit.Save(run); err != nil {
+		t.Fatal(err)
+	}
+
+	var byID audit.Run
+	resp, err := http.Get(env.http.URL + "/v1/runs?runId=" + run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.NewDecoder(resp.Body).Decode(&byID)
+	resp.Body.Close()
+	if byID.ID != run.ID || byID.MarketID != 42 {
+		t.Errorf("unexpected run %s for market %d", byID.ID, byID.MarketID)
+	}
+
+	var byMarket struct {
+		Count int `json:"count"`
+	}
+	resp, err = http.Get(env.http.URL + "/v1/runs?marketId=42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.NewDecoder(resp.Body).Decode(&byMarket)
+	resp.Body.Close()
+	if byMarket.Count != 1 {
+		t.Errorf("expected 1 run for the market, got %d", byMarket.Count)
+	}
+
+	resp, err = http.Get(env.http.URL + "/v1/runs?runId=missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown run, got %d", resp.StatusCode)
+	}
+
+	// Chain-scoped routes serve only their own chain's runs
+	other := audit.NewRun(chain.ChainID.Int64()+1, 42, "test-model")
+	other.Finish(nil)
+	if err := env.server.audit.Save(other); err != nil {
+		t.Fatal(err)
+	}
+	scoped := fmt.Sprintf("%s/v1/%d/runs", env.http.URL, chain.ChainID.Int64())
+	for url, want := range map[string]int{
+		scoped + "?runId=" + run.ID:               http.StatusOK,
+		scoped + "?runId=" + other.ID:             http.StatusNotFound,
+		scoped + "?marketId=42":                   http.StatusOK,
+		env.http.URL + "/v1/999/runs?marketId=42": http.StatusNotFound,
+	} {
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%s: expected %d, got %d", url, want, resp.StatusCode)
+		}
+	}
+}
+
+// TestUsageEndpoints tests the usage report and the Prometheus metrics
+func TestUsageEndpoints(t *testing.T) {
+	chain, err := simchain.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Close()
+
+	env := newTestServer(t, chain, &simchain.Deployment{})
+	meter := env.server.usage.Meter(chain.ChainID.Int64(), 42)
+	meter.Record("decide_outcome", "gpt-4o", usage.Tokens{Input: 1_000_000})
+
+	var market struct {
+		Usage usage.Totals `json:"usage"`
+	}
+	resp, err := http.Get(env.http.URL + "/v1/usage?marketId=42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.NewDecoder(resp.Body).Decode(&market)
+	resp.Body.Close()
+	if market.Usage.Calls != 1 || market.Usage.CostUSD != 2.5 {
+		t.Errorf("unexpected market usage: %+v", market.Usage)
+	}
+
+	// The $2.50 call spent the $1 market budget
+	if err := meter.Check(); !errors.Is(err, usage.ErrBudgetExceeded) || errorStatus(err) != http.StatusTooManyRequests {
+		t.Errorf("expected a budget error mapped to 429, got %v", err)
+	}
+
+	resp, err = http.Get(env.http.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	for _, want := range []string{
+		`resolver_llm_cost_usd_total{by="model",model="gpt-4o"} 2.5`,
+		`resolver_llm_budget_usd{scope="market"} 1`,
+	} {
+		if !strings.Contains(string(metrics), want) {
+			t.Errorf("expected %q in metrics:\n%s", want, metrics)
+		}
+	}
+}
+
+// TestLintEndpoint tests question linting with and without the model review
+func TestLintEndpoint(t *testing.T) {
+	chain, err := simchain.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Close()
+
+	env := newTestServer(t, chain, &simchain.Deployment{})
+	lintQuestion := func(body string) (*lint.Result, int) {
+		t.Helper()
+		resp, err := http.Post(env.http.URL+"/v1/questions/lint", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var result lint.Result
+		json.NewDecoder(resp.Body).Decode(&result)
+		return &result, resp.StatusCode
+	}
+
+	result, status := lintQuestion(`{"question": "Will Team A win the final tomorrow?", "review": false}`)
+	if status != http.StatusOK || result.OK || result.Reviewed || env.llm.Remaining() != 0 || len(env.llm.Requests()) != 0 {
+		t.Errorf("expected a failing rules-only result, got %d: %+v", status, result)
+	}
+
+	env.llm.Queue(`{"issues": [], "normalizedQuestion": "Will Team A win the final on 2025-05-31?", "criteria": "Yes if Team A wins.", "sources": ["uefa.com"], "outcomes": []}`)
+	result, status = lintQuestion(`{"question": "Will Team A win the final on 2025-05-31 at 20:00 UTC?", "sources": ["uefa.com"]}`)
+	if status != http.StatusOK || !result.OK || !result.Reviewed || result.Document.Resolution.Criteria != "Yes if Team A wins." {
+		t.Errorf("expected a clean reviewed result, got %d: %+v", status, result)
+	}
+	if market := env.server.usage.Market(chain.ChainID.Int64(), 0); market.Calls != 1 {
+		t.Errorf("expected the review metered, got %+v", market)
+	}
+
+	if _, status := lintQuestion(`{"question": " "}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for an empty question, got %d", status)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/adapter"
+)
+
+// watchPageSize is the number of markets fetched per MarketFactory.getMarkets call
+const watchPageSize = 100
+
+// pendingMarket is a closed market that has no resolution proposal yet
+type pendingMarket struct {
+	MarketID    uint64 `json:"marketId"`
+	Category    string `json:"category"`
+	CloseTime   int64  `json:"closeTime"`
+	MetadataURI string `json:"metadataUri"`
+}
+
+// marketWatcher periodically scans one chain for markets awaiting resolution
+type marketWatcher struct {
+	name     string
+	client   *adapter.Client
+	interval time.Duration
+
+	mu       sync.RWMutex
+	pending  []pendingMarket
+	lastScan time.Time
+	lastErr  error
+}
+
+// newMarketWatcher creates a watcher for the given chain client
+func newMarketWatcher(name string, client *adapter.Client, interval time.Duration) *marketWatcher {
+	return &marketWatcher{
+		name:     name,
+		client:   client,
+		interval: interval,
+	}
+}
+
+// Run scans immediately and then on every tick until ctx is cancelled
+func (w *marketWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh runs a scan and stores its result
+func (w *marketWatcher) refresh(ctx context.Context) {
+	pending, err := w.scan(ctx)
+	if err != nil {
+		log.Printf("[%s] Market scan failed: %v", w.name, err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastScan = time.Now()
+	w.lastErr = err
+	if err == nil {
+		w.pending = pending
+	}
+}
+
+// scan pages through all markets and collects closed ones with no proposal
+func (w *marketWatcher) scan(ctx context.Context) ([]pendingMarket, error) {
+	pending := make([]pendingMarket, 0)
+
+	for offset := uint64(0); ; offset += watchPageSize {
+		markets, err := w.client.ListMarkets(ctx, offset, watchPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, market := range markets {
+			if market.Status != adapter.MarketStatusClosed {
+				continue
+			}
+
+			state, err := w.client.GetResolutionState(ctx, market.ID)
+			if err != nil {
+				return nil, err
+			}
+			if state != adapter.ResolutionNone {
+				continue
+			}
+
+			pending = append(pending, pendingMarket{
+				MarketID:    market.ID.Uint64(),
+				Category:    market.Category,
+				CloseTime:   market.CloseTime.Int64(),
+				MetadataURI: market.MetadataURI,
+			})
+		}
+
+		if len(markets) < watchPageSize {
+			return pending, nil
+		}
+	}
+}
+
+// Snapshot returns the markets found by the most recent successful scan
+func (w *marketWatcher) Snapshot() ([]pendingMarket, time.Time, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.pending, w.lastScan, w.lastErr
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/project-gamma/ai-resolver/internal/config"
+	"github.com/project-gamma/ai-resolver/internal/httprec"
 	"github.com/project-gamma/ai-resolver/internal/llm"
 	"github.com/project-gamma/ai-resolver/internal/tools"
 )
@@ -108,7 +109,8 @@ func (t *simpleTool) Execute(ctx context.Context, arguments map[string]any) (map
 }
 
 func main() {
-	fmt.Println("=== AI Resolver End-to-End Test ===\n")
+	fmt.Println("=== AI Resolver End-to-End Test ===")
+	fmt.Println()
 
 	// Load config
 	cfg, err := config.LoadFromEnv()
@@ -116,7 +118,7 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	if cfg.OpenAIAPIKey == "" {
+	if cfg.OpenAIAPIKey == "" && (cfg.HTTPCassette == "" || cfg.HTTPCassetteMode != "replay") {
 		log.Fatal("OPENAI_API_KEY not set in environment")
 	}
 
@@ -139,16 +141,37 @@ func main() {
 	registry.Register(&simpleTool{tool: marketDataTool})
 	fmt.Println("✓ Registered market data tool")
 
-	// Register web search tool
-	webSearchTool := tools.NewWebSearchTool()
-	registry.Register(&simpleTool{tool: webSearchTool})
-	fmt.Println("✓ Registered web search tool")
+	// Register web search tool (if a search provider is configured)
+	if cfg.SearchProvider != "" {
+		provider, err := tools.NewSearchProvider(cfg.SearchProvider, cfg.SearchURL, cfg.SearchAPIKey)
+		if err != nil {
+			log.Fatalf("Invalid SEARCH_PROVIDER: %v", err)
+		}
+		webSearchTool := tools.NewWebSearchTool(provider)
+		webSearchTool.SetDefaultLimit(cfg.MaxSearchResults)
+		registry.Register(&simpleTool{tool: webSearchTool})
+		fmt.Printf("✓ Registered web search tool (%s)\n", provider.Name())
+	}
 
 	fmt.Printf("\nTotal tools registered: %d\n\n", len(registry.List()))
 
 	// Create LLM pipeline
 	pipeline := llm.NewOpenAIPipeline(cfg.OpenAIAPIKey, "gpt-4o")
 	pipeline.SetToolRegistry(registry)
+
+	// HTTP_CASSETTE records this run (HTTP_CASSETTE_MODE=record) or replays one
+	if cfg.HTTPCassette != "" {
+		mode, err := httprec.ParseMode(cfg.HTTPCassetteMode)
+		if err != nil {
+			log.Fatal(err)
+		}
+		recorder, err := httprec.New(cfg.HTTPCassette, mode, nil)
+		if err != nil {
+			log.Fatalf("Failed to open cassette: %v", err)
+		}
+		pipeline.SetTransport(recorder)
+		fmt.Printf("✓ Using HTTP cassette %s (%s)\n", cfg.HTTPCassette, mode)
+	}
 	fmt.Println("✓ Created LLM pipeline with tool registry")
 
 	// Test 1: Simple market question that requires calculation
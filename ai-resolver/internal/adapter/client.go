@@ -5,8 +5,10 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -15,9 +17,17 @@ import (
 	"github.com/project-gamma/ai-resolver/pkg/abi"
 )
 
+// Backend is the subset of the Ethereum RPC API used by Client. It is satisfied
+// by *ethclient.Client and by go-ethereum's simulated backend client.
+type Backend interface {
+	bind.ContractBackend
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
 // Client wraps Ethereum client and contract bindings
 type Client struct {
-	eth        *ethclient.Client
+	eth        Backend
+	rpc        *ethclient.Client // nil when created with NewClientWithBackend
 	chainID    *big.Int
 	signer     *ecdsa.PrivateKey
 	signerAddr common.Address
@@ -39,7 +49,7 @@ type Client struct {
 type Config struct {
 	RPCURL            string
 	ChainID           int64
-	SignerPrivateKey  string
+	SignerPrivateKey  string // Optional: without a key the client is read-only
 	AdapterAddress    string
 	FactoryAddress    string
 	ResolutionAddress string
@@ -54,13 +64,30 @@ func NewClient(ctx context.Context, cfg Config) (*Client, error) {
 		return nil, fmt.Errorf("failed to connect to Ethereum node: %w", err)
 	}
 
-	// Parse private key
-	privateKey, err := crypto.HexToECDSA(cfg.SignerPrivateKey)
+	client, err := NewClientWithBackend(eth, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
+		eth.Close()
+		return nil, err
+	}
+	client.rpc = eth
+	return client, nil
+}
+
+// NewClientWithBackend creates a contract client on an existing backend, such as
+// a simulated chain in tests. cfg.RPCURL is ignored and GetETHClient returns nil.
+func NewClientWithBackend(eth Backend, cfg Config) (*Client, error) {
+	// Parse private key
+	var privateKey *ecdsa.PrivateKey
+	var signerAddr common.Address
+	if cfg.SignerPrivateKey != "" {
+		var err error
+		privateKey, err = crypto.HexToECDSA(cfg.SignerPrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		signerAddr = crypto.PubkeyToAddress(privateKey.PublicKey)
 	}
 
-	signerAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
 	chainID := big.NewInt(cfg.ChainID)
 
 	// Parse contract addresses
@@ -108,23 +135,35 @@ func NewClient(ctx context.Context, cfg Config) (*Client, error) {
 
 // Close closes the Ethereum client connection
 func (c *Client) Close() {
-	c.eth.Close()
+	if c.rpc != nil {
+		c.rpc.Close()
+	}
 }
 
 // MarketInfo holds market details
 type MarketInfo struct {
 	ID              *big.Int
 	Creator         common.Address
+	MarketType      uint8 // MarketFactory.MarketType: binary, multi-choice, limit order or pooled liquidity
 	AMM             common.Address
 	CollateralToken common.Address
 	CloseTime       *big.Int
 	Category        string
 	MetadataURI     string
 	CreatorStake    *big.Int
+	OutcomeCount    uint8
 	StakeRefunded   bool
 	Status          uint8
 }
 
+// Market status values mirror MarketFactory.MarketStatus
+const (
+	MarketStatusActive uint8 = iota
+	MarketStatusClosed
+	MarketStatusResolved
+	MarketStatusInvalid
+)
+
 // GetMarket fetches market details from the factory
 func (c *Client) GetMarket(ctx context.Context, marketID *big.Int) (*MarketInfo, error) {
 	market, err := c.factory.GetMarket(&bind.CallOpts{Context: ctx}, marketID)
@@ -135,17 +174,222 @@ func (c *Client) GetMarket(ctx context.Context, marketID *big.Int) (*MarketInfo,
 	return &MarketInfo{
 		ID:              market.Id,
 		Creator:         market.Creator,
+		MarketType:      market.MarketType,
 		AMM:             market.Amm,
 		CollateralToken: market.CollateralToken,
 		CloseTime:       market.CloseTime,
 		Category:        market.Category,
 		MetadataURI:     market.MetadataURI,
 		CreatorStake:    market.CreatorStake,
+		OutcomeCount:    market.OutcomeCount,
 		StakeRefunded:   market.StakeRefunded,
 		Status:          market.Status,
 	}, nil
 }
 
+// ListMarkets fetches a page of markets from the factory in creation order
+func (c *Client) ListMarkets(ctx context.Context, offset, limit uint64) ([]*MarketInfo, error) {
+	markets, err := c.factory.GetMarkets(&bind.CallOpts{Context: ctx}, new(big.Int).SetUint64(offset), new(big.Int).SetUint64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list markets: %w", err)
+	}
+
+	result := make([]*MarketInfo, 0, len(markets))
+	for _, market := range markets {
+		result = append(result, &MarketInfo{
+			ID:              market.Id,
+			Creator:         market.Creator,
+			MarketType:      market.MarketType,
+			AMM:             market.Amm,
+			CollateralToken: market.CollateralToken,
+			CloseTime:       market.CloseTime,
+			Category:        market.Category,
+			MetadataURI:     market.MetadataURI,
+			CreatorStake:    market.CreatorStake,
+			OutcomeCount:    market.OutcomeCount,
+			StakeRefunded:   market.StakeRefunded,
+			Status:          market.Status,
+		})
+	}
+	return result, nil
+}
+
+// ResolutionState mirrors ResolutionModule.ResolutionState
+type ResolutionState uint8
+
+const (
+	ResolutionNone ResolutionState = iota
+	ResolutionProposed
+	ResolutionDisputed
+	ResolutionFinalized
+)
+
+// String returns the Solidity enum name
+func (s ResolutionState) String() string {
+	switch s {
+	case ResolutionNone:
+		return "None"
+	case ResolutionProposed:
+		return "Proposed"
+	case ResolutionDisputed:
+		return "Disputed"
+	case ResolutionFinalized:
+		return "Finalized"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(s))
+	}
+}
+
+// GetResolutionState fetches the resolution state of a market
+func (c *Client) GetResolutionState(ctx context.Context, marketID *big.Int) (ResolutionState, error) {
+	state, err := c.resolutionMod.GetResolutionState(&bind.CallOpts{Context: ctx}, marketID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get resolution state: %w", err)
+	}
+	return ResolutionState(state), nil
+}
+
+// Resolution holds the ResolutionModule record for a market
+type Resolution struct {
+	State           ResolutionState
+	ProposedOutcome *big.Int
+	ProposalTime    *big.Int
+	Proposer        common.Address
+	ProposerBond    *big.Int
+	Disputer        common.Address
+	DisputerBond    *big.Int
+	EvidenceURI     string
+}
+
+// GetResolution fetches the full resolution record for a market
+func (c *Client) GetResolution(ctx context.Context, marketID *big.Int) (*Resolution, error) {
+	res, err := c.resolutionMod.Resolutions(&bind.CallOpts{Context: ctx}, marketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resolution: %w", err)
+	}
+
+	return &Resolution{
+		State:           ResolutionState(res.State),
+		ProposedOutcome: res.ProposedOutcome,
+		ProposalTime:    res.ProposalTime,
+		Proposer:        res.Proposer,
+		ProposerBond:    res.ProposerBond,
+		Disputer:        res.Disputer,
+		DisputerBond:    res.DisputerBond,
+		EvidenceURI:     res.EvidenceURI,
+	}, nil
+}
+
+// outcomeTokenABI is the OutcomeToken view the client reads; the token has no
+// generated binding
+const outcomeTokenABI = `[{"inputs":[{"name":"marketId","type":"uint256"}],"name":"winningOutcome","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+var parsedOutcomeTokenABI = func() ethabi.ABI {
+	parsed, err := ethabi.JSON(strings.NewReader(outcomeTokenABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// unresolvedOutcome is OutcomeToken.UNRESOLVED, the winning outcome of a
+// market that is not finalized
+var unresolvedOutcome = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// GetWinningOutcome fetches a finalized market's winning outcome from the
+// OutcomeToken. After a dispute it is the arbitrator's ruling, which the
+// resolution record's proposed outcome does not reflect.
+func (c *Client) GetWinningOutcome(ctx context.Context, marketID *big.Int) (*big.Int, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	tokenAddr, err := c.resolutionMod.OutcomeToken(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outcome token address: %w", err)
+	}
+
+	var out []any
+	token := bind.NewBoundContract(tokenAddr, parsedOutcomeTokenABI, c.eth, nil, nil)
+	if err := token.Call(opts, &out, "winningOutcome", marketID); err != nil {
+		return nil, fmt.Errorf("failed to get winning outcome: %w", err)
+	}
+	outcome := out[0].(*big.Int)
+	if outcome.Cmp(unresolvedOutcome) == 0 {
+		return nil, fmt.Errorf("market %s has no winning outcome", marketID)
+	}
+	return outcome, nil
+}
+
+// GetDisputeTimeRemaining returns the seconds left in a market's dispute window
+func (c *Client) GetDisputeTimeRemaining(ctx context.Context, marketID *big.Int) (*big.Int, error) {
+	remaining, err := c.resolutionMod.GetDisputeTimeRemaining(&bind.CallOpts{Context: ctx}, marketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dispute time remaining: %w", err)
+	}
+	return remaining, nil
+}
+
+// CanFinalize reports whether a market's proposal can be finalized now
+func (c *Client) CanFinalize(ctx context.Context, marketID *big.Int) (bool, error) {
+	ok, err := c.resolutionMod.CanFinalize(&bind.CallOpts{Context: ctx}, marketID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check finalization: %w", err)
+	}
+	return ok, nil
+}
+
+// Finalize finalizes an undisputed proposal after the dispute window
+func (c *Client) Finalize(ctx context.Context, marketID *big.Int) (*types.Transaction, error) {
+	auth, err := c.newTransactor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := c.resolutionMod.Finalize(auth, marketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finalize: %w", err)
+	}
+
+	return tx, nil
+}
+
+// IsAllowedSigner reports whether an address may sign AI proposals
+func (c *Client) IsAllowedSigner(ctx context.Context, signer common.Address) (bool, error) {
+	allowed, err := c.adapter.AllowedSigners(&bind.CallOpts{Context: ctx}, signer)
+	if err != nil {
+		return false, fmt.Errorf("failed to check signer: %w", err)
+	}
+	return allowed, nil
+}
+
+// AddSigner allows an address to sign AI proposals (adapter owner only)
+func (c *Client) AddSigner(ctx context.Context, signer common.Address) (*types.Transaction, error) {
+	auth, err := c.newTransactor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := c.adapter.AddSigner(auth, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add signer: %w", err)
+	}
+
+	return tx, nil
+}
+
+// RemoveSigner revokes an address's permission to sign AI proposals (adapter owner only)
+func (c *Client) RemoveSigner(ctx context.Context, signer common.Address) (*types.Transaction, error) {
+	auth, err := c.newTransactor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := c.adapter.RemoveSigner(auth, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove signer: %w", err)
+	}
+
+	return tx, nil
+}
+
 // CheckAllowance checks if the adapter has sufficient token allowance
 func (c *Client) CheckAllowance(ctx context.Context) (*big.Int, error) {
 	allowance, err := c.token.Allowance(&bind.CallOpts{Context: ctx}, c.signerAddr, c.adapterAddr)
@@ -230,6 +474,11 @@ func (c *Client) GetChainID() *big.Int {
 	return c.chainID
 }
 
+// GetResolutionAddress returns the ResolutionModule address
+func (c *Client) GetResolutionAddress() common.Address {
+	return c.resolutionAddr
+}
+
 // GetCurrentBlockTimestamp fetches the current blockchain timestamp
 func (c *Client) GetCurrentBlockTimestamp(ctx context.Context) (int64, error) {
 	header, err := c.eth.HeaderByNumber(ctx, nil)
@@ -241,6 +490,10 @@ func (c *Client) GetCurrentBlockTimestamp(ctx context.Context) (int64, error) {
 
 // newTransactor creates a new transaction signer
 func (c *Client) newTransactor(ctx context.Context) (*bind.TransactOpts, error) {
+	if c.signer == nil {
+		return nil, fmt.Errorf("client is read-only: no signer private key configured")
+	}
+
 	nonce, err := c.eth.PendingNonceAt(ctx, c.signerAddr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get nonce: %w", err)
@@ -265,7 +518,8 @@ func (c *Client) newTransactor(ctx context.Context) (*bind.TransactOpts, error)
 	return auth, nil
 }
 
-// GetETHClient returns the underlying ethclient.Client
+// GetETHClient returns the underlying ethclient.Client, or nil when the client
+// was created with NewClientWithBackend
 func (c *Client) GetETHClient() *ethclient.Client {
-	return c.eth
+	return c.rpc
 }
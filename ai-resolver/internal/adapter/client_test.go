@@ -0,0 +1,118 @@
+package adapter_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/project-gamma/ai-resolver/internal/adapter"
+	"github.com/project-gamma/ai-resolver/internal/simchain"
+)
+
+// TestBondTokenOnSimulatedChain tests balance, approval and block time reads
+// against the Token contract deployed from pkg/abi
+func TestBondTokenOnSimulatedChain(t *testing.T) {
+	chain, err := simchain.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Close()
+
+	supply := big.NewInt(1_000_000)
+	tokenAddr, token, err := chain.DeployToken(supply)
+	if err != nil {
+		t.Fatalf("failed to deploy token: %v", err)
+	}
+	if err := chain.Fund(token, big.NewInt(5_000), chain.Resolver.Address); err != nil {
+		t.Fatal(err)
+	}
+
+	// The adapter address only receives an allowance here, so any address works
+	deployment := &simchain.Deployment{
+		Token:           tokenAddr,
+		AIOracleAdapter: common.HexToAddress("0x00000000000000000000000000000000000000aa"),
+	}
+	client, err := chain.NewAdapterClient(deployment, chain.Resolver)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	if client.GetSignerAddress() != chain.Resolver.Address {
+		t.Errorf("expected signer %s, got %s", chain.Resolver.Address.Hex(), client.GetSignerAddress().Hex())
+	}
+	if client.GetETHClient() != nil {
+		t.Error("expected no ethclient for a client created from a backend")
+	}
+
+	balance, err := client.GetBalance(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if balance.Cmp(big.NewInt(5_000)) != 0 {
+		t.Errorf("expected balance 5000, got %s", balance)
+	}
+
+	tx, err := client.ApproveBond(ctx, big.NewInt(1_234))
+	if err != nil {
+		t.Fatalf("failed to approve: %v", err)
+	}
+	if _, err := client.WaitForTransaction(ctx, tx); err != nil {
+		t.Fatalf("approval failed: %v", err)
+	}
+
+	allowance, err := client.CheckAllowance(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if allowance.Cmp(big.NewInt(1_234)) != 0 {
+		t.Errorf("expected allowance 1234, got %s", allowance)
+	}
+
+	before, err := client.GetCurrentBlockTimestamp(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := chain.AdvanceTime(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	after, err := client.GetCurrentBlockTimestamp(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if after-before < int64(time.Hour.Seconds()) {
+		t.Errorf("expected block time to advance by an hour, got %d -> %d", before, after)
+	}
+}
+
+// TestReadOnlyClient tests that a client without a key refuses to transact
+func TestReadOnlyClient(t *testing.T) {
+	chain, err := simchain.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Close()
+
+	tokenAddr, _, err := chain.DeployToken(big.NewInt(1_000))
+	if err != nil {
+		t.Fatalf("failed to deploy token: %v", err)
+	}
+
+	client, err := adapter.NewClientWithBackend(chain.Client, adapter.Config{
+		ChainID:      chain.ChainID.Int64(),
+		TokenAddress: tokenAddr.Hex(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = client.ApproveBond(context.Background(), big.NewInt(1))
+	if err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("expected read-only error, got %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+// Package audit records everything that went into a resolution: the market,
+// every prompt and model parameter, raw API responses, tool invocations and
+// results, the decision and the on-chain proposal. Records are keyed by chain,
+// market and run ID so a proposal can be explained and replayed later.
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/usage"
+)
+
+// EventKind identifies what an Event records
+type EventKind string
+
+const (
+	EventLLMCall  EventKind = "llm_call"
+	EventToolCall EventKind = "tool_call"
+)
+
+// Event is one LLM request/response or tool invocation, in execution order
+type Event struct {
+	Seq        int       `json:"seq"`
+	Kind       EventKind `json:"kind"`
+	Step       string    `json:"step,omitempty"`
+	Time       time.Time `json:"time"`
+	DurationMs int64     `json:"durationMs"`
+
+	// LLM calls
+	Endpoint string          `json:"endpoint,omitempty"`
+	Prompt   string          `json:"prompt,omitempty"`
+	Params   map[string]any  `json:"params,omitempty"` // Request body without the prompt
+	Status   int             `json:"status,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"` // Raw response body
+	Usage    *usage.Tokens   `json:"usage,omitempty"`
+	CostUSD  float64         `json:"costUsd,omitempty"`
+
+	// Tool calls
+	Tool      string          `json:"tool,omitempty"`
+	CallID    string          `json:"callId,omitempty"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// Proposal records what was signed and submitted on-chain
+type Proposal struct {
+	OutcomeID    uint64   `json:"outcomeId"`
+	CloseTime    int64    `json:"closeTime"`
+	NotBefore    int64    `json:"notBefore"`
+	Deadline     int64    `json:"deadline"`
+	EvidenceURIs []string `json:"evidenceUris"`
+	EvidenceHash string   `json:"evidenceHash"`
+	Signer       string   `json:"signer"`
+	Signature    string   `json:"signature"`
+	BondAmount   string   `json:"bondAmount"`
+	TxHash       string   `json:"txHash,omitempty"`
+
+	// Prompt templates behind the decision (see llm.PromptInfo)
+	PromptVersion string `json:"promptVersion,omitempty"`
+	PromptHash    string `json:"promptHash,omitempty"`
+}
+
+// Run is the audit record of one analysis
+type Run struct {
+	ID         string          `json:"id"`
+	ChainID    int64           `json:"chainId"`
+	MarketID   uint64          `json:"marketId"`
+	Model      string          `json:"model"`
+	DryRun     bool            `json:"dryRun"`
+	ReplayOf   string          `json:"replayOf,omitempty"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt time.Time       `json:"finishedAt,omitempty"`
+	Market     json.RawMessage `json:"market,omitempty"`
+	Settings   json.RawMessage `json:"settings,omitempty"` // Pipeline settings, see llm.Settings
+	Events     []Event         `json:"events"`
+	Decision   json.RawMessage `json:"decision,omitempty"`
+	Proposal   *Proposal       `json:"proposal,omitempty"`
+	Usage      *usage.Summary  `json:"usage,omitempty"`
+	Error      string          `json:"error,omitempty"`
+
+	mu sync.Mutex
+}
+
+// NewRun starts a run record with a fresh ID
+func NewRun(chainID int64, marketID uint64, model string) *Run {
+	now := time.Now().UTC()
+	return &Run{
+		ID:        newRunID(now),
+		ChainID:   chainID,
+		MarketID:  marketID,
+		Model:     model,
+		StartedAt: now,
+		Events:    []Event{},
+	}
+}
+
+// newRunID returns a sortable, unique run ID such as 20250129T181500Z-9f2c41d0
+func newRunID(now time.Time) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return now.Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
+
+// SetMarket records the market information given to the pipeline
+func (r *Run) SetMarket(market any) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Market = RawJSON(market)
+}
+
+// SetSettings records the settings the pipeline analyzed with
+func (r *Run) SetSettings(settings any) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Settings = RawJSON(settings)
+}
+
+// SetDecision records the pipeline's decision
+func (r *Run) SetDecision(decision any) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Decision = RawJSON(decision)
+}
+
+// SetProposal records the signed proposal
+func (r *Run) SetProposal(p Proposal) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Proposal = &p
+}
+
+// SetUsage records the run's token usage and cost
+func (r *Run) SetUsage(summary *usage.Summary) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Usage = summary
+}
+
+// Finish marks the run complete, recording err if the analysis failed
+func (r *Run) Finish(err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.FinishedAt = time.Now().UTC()
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// EventsOf returns the run's events of one kind
+func (r *Run) EventsOf(kind EventKind) []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []Event
+	for _, e := range r.Events {
+		if e.Kind == kind {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func (r *Run) record(ctx context.Context, kind EventKind, e Event) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e.Seq = len(r.Events) + 1
+	e.Kind = kind
+	e.Step = StepFromContext(ctx)
+	r.Events = append(r.Events, e)
+}
+
+// RawJSON marshals v, or wraps raw bytes, as a JSON value. Bytes that are not
+// valid JSON (e.g. an HTML error page) are stored as a JSON string.
+func RawJSON(v any) json.RawMessage {
+	if b, ok := v.([]byte); ok {
+		if json.Valid(b) {
+			return append(json.RawMessage(nil), b...)
+		}
+		v = string(b)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(err.Error())
+	}
+	return data
+}
+
+type runKey struct{}
+type stepKey struct{}
+
+// WithRun attaches a run record to ctx
+func WithRun(ctx context.Context, run *Run) context.Context {
+	return context.WithValue(ctx, runKey{}, run)
+}
+
+// FromContext returns the run attached to ctx, or nil. Run methods are no-ops
+// on a nil run, so callers need not check.
+func FromContext(ctx context.Context) *Run {
+	run, _ := ctx.Value(runKey{}).(*Run)
+	return run
+}
+
+// WithStep labels events recorded under ctx with a pipeline step
+func WithStep(ctx context.Context, step string) context.Context {
+	return context.WithValue(ctx, stepKey{}, step)
+}
+
+// StepFromContext returns the step label set by WithStep
+func StepFromContext(ctx context.Context) string {
+	step, _ := ctx.Value(stepKey{}).(string)
+	return step
+}
+
+// RecordLLMCall appends an LLM request/response to the run in ctx
+func RecordLLMCall(ctx context.Context, e Event) {
+	FromContext(ctx).record(ctx, EventLLMCall, e)
+}
+
+// RecordToolCall appends a tool invocation to the run in ctx
+func RecordToolCall(ctx context.Context, e Event) {
+	FromContext(ctx).record(ctx, EventToolCall, e)
+}
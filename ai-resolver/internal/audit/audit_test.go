@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestRecordAndStore tests that events recorded through a context are persisted
+func TestRecordAndStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := NewRun(56, 7, "gpt-4o")
+	ctx := WithRun(context.Background(), run)
+
+	RecordLLMCall(WithStep(ctx, "extract_facts"), Event{
+		Endpoint: "https://api.openai.com/v1/responses",
+		Prompt:   "Will it rain?",
+		Params:   map[string]any{"model": "gpt-4o"},
+		Status:   200,
+		Response: RawJSON([]byte(`{"id":"resp_1"}`)),
+	})
+	RecordToolCall(ctx, Event{Tool: "get_bnb_price", Arguments: RawJSON([]byte(`{}`)), Result: RawJSON(map[string]any{"usd": 600})})
+	RecordLLMCall(ctx, Event{Status: 502, Response: RawJSON([]byte("<html>Bad Gateway</html>"))})
+	run.SetDecision(map[string]any{"outcomeId": 1})
+	run.Finish(errors.New("partial"))
+
+	// Recording without a run is a no-op
+	RecordLLMCall(context.Background(), Event{})
+
+	if err := store.Save(run); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load(run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Events) != 3 || loaded.Events[0].Step != "extract_facts" || loaded.Events[2].Seq != 3 {
+		t.Errorf("unexpected events: %+v", loaded.Events)
+	}
+	if got := loaded.EventsOf(EventToolCall); len(got) != 1 || got[0].Tool != "get_bnb_price" {
+		t.Errorf("unexpected tool calls: %+v", got)
+	}
+	var body string
+	if err := json.Unmarshal(loaded.Events[2].Response, &body); err != nil || body != "<html>Bad Gateway</html>" {
+		t.Errorf("expected non-JSON body as string, got %s", loaded.Events[2].Response)
+	}
+	if loaded.Error != "partial" || loaded.FinishedAt.IsZero() {
+		t.Errorf("expected finished run with error, got %+v", loaded)
+	}
+
+	runs, err := store.List(56, 7)
+	if err != nil || len(runs) != 1 || runs[0].ID != run.ID {
+		t.Errorf("expected one listed run, got %v (%v)", runs, err)
+	}
+	for _, id := range []string{"../escape", "*", "[0-9]*", run.ID[:10] + "?" + run.ID[11:]} {
+		if _, err := store.Load(id); err == nil {
+			t.Errorf("expected error for run ID %q", id)
+		}
+	}
+}
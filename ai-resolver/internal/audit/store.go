@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// runIDPattern matches the IDs newRunID generates
+var runIDPattern = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}Z-[0-9a-f]{8}$`)
+
+// Store persists run records
+type Store interface {
+	Save(run *Run) error
+	Load(runID string) (*Run, error)
+	List(chainID int64, marketID uint64) ([]*Run, error)
+}
+
+// FileStore keeps one JSON file per run under <dir>/<chainId>/<marketId>/<runId>.json
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a file store rooted at dir
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Save writes the run record, replacing any earlier version
+func (s *FileStore) Save(run *Run) error {
+	run.mu.Lock()
+	data, err := json.MarshalIndent(run, "", "  ")
+	run.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal run: %w", err)
+	}
+
+	dir := filepath.Join(s.dir, strconv.FormatInt(run.ChainID, 10), strconv.FormatUint(run.MarketID, 10))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create run directory: %w", err)
+	}
+
+	// Write then rename so readers never see a partial record
+	path := filepath.Join(dir, run.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write run: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write run: %w", err)
+	}
+	return nil
+}
+
+// Load reads a run by ID
+func (s *FileStore) Load(runID string) (*Run, error) {
+	// The ID becomes part of a glob pattern, so only IDs newRunID could
+	// have generated are looked up
+	if !runIDPattern.MatchString(runID) {
+		return nil, fmt.Errorf("invalid run ID %q", runID)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(s.dir, "*", "*", runID+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search audit directory: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("run %s not found", runID)
+	}
+	return readRun(matches[0])
+}
+
+// List returns the runs for a market, oldest first
+func (s *FileStore) List(chainID int64, marketID uint64) ([]*Run, error) {
+	pattern := filepath.Join(s.dir, strconv.FormatInt(chainID, 10), strconv.FormatUint(marketID, 10), "*.json")
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search audit directory: %w", err)
+	}
+	sort.Strings(matches) // Run IDs start with a UTC timestamp
+
+	runs := make([]*Run, 0, len(matches))
+	for _, path := range matches {
+		run, err := readRun(path)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+func readRun(path string) (*Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run: %w", err)
+	}
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &run, nil
+}
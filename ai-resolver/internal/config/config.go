@@ -1,10 +1,15 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/jsontime"
 )
 
 // Config holds all application configuration
@@ -13,7 +18,7 @@ type Config struct {
 	ServerPort string
 	ServerHost string
 
-	// Blockchain settings
+	// Blockchain settings (single-chain deployments; see Chains for multi-chain)
 	ChainID              int64
 	RPCEndpoint          string
 	AIOracleAdapterAddr  string
@@ -44,10 +49,143 @@ type Config struct {
 
 	// Security
 	AllowedOrigins []string
+
+	// HTTP record/replay (see internal/httprec)
+	HTTPCassette     string // Cassette file for LLM and tool HTTP traffic
+	HTTPCassetteMode string // "record" or "replay"
+
+	// Audit trail (see internal/audit)
+	AuditDir string // Directory for per-run audit records
+
+	// Prompt templates (see llm.PromptSet)
+	PromptsDir string // Optional directory replacing the built-in prompts; reloaded on SIGHUP
+
+	// Category strategies (see llm.StrategyRegistry)
+	StrategiesFile string // Optional JSON file replacing the built-in strategies
+
+	// Analysis stages (see llm.PipelineConfig)
+	PipelineFile string // Optional JSON stage layout replacing the built-in one
+	IPFSGateway  string // HTTP gateway the fetch_metadata stage reads ipfs:// URIs through
+
+	// Conditional markets (see internal/dependent)
+	ParentWait         time.Duration // How long check_parent waits for a parent to be final; zero fails at once
+	ParentPollInterval time.Duration // How often it reads the parent while waiting
+
+	// Adversarial review (see llm.OpenAIPipeline.SetChallenge)
+	ChallengeDecisions bool // Run the devil's advocate and judge before proposing
+
+	// Citation verification (see internal/verify and internal/fetch)
+	VerifyCitations bool          // Fetch cited pages and drop citations they do not confirm
+	VerifyMinScore  float64       // Share of a claim's words that must appear on the cited page
+	FetchTimeout    time.Duration // Per page fetch, redirects included
+	FetchMaxBytes   int64         // Page bytes read; longer pages are truncated
+
+	// Page fetching for fetch_url and verification (see internal/fetch)
+	FetchAllowedDomains []string      // Domains pages may be fetched from; empty allows any public host
+	FetchRobots         bool          // Honor robots.txt
+	FetchArchiveTTL     time.Duration // How long fetched pages are reused, so verification sees what the model read
+
+	// Local document corpus (see internal/corpus)
+	CorpusFile           string // Optional index file; enables the search_documents tool
+	CorpusEmbeddingModel string // Embedding model of the corpus; empty searches by BM25 only
+	HostedWebSearch      bool   // Offer the LLM API's hosted web_search tool
+
+	// Web search providers (see internal/tools/search.go)
+	SearchProvider   string // searxng, brave or bing; empty leaves out the search_web tool
+	SearchURL        string // SearxNG instance, or another endpoint for brave/bing
+	SearchAPIKey     string // Brave or Bing subscription key
+	MaxSearchResults int    // Results a search returns unless the LLM asks for fewer or more
+
+	// Source credibility (see internal/credibility)
+	SourceTiersFile string // Optional JSON domain tiers layered over the defaults
+
+	// Deterministic price resolution (see internal/pricefeed)
+	PriceFeedsFile string // Optional JSON file replacing the built-in oracle feeds
+
+	// LLM resilience (see llm.Router)
+	LLMMaxAttempts      int           // Attempts per endpoint, including the first (0 means 1)
+	LLMRetryBaseDelay   time.Duration // First retry backoff, doubled per attempt
+	LLMRetryMaxDelay    time.Duration // Backoff cap and longest Retry-After honored
+	LLMMaxConcurrent    int           // LLM API calls in flight across all chains (0 = unlimited)
+	LLMBreakerThreshold int           // Consecutive failures that open an endpoint's circuit (0 = never)
+	LLMBreakerCooldown  time.Duration // How long an open circuit rejects calls
+	LLMFallbacksFile    string        // Optional JSON list of fallback models and providers
+
+	// LLM usage accounting (see internal/usage)
+	PriceTableFile     string  // Optional JSON price table layered over the defaults
+	UsageFile          string  // File the usage ledger is persisted to
+	BudgetPerMarketUSD float64 // LLM spend limit per market (0 = unlimited)
+	BudgetPerDayUSD    float64 // LLM spend limit per UTC day (0 = unlimited)
+
+	// Multi-chain settings
+	ChainsFile     string         // Optional JSON file with one profile per chain
+	DefaultChainID int64          // Chain used by the unscoped /v1/* routes
+	Chains         []ChainProfile // Resolved deployment profiles (always at least one)
+}
+
+// ChainProfile holds the deployment settings for a single chain. Fields left
+// empty in a profile inherit the process-wide defaults from Config.
+type ChainProfile struct {
+	Name                 string            `json:"name"`
+	ChainID              int64             `json:"chainId"`
+	RPCEndpoint          string            `json:"rpcEndpoint"`
+	AIOracleAdapterAddr  string            `json:"aiOracleAdapterAddr"`
+	ResolutionModuleAddr string            `json:"resolutionModuleAddr"`
+	TokenAddr            string            `json:"tokenAddr"`
+	MarketFactoryAddr    string            `json:"marketFactoryAddr"`
+	SignerPrivateKey     string            `json:"signerPrivateKey,omitempty"`
+	DefaultBondAmount    string            `json:"defaultBondAmount,omitempty"`
+	ProposalValidity     jsontime.Duration `json:"proposalValidity,omitempty"` // Deadline - NotBefore of signed proposals
+	WatchInterval        jsontime.Duration `json:"watchInterval,omitempty"`    // 0 disables the market watcher
 }
 
 // LoadFromEnv loads configuration from environment variables
 func LoadFromEnv() (*Config, error) {
+	cfg, err := loadFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate required fields
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// LoadChainProfile loads the chain profiles from the environment and returns
+// the one for chainID (0 selects the default chain). Only the selected profile
+// is validated, so operator tools can use it without LLM settings. The signer
+// key is optional here; read-only callers may leave it unset.
+func LoadChainProfile(chainID int64) (ChainProfile, error) {
+	cfg, err := loadFromEnv()
+	if err != nil {
+		return ChainProfile{}, err
+	}
+
+	if chainID == 0 {
+		chainID = cfg.DefaultChainID
+	}
+	profile, ok := cfg.Chain(chainID)
+	if !ok {
+		return ChainProfile{}, fmt.Errorf("chain %d has no profile", chainID)
+	}
+	if err := profile.Validate(); err != nil {
+		return ChainProfile{}, fmt.Errorf("chain %s: %w", profile.Name, err)
+	}
+
+	return profile, nil
+}
+
+// LoadOperatorConfig loads the environment without validating it, for operator
+// tools that only need a few settings. Callers check what they use.
+func LoadOperatorConfig() (*Config, error) {
+	return loadFromEnv()
+}
+
+// loadFromEnv reads the environment without validating the result
+func loadFromEnv() (*Config, error) {
 	cfg := &Config{
 		// Defaults
 		ServerPort:           getEnv("SERVER_PORT", "8080"),
@@ -70,41 +208,157 @@ func LoadFromEnv() (*Config, error) {
 		MaxConcurrentMarkets: getEnvInt("MAX_CONCURRENT_MARKETS", 10),
 		LogLevel:             getEnv("LOG_LEVEL", "info"),
 		AllowedOrigins:       []string{"*"}, // Configure based on deployment
+		ChainsFile:           getEnv("CHAINS_FILE", ""),
+		HTTPCassette:         getEnv("HTTP_CASSETTE", ""),
+		HTTPCassetteMode:     getEnv("HTTP_CASSETTE_MODE", "replay"),
+		AuditDir:             getEnv("AUDIT_DIR", "./data/audit"),
+		PromptsDir:           getEnv("PROMPTS_DIR", ""),
+		StrategiesFile:       getEnv("STRATEGIES_FILE", ""),
+		SourceTiersFile:      getEnv("SOURCE_TIERS_FILE", ""),
+		PipelineFile:         getEnv("PIPELINE_FILE", ""),
+		IPFSGateway:          getEnv("IPFS_GATEWAY", "https://ipfs.io"),
+		ParentWait:           getEnvDuration("PARENT_WAIT", 0),
+		ParentPollInterval:   getEnvDuration("PARENT_POLL_INTERVAL", 15*time.Second),
+		ChallengeDecisions:   getEnvBool("CHALLENGE_DECISIONS", true),
+		VerifyCitations:      getEnvBool("VERIFY_CITATIONS", true),
+		VerifyMinScore:       getEnvFloat("VERIFY_MIN_SCORE", 0.8),
+		FetchTimeout:         getEnvDuration("FETCH_TIMEOUT", 15*time.Second),
+		FetchMaxBytes:        getEnvInt64("FETCH_MAX_BYTES", 2<<20),
+		FetchAllowedDomains:  getEnvList("FETCH_ALLOWED_DOMAINS"),
+		FetchRobots:          getEnvBool("FETCH_ROBOTS", true),
+		FetchArchiveTTL:      getEnvDuration("FETCH_ARCHIVE_TTL", 15*time.Minute),
+		CorpusFile:           getEnv("CORPUS_FILE", ""),
+		CorpusEmbeddingModel: getEnv("CORPUS_EMBEDDING_MODEL", ""),
+		HostedWebSearch:      getEnvBool("HOSTED_WEB_SEARCH", true),
+		SearchProvider:       getEnv("SEARCH_PROVIDER", ""),
+		SearchURL:            getEnv("SEARCH_URL", ""),
+		SearchAPIKey:         getEnv("SEARCH_API_KEY", ""),
+		MaxSearchResults:     getEnvInt("MAX_SEARCH_RESULTS", 10),
+		PriceFeedsFile:       getEnv("PRICE_FEEDS_FILE", ""),
+		LLMMaxAttempts:       getEnvInt("LLM_MAX_ATTEMPTS", 3),
+		LLMRetryBaseDelay:    getEnvDuration("LLM_RETRY_BASE_DELAY", time.Second),
+		LLMRetryMaxDelay:     getEnvDuration("LLM_RETRY_MAX_DELAY", 30*time.Second),
+		LLMMaxConcurrent:     getEnvInt("LLM_MAX_CONCURRENT", 8),
+		LLMBreakerThreshold:  getEnvInt("LLM_BREAKER_THRESHOLD", 5),
+		LLMBreakerCooldown:   getEnvDuration("LLM_BREAKER_COOLDOWN", 30*time.Second),
+		LLMFallbacksFile:     getEnv("LLM_FALLBACKS_FILE", ""),
+		PriceTableFile:       getEnv("PRICE_TABLE_FILE", ""),
+		UsageFile:            getEnv("USAGE_FILE", "./data/usage.json"),
+		BudgetPerMarketUSD:   getEnvFloat("LLM_BUDGET_PER_MARKET_USD", 0),
+		BudgetPerDayUSD:      getEnvFloat("LLM_BUDGET_PER_DAY_USD", 0),
 	}
 
-	// Validate required fields
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
+	if err := cfg.loadChains(); err != nil {
+		return nil, fmt.Errorf("failed to load chain profiles: %w", err)
 	}
+	cfg.DefaultChainID = getEnvInt64("DEFAULT_CHAIN_ID", cfg.Chains[0].ChainID)
 
 	return cfg, nil
 }
 
+// loadChains resolves the chain profiles, either from CHAINS_FILE or from the
+// single-chain environment variables
+func (c *Config) loadChains() error {
+	if c.ChainsFile == "" {
+		c.Chains = []ChainProfile{{
+			Name:                 fmt.Sprintf("chain-%d", c.ChainID),
+			ChainID:              c.ChainID,
+			RPCEndpoint:          c.RPCEndpoint,
+			AIOracleAdapterAddr:  c.AIOracleAdapterAddr,
+			ResolutionModuleAddr: c.ResolutionModuleAddr,
+			TokenAddr:            c.TokenAddr,
+			MarketFactoryAddr:    c.MarketFactoryAddr,
+			WatchInterval:        jsontime.Duration(getEnvDuration("WATCH_INTERVAL", 0)),
+		}}
+	} else {
+		data, err := os.ReadFile(c.ChainsFile)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &c.Chains); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", c.ChainsFile, err)
+		}
+		if len(c.Chains) == 0 {
+			return fmt.Errorf("%s defines no chains", c.ChainsFile)
+		}
+	}
+
+	// Inherit process-wide defaults
+	for i := range c.Chains {
+		chain := &c.Chains[i]
+		if chain.Name == "" {
+			chain.Name = fmt.Sprintf("chain-%d", chain.ChainID)
+		}
+		if chain.SignerPrivateKey == "" {
+			chain.SignerPrivateKey = c.SignerPrivateKey
+		}
+		if chain.DefaultBondAmount == "" {
+			chain.DefaultBondAmount = c.DefaultBondAmount
+		}
+		if chain.ProposalValidity == 0 {
+			chain.ProposalValidity = jsontime.Duration(2 * time.Hour) // Accounts for LLM processing time
+		}
+	}
+
+	return nil
+}
+
+// Chain returns the profile for the given chain ID
+func (c *Config) Chain(chainID int64) (ChainProfile, bool) {
+	for _, chain := range c.Chains {
+		if chain.ChainID == chainID {
+			return chain, true
+		}
+	}
+	return ChainProfile{}, false
+}
+
 // Validate checks if all required configuration is present
 func (c *Config) Validate() error {
-	if c.RPCEndpoint == "" {
-		return fmt.Errorf("RPC_ENDPOINT is required")
+	if len(c.Chains) == 0 {
+		return fmt.Errorf("at least one chain profile is required")
 	}
-	if c.AIOracleAdapterAddr == "" {
-		return fmt.Errorf("AI_ORACLE_ADAPTER_ADDR is required")
-	}
-	if c.ResolutionModuleAddr == "" {
-		return fmt.Errorf("RESOLUTION_MODULE_ADDR is required")
+
+	seen := make(map[int64]bool)
+	for _, chain := range c.Chains {
+		if seen[chain.ChainID] {
+			return fmt.Errorf("duplicate chain profile for chain %d", chain.ChainID)
+		}
+		seen[chain.ChainID] = true
+
+		if err := chain.Validate(); err != nil {
+			return fmt.Errorf("chain %s: %w", chain.Name, err)
+		}
+		if !c.UseKMS && chain.SignerPrivateKey == "" {
+			return fmt.Errorf("chain %s: either SIGNER_PRIVATE_KEY or USE_KMS=true must be set", chain.Name)
+		}
 	}
-	if c.TokenAddr == "" {
-		return fmt.Errorf("TOKEN_ADDR is required")
+	if !seen[c.DefaultChainID] {
+		return fmt.Errorf("DEFAULT_CHAIN_ID %d has no chain profile", c.DefaultChainID)
 	}
-	if c.MarketFactoryAddr == "" {
-		return fmt.Errorf("MARKET_FACTORY_ADDR is required")
+
+	if c.HTTPCassette != "" && c.HTTPCassetteMode != "record" && c.HTTPCassetteMode != "replay" {
+		return fmt.Errorf("HTTP_CASSETTE_MODE must be record or replay, got %q", c.HTTPCassetteMode)
 	}
-	if c.OpenAIAPIKey == "" {
+
+	// Replayed cassettes never reach the API
+	if c.OpenAIAPIKey == "" && !c.replaying() {
 		return fmt.Errorf("OPENAI_API_KEY is required")
 	}
 
-	// Validate signer configuration
-	if !c.UseKMS && c.SignerPrivateKey == "" {
-		return fmt.Errorf("either SIGNER_PRIVATE_KEY or USE_KMS=true must be set")
+	if c.LLMMaxAttempts < 0 || c.LLMMaxConcurrent < 0 || c.LLMBreakerThreshold < 0 {
+		return fmt.Errorf("LLM_MAX_ATTEMPTS, LLM_MAX_CONCURRENT and LLM_BREAKER_THRESHOLD must not be negative")
 	}
+
+	if c.VerifyMinScore < 0 || c.VerifyMinScore > 1 {
+		return fmt.Errorf("VERIFY_MIN_SCORE must be between 0 and 1")
+	}
+
+	if c.BudgetPerMarketUSD < 0 || c.BudgetPerDayUSD < 0 {
+		return fmt.Errorf("LLM budgets must not be negative")
+	}
+
+	// Validate signer configuration
 	if c.UseKMS && c.KMSKeyID == "" {
 		return fmt.Errorf("KMS_KEY_ID is required when USE_KMS=true")
 	}
@@ -112,6 +366,37 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// replaying reports whether HTTP traffic is served from a cassette
+func (c *Config) replaying() bool {
+	return c.HTTPCassette != "" && c.HTTPCassetteMode == "replay"
+}
+
+// Validate checks if a chain profile is complete
+func (p *ChainProfile) Validate() error {
+	if p.ChainID <= 0 {
+		return fmt.Errorf("chainId must be positive")
+	}
+	if p.RPCEndpoint == "" {
+		return fmt.Errorf("RPC_ENDPOINT is required")
+	}
+	if p.AIOracleAdapterAddr == "" {
+		return fmt.Errorf("AI_ORACLE_ADAPTER_ADDR is required")
+	}
+	if p.ResolutionModuleAddr == "" {
+		return fmt.Errorf("RESOLUTION_MODULE_ADDR is required")
+	}
+	if p.TokenAddr == "" {
+		return fmt.Errorf("TOKEN_ADDR is required")
+	}
+	if p.MarketFactoryAddr == "" {
+		return fmt.Errorf("MARKET_FACTORY_ADDR is required")
+	}
+	if _, ok := new(big.Int).SetString(p.DefaultBondAmount, 10); !ok {
+		return fmt.Errorf("invalid bond amount %q", p.DefaultBondAmount)
+	}
+	return nil
+}
+
 // Helper functions for environment variable parsing
 
 func getEnv(key, defaultVal string) string {
@@ -139,6 +424,15 @@ func getEnvInt64(key string, defaultVal int64) int64 {
 	return defaultVal
 }
 
+func getEnvFloat(key string, defaultVal float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if floatVal, err := strconv.ParseFloat(val, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultVal
+}
+
 func getEnvBool(key string, defaultVal bool) bool {
 	if val := os.Getenv(key); val != "" {
 		if boolVal, err := strconv.ParseBool(val); err == nil {
@@ -148,6 +442,17 @@ func getEnvBool(key string, defaultVal bool) bool {
 	return defaultVal
 }
 
+// getEnvList splits a comma-separated variable, dropping empty entries
+func getEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
 	if val := os.Getenv(key); val != "" {
 		if duration, err := time.ParseDuration(val); err == nil {
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLoadChainsFromFile tests multi-chain profiles and default inheritance
+func TestLoadChainsFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chains.json")
+	profiles := `[
+		{
+			"name": "bsc",
+			"chainId": 56,
+			"rpcEndpoint": "https://bsc.example",
+			"aiOracleAdapterAddr": "0x01",
+			"resolutionModuleAddr": "0x02",
+			"tokenAddr": "0x03",
+			"marketFactoryAddr": "0x04",
+			"watchInterval": "30s"
+		},
+		{
+			"chainId": 97,
+			"rpcEndpoint": "https://bsc-testnet.example",
+			"aiOracleAdapterAddr": "0x11",
+			"resolutionModuleAddr": "0x12",
+			"tokenAddr": "0x13",
+			"marketFactoryAddr": "0x14",
+			"signerPrivateKey": "testnet-key",
+			"defaultBondAmount": "5"
+		}
+	]`
+	if err := os.WriteFile(path, []byte(profiles), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{
+		ChainsFile:        path,
+		SignerPrivateKey:  "global-key",
+		DefaultBondAmount: "1000",
+		OpenAIAPIKey:      "sk-test",
+	}
+	if err := cfg.loadChains(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.DefaultChainID = 56
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	mainnet, ok := cfg.Chain(56)
+	if !ok {
+		t.Fatal("expected chain 56")
+	}
+	if mainnet.SignerPrivateKey != "global-key" || mainnet.DefaultBondAmount != "1000" {
+		t.Errorf("expected mainnet to inherit defaults, got %+v", mainnet)
+	}
+	if time.Duration(mainnet.WatchInterval) != 30*time.Second {
+		t.Errorf("expected 30s watch interval, got %v", time.Duration(mainnet.WatchInterval))
+	}
+
+	testnet, ok := cfg.Chain(97)
+	if !ok {
+		t.Fatal("expected chain 97")
+	}
+	if testnet.Name != "chain-97" {
+		t.Errorf("expected generated name 'chain-97', got %s", testnet.Name)
+	}
+	if testnet.SignerPrivateKey != "testnet-key" || testnet.DefaultBondAmount != "5" {
+		t.Errorf("expected testnet overrides to be kept, got %+v", testnet)
+	}
+
+	// Unknown default chain is rejected
+	cfg.DefaultChainID = 204
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for unconfigured default chain")
+	}
+}
+
+// TestLoadChainsFromEnv tests the single-chain fallback
+func TestLoadChainsFromEnv(t *testing.T) {
+	cfg := &Config{
+		ChainID:              97,
+		RPCEndpoint:          "https://bsc-testnet.example",
+		AIOracleAdapterAddr:  "0x11",
+		ResolutionModuleAddr: "0x12",
+		TokenAddr:            "0x13",
+		MarketFactoryAddr:    "0x14",
+		SignerPrivateKey:     "key",
+		DefaultBondAmount:    "1",
+	}
+	if err := cfg.loadChains(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(cfg.Chains) != 1 {
+		t.Fatalf("expected 1 chain, got %d", len(cfg.Chains))
+	}
+	if cfg.Chains[0].ChainID != 97 || cfg.Chains[0].RPCEndpoint != "https://bsc-testnet.example" {
+		t.Errorf("unexpected profile: %+v", cfg.Chains[0])
+	}
+	if time.Duration(cfg.Chains[0].ProposalValidity) != 2*time.Hour {
+		t.Errorf("expected 2h default proposal validity, got %v", time.Duration(cfg.Chains[0].ProposalValidity))
+	}
+}
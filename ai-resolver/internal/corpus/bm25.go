@@ -0,0 +1,190 @@
+package corpus
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// BM25 parameters: term frequency saturation and length normalization
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// rrfK damps the reciprocal rank fusion of keyword and vector rankings
+const rrfK = 60
+
+// passageWords is the passage size documents are split into
+const passageWords = 150
+
+// posting is one passage a term occurs in
+type posting struct {
+	Passage int `json:"p"` // Index into the passages
+	Freq    int `json:"f"`
+}
+
+// ranked is a passage and its score in one ranking
+type ranked struct {
+	passage int
+	score   float64
+}
+
+// stopwords are left out of the index; they match nearly every passage
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "has": true, "in": true, "is": true, "it": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "this": true, "to": true,
+	"was": true, "were": true, "will": true, "with": true,
+}
+
+// tokenize splits text into lowercase index terms. Letters and digits make
+// up terms, so "3.1%" yields "3" and "1" and "Q1-2025" yields "q1" and "2025".
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := words[:0]
+	for _, w := range words {
+		if !stopwords[w] {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// chunk splits text into passages of about passageWords words, breaking at
+// line ends where it can. The split depends only on the text, so passage IDs
+// are stable across ingestions of the same document.
+func chunk(text string) []string {
+	var passages, current []string
+	count := 0
+	flush := func() {
+		if len(current) > 0 {
+			passages = append(passages, strings.Join(current, "\n"))
+		}
+		current, count = nil, 0
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			continue
+		}
+		if count+len(words) > passageWords {
+			flush()
+		}
+		for len(words) > passageWords {
+			passages = append(passages, strings.Join(words[:passageWords], " "))
+			words = words[passageWords:]
+		}
+		current = append(current, strings.Join(words, " "))
+		count += len(words)
+	}
+	flush()
+	return passages
+}
+
+// reindex rebuilds the inverted index after passages changed
+func (ix *Index) reindex() {
+	ix.terms = make(map[string][]posting)
+	ix.lengths = make([]int, len(ix.passages))
+	for i, p := range ix.passages {
+		terms := tokenize(p.Text)
+		ix.lengths[i] = len(terms)
+
+		freq := make(map[string]int, len(terms))
+		for _, t := range terms {
+			freq[t]++
+		}
+		for t, n := range freq {
+			ix.terms[t] = append(ix.terms[t], posting{Passage: i, Freq: n})
+		}
+	}
+}
+
+// bm25 ranks the passages containing any query term, best first
+func (ix *Index) bm25(query []string) []ranked {
+	n := float64(len(ix.passages))
+	if n == 0 {
+		return nil
+	}
+	total := 0
+	for _, l := range ix.lengths {
+		total += l
+	}
+	avgLen := math.Max(float64(total)/n, 1)
+
+	scores := make(map[int]float64)
+	seen := make(map[string]bool, len(query))
+	for _, term := range query {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		postings := ix.terms[term]
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			tf := float64(p.Freq)
+			norm := 1 - bm25B + bm25B*float64(ix.lengths[p.Passage])/avgLen
+			scores[p.Passage] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+	return sortRanked(scores)
+}
+
+// nearest ranks the passages by cosine similarity to a query vector
+func (ix *Index) nearest(query []float32) []ranked {
+	scores := make(map[int]float64, len(ix.passages))
+	for i, p := range ix.passages {
+		if len(p.Vector) == len(query) {
+			scores[i] = cosine(query, p.Vector)
+		}
+	}
+	return sortRanked(scores)
+}
+
+// fuse merges rankings by reciprocal rank fusion, so that neither ranking's
+// score scale dominates
+func fuse(rankings ...[]ranked) []ranked {
+	scores := make(map[int]float64)
+	for _, ranking := range rankings {
+		for rank, r := range ranking {
+			scores[r.passage] += 1 / float64(rrfK+rank+1)
+		}
+	}
+	return sortRanked(scores)
+}
+
+// sortRanked orders scored passages best first, ties by passage order
+func sortRanked(scores map[int]float64) []ranked {
+	out := make([]ranked, 0, len(scores))
+	for passage, score := range scores {
+		out = append(out, ranked{passage: passage, score: score})
+	}
+	slices.SortFunc(out, func(a, b ranked) int {
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		}
+		return a.passage - b.passage
+	})
+	return out
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
@@ -0,0 +1,332 @@
+// Package corpus is a local store of documents from official sources, for
+// deployments that cannot use hosted web search. Documents are ingested from
+// URLs or files (HTML, PDF and text), split into passages and indexed for
+// BM25 keyword search; with an Embedder, passages are also embedded and
+// searches fuse both rankings.
+//
+// Every document is cited by the URL it was published at, so passages are
+// scored, filtered and verified like the pages web search returns. Document
+// IDs derive from that URL and passage IDs from the document and position,
+// so they stay the same when a document is ingested again.
+package corpus
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+)
+
+// fileVersion is the layout of the index file
+const fileVersion = 1
+
+// Document is an ingested document
+type Document struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`              // Where it was published; passages are cited by it
+	Source      string    `json:"source,omitempty"` // File it was read from, when not fetched from URL
+	Title       string    `json:"title"`
+	ContentType string    `json:"contentType"`
+	Hash        string    `json:"hash"` // SHA-256 of the ingested bytes, hex
+	Passages    int       `json:"passages"`
+	IngestedAt  time.Time `json:"ingestedAt"`
+}
+
+// Passage is a searchable span of a document
+type Passage struct {
+	ID         string    `json:"id"` // "<document ID>#p<index>"
+	DocumentID string    `json:"documentId"`
+	Index      int       `json:"index"`
+	Text       string    `json:"text"`
+	Vector     []float32 `json:"vector,omitempty"`
+}
+
+// Hit is a passage returned by a search
+type Hit struct {
+	PassageID  string  `json:"passageId"`
+	DocumentID string  `json:"documentId"`
+	Title      string  `json:"title"`
+	URL        string  `json:"url"`
+	Text       string  `json:"text"`
+	Score      float64 `json:"score"`
+}
+
+// Index is a corpus persisted to one JSON file. It is safe for concurrent
+// searches; changes are written with Save.
+type Index struct {
+	path     string
+	embedder Embedder
+
+	mu        sync.RWMutex
+	model     string // Embedding model of the stored vectors; empty without vectors
+	documents []Document
+	passages  []Passage
+	terms     map[string][]posting
+	lengths   []int // Terms per passage
+}
+
+// indexFile is the on-disk layout
+type indexFile struct {
+	Version        int                  `json:"version"`
+	EmbeddingModel string               `json:"embeddingModel,omitempty"`
+	Documents      []Document           `json:"documents"`
+	Passages       []Passage            `json:"passages"`
+	Terms          map[string][]posting `json:"terms"` // Inverted index over Passages
+	Lengths        []int                `json:"lengths"`
+}
+
+// DocumentID returns the stable ID of the document published at rawURL
+func DocumentID(rawURL string) string {
+	key := credibility.CanonicalURL(rawURL)
+	if key == "" {
+		key = rawURL
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "doc-" + hex.EncodeToString(sum[:8])
+}
+
+// Open loads the index at path, or starts an empty one if the file does not
+// exist yet
+func Open(path string) (*Index, error) {
+	ix := &Index{path: path, terms: map[string][]posting{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read corpus: %w", err)
+	}
+
+	var file indexFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse corpus %s: %w", path, err)
+	}
+	if file.Version != fileVersion {
+		return nil, fmt.Errorf("corpus %s has version %d, expected %d", path, file.Version, fileVersion)
+	}
+	if len(file.Lengths) != len(file.Passages) {
+		return nil, fmt.Errorf("corpus %s is corrupt: %d passage lengths for %d passages", path, len(file.Lengths), len(file.Passages))
+	}
+	ix.model, ix.documents, ix.passages, ix.lengths = file.EmbeddingModel, file.Documents, file.Passages, file.Lengths
+	if file.Terms != nil {
+		ix.terms = file.Terms
+	}
+	return ix, nil
+}
+
+// SetEmbedder enables the embedding index: ingested passages are embedded
+// and searches rank by both BM25 and vector similarity. Vectors stored with
+// another model are ignored until the documents are ingested again.
+func (ix *Index) SetEmbedder(embedder Embedder) {
+	ix.embedder = embedder
+}
+
+// Save writes the index to its file, replacing it atomically
+func (ix *Index) Save() error {
+	ix.mu.RLock()
+	data, err := json.Marshal(indexFile{
+		Version:        fileVersion,
+		EmbeddingModel: ix.model,
+		Documents:      ix.documents,
+		Passages:       ix.passages,
+		Terms:          ix.terms,
+		Lengths:        ix.lengths,
+	})
+	ix.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode corpus: %w", err)
+	}
+
+	if dir := filepath.Dir(ix.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create corpus directory: %w", err)
+		}
+	}
+	tmp := ix.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write corpus: %w", err)
+	}
+	if err := os.Rename(tmp, ix.path); err != nil {
+		return fmt.Errorf("failed to write corpus: %w", err)
+	}
+	return nil
+}
+
+// Add indexes a document's text, replacing an earlier version of the same
+// document. The document's ID and passage count are filled in.
+func (ix *Index) Add(ctx context.Context, doc Document, text string) (Document, error) {
+	if doc.URL == "" {
+		return doc, fmt.Errorf("document has no URL to cite it by")
+	}
+	doc.ID = DocumentID(doc.URL)
+	if doc.IngestedAt.IsZero() {
+		doc.IngestedAt = time.Now().UTC()
+	}
+
+	chunks := chunk(text)
+	if len(chunks) == 0 {
+		return doc, fmt.Errorf("document %s has no text", doc.URL)
+	}
+	passages := make([]Passage, len(chunks))
+	for i, c := range chunks {
+		passages[i] = Passage{ID: fmt.Sprintf("%s#p%d", doc.ID, i), DocumentID: doc.ID, Index: i, Text: c}
+	}
+	doc.Passages = len(passages)
+
+	var model string
+	if ix.embedder != nil {
+		model = ix.embedder.Model()
+		vectors, err := embedAll(ctx, ix.embedder, chunks)
+		if err != nil {
+			return doc, err
+		}
+		for i := range passages {
+			passages[i].Vector = vectors[i]
+		}
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	// Every passage is embedded with the same model, or none is
+	others := slices.ContainsFunc(ix.passages, func(p Passage) bool { return p.DocumentID != doc.ID })
+	if others && model != ix.model {
+		switch {
+		case ix.model == "":
+			return doc, fmt.Errorf("corpus has no embeddings; ingest its documents into a new corpus to embed them with %s", model)
+		case model == "":
+			return doc, fmt.Errorf("corpus is embedded with %s; configure that embedding model to ingest into it", ix.model)
+		default:
+			return doc, fmt.Errorf("corpus is embedded with %s, not %s", ix.model, model)
+		}
+	}
+	ix.model = model
+
+	ix.remove(doc.ID)
+	ix.documents = append(ix.documents, doc)
+	ix.passages = append(ix.passages, passages...)
+	ix.reindex()
+	return doc, nil
+}
+
+// Remove deletes a document, by ID or URL, and its passages. It reports
+// whether the document was in the corpus.
+func (ix *Index) Remove(idOrURL string) bool {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	i := ix.find(idOrURL)
+	if i < 0 {
+		return false
+	}
+	ix.remove(ix.documents[i].ID)
+	if len(ix.passages) == 0 {
+		ix.model = ""
+	}
+	ix.reindex()
+	return true
+}
+
+// remove drops a document without rebuilding the inverted index
+func (ix *Index) remove(id string) bool {
+	i := slices.IndexFunc(ix.documents, func(d Document) bool { return d.ID == id })
+	if i < 0 {
+		return false
+	}
+	ix.documents = slices.Delete(ix.documents, i, i+1)
+	ix.passages = slices.DeleteFunc(ix.passages, func(p Passage) bool { return p.DocumentID == id })
+	return true
+}
+
+// Documents returns the ingested documents, in ingestion order
+func (ix *Index) Documents() []Document {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return slices.Clone(ix.documents)
+}
+
+// Document returns the document with the given ID, or the document
+// published at the given URL
+func (ix *Index) Document(idOrURL string) (Document, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if i := ix.find(idOrURL); i >= 0 {
+		return ix.documents[i], true
+	}
+	return Document{}, false
+}
+
+// Text returns the full text of a document, its passages joined
+func (ix *Index) Text(id string) string {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	var parts []string
+	for _, p := range ix.passages {
+		if p.DocumentID == id {
+			parts = append(parts, p.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// find returns the index of a document by ID or URL, or -1
+func (ix *Index) find(idOrURL string) int {
+	id := idOrURL
+	if !strings.HasPrefix(idOrURL, "doc-") {
+		id = DocumentID(idOrURL)
+	}
+	return slices.IndexFunc(ix.documents, func(d Document) bool { return d.ID == id })
+}
+
+// Search returns the passages that best match a query, at most limit
+func (ix *Index) Search(ctx context.Context, query string, limit int) ([]Hit, error) {
+	if limit <= 0 {
+		limit = 5
+	}
+
+	var queryVector []float32
+	if ix.embedder != nil {
+		ix.mu.RLock()
+		usable := ix.model == ix.embedder.Model() && len(ix.passages) > 0
+		ix.mu.RUnlock()
+		if usable {
+			vectors, err := ix.embedder.Embed(ctx, []string{query})
+			if err != nil {
+				return nil, fmt.Errorf("failed to embed query: %w", err)
+			}
+			queryVector = vectors[0]
+		}
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	ranked := ix.bm25(tokenize(query))
+	if queryVector != nil {
+		ranked = fuse(ranked, ix.nearest(queryVector))
+	}
+
+	hits := make([]Hit, 0, min(limit, len(ranked)))
+	for _, r := range ranked[:min(limit, len(ranked))] {
+		p := ix.passages[r.passage]
+		doc := ix.documents[ix.find(p.DocumentID)]
+		hits = append(hits, Hit{
+			PassageID:  p.ID,
+			DocumentID: doc.ID,
+			Title:      doc.Title,
+			URL:        doc.URL,
+			Text:       p.Text,
+			Score:      r.score,
+		})
+	}
+	return hits, nil
+}
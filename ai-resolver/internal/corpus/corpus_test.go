@@ -0,0 +1,155 @@
+package corpus
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+)
+
+// keywords is a fake embedder: one dimension per keyword, so passages about
+// the same keywords are near each other without sharing other words
+type keywords []string
+
+func (k keywords) Model() string { return "keywords" }
+func (k keywords) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float32, len(k))
+		for j, word := range k {
+			if strings.Contains(strings.ToLower(text), word) {
+				vectors[i][j] = 1
+			}
+		}
+	}
+	return vectors, nil
+}
+
+func add(t *testing.T, ix *Index, url, title, text string) Document {
+	t.Helper()
+	doc, err := ix.Add(context.Background(), Document{URL: url, Title: title, ContentType: "text/plain"}, text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// TestSearch tests BM25 ranking, stable IDs, re-ingestion and persistence
+func TestSearch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corpus.json")
+	ix, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpi := add(t, ix, "https://www.bls.gov/news.release/cpi.nr0.htm", "CPI March 2025",
+		"The Consumer Price Index rose 0.2 percent in March.\nOver the last 12 months, the all items index increased 3.1 percent.")
+	add(t, ix, "https://www.bls.gov/news.release/empsit.nr0.htm", "Employment Situation",
+		"Total nonfarm payroll employment rose by 228,000 in March, and the unemployment rate was 4.2 percent.")
+
+	hits, err := ix.Search(context.Background(), "CPI all items index 12 months", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) == 0 || hits[0].DocumentID != cpi.ID || hits[0].PassageID != cpi.ID+"#p0" || hits[0].URL != cpi.URL {
+		t.Fatalf("expected the CPI release first, got %+v", hits)
+	}
+	if cpi.ID != DocumentID("https://bls.gov/news.release/cpi.nr0.htm?utm_source=x") {
+		t.Errorf("expected the ID to follow the canonical URL, got %s", cpi.ID)
+	}
+
+	// Ingesting again replaces the document under the same ID
+	again := add(t, ix, cpi.URL, "CPI March 2025", "Revised: the all items index increased 3.0 percent.")
+	if again.ID != cpi.ID || len(ix.Documents()) != 2 || !strings.HasPrefix(ix.Text(cpi.ID), "Revised") {
+		t.Errorf("expected the document replaced, got %+v", ix.Documents())
+	}
+
+	if err := ix.Save(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits, err = reopened.Search(context.Background(), "unemployment rate", 1)
+	if err != nil || len(hits) != 1 || !strings.Contains(hits[0].Text, "4.2 percent") {
+		t.Errorf("expected the saved index to search alike, got %+v %v", hits, err)
+	}
+
+	if !reopened.Remove(cpi.URL) || reopened.Remove(cpi.ID) || len(reopened.Documents()) != 1 {
+		t.Errorf("expected the document removed once, got %+v", reopened.Documents())
+	}
+}
+
+// TestHybridSearch tests that the embedding ranking finds passages sharing no
+// words with the query
+func TestHybridSearch(t *testing.T) {
+	ix, _ := Open(filepath.Join(t.TempDir(), "corpus.json"))
+	ix.SetEmbedder(keywords{"inflation", "prices", "jobs"})
+	add(t, ix, "https://example.gov/prices", "Prices", "Consumer prices climbed again.")
+	add(t, ix, "https://example.gov/jobs", "Jobs", "Employers added jobs in March.")
+
+	hits, err := ix.Search(context.Background(), "inflation", 1)
+	if err != nil || len(hits) != 1 || hits[0].URL != "https://example.gov/prices" {
+		t.Fatalf("expected the prices page, got %+v %v", hits, err)
+	}
+
+	// A corpus is embedded with one model throughout
+	ix.SetEmbedder(nil)
+	if _, err := ix.Add(context.Background(), Document{URL: "https://example.gov/other"}, "Other text."); err == nil {
+		t.Error("expected ingesting without the embedding model to fail")
+	}
+}
+
+// TestChunk tests splitting text into passages at line ends
+func TestChunk(t *testing.T) {
+	line := strings.TrimSpace(strings.Repeat("word ", 60))
+	passages := chunk(strings.Join([]string{line, line, "", line, strings.Repeat("long ", 400)}, "\n"))
+	if len(passages) != 5 {
+		t.Fatalf("expected 5 passages, got %d", len(passages))
+	}
+	if got := len(strings.Fields(passages[0])); got != 120 {
+		t.Errorf("expected the first passage to hold two lines, got %d words", got)
+	}
+	if got := len(strings.Fields(passages[2])); got != passageWords {
+		t.Errorf("expected a long line split at %d words, got %d", passageWords, got)
+	}
+}
+
+// TestIngestFile tests ingesting local files and serving them to the verifier
+func TestIngestFile(t *testing.T) {
+	dir := t.TempDir()
+	ix, _ := Open(filepath.Join(dir, "corpus.json"))
+	in := NewIngester(ix, fetch.New(fetch.Options{}))
+
+	page := filepath.Join(dir, "results.html")
+	os.WriteFile(page, []byte("<title>Official results</title><p>Candidate A received 52.4% of the vote.</p>"), 0o644)
+	doc, err := in.IngestFile(context.Background(), page, "https://elections.example.gov/results")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "Official results" || doc.ContentType != "text/html" || len(doc.Hash) != 64 {
+		t.Errorf("unexpected document %+v", doc)
+	}
+	if _, err := in.IngestFile(context.Background(), page, ""); err == nil {
+		t.Error("expected a file without its published URL to be refused")
+	}
+
+	image := filepath.Join(dir, "chart.png")
+	os.WriteFile(image, []byte("\x89PNG\r\n\x1a\n"), 0o644)
+	if _, err := in.IngestFile(context.Background(), image, "https://elections.example.gov/chart.png"); !errors.Is(err, fetch.ErrUnsupported) {
+		t.Errorf("expected images to be unsupported, got %v", err)
+	}
+
+	f := NewFetcher(ix, fetch.New(fetch.Options{}))
+	got, err := f.Fetch(context.Background(), "https://www.elections.example.gov/results/")
+	if err != nil || got.Text != "Candidate A received 52.4% of the vote." || got.Hash != doc.Hash {
+		t.Errorf("expected the page served from the corpus, got %+v %v", got, err)
+	}
+	if _, err := f.Fetch(context.Background(), "http://127.0.0.1/other"); !errors.Is(err, fetch.ErrBlocked) {
+		t.Errorf("expected other URLs fetched by the next fetcher, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package corpus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// embedBatch is the number of passages embedded per request
+const embedBatch = 64
+
+// Embedder turns texts into vectors for similarity search
+type Embedder interface {
+	// Model names the embedding model; vectors of different models are not
+	// comparable
+	Model() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// OpenAIEmbedder embeds texts with an OpenAI-compatible /embeddings API
+type OpenAIEmbedder struct {
+	apiKey     string
+	model      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewOpenAIEmbedder creates an embedder for the given model, e.g.
+// "text-embedding-3-small"
+func NewOpenAIEmbedder(apiKey, model string) *OpenAIEmbedder {
+	return &OpenAIEmbedder{
+		apiKey:     apiKey,
+		model:      model,
+		baseURL:    "https://api.openai.com/v1",
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// SetBaseURL points the embedder at another OpenAI-compatible API
+func (e *OpenAIEmbedder) SetBaseURL(baseURL string) {
+	e.baseURL = baseURL
+}
+
+// SetTransport replaces the HTTP transport, e.g. with a record/replay cassette
+func (e *OpenAIEmbedder) SetTransport(transport http.RoundTripper) {
+	e.httpClient.Transport = transport
+}
+
+// Model implements Embedder
+func (e *OpenAIEmbedder) Model() string {
+	return e.model
+}
+
+// Embed implements Embedder
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]any{"model": e.model, "input": texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call embeddings API: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings API returned status %d: %s", resp.StatusCode, data)
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embeddings API returned index %d for %d inputs", d.Index, len(texts))
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("embeddings API returned no vector for input %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+// embedAll embeds texts in batches
+func embedAll(ctx context.Context, embedder Embedder, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embedBatch {
+		end := min(start+embedBatch, len(texts))
+		batch, err := embedder.Embed(ctx, texts[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed passages: %w", err)
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("embedder returned %d vectors for %d passages", len(batch), end-start)
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
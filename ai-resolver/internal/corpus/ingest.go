@@ -0,0 +1,114 @@
+package corpus
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+)
+
+// Ingester reads documents from URLs and files into an index
+type Ingester struct {
+	index   *Index
+	fetcher *fetch.Fetcher
+}
+
+// NewIngester creates an ingester. URLs are downloaded with fetcher, under
+// its address checks and size limit.
+func NewIngester(index *Index, fetcher *fetch.Fetcher) *Ingester {
+	return &Ingester{index: index, fetcher: fetcher}
+}
+
+// IngestURL downloads and indexes the document at rawURL
+func (in *Ingester) IngestURL(ctx context.Context, rawURL string) (Document, error) {
+	body, err := in.fetcher.Download(ctx, rawURL)
+	if err != nil {
+		return Document{}, err
+	}
+	if body.Truncated {
+		return Document{}, fmt.Errorf("%s is larger than the fetch limit", rawURL)
+	}
+	return in.add(ctx, Document{URL: body.URL, ContentType: body.ContentType, Hash: body.Hash}, body.Data)
+}
+
+// IngestFile indexes a local copy of the document published at publishedURL.
+// The content type follows from the file extension, or from the content.
+func (in *Ingester) IngestFile(ctx context.Context, path, publishedURL string) (Document, error) {
+	if !strings.HasPrefix(publishedURL, "https://") && !strings.HasPrefix(publishedURL, "http://") {
+		return Document{}, fmt.Errorf("%s needs the http(s) URL it was published at, got %q", path, publishedURL)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	sum := sha256.Sum256(data)
+	doc := Document{URL: publishedURL, Source: path, ContentType: mediaType, Hash: hex.EncodeToString(sum[:])}
+	return in.add(ctx, doc, data)
+}
+
+// add extracts a document's text and indexes it
+func (in *Ingester) add(ctx context.Context, doc Document, data []byte) (Document, error) {
+	title, text, err := Extract(data, doc.ContentType)
+	if err != nil {
+		return doc, fmt.Errorf("failed to extract %s: %w", doc.URL, err)
+	}
+	doc.Title = title
+	if doc.Title == "" {
+		doc.Title = doc.URL
+	}
+	return in.index.Add(ctx, doc, text)
+}
+
+// Extract returns the title and text of a document, as fetch.Extract does
+func Extract(data []byte, mediaType string) (title, text string, err error) {
+	return fetch.Extract(data, mediaType)
+}
+
+// Fetcher serves the pages of ingested documents from the corpus and fetches
+// any other URL with the next fetcher, so that citations of corpus passages
+// are verified against the text that was indexed
+type Fetcher struct {
+	index *Index
+	next  PageFetcher
+}
+
+// PageFetcher fetches pages; *fetch.Fetcher implements it
+type PageFetcher interface {
+	Fetch(ctx context.Context, url string) (*fetch.Page, error)
+}
+
+// NewFetcher creates a fetcher that prefers the corpus over next
+func NewFetcher(index *Index, next PageFetcher) *Fetcher {
+	return &Fetcher{index: index, next: next}
+}
+
+// Fetch implements PageFetcher
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*fetch.Page, error) {
+	doc, ok := f.index.Document(rawURL)
+	if !ok {
+		return f.next.Fetch(ctx, rawURL)
+	}
+	return &fetch.Page{
+		URL:         doc.URL,
+		Status:      http.StatusOK,
+		ContentType: doc.ContentType,
+		Title:       doc.Title,
+		Text:        f.index.Text(doc.ID),
+		Hash:        doc.Hash,
+		FetchedAt:   time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,206 @@
+// Package credibility scores web sources by the tier of their domain and
+// groups syndicated copies of one article, so that citations are weighted by
+// who published them and a wire story reprinted five times counts once.
+package credibility
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Tier is a class of publisher
+type Tier string
+
+const (
+	TierOfficial  Tier = "official"  // The body that decides the outcome: governments, leagues, oracles
+	TierWire      Tier = "wire"      // News agencies
+	TierReputable Tier = "reputable" // Established outlets and data providers
+	TierUnknown   Tier = "unknown"   // Anything not listed
+	TierLow       Tier = "low"       // User-generated content and social media
+	TierBlocked   Tier = "blocked"   // Never cited
+)
+
+// Valid reports whether t is one of the tiers above
+func (t Tier) Valid() bool {
+	_, ok := DefaultWeights()[t]
+	return ok
+}
+
+// DefaultWeights are the credibility scores of the tiers
+func DefaultWeights() map[Tier]float64 {
+	return map[Tier]float64{
+		TierOfficial:  1.0,
+		TierWire:      0.9,
+		TierReputable: 0.75,
+		TierUnknown:   0.4,
+		TierLow:       0.15,
+		TierBlocked:   0,
+	}
+}
+
+// Model maps domains to tiers and tiers to credibility
+type Model struct {
+	Weights map[Tier]float64 `json:"weights"`
+
+	// Domains maps a domain, which also matches its subdomains, or a suffix
+	// such as "gov" to its tier
+	Domains map[string]Tier `json:"domains"`
+}
+
+// Score is the credibility of one source
+type Score struct {
+	Domain      string  `json:"domain"` // The listed domain that matched, or the host's registrable domain
+	Tier        Tier    `json:"tier"`
+	Credibility float64 `json:"credibility"` // 0-1
+}
+
+// DefaultModel returns the built-in tiers
+func DefaultModel() *Model {
+	m := &Model{Weights: DefaultWeights(), Domains: make(map[string]Tier)}
+	for tier, domains := range map[Tier][]string{
+		TierOfficial: {
+			"gov", "mil", "gov.uk", "gov.au", "gc.ca", "europa.eu", "un.org", "who.int", "imf.org", "worldbank.org",
+			"fifa.com", "uefa.com", "premierleague.com", "olympics.com", "nba.com", "nfl.com", "mlb.com", "nhl.com",
+			"ecmwf.int", "chain.link", "bscscan.com", "etherscan.io",
+		},
+		TierWire: {"reuters.com", "apnews.com", "afp.com", "bloomberg.com"},
+		TierReputable: {
+			"bbc.com", "bbc.co.uk", "nytimes.com", "washingtonpost.com", "wsj.com", "ft.com", "economist.com",
+			"theguardian.com", "politico.com", "cnbc.com", "espn.com", "skysports.com", "coindesk.com",
+			"coingecko.com", "coinmarketcap.com", "binance.com", "coinbase.com", "kraken.com",
+			"cryptocompare.com", "pancakeswap.finance", "weather.com", "accuweather.com", "wunderground.com",
+			"meteoblue.com",
+		},
+		TierLow: {
+			"reddit.com", "medium.com", "substack.com", "blogspot.com", "wordpress.com", "quora.com", "x.com",
+			"twitter.com", "facebook.com", "instagram.com", "tiktok.com", "youtube.com", "pinterest.com", "t.me",
+		},
+	} {
+		for _, domain := range domains {
+			m.Domains[domain] = tier
+		}
+	}
+	return m
+}
+
+// LoadModel reads a JSON model layered over the defaults: its weights and
+// domains replace the built-in entries of the same name
+func LoadModel(path string) (*Model, error) {
+	m := DefaultModel()
+	if path == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source tiers: %w", err)
+	}
+	var overrides Model
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse source tiers %s: %w", path, err)
+	}
+	for tier, weight := range overrides.Weights {
+		if !tier.Valid() {
+			return nil, fmt.Errorf("%s: unknown tier %q", path, tier)
+		}
+		if weight < 0 || weight > 1 {
+			return nil, fmt.Errorf("%s: weight of tier %s must be between 0 and 1", path, tier)
+		}
+		m.Weights[tier] = weight
+	}
+	for domain, tier := range overrides.Domains {
+		if !tier.Valid() {
+			return nil, fmt.Errorf("%s: domain %s has unknown tier %q", path, domain, tier)
+		}
+	}
+	return m.With(overrides.Domains), nil
+}
+
+// With returns a copy of the model with domain tiers overridden, e.g. a
+// category's own official sites. Tiers must be valid.
+func (m *Model) With(domains map[string]Tier) *Model {
+	if len(domains) == 0 {
+		return m
+	}
+	out := &Model{Weights: maps.Clone(m.Weights), Domains: maps.Clone(m.Domains)}
+	for domain, tier := range domains {
+		out.Domains[NormalizeDomain(domain)] = tier
+	}
+	return out
+}
+
+// Score rates a URL by its domain's tier; unlisted domains are TierUnknown
+func (m *Model) Score(rawURL string) Score {
+	host := Host(rawURL)
+	if host == "" {
+		return Score{Tier: TierUnknown, Credibility: m.Weights[TierUnknown]}
+	}
+
+	// The most specific listed suffix wins: "news.example.gov" before "gov"
+	labels := strings.Split(host, ".")
+	for i := range labels {
+		suffix := strings.Join(labels[i:], ".")
+		if tier, ok := m.Domains[suffix]; ok {
+			return Score{Domain: suffix, Tier: tier, Credibility: m.Weights[tier]}
+		}
+	}
+	return Score{Domain: RegistrableDomain(host), Tier: TierUnknown, Credibility: m.Weights[TierUnknown]}
+}
+
+// Host returns the lowercased host of a URL without "www.", or "" if there is none
+func Host(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// NormalizeDomain lowercases a configured domain and strips a leading dot
+func NormalizeDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// MatchesDomain reports whether host is domain or one of its subdomains
+func MatchesDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// RegistrableDomain approximates the domain an organization registered:
+// the last two labels, or three under a two-letter country code with a
+// generic second level such as "co.uk"
+func RegistrableDomain(host string) string {
+	labels := strings.Split(host, ".")
+	n := 2
+	if len(labels) >= 3 && len(labels[len(labels)-1]) == 2 {
+		switch labels[len(labels)-2] {
+		case "co", "com", "org", "net", "gov", "ac", "edu", "or", "ne", "go":
+			n = 3
+		}
+	}
+	if len(labels) <= n {
+		return host
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+// policyKey carries a source policy in a context
+type policyKey struct{}
+
+// WithPolicy returns a context in which tools only read URLs that allow
+// accepts, e.g. a market strategy's source allowlist
+func WithPolicy(ctx context.Context, allow func(url string) bool) context.Context {
+	return context.WithValue(ctx, policyKey{}, allow)
+}
+
+// Allowed reports whether the context's source policy accepts url; without a
+// policy every URL is
+func Allowed(ctx context.Context, url string) bool {
+	allow, ok := ctx.Value(policyKey{}).(func(string) bool)
+	return !ok || allow(url)
+}
@@ -0,0 +1,110 @@
+package credibility
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestScore tests tier lookup by domain and suffix
+func TestScore(t *testing.T) {
+	m := DefaultModel()
+
+	tests := []struct {
+		url    string
+		domain string
+		tier   Tier
+	}{
+		{"https://www.reuters.com/world/story", "reuters.com", TierWire},
+		{"https://www.fec.gov/data", "gov", TierOfficial},
+		{"https://www.metoffice.gov.uk/weather", "gov.uk", TierOfficial},
+		{"https://sport.bbc.co.uk/football", "bbc.co.uk", TierReputable},
+		{"https://old.reddit.com/r/x", "reddit.com", TierLow},
+		{"https://news.someblog.co.uk/post", "someblog.co.uk", TierUnknown},
+		{"https://box.com/file", "box.com", TierUnknown}, // Not x.com
+		{"not a url", "", TierUnknown},
+	}
+	for _, tt := range tests {
+		score := m.Score(tt.url)
+		if score.Domain != tt.domain || score.Tier != tt.tier || score.Credibility != m.Weights[tt.tier] {
+			t.Errorf("%s: expected %s/%s, got %+v", tt.url, tt.domain, tt.tier, score)
+		}
+	}
+
+	// Overrides apply to the copy only, and the most specific domain wins
+	scoped := m.With(map[string]Tier{"Blog.fec.gov": TierLow})
+	if scoped.Score("https://blog.fec.gov/post").Tier != TierLow || scoped.Score("https://www.fec.gov").Tier != TierOfficial {
+		t.Error("expected the subdomain override to win over the suffix")
+	}
+	if m.Score("https://blog.fec.gov/post").Tier != TierOfficial {
+		t.Error("expected the base model unchanged")
+	}
+}
+
+// TestLoadModel tests layering a tiers file over the defaults
+func TestLoadModel(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tiers.json")
+	os.WriteFile(path, []byte(`{"weights": {"unknown": 0.2}, "domains": {"reuters.com": "reputable", "contentfarm.example": "blocked"}}`), 0o644)
+
+	m, err := LoadModel(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Weights[TierUnknown] != 0.2 || m.Weights[TierWire] != 0.9 {
+		t.Errorf("unexpected weights %v", m.Weights)
+	}
+	if m.Score("https://reuters.com").Tier != TierReputable || m.Score("https://contentfarm.example/a").Credibility != 0 {
+		t.Error("expected the file's domains to replace the defaults")
+	}
+
+	for name, data := range map[string]string{
+		"tier":   `{"domains": {"a.example": "gold"}}`,
+		"weight": `{"weights": {"wire": 2}}`,
+	} {
+		bad := filepath.Join(dir, name+".json")
+		os.WriteFile(bad, []byte(data), 0o644)
+		if _, err := LoadModel(bad); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// TestGroup tests grouping syndicated copies
+func TestGroup(t *testing.T) {
+	snippet := "The central bank held its benchmark rate steady on Wednesday, citing persistent inflation and a resilient labor market, officials said."
+	items := []Item{
+		{URL: "https://apnews.com/article/fed-rates-123", Title: "Fed holds rates steady, citing persistent inflation"},
+		{URL: "https://localpaper.example/news/fed-holds", Title: "Fed holds rates steady, citing persistent inflation"},
+		{URL: "https://amp.apnews.com/article/fed-rates-123/amp?utm_source=feed", Title: "AP"},
+		{URL: "https://otherpaper.example/a", Title: "Rates unchanged", Snippet: snippet},
+		{URL: "https://thirdpaper.example/b", Title: "Fed decision", Snippet: "WASHINGTON (AP) — " + snippet},
+		{URL: "https://reuters.com/markets/fed", Title: "Fed keeps rates on hold as inflation lingers"},
+		{URL: "https://a.example/score", Title: "Final score"},
+		{URL: "https://b.example/score", Title: "Final score"},
+	}
+	want := []int{0, 0, 0, 1, 1, 2, 3, 4}
+	if got := Group(items); !slices.Equal(got, want) {
+		t.Errorf("expected groups %v, got %v", want, got)
+	}
+}
+
+// TestCanonicalURL tests reducing mirror URLs to one page
+func TestCanonicalURL(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://www.example.com/a/":                  "example.com/a",
+		"http://m.example.com/a?utm_medium=x&id=7":    "example.com/a?id=7",
+		"https://example.com/a/amp?fbclid=1#top":      "example.com/a",
+		"https://example.com/a?outputType=amp&page=2": "example.com/a?page=2",
+		"relative/path": "",
+	} {
+		if got := CanonicalURL(raw); got != want {
+			t.Errorf("%s: expected %q, got %q", raw, want, got)
+		}
+	}
+	if !strings.HasPrefix(CanonicalURL("https://Example.COM/Path"), "example.com/Path") {
+		t.Error("expected the host lowercased and the path kept")
+	}
+}
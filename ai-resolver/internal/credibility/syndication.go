@@ -0,0 +1,175 @@
+package credibility
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Item is a source to group: its URL and what the search returned for it
+type Item struct {
+	URL     string
+	Title   string
+	Snippet string
+}
+
+// Thresholds for treating two items as copies of one article
+const (
+	minTitleWords   = 5   // Shorter titles ("Final score") are too generic to match on
+	titleSimilarity = 0.8 // Jaccard similarity of title words
+	minSnippetWords = 12
+	snippetOverlap  = 0.5 // Jaccard similarity of snippet word trigrams
+)
+
+// Query parameters that do not change the page: tracking and AMP switches
+var (
+	trackingParams   = map[string]bool{"fbclid": true, "gclid": true, "ref": true, "cmpid": true, "ocid": true, "taid": true, "amp": true, "outputtype": true}
+	trackingPrefixes = []string{"utm_", "mc_"}
+)
+
+// Group assigns each item a group number; items in one group are syndicated
+// copies of the same article: the same page under mirror or AMP URLs, a
+// matching headline, or a largely identical snippet. Groups are numbered from
+// 0 in order of their first item.
+func Group(items []Item) []int {
+	parent := make([]int, len(items))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(i, j int) {
+		ri, rj := find(i), find(j)
+		if ri < rj {
+			parent[rj] = ri
+		} else if rj < ri {
+			parent[ri] = rj
+		}
+	}
+
+	type signature struct {
+		page     string
+		title    map[string]bool
+		snippet  map[string]bool
+		titleKey string
+	}
+	sigs := make([]signature, len(items))
+	for i, item := range items {
+		titleWords := words(item.Title)
+		sigs[i] = signature{
+			page:     CanonicalURL(item.URL),
+			title:    set(titleWords),
+			snippet:  shingles(words(item.Snippet), 3),
+			titleKey: strings.Join(titleWords, " "),
+		}
+		if len(titleWords) < minTitleWords {
+			sigs[i].title = nil
+		}
+		if len(words(item.Snippet)) < minSnippetWords {
+			sigs[i].snippet = nil
+		}
+	}
+
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			a, b := sigs[i], sigs[j]
+			switch {
+			case a.page != "" && a.page == b.page:
+			case a.title != nil && b.title != nil && (a.titleKey == b.titleKey || jaccard(a.title, b.title) >= titleSimilarity):
+			case a.snippet != nil && b.snippet != nil && jaccard(a.snippet, b.snippet) >= snippetOverlap:
+			default:
+				continue
+			}
+			union(i, j)
+		}
+	}
+
+	groups := make([]int, len(items))
+	numbers := make(map[int]int)
+	for i := range items {
+		root := find(i)
+		if _, ok := numbers[root]; !ok {
+			numbers[root] = len(numbers)
+		}
+		groups[i] = numbers[root]
+	}
+	return groups
+}
+
+// CanonicalURL reduces a URL to the page it shows: no scheme, "www.", "m."
+// or "amp." host prefix, AMP path suffix, fragment, trailing slash or
+// tracking parameters. It returns "" for URLs without a host.
+func CanonicalURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, prefix := range []string{"www.", "m.", "amp.", "mobile."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+	for _, suffix := range []string{"/amp", ".amp", "/amp.html"} {
+		path = strings.TrimSuffix(path, suffix)
+	}
+
+	query := u.Query()
+	for key := range query {
+		lower := strings.ToLower(key)
+		if trackingParams[lower] || slices.ContainsFunc(trackingPrefixes, func(p string) bool { return strings.HasPrefix(lower, p) }) {
+			query.Del(key)
+		}
+	}
+
+	canonical := host + path
+	if encoded := query.Encode(); encoded != "" {
+		canonical += "?" + encoded
+	}
+	return canonical
+}
+
+// words lowercases text and splits it into words, dropping punctuation
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func set(list []string) map[string]bool {
+	out := make(map[string]bool, len(list))
+	for _, w := range list {
+		out[w] = true
+	}
+	return out
+}
+
+// shingles returns the runs of n consecutive words
+func shingles(list []string, n int) map[string]bool {
+	out := make(map[string]bool)
+	for i := 0; i+n <= len(list); i++ {
+		out[strings.Join(list[i:i+n], " ")] = true
+	}
+	return out
+}
+
+// jaccard is the size of the intersection over the size of the union
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for k := range a {
+		if b[k] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
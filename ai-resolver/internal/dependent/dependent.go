@@ -0,0 +1,166 @@
+// Package dependent resolves conditional markets: markets whose metadata
+// names a parent market and the parent outcomes they are conditional on.
+//
+// The check_parent pipeline stage waits for the parent's ResolutionModule
+// record to be finalized. When the parent's outcome meets the condition, it
+// is handed to the rest of the analysis as settled; when it does not, the
+// market resolves to its void outcome without any LLM call.
+package dependent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/project-gamma/ai-resolver/internal/adapter"
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+	"github.com/project-gamma/ai-resolver/internal/metadata"
+)
+
+// StageName is the name pipeline layouts use for the parent check
+const StageName = "check_parent"
+
+// StrategyName labels void decisions made by the stage
+const StrategyName = "parent-condition"
+
+// ErrParentPending is returned while the parent market is not finalized; the
+// market should be retried later
+var ErrParentPending = errors.New("parent market is not final")
+
+// Chain reads ResolutionModule records and winning outcomes; *adapter.Client
+// implements it
+type Chain interface {
+	GetResolution(ctx context.Context, marketID *big.Int) (*adapter.Resolution, error)
+	GetWinningOutcome(ctx context.Context, marketID *big.Int) (*big.Int, error)
+}
+
+// Stage checks the parent of conditional markets
+type Stage struct {
+	chain   Chain
+	chainID int64
+	module  common.Address // ResolutionModule, for evidence URIs
+	wait    time.Duration
+	poll    time.Duration
+}
+
+// NewStage creates a parent check for the markets of one chain. It does not
+// wait: a parent that is not final fails the stage with ErrParentPending.
+func NewStage(chain Chain, chainID int64, module common.Address) *Stage {
+	return &Stage{chain: chain, chainID: chainID, module: module, poll: 15 * time.Second}
+}
+
+// SetWait makes the stage poll the parent every poll interval, for up to
+// wait, before giving up with ErrParentPending
+func (s *Stage) SetWait(wait, poll time.Duration) {
+	s.wait = wait
+	if poll > 0 {
+		s.poll = poll
+	}
+}
+
+// Name implements llm.Stage
+func (s *Stage) Name() string { return StageName }
+
+// Inputs implements llm.Stage
+func (s *Stage) Inputs() []llm.Artifact { return []llm.Artifact{llm.ArtifactMetadata} }
+
+// Outputs implements llm.Stage
+func (s *Stage) Outputs() []llm.Artifact { return []llm.Artifact{llm.ArtifactParent} }
+
+// Run checks the parent of a conditional market. Markets without a
+// dependency skip the stage.
+func (s *Stage) Run(ctx context.Context, a *llm.Analysis) error {
+	doc, _ := a.Artifacts[llm.ArtifactMetadata].(*metadata.Document)
+	if doc == nil || doc.Resolution == nil || doc.Resolution.Dependency == nil {
+		return llm.ErrStageSkipped
+	}
+	dep := doc.Resolution.Dependency
+
+	started := time.Now()
+	outcome, err := s.finalOutcome(ctx, dep.ParentMarketID)
+	event := audit.Event{
+		Time:       started.UTC(),
+		DurationMs: time.Since(started).Milliseconds(),
+		Tool:       "parent_resolution",
+		Arguments:  audit.RawJSON(dep),
+	}
+	if err != nil {
+		event.Error = err.Error()
+		audit.RecordToolCall(ctx, event)
+		return err
+	}
+	met := slices.Contains(dep.ParentOutcomes, outcome)
+	event.Result = audit.RawJSON(map[string]any{"outcomeId": outcome, "conditionMet": met})
+	audit.RecordToolCall(ctx, event)
+
+	parent := &llm.ParentResult{MarketID: dep.ParentMarketID, OutcomeID: outcome, Condition: dep.Condition}
+	a.Artifacts[llm.ArtifactParent] = parent
+	if met {
+		a.Market.Parent = parent
+		return nil
+	}
+	a.Decision = s.voidDecision(a, dep, outcome)
+	return llm.ErrResolved
+}
+
+// finalOutcome returns the parent's winning outcome once it is finalized,
+// polling while the stage may wait. The winning outcome, not the proposed
+// one: a disputed parent may have been overturned.
+func (s *Stage) finalOutcome(ctx context.Context, marketID uint64) (uint64, error) {
+	deadline := time.Now().Add(s.wait)
+	id := new(big.Int).SetUint64(marketID)
+	for {
+		res, err := s.chain.GetResolution(ctx, id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read parent market %d: %w", marketID, err)
+		}
+		if res.State == adapter.ResolutionFinalized {
+			outcome, err := s.chain.GetWinningOutcome(ctx, id)
+			if err != nil {
+				return 0, fmt.Errorf("failed to read parent market %d: %w", marketID, err)
+			}
+			return outcome.Uint64(), nil
+		}
+		if !time.Now().Add(s.poll).Before(deadline) {
+			return 0, fmt.Errorf("%w: market %d is %s", ErrParentPending, marketID, res.State)
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(s.poll):
+		}
+	}
+}
+
+// voidDecision resolves a market whose condition failed to its void outcome,
+// with the parent's resolution record as evidence
+func (s *Stage) voidDecision(a *llm.Analysis, dep *metadata.Dependency, outcome uint64) *llm.Decision {
+	evidence := fmt.Sprintf("eip155:%d:%s?market=%d", s.chainID, s.module.Hex(), dep.ParentMarketID)
+	statement := fmt.Sprintf("Parent market #%d was finalized with outcome %d", dep.ParentMarketID, outcome)
+	condition := fmt.Sprintf("outcome %v", dep.ParentOutcomes)
+	if dep.Condition != "" {
+		condition = fmt.Sprintf("%q, outcome %v", dep.Condition, dep.ParentOutcomes)
+	}
+
+	return &llm.Decision{
+		OutcomeID:  dep.VoidOutcome,
+		Confidence: 1,
+		Reasoning: fmt.Sprintf("%s. The market is conditional on %s, which was not met, so it resolves to its void outcome %d.",
+			statement, condition, dep.VoidOutcome),
+		Facts: []llm.Fact{{Statement: statement, Sources: []string{evidence}, Confidence: 1}},
+		Citations: []llm.Citation{{
+			URL:     evidence,
+			Title:   fmt.Sprintf("ResolutionModule market %d", dep.ParentMarketID),
+			Snippet: statement,
+			Weight:  1,
+		}},
+		Timestamp: time.Now().Unix(),
+		Strategy:  StrategyName,
+	}
+}
@@ -0,0 +1,145 @@
+package dependent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/project-gamma/ai-resolver/internal/adapter"
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+	"github.com/project-gamma/ai-resolver/internal/llm/llmtest"
+	"github.com/project-gamma/ai-resolver/internal/metadata"
+)
+
+// chain serves resolution records, finalizing the parent after a few reads
+type chain struct {
+	mu        sync.Mutex
+	reads     int
+	finalAt   int // Reads before the parent is final; -1 never
+	outcome   int64
+	overruled *int64 // The arbitrator's ruling on a disputed proposal
+	unhealthy bool
+}
+
+func (c *chain) GetResolution(_ context.Context, _ *big.Int) (*adapter.Resolution, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unhealthy {
+		return nil, errors.New("rpc down")
+	}
+	c.reads++
+	if c.finalAt < 0 || c.reads <= c.finalAt {
+		return &adapter.Resolution{State: adapter.ResolutionProposed, ProposedOutcome: big.NewInt(c.outcome)}, nil
+	}
+	return &adapter.Resolution{State: adapter.ResolutionFinalized, ProposedOutcome: big.NewInt(c.outcome)}, nil
+}
+
+func (c *chain) GetWinningOutcome(_ context.Context, _ *big.Int) (*big.Int, error) {
+	if c.overruled != nil {
+		return big.NewInt(*c.overruled), nil
+	}
+	return big.NewInt(c.outcome), nil
+}
+
+func conditional() *llm.Analysis {
+	doc := &metadata.Document{Resolution: &metadata.ResolutionSpec{Dependency: &metadata.Dependency{
+		ParentMarketID: 7,
+		ParentOutcomes: []uint64{1},
+		Condition:      "Team A reaches the final",
+		VoidOutcome:    2,
+	}}}
+	return &llm.Analysis{Artifacts: map[llm.Artifact]any{llm.ArtifactMetadata: doc}}
+}
+
+// TestStage tests conditions met and failed, and waiting for the parent
+func TestStage(t *testing.T) {
+	module := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	// Condition met: the parent's result is handed to the analysis
+	stage := NewStage(&chain{outcome: 1}, 56, module)
+	a := conditional()
+	if err := stage.Run(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Market.Parent == nil || a.Market.Parent.OutcomeID != 1 || a.Decision != nil {
+		t.Errorf("expected the parent result on the market, got %+v", a.Market.Parent)
+	}
+
+	// Condition failed: the market resolves to its void outcome
+	a = conditional()
+	if err := NewStage(&chain{outcome: 0}, 56, module).Run(context.Background(), a); !errors.Is(err, llm.ErrResolved) {
+		t.Fatalf("expected the market to resolve, got %v", err)
+	}
+	if a.Decision.OutcomeID != 2 || a.Decision.Strategy != StrategyName || a.Decision.Citations[0].URL != "eip155:56:0x00000000000000000000000000000000000000AA?market=7" {
+		t.Errorf("unexpected void decision %+v", a.Decision)
+	}
+
+	// Disputed and overturned: the ruling counts, not the proposal
+	ruling := int64(0)
+	a = conditional()
+	if err := NewStage(&chain{outcome: 1, overruled: &ruling}, 56, module).Run(context.Background(), a); !errors.Is(err, llm.ErrResolved) {
+		t.Fatalf("expected the overturned parent to void the market, got %v", err)
+	}
+	if a.Decision.OutcomeID != 2 || !strings.Contains(a.Decision.Reasoning, "finalized with outcome 0") {
+		t.Errorf("expected a void decision on the ruling, got %+v", a.Decision)
+	}
+
+	// Not final: fail at once, or wait for it
+	if err := NewStage(&chain{finalAt: -1}, 56, module).Run(context.Background(), conditional()); !errors.Is(err, ErrParentPending) || !strings.Contains(err.Error(), "Proposed") {
+		t.Errorf("expected the parent to be pending, got %v", err)
+	}
+	waiting := NewStage(&chain{finalAt: 2, outcome: 1}, 56, module)
+	waiting.SetWait(time.Second, time.Millisecond)
+	if err := waiting.Run(context.Background(), conditional()); err != nil {
+		t.Errorf("expected the stage to wait for the parent, got %v", err)
+	}
+
+	if err := stage.Run(context.Background(), &llm.Analysis{Artifacts: map[llm.Artifact]any{}}); !errors.Is(err, llm.ErrStageSkipped) {
+		t.Errorf("expected markets without a dependency to skip, got %v", err)
+	}
+	if err := NewStage(&chain{unhealthy: true}, 56, module).Run(context.Background(), conditional()); err == nil || errors.Is(err, ErrParentPending) {
+		t.Errorf("expected a read error, got %v", err)
+	}
+}
+
+// unreachable is a metadata fetcher whose gateway is down
+type unreachable struct{}
+
+func (unreachable) Fetch(_ context.Context, url string) (*fetch.Page, error) {
+	return nil, fmt.Errorf("%s: gateway timeout", url)
+}
+
+// TestStageMetadataUnavailable tests that a conditional YES/NO market whose
+// metadata cannot be fetched is held for a retry, not answered unconditionally
+func TestStageMetadataUnavailable(t *testing.T) {
+	server := llmtest.NewServer(llmtest.Script(llm.Decision{OutcomeID: 1, Confidence: 0.9, Reasoning: "scripted"}, nil)...)
+	defer server.Close()
+
+	pipeline := server.Pipeline()
+	if err := pipeline.RegisterStage(metadata.NewStage(unreachable{}, "https://ipfs.io")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pipeline.RegisterStage(NewStage(&chain{outcome: 0}, 56, common.Address{})); err != nil {
+		t.Fatal(err)
+	}
+	layout := append([]llm.StageConfig{{Name: metadata.StageName}, {Name: StageName}}, llm.DefaultPipelineConfig().Stages...)
+	if err := pipeline.SetStages(&llm.PipelineConfig{Stages: layout}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := pipeline.AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "Will Team A win the final?", OutcomeCount: 2, MetadataURI: "ipfs://QmConditional"})
+	if !errors.Is(err, metadata.ErrUnavailable) {
+		t.Errorf("expected the metadata to be unavailable, got %v", err)
+	}
+	if requests := server.Requests(); len(requests) != 0 {
+		t.Errorf("expected no model call, got %d", len(requests))
+	}
+}
@@ -0,0 +1,140 @@
+package eip712
+
+import (
+	"math/big"
+	"testing"
+
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+func testProposal() ProposedOutcome {
+	return ProposedOutcome{
+		MarketID:     big.NewInt(42),
+		OutcomeID:    big.NewInt(1),
+		CloseTime:    big.NewInt(1_700_000_000),
+		EvidenceHash: ComputeEvidenceHash([]string{"https://example.com/a", "https://example.com/b"}),
+		NotBefore:    big.NewInt(1_700_000_100),
+		Deadline:     big.NewInt(1_700_007_300),
+	}
+}
+
+// TestSignAndVerify tests that signatures recover to the signing key only
+func TestSignAndVerify(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := NewSigner(big.NewInt(56), common.HexToAddress("0x1111111111111111111111111111111111111111"))
+	proposal := testProposal()
+
+	sig, err := signer.SignProposal(proposal, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sig) != 65 || (sig[64] != 27 && sig[64] != 28) {
+		t.Fatalf("expected 65-byte signature with v in {27,28}, got len=%d v=%d", len(sig), sig[64])
+	}
+
+	valid, err := signer.VerifySignature(proposal, sig, GetAddress(key))
+	if err != nil || !valid {
+		t.Fatalf("expected valid signature, got valid=%v err=%v", valid, err)
+	}
+
+	// Any field change invalidates the signature
+	tampered := testProposal()
+	tampered.OutcomeID = big.NewInt(0)
+	if valid, _ := signer.VerifySignature(tampered, sig, GetAddress(key)); valid {
+		t.Error("expected tampered proposal to fail verification")
+	}
+
+	// So does a different domain
+	other := NewSigner(big.NewInt(97), common.HexToAddress("0x1111111111111111111111111111111111111111"))
+	if valid, _ := other.VerifySignature(proposal, sig, GetAddress(key)); valid {
+		t.Error("expected signature from another chain to fail verification")
+	}
+
+	if _, err := signer.VerifySignature(proposal, sig[:64], GetAddress(key)); err == nil {
+		t.Error("expected error for short signature")
+	}
+}
+
+// TestDigestMatchesTypedData tests the digest against go-ethereum's EIP-712 encoder
+func TestDigestMatchesTypedData(t *testing.T) {
+	verifyingContract := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	signer := NewSigner(big.NewInt(97), verifyingContract)
+	proposal := testProposal()
+
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"ProposedOutcome": {
+				{Name: "marketId", Type: "uint256"},
+				{Name: "outcomeId", Type: "uint256"},
+				{Name: "closeTime", Type: "uint256"},
+				{Name: "evidenceHash", Type: "bytes32"},
+				{Name: "notBefore", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "ProposedOutcome",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "AIOracleAdapter",
+			Version:           "1",
+			ChainId:           math.NewHexOrDecimal256(97),
+			VerifyingContract: verifyingContract.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"marketId":     proposal.MarketID.String(),
+			"outcomeId":    proposal.OutcomeID.String(),
+			"closeTime":    proposal.CloseTime.String(),
+			"evidenceHash": proposal.EvidenceHash[:],
+			"notBefore":    proposal.NotBefore.String(),
+			"deadline":     proposal.Deadline.String(),
+		},
+	}
+
+	expected, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		t.Fatalf("failed to hash typed data: %v", err)
+	}
+
+	if got := signer.computeDigest(proposal); got != common.BytesToHash(expected) {
+		t.Errorf("digest mismatch: got %x, want %x", got, expected)
+	}
+}
+
+// TestComputeEvidenceHash tests the hash against keccak256(abi.encode(string[]))
+func TestComputeEvidenceHash(t *testing.T) {
+	stringArray, err := ethabi.NewType("string[]", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := ethabi.Arguments{{Type: stringArray}}
+
+	tests := [][]string{
+		{},
+		{"https://example.com"},
+		{"https://example.com/a-very-long-path-that-spans-more-than-one-32-byte-word", "ipfs://bafy"},
+	}
+
+	for _, uris := range tests {
+		encoded, err := args.Pack(uris)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := crypto.Keccak256Hash(encoded)
+
+		if got := ComputeEvidenceHash(uris); got != expected {
+			t.Errorf("evidence hash mismatch for %v: got %x, want %x", uris, got, expected)
+		}
+	}
+}
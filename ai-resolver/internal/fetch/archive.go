@@ -0,0 +1,65 @@
+package fetch
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// archiveSize caps the pages an Archive holds; the oldest go first
+const archiveSize = 256
+
+// PageFetcher fetches pages; *Fetcher and *Archive implement it
+type PageFetcher interface {
+	Fetch(ctx context.Context, url string) (*Page, error)
+}
+
+// Archive keeps the pages fetched through it for a while, by the URL asked
+// for. The page a tool read is then the page its citations are verified
+// against, with the same hash, rather than a second download that may have
+// changed in between.
+type Archive struct {
+	next PageFetcher
+	ttl  time.Duration
+
+	mu    sync.Mutex
+	pages map[string]*Page
+	order []string // URLs, oldest first
+}
+
+// NewArchive creates an archive that fetches with next and keeps pages for ttl
+func NewArchive(next PageFetcher, ttl time.Duration) *Archive {
+	return &Archive{
+		next:  next,
+		ttl:   ttl,
+		pages: make(map[string]*Page),
+	}
+}
+
+// Fetch implements PageFetcher. A page archived less than ttl ago is
+// returned without fetching it again.
+func (a *Archive) Fetch(ctx context.Context, url string) (*Page, error) {
+	a.mu.Lock()
+	page, ok := a.pages[url]
+	a.mu.Unlock()
+	if ok && time.Since(page.FetchedAt) < a.ttl {
+		return page, nil
+	}
+
+	page, err := a.next.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.pages[url]; !ok {
+		a.order = append(a.order, url)
+	}
+	a.pages[url] = page
+	for len(a.order) > archiveSize {
+		delete(a.pages, a.order[0])
+		a.order = a.order[1:]
+	}
+	return page, nil
+}
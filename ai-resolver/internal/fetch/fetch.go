@@ -0,0 +1,327 @@
+// Package fetch downloads web pages on behalf of the model without letting a
+// URL reach the resolver's own network. Private, loopback, link-local and
+// cloud metadata addresses are refused when the connection is made, after
+// DNS resolution, so neither redirects nor DNS rebinding get around the check.
+// A fetcher can also be held to a domain allowlist and to sites' robots.txt.
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+)
+
+// ErrBlocked is returned for URLs that resolve to an address the fetcher
+// must not connect to
+var ErrBlocked = errors.New("address not allowed")
+
+// ErrUnsupported is returned for responses without extractable text, e.g.
+// images
+var ErrUnsupported = errors.New("unsupported content type")
+
+// ErrDisallowed is returned for URLs the site's robots.txt excludes
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// Options configures a Fetcher
+type Options struct {
+	Timeout      time.Duration // Whole request, redirects included
+	MaxBytes     int64         // Body bytes read; longer pages are truncated
+	MaxRedirects int
+	UserAgent    string
+
+	// AllowDomains limits fetches, redirects included, to these domains and
+	// their subdomains, e.g. "bls.gov". Empty allows any public host.
+	AllowDomains []string
+
+	// Robots honors the robots.txt of each site fetched
+	Robots bool
+
+	// AllowPrivate lifts the address checks, for tests against local servers
+	AllowPrivate bool
+}
+
+// DefaultOptions returns the options used when none are configured
+func DefaultOptions() Options {
+	return Options{
+		Timeout:      15 * time.Second,
+		MaxBytes:     2 << 20,
+		MaxRedirects: 5,
+		UserAgent:    "ai-resolver/1.0 (+https://github.com/project-gamma/ai-resolver)",
+	}
+}
+
+// Fetcher downloads pages and extracts their text
+type Fetcher struct {
+	opts   Options
+	client *http.Client
+	robots *robotsCache
+}
+
+// New creates a fetcher. Zero options take their defaults.
+func New(opts Options) *Fetcher {
+	defaults := DefaultOptions()
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaults.Timeout
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaults.MaxBytes
+	}
+	if opts.MaxRedirects <= 0 {
+		opts.MaxRedirects = defaults.MaxRedirects
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = defaults.UserAgent
+	}
+	// Normalized into a copy: the caller's list may be shared, e.g. with a
+	// strategy's source rules
+	domains := make([]string, len(opts.AllowDomains))
+	for i, domain := range opts.AllowDomains {
+		domains[i] = credibility.NormalizeDomain(domain)
+	}
+	opts.AllowDomains = domains
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !opts.AllowPrivate {
+		dialer.Control = checkDial
+	}
+	transport := &http.Transport{
+		Proxy:                 nil, // A proxy would make the dial checks meaningless
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConns:          16,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	f := &Fetcher{opts: opts, robots: newRobotsCache()}
+	f.client = &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > opts.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", opts.MaxRedirects)
+			}
+			if err := f.checkURL(req.URL); err != nil {
+				return err
+			}
+			if req.URL.Path == robotsPath {
+				return nil
+			}
+			return f.checkRobots(req.Context(), req.URL)
+		},
+	}
+	return f
+}
+
+// Page is a fetched page
+type Page struct {
+	URL         string    `json:"url"` // After redirects
+	Status      int       `json:"status"`
+	ContentType string    `json:"contentType"`
+	Title       string    `json:"title,omitempty"`
+	Text        string    `json:"text"`      // Readable text, markup removed
+	Hash        string    `json:"hash"`      // SHA-256 of the body as served, hex
+	Truncated   bool      `json:"truncated"` // The body was longer than MaxBytes
+	FetchedAt   time.Time `json:"fetchedAt"`
+}
+
+// Body is a downloaded response body, before text extraction
+type Body struct {
+	URL         string // After redirects
+	Status      int
+	ContentType string // Media type, without parameters
+	Data        []byte
+	Hash        string // SHA-256 of Data, hex
+	Truncated   bool
+	FetchedAt   time.Time
+}
+
+// Download fetches a URL's body as served, up to MaxBytes, with the same
+// address checks as Fetch
+func (f *Fetcher) Download(ctx context.Context, rawURL string) (*Body, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	if err := f.checkURL(u); err != nil {
+		return nil, err
+	}
+	if err := f.checkRobots(ctx, u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", f.opts.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.5")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s returned status %d", rawURL, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.opts.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	truncated := int64(len(data)) > f.opts.MaxBytes
+	if truncated {
+		data = data[:f.opts.MaxBytes]
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	sum := sha256.Sum256(data)
+
+	return &Body{
+		URL:         resp.Request.URL.String(),
+		Status:      resp.StatusCode,
+		ContentType: mediaType,
+		Data:        data,
+		Hash:        hex.EncodeToString(sum[:]),
+		Truncated:   truncated,
+		FetchedAt:   time.Now().UTC(),
+	}, nil
+}
+
+// Fetch downloads a page and extracts its text
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	body, err := f.Download(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{
+		URL:         body.URL,
+		Status:      body.Status,
+		ContentType: body.ContentType,
+		Hash:        body.Hash,
+		Truncated:   body.Truncated,
+		FetchedAt:   body.FetchedAt,
+	}
+	page.Title, page.Text, err = Extract(body.Data, body.ContentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract %s: %w", rawURL, err)
+	}
+	return page, nil
+}
+
+// Extract returns the title and readable text of a document: HTML reduced to
+// its text, PDFs to the text of their pages, JSON indented, and other text as
+// is
+func Extract(data []byte, mediaType string) (title, text string, err error) {
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		title, text = ExtractText(string(data))
+	case mediaType == "application/pdf":
+		return ExtractPDF(data)
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var b bytes.Buffer
+		if json.Indent(&b, data, "", "  ") != nil {
+			return "", strings.TrimSpace(string(data)), nil // Truncated or invalid: as served
+		}
+		text = b.String()
+	case strings.HasPrefix(mediaType, "text/"), mediaType == "application/xml":
+		text = strings.TrimSpace(string(data))
+	default:
+		return "", "", fmt.Errorf("%w: %s", ErrUnsupported, mediaType)
+	}
+	return title, text, nil
+}
+
+// checkURL accepts http and https URLs without credentials, on the domain
+// allowlist when there is one
+func (f *Fetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrBlocked, u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("%w: no host in %s", ErrBlocked, u)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in URL", ErrBlocked)
+	}
+	if len(f.opts.AllowDomains) > 0 {
+		host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+		if !slices.ContainsFunc(f.opts.AllowDomains, func(domain string) bool { return credibility.MatchesDomain(host, domain) }) {
+			return fmt.Errorf("%w: %s is not on the domain allowlist", ErrBlocked, host)
+		}
+	}
+	return nil
+}
+
+// checkDial refuses connections to non-public addresses. It runs on the
+// resolved address of every connection, redirects included.
+func checkDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlocked, address)
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !Public(addr) {
+		return fmt.Errorf("%w: %s", ErrBlocked, host)
+	}
+	return nil
+}
+
+// nonPublic are the ranges Public rejects beyond what netip classifies
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),   // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),     // Reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, which can embed private IPv4
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // Local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // Documentation
+	netip.MustParsePrefix("fec0::/10"),       // Deprecated site-local
+	netip.MustParsePrefix("2002::/16"),       // 6to4, which can embed private IPv4
+	netip.MustParsePrefix("2001::/32"),       // Teredo
+	netip.MustParsePrefix("100::/64"),        // Discard-only
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("198.51.100.0/24"), // Documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // Documentation
+}
+
+// Public reports whether addr is a public unicast address. Loopback, private,
+// link-local (which holds the cloud metadata service at 169.254.169.254),
+// multicast and reserved addresses are not.
+func Public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublic {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
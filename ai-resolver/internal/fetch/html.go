@@ -0,0 +1,161 @@
+package fetch
+
+import (
+	"html"
+	"strings"
+)
+
+// skipped are elements whose content is not readable text
+var skipped = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"svg": true, "iframe": true, "object": true, "canvas": true,
+}
+
+// blocks are elements that start a new line of text
+var blocks = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "figcaption": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hr": true, "li": true, "main": true,
+	"nav": true, "ol": true, "p": true, "pre": true, "section": true, "table": true,
+	"td": true, "th": true, "tr": true, "ul": true,
+}
+
+// ExtractText returns the title and readable text of an HTML document:
+// markup, scripts and styles removed, entities decoded, one line per block
+func ExtractText(doc string) (title, text string) {
+	var b strings.Builder
+	for i := 0; i < len(doc); {
+		lt := strings.IndexByte(doc[i:], '<')
+		if lt < 0 {
+			b.WriteString(doc[i:])
+			break
+		}
+		b.WriteString(doc[i : i+lt])
+		i += lt
+
+		rest := doc[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			i = skipPast(doc, i+4, "-->")
+			continue
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			i = skipPast(doc, i+2, ">")
+			continue
+		}
+
+		name, closing, end := parseTag(doc, i)
+		if name == "" {
+			b.WriteByte('<') // A stray '<' in text
+			i++
+			continue
+		}
+		i = end
+
+		switch {
+		case closing:
+		case name == "title":
+			stop := indexFold(doc[i:], "</title")
+			if stop < 0 {
+				stop = len(doc) - i
+			}
+			if title == "" {
+				title = collapse(html.UnescapeString(doc[i : i+stop]))
+			}
+			i = skipPast(doc, i+stop, ">")
+			continue
+		case skipped[name]:
+			stop := indexFold(doc[i:], "</"+name)
+			if stop < 0 {
+				i = len(doc)
+				continue
+			}
+			i = skipPast(doc, i+stop, ">")
+			continue
+		}
+		if blocks[name] {
+			b.WriteByte('\n')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+
+	lines := strings.Split(html.UnescapeString(b.String()), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = collapse(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return title, strings.Join(kept, "\n")
+}
+
+// parseTag reads the tag starting at doc[i] == '<'. It returns the lowercase
+// element name, whether it is a closing tag, and the index after its '>'.
+// Quoted attribute values may contain '>'.
+func parseTag(doc string, i int) (name string, closing bool, end int) {
+	j := i + 1
+	if j < len(doc) && doc[j] == '/' {
+		closing = true
+		j++
+	}
+	start := j
+	for j < len(doc) && isNameByte(doc[j]) {
+		j++
+	}
+	if j == start || !isLetter(doc[start]) {
+		return "", false, i
+	}
+	name = strings.ToLower(doc[start:j])
+
+	var quote byte
+	for ; j < len(doc); j++ {
+		c := doc[j]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return name, closing, j + 1
+		}
+	}
+	return name, closing, len(doc)
+}
+
+// skipPast returns the index after the first marker at or after i, or the
+// end of doc
+func skipPast(doc string, i int, marker string) int {
+	if i > len(doc) {
+		return len(doc)
+	}
+	if k := strings.Index(doc[i:], marker); k >= 0 {
+		return i + k + len(marker)
+	}
+	return len(doc)
+}
+
+// indexFold is strings.Index ignoring ASCII case in s; substr is lowercase
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// collapse trims s and reduces whitespace runs to single spaces
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isNameByte(c byte) bool {
+	return isLetter(c) || c >= '0' && c <= '9' || c == '-' || c == ':'
+}
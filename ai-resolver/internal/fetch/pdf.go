@@ -0,0 +1,284 @@
+package fetch
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// ErrNoText is returned for PDFs without text the extractor can read, e.g.
+// scans, encrypted files, or fonts with custom encodings
+var ErrNoText = errors.New("no extractable text")
+
+// maxStreamBytes bounds each decompressed PDF stream
+const maxStreamBytes = 8 << 20
+
+var (
+	pdfStream   = regexp.MustCompile(`stream\r?\n`)
+	pdfFilter   = regexp.MustCompile(`/(\w+)Decode\b`)
+	pdfTitle    = regexp.MustCompile(`/Title\s*(\(|<)`)
+	pdfSkipDict = regexp.MustCompile(`/(Subtype|Type|Length1|Length2|Length3)\b`)
+)
+
+// ExtractPDF returns the title and text of a PDF. It reads the text showing
+// operators of uncompressed and Flate-compressed content streams, which
+// covers PDFs written with standard fonts, as most official reports are;
+// text in scans, encrypted files and fonts with custom encodings is not
+// recovered.
+func ExtractPDF(data []byte) (title, text string, err error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF-")) {
+		return "", "", errors.New("not a PDF")
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", "", errors.New("PDF is encrypted")
+	}
+
+	var lines []string
+	for pos := 0; ; {
+		loc := pdfStream.FindIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		start := pos + loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		dict := data[pos:][:loc[0]]
+		if obj := bytes.LastIndex(dict, []byte("obj")); obj >= 0 {
+			dict = dict[obj:]
+		}
+		if content, ok := decodeStream(dict, data[start:start+end]); ok {
+			lines = append(lines, showText(content)...)
+		}
+		pos = start + end + len("endstream")
+	}
+
+	if m := pdfTitle.FindIndex(data); m != nil {
+		if s, _ := pdfString(data, m[1]-1); s != "" {
+			title = collapse(decodeTextString(s))
+		}
+	}
+	text = strings.Join(lines, "\n")
+	if strings.TrimSpace(text) == "" {
+		return title, "", ErrNoText
+	}
+	return title, text, nil
+}
+
+// decodeStream returns the content of a page content stream. Streams that
+// are not page content, such as images, fonts and object streams, and
+// filters other than Flate are skipped.
+func decodeStream(dict, raw []byte) ([]byte, bool) {
+	if pdfSkipDict.Match(dict) {
+		return nil, false
+	}
+	filters := pdfFilter.FindAllSubmatch(dict, -1)
+	if len(filters) == 0 {
+		return raw, true
+	}
+	if len(filters) > 1 || string(filters[0][1]) != "Flate" {
+		return nil, false
+	}
+	r, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, false
+	}
+	defer r.Close()
+	content, err := io.ReadAll(io.LimitReader(r, maxStreamBytes))
+	if err != nil && len(content) == 0 {
+		return nil, false
+	}
+	return content, true
+}
+
+// showText runs the text operators of a content stream and returns its lines
+func showText(content []byte) []string {
+	var lines []string
+	var line strings.Builder
+	newline := func() {
+		if s := collapse(line.String()); s != "" {
+			lines = append(lines, s)
+		}
+		line.Reset()
+	}
+
+	var operands []string // Strings shown by the next operator
+	var numbers []float64 // Numeric operands, for TJ spacing and Td offsets
+	inArray := false
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(' || (c == '<' && i+1 < len(content) && content[i+1] != '<'):
+			s, next := pdfString(content, i)
+			operands = append(operands, s)
+			i = next
+		case c == '/': // A name, e.g. a font resource
+			for i++; i < len(content) && !isPDFDelimiter(content[i]); i++ {
+			}
+		case c == '[':
+			inArray = true
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case isPDFDelimiter(c):
+			i++
+		default:
+			start := i
+			for i < len(content) && !isPDFDelimiter(content[i]) {
+				i++
+			}
+			token := string(content[start:i])
+			if n, err := strconv.ParseFloat(token, 64); err == nil {
+				if inArray && n < -200 && len(operands) > 0 {
+					operands[len(operands)-1] += " " // Wide kerning separates words
+				}
+				numbers = append(numbers, n)
+				continue
+			}
+			if inArray {
+				continue
+			}
+			switch token {
+			case "Tj", "TJ":
+				line.WriteString(strings.Join(operands, ""))
+			case "'", `"`:
+				newline()
+				line.WriteString(strings.Join(operands, ""))
+			case "T*", "ET", "Tm":
+				newline()
+			case "Td", "TD":
+				if len(numbers) >= 2 && numbers[len(numbers)-1] != 0 {
+					newline()
+				} else {
+					line.WriteByte(' ')
+				}
+			}
+			operands, numbers = operands[:0], numbers[:0]
+		}
+	}
+	newline()
+	return lines
+}
+
+// pdfString reads the literal or hex string starting at content[i] and
+// returns its bytes as Latin-1 text, with control characters as spaces
+func pdfString(content []byte, i int) (string, int) {
+	var raw []byte
+	if content[i] == '<' {
+		end := bytes.IndexByte(content[i:], '>')
+		if end < 0 {
+			return "", len(content)
+		}
+		hex := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(" \t\r\n", r) {
+				return -1
+			}
+			return r
+		}, string(content[i+1:i+end]))
+		if len(hex)%2 == 1 {
+			hex += "0"
+		}
+		for j := 0; j+1 < len(hex); j += 2 {
+			if b, err := strconv.ParseUint(hex[j:j+2], 16, 8); err == nil {
+				raw = append(raw, byte(b))
+			}
+		}
+		return latin1(raw), i + end + 1
+	}
+
+	depth := 0
+	for i++; i < len(content); i++ {
+		c := content[i]
+		switch c {
+		case '\\':
+			i++
+			if i >= len(content) {
+				break
+			}
+			switch e := content[i]; e {
+			case 'n':
+				raw = append(raw, '\n')
+			case 'r':
+				raw = append(raw, '\r')
+			case 't':
+				raw = append(raw, '\t')
+			case 'b', 'f':
+			case '\r', '\n': // Line continuation
+				if e == '\r' && i+1 < len(content) && content[i+1] == '\n' {
+					i++
+				}
+			default:
+				if e >= '0' && e <= '7' {
+					n := 0
+					for k := 0; k < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7'; k++ {
+						n = n*8 + int(content[i]-'0')
+						i++
+					}
+					i--
+					raw = append(raw, byte(n))
+				} else {
+					raw = append(raw, e)
+				}
+			}
+		case '(':
+			depth++
+			raw = append(raw, c)
+		case ')':
+			if depth == 0 {
+				return latin1(raw), i + 1
+			}
+			depth--
+			raw = append(raw, c)
+		default:
+			raw = append(raw, c)
+		}
+	}
+	return latin1(raw), len(content)
+}
+
+// latin1 decodes bytes as Latin-1, keeping UTF-16 byte order marks intact
+// for decodeTextString and turning control characters into spaces
+func latin1(raw []byte) string {
+	if bytes.HasPrefix(raw, []byte{0xFE, 0xFF}) {
+		return string(raw)
+	}
+	runes := make([]rune, 0, len(raw))
+	for _, b := range raw {
+		if b < 0x20 || b == 0x7F {
+			runes = append(runes, ' ')
+			continue
+		}
+		runes = append(runes, rune(b))
+	}
+	return string(runes)
+}
+
+// decodeTextString decodes a PDF text string such as a title, which is
+// UTF-16BE when it starts with a byte order mark
+func decodeTextString(s string) string {
+	raw := []byte(s)
+	if !bytes.HasPrefix(raw, []byte{0xFE, 0xFF}) {
+		return s
+	}
+	units := make([]uint16, 0, len(raw)/2)
+	for j := 2; j+1 < len(raw); j += 2 {
+		units = append(units, uint16(raw[j])<<8|uint16(raw[j+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// isPDFDelimiter reports whether c ends a PDF token
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\r\n\f\x00()<>[]{}/%", c) >= 0
+}
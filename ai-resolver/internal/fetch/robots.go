@@ -0,0 +1,210 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// robotsPath is where a site publishes its robots.txt
+	robotsPath = "/robots.txt"
+
+	// robotsTTL is how long a site's robots.txt is cached
+	robotsTTL = time.Hour
+
+	// robotsMaxBytes is how much of a robots.txt is read, as RFC 9309 allows
+	robotsMaxBytes = 500 << 10
+)
+
+// robotsRule allows or disallows the paths matching a pattern
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+// robotsRules are the rules of the group that applies to the fetcher
+type robotsRules struct {
+	rules    []robotsRule
+	denyAll  bool // The site's robots.txt failed with a server error
+	loadedAt time.Time
+}
+
+// robotsCache holds the rules of the sites fetched, by scheme and host
+type robotsCache struct {
+	mu    sync.Mutex
+	sites map[string]*robotsRules
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{sites: make(map[string]*robotsRules)}
+}
+
+// checkRobots refuses u when Robots is set and the site's robots.txt excludes
+// it for the fetcher's user agent
+func (f *Fetcher) checkRobots(ctx context.Context, u *url.URL) error {
+	if !f.opts.Robots {
+		return nil
+	}
+	site := u.Scheme + "://" + u.Host
+	f.robots.mu.Lock()
+	rules, ok := f.robots.sites[site]
+	f.robots.mu.Unlock()
+
+	if !ok || time.Since(rules.loadedAt) > robotsTTL {
+		var err error
+		if rules, err = f.loadRobots(ctx, site); err != nil {
+			return err
+		}
+		f.robots.mu.Lock()
+		f.robots.sites[site] = rules
+		f.robots.mu.Unlock()
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	if !rules.allows(path) {
+		return fmt.Errorf("%w: %s", ErrDisallowed, u)
+	}
+	return nil
+}
+
+// loadRobots downloads and parses a site's robots.txt. Following RFC 9309, a
+// missing file allows everything and a server error disallows everything.
+func (f *Fetcher) loadRobots(ctx context.Context, site string) (*robotsRules, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, site+robotsPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", f.opts.UserAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s%s: %w", site, robotsPath, err)
+	}
+	defer resp.Body.Close()
+
+	rules := &robotsRules{loadedAt: time.Now()}
+	switch {
+	case resp.StatusCode >= 500:
+		rules.denyAll = true
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		data, err := io.ReadAll(io.LimitReader(resp.Body, robotsMaxBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s%s: %w", site, robotsPath, err)
+		}
+		rules.rules = parseRobots(string(data), productToken(f.opts.UserAgent))
+	}
+	return rules, nil
+}
+
+// productToken returns the name a robots.txt addresses a user agent by, e.g.
+// "ai-resolver" for "ai-resolver/1.0 (+https://...)"
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(userAgent, "/")
+	token, _, _ = strings.Cut(token, " ")
+	return strings.ToLower(token)
+}
+
+// parseRobots returns the rules of the group naming agent, or of the "*"
+// group when none does. Groups naming the same agent are merged.
+func parseRobots(text, agent string) []robotsRule {
+	var named, wildcard []robotsRule
+	var inNamed, inWildcard, inRules bool
+	for line := range strings.SplitSeq(text, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				// A user-agent line after rules starts a new group
+				inNamed, inWildcard, inRules = false, false, false
+			}
+			name := strings.ToLower(value)
+			inNamed = inNamed || (agent != "" && name == agent)
+			inWildcard = inWildcard || name == "*"
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue // An empty disallow allows everything
+			}
+			rule := robotsRule{pattern: value, allow: key == "allow"}
+			if inNamed {
+				named = append(named, rule)
+			}
+			if inWildcard {
+				wildcard = append(wildcard, rule)
+			}
+		}
+	}
+	if named != nil {
+		return named
+	}
+	return wildcard
+}
+
+// allows reports whether path may be fetched: the longest matching rule
+// decides, and allow wins a tie
+func (r *robotsRules) allows(path string) bool {
+	if r.denyAll {
+		return false
+	}
+	if path == robotsPath {
+		return true
+	}
+	best, allow := -1, true
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > best || (n == best && rule.allow) {
+			best, allow = n, rule.allow
+		}
+	}
+	return allow
+}
+
+// robotsMatch matches a path against a robots.txt pattern, in which "*"
+// matches any characters and a trailing "$" anchors the end
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	parts := strings.Split(strings.TrimSuffix(pattern, "$"), "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	if len(parts) == 1 {
+		return !anchored || rest == ""
+	}
+
+	last := parts[len(parts)-1]
+	if anchored {
+		if !strings.HasSuffix(rest, last) {
+			return false
+		}
+		rest = rest[:len(rest)-len(last)]
+	}
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return anchored || strings.Contains(rest, last)
+}
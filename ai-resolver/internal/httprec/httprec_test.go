@@ -0,0 +1,96 @@
+package httprec
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failingTransport fails the test if replay touches the network
+type failingTransport struct{ t *testing.T }
+
+func (f failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.t.Errorf("unexpected network request to %s", req.URL)
+	return nil, fmt.Errorf("network disabled")
+}
+
+func get(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+// TestRecordAndReplay tests that replay serves recorded responses in order
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"call":%d,"path":%q}`, calls, r.URL.Path)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "test.json")
+
+	recorder, err := New(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: recorder}
+	first := get(t, client, server.URL+"/price?apikey=secret")
+	second := get(t, client, server.URL+"/price?apikey=secret")
+	other := get(t, client, server.URL+"/other")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cassette not written: %v", err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Error("expected API key to be redacted from the cassette")
+	}
+
+	replayer, err := New(path, ModeReplay, failingTransport{t})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = &http.Client{Transport: replayer}
+
+	// Requests to different endpoints may interleave differently than recorded
+	if got := get(t, client, server.URL+"/other"); got != other {
+		t.Errorf("expected %s, got %s", other, got)
+	}
+	if got := get(t, client, server.URL+"/price?apikey=another"); got != first {
+		t.Errorf("expected %s, got %s", first, got)
+	}
+	if got := get(t, client, server.URL+"/price?apikey=another"); got != second {
+		t.Errorf("expected %s, got %s", second, got)
+	}
+	if replayer.Unused() != 0 {
+		t.Errorf("expected all interactions to be used, %d left", replayer.Unused())
+	}
+
+	// The cassette is exhausted
+	if _, err := client.Get(server.URL + "/other"); err == nil {
+		t.Error("expected error for unrecorded request")
+	}
+}
+
+// TestReplayMissingCassette tests that replay requires an existing fixture
+func TestReplayMissingCassette(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, nil); err == nil {
+		t.Error("expected error for missing cassette")
+	}
+	if _, err := ParseMode("stream"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
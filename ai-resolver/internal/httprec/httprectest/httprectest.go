@@ -0,0 +1,31 @@
+// Package httprectest opens httprec cassettes for tests, so the testing
+// package stays out of binaries that use httprec.
+package httprectest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/project-gamma/ai-resolver/internal/httprec"
+)
+
+// New opens a cassette for a test. It replays by default; set
+// HTTPREC_MODE=record (with real API keys) to re-record the fixture.
+func New(t testing.TB, path string) *httprec.Recorder {
+	t.Helper()
+
+	mode := httprec.ModeReplay
+	if env := os.Getenv("HTTPREC_MODE"); env != "" {
+		parsed, err := httprec.ParseMode(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mode = parsed
+	}
+
+	r, err := httprec.New(path, mode, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
@@ -0,0 +1,25 @@
+package jsontime
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestDuration tests the round trip through a duration string
+func TestDuration(t *testing.T) {
+	var d Duration
+	if err := json.Unmarshal([]byte(`"1m30s"`), &d); err != nil || time.Duration(d) != 90*time.Second {
+		t.Fatalf("expected 1m30s, got %v (%v)", time.Duration(d), err)
+	}
+	data, err := json.Marshal(d)
+	if err != nil || string(data) != `"1m30s"` {
+		t.Errorf("expected \"1m30s\", got %s (%v)", data, err)
+	}
+
+	for _, bad := range []string{`90`, `"soon"`} {
+		if err := json.Unmarshal([]byte(bad), &d); err == nil {
+			t.Errorf("expected %s to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Package lint checks market questions for ambiguities before creation and
+// builds the resolution spec to embed in the market's metadata document.
+//
+// Deterministic rules catch missing dates and timezones, subjective terms,
+// missing resolution sources and overlapping outcomes; an optional model
+// review (llm.OpenAIPipeline.ReviewQuestion) adds issues the rules cannot see
+// and a normalized rewrite of the question.
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/llm"
+	"github.com/project-gamma/ai-resolver/internal/metadata"
+	"github.com/project-gamma/ai-resolver/internal/pricefeed"
+)
+
+// Severity of an issue: errors make the question unresolvable as written
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue codes reported by the rules
+const (
+	CodeMissingDate         = "missing_date"
+	CodeVagueDate           = "vague_date"
+	CodeRelativeDate        = "relative_date"
+	CodeImplicitTime        = "implicit_time"
+	CodeMissingTimezone     = "missing_timezone"
+	CodeAmbiguousTimezone   = "ambiguous_timezone"
+	CodeClosesAfterEvent    = "closes_after_event"
+	CodeSubjectiveTerm      = "subjective_term"
+	CodeMissingSource       = "missing_source"
+	CodeTooFewOutcomes      = "too_few_outcomes"
+	CodeDuplicateOutcomes   = "duplicate_outcomes"
+	CodeOverlappingOutcomes = "overlapping_outcomes"
+	CodeOutcomeGap          = "outcome_gap"
+	CodeReviewFailed        = "review_failed"
+)
+
+// Issue is one problem found in a question
+type Issue struct {
+	Code       string   `json:"code"`
+	Severity   Severity `json:"severity"`
+	Message    string   `json:"message"`
+	Suggestion string   `json:"suggestion,omitempty"`
+	Source     string   `json:"source"` // "rules" or "model"
+}
+
+// Result is the outcome of linting a question
+type Result struct {
+	OK       bool                     `json:"ok"` // No error-severity issues
+	Issues   []Issue                  `json:"issues"`
+	Reviewed bool                     `json:"reviewed"` // The model review ran
+	Spec     *metadata.ResolutionSpec `json:"spec"`
+
+	// Document is the metadata to upload and pass as MetadataURI to
+	// MarketFactory.CreateMarket, with Spec embedded
+	Document *metadata.Document `json:"document"`
+}
+
+// Reviewer reviews a question with a model; *llm.OpenAIPipeline implements it
+type Reviewer interface {
+	ReviewQuestion(ctx context.Context, draft llm.QuestionDraft) (*llm.QuestionReview, error)
+}
+
+// Linter checks questions with rules and, if it has a reviewer, the model
+type Linter struct {
+	reviewer Reviewer
+	now      func() time.Time
+}
+
+// NewLinter creates a linter. A nil reviewer runs the rules only.
+func NewLinter(reviewer Reviewer) *Linter {
+	return &Linter{reviewer: reviewer, now: time.Now}
+}
+
+// Lint checks a draft question and builds its resolution spec. review=false
+// skips the model even when the linter has a reviewer.
+func (l *Linter) Lint(ctx context.Context, draft llm.QuestionDraft, review bool) (*Result, error) {
+	draft.Question = strings.TrimSpace(draft.Question)
+	if draft.Question == "" {
+		return nil, fmt.Errorf("question is required")
+	}
+
+	r := &rules{draft: draft, now: l.now()}
+	r.run()
+
+	result := &Result{Issues: r.issues, Spec: r.spec()}
+
+	if review && l.reviewer != nil {
+		rev, err := l.reviewer.ReviewQuestion(ctx, draft)
+		if err != nil {
+			log.Printf("Question review failed: %v", err)
+			result.Issues = append(result.Issues, Issue{
+				Code:     CodeReviewFailed,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("model review failed, rules only: %v", err),
+				Source:   "rules",
+			})
+		} else {
+			result.Reviewed = true
+			mergeReview(result, rev)
+		}
+	}
+
+	result.OK = !slices.ContainsFunc(result.Issues, func(i Issue) bool { return i.Severity == SeverityError })
+	result.Document = &metadata.Document{
+		Question:    draft.Question,
+		Description: draft.Description,
+		Category:    draft.Category,
+		CreatedAt:   l.now().Unix(),
+		Resolution:  result.Spec,
+	}
+	return result, nil
+}
+
+// EventWindow returns the window spanned by the dates a question names, in
+// UTC, or nil if it names none that can be normalized
+func EventWindow(question, description string) *metadata.Window {
+	r := &rules{draft: llm.QuestionDraft{Question: question, Description: description}}
+	r.checkDates(question + "\n" + description)
+	return r.window
+}
+
+// mergeReview adds the model's issues and fills the spec from its rewrite.
+// Rule issues win over model issues with the same code.
+func mergeReview(result *Result, rev *llm.QuestionReview) {
+	for _, issue := range rev.Issues {
+		code := strings.ToLower(strings.TrimSpace(issue.Code))
+		if slices.ContainsFunc(result.Issues, func(i Issue) bool { return i.Code == code }) {
+			continue
+		}
+		result.Issues = append(result.Issues, Issue{
+			Code:       code,
+			Severity:   Severity(issue.Severity),
+			Message:    issue.Message,
+			Suggestion: issue.Suggestion,
+			Source:     "model",
+		})
+	}
+
+	spec := result.Spec
+	if q := strings.TrimSpace(rev.NormalizedQuestion); q != "" {
+		spec.Question = q
+	}
+	if c := strings.TrimSpace(rev.Criteria); c != "" {
+		spec.Criteria = c
+	}
+	for _, source := range rev.Sources {
+		spec.Sources = appendUnique(spec.Sources, source)
+	}
+	if len(rev.Outcomes) == len(spec.Outcomes) {
+		for i, outcome := range rev.Outcomes {
+			spec.Outcomes[i].Condition = strings.TrimSpace(outcome.Condition)
+		}
+	}
+}
+
+// spec builds the resolution spec from what the rules found
+func (r *rules) spec() *metadata.ResolutionSpec {
+	spec := &metadata.ResolutionSpec{
+		Version:         metadata.SpecVersion,
+		Question:        r.draft.Question,
+		Outcomes:        metadata.BinaryOutcomes(),
+		Window:          r.window,
+		ObservationTime: r.draft.CloseTime,
+		Timezone:        "UTC",
+		Sources:         []string{},
+	}
+	if r.window != nil {
+		spec.ObservationTime = r.window.End
+	}
+
+	if len(r.draft.Outcomes) > 0 {
+		spec.Outcomes = make([]metadata.Outcome, len(r.draft.Outcomes))
+		for i, label := range r.draft.Outcomes {
+			spec.Outcomes[i] = metadata.Outcome{ID: uint64(i), Label: strings.TrimSpace(label)}
+		}
+	}
+
+	for _, source := range append(append([]string{}, r.draft.Sources...), r.sources...) {
+		spec.Sources = appendUnique(spec.Sources, source)
+	}
+
+	if price, err := pricefeed.Parse(r.draft.Question, spec.ObservationTime); err == nil {
+		spec.Price = price
+		spec.Sources = appendUnique(spec.Sources, string(orDefault(price.Source, pricefeed.SourceChainlink)))
+	} else if !errors.Is(err, pricefeed.ErrNotPriceQuestion) {
+		log.Printf("Price question not parsed: %v", err)
+	}
+
+	at := "the market close"
+	if spec.ObservationTime > 0 {
+		at = metadata.FormatTime(spec.ObservationTime)
+	}
+	if len(r.draft.Outcomes) == 0 {
+		spec.Criteria = fmt.Sprintf("Resolves Yes if the answer to %q is yes as of %s, otherwise No.", spec.Question, at)
+	} else {
+		spec.Criteria = fmt.Sprintf("Resolves to the outcome of %q that holds as of %s.", spec.Question, at)
+	}
+	return spec
+}
+
+func appendUnique(list []string, s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" || slices.ContainsFunc(list, func(e string) bool { return strings.EqualFold(e, s) }) {
+		return list
+	}
+	return append(list, s)
+}
+
+func orDefault(source, fallback pricefeed.Source) pricefeed.Source {
+	if source == "" {
+		return fallback
+	}
+	return source
+}
@@ -0,0 +1,187 @@
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/llm"
+	"github.com/project-gamma/ai-resolver/internal/llm/llmtest"
+)
+
+var testNow = time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+func lint(t *testing.T, l *Linter, draft llm.QuestionDraft) *Result {
+	t.Helper()
+	l.now = func() time.Time { return testNow }
+	result, err := l.Lint(context.Background(), draft, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func codes(result *Result) []string {
+	var out []string
+	for _, issue := range result.Issues {
+		out = append(out, issue.Code)
+	}
+	return out
+}
+
+// TestRules tests which issues the rules report
+func TestRules(t *testing.T) {
+	closeTime := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+	tests := []struct {
+		name  string
+		draft llm.QuestionDraft
+		want  []string // Issue codes, in order
+		ok    bool
+	}{
+		{
+			name:  "clean",
+			draft: llm.QuestionDraft{Question: "Will the Fed announce a rate cut on 2025-03-19 at 18:00 UTC according to federalreserve.gov?", CloseTime: closeTime},
+			ok:    true,
+		},
+		{
+			name:  "no date",
+			draft: llm.QuestionDraft{Question: "Will Team A win the final?", Sources: []string{"espn.com"}, CloseTime: closeTime},
+			want:  []string{CodeMissingDate},
+			ok:    true,
+		},
+		{
+			name:  "no date, no close time",
+			draft: llm.QuestionDraft{Question: "Will Team A win the final?", Sources: []string{"espn.com"}},
+			want:  []string{CodeMissingDate},
+		},
+		{
+			name:  "time without timezone",
+			draft: llm.QuestionDraft{Question: "Will the launch happen by March 1, 2025 at 9:30 pm?", Sources: []string{"nasa.gov"}},
+			want:  []string{CodeMissingTimezone},
+		},
+		{
+			name:  "ambiguous timezone, subjective term, no source",
+			draft: llm.QuestionDraft{Question: "Will the index rise significantly before 2025-03-01 16:00 ET?", CloseTime: closeTime},
+			want:  []string{CodeAmbiguousTimezone, CodeSubjectiveTerm, CodeMissingSource},
+		},
+		{
+			name:  "relative and vague dates",
+			draft: llm.QuestionDraft{Question: "Will it snow in Paris tomorrow or in March 2025 per Meteo France?", CloseTime: closeTime},
+			want:  []string{CodeRelativeDate, CodeVagueDate},
+		},
+		{
+			name: "overlapping brackets",
+			draft: llm.QuestionDraft{
+				Question: "What will BTC close at on 2025-02-28 per CoinGecko?", CloseTime: closeTime,
+				Outcomes: []string{"under $90k", "$90k-$100k", "$100k-$110k", "$110k+"},
+			},
+			want: []string{CodeImplicitTime, CodeClosesAfterEvent, CodeOverlappingOutcomes, CodeOverlappingOutcomes},
+		},
+		{
+			name: "duplicate outcomes and gap",
+			draft: llm.QuestionDraft{
+				Question: "How many goals will be scored on 2025-03-01 per UEFA?", CloseTime: closeTime,
+				Outcomes: []string{"0", "1", "3 or more", "3 or more"},
+			},
+			want: []string{CodeImplicitTime, CodeDuplicateOutcomes, CodeOutcomeGap, CodeOverlappingOutcomes},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := lint(t, NewLinter(nil), tt.draft)
+			if got := codes(result); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+				for _, issue := range result.Issues {
+					t.Logf("  %s: %s", issue.Code, issue.Message)
+				}
+			}
+			if result.OK != tt.ok {
+				t.Errorf("expected ok=%v", tt.ok)
+			}
+		})
+	}
+}
+
+// TestSpec tests the normalized resolution spec
+func TestSpec(t *testing.T) {
+	result := lint(t, NewLinter(nil), llm.QuestionDraft{
+		Question:  "Will the merger close between Feb 1, 2025 and March 3rd, 2025 5:00 PM EST according to the SEC?",
+		Category:  "business",
+		CloseTime: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC).Unix(),
+	})
+	spec := result.Spec
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("invalid spec: %v", err)
+	}
+
+	start := time.Date(2025, 2, 1, 23, 59, 59, 0, time.UTC).Unix()
+	end := time.Date(2025, 3, 3, 22, 0, 0, 0, time.UTC).Unix() // 17:00 EST
+	if spec.Window == nil || spec.Window.Start != start || spec.Window.End != end || spec.ObservationTime != end {
+		t.Errorf("unexpected window %+v, observation %d", spec.Window, spec.ObservationTime)
+	}
+	if w := EventWindow("Will the merger close between Feb 1, 2025 and March 3rd, 2025 5:00 PM EST?", ""); w == nil || *w != *spec.Window {
+		t.Errorf("expected EventWindow to match the spec, got %+v", w)
+	}
+	if !slices.Equal(spec.Sources, []string{"SEC"}) {
+		t.Errorf("unexpected sources %v", spec.Sources)
+	}
+	if !strings.Contains(spec.Criteria, "2025-03-03 22:00:00 UTC") {
+		t.Errorf("expected the UTC deadline in the criteria: %s", spec.Criteria)
+	}
+	if result.Document.Resolution != spec || result.Document.CreatedAt != testNow.Unix() {
+		t.Errorf("expected the spec embedded in the document: %+v", result.Document)
+	}
+
+	price := lint(t, NewLinter(nil), llm.QuestionDraft{Question: "Will BNB close above $700 on 2025-03-01?"}).Spec
+	if price.Price == nil || price.Price.Threshold != "700" || !slices.Contains(price.Sources, "chainlink") {
+		t.Errorf("expected a price spec with a chainlink source, got %+v", price)
+	}
+}
+
+// TestReview tests merging the model review into the rules' result
+func TestReview(t *testing.T) {
+	review, _ := json.Marshal(llm.QuestionReview{
+		Issues: []llm.ReviewIssue{
+			{Code: "missing_source", Severity: "error", Message: "duplicate of a rule"},
+			{Code: "undefined_event", Severity: "error", Message: "\"win\" is undefined for a tie", Suggestion: "Say how ties resolve"},
+		},
+		NormalizedQuestion: "Will Team A win the 2025 final on 2025-05-31, per uefa.com, with a tie resolving No?",
+		Criteria:           "Yes if Team A is the official winner, including extra time and penalties.",
+		Sources:            []string{"uefa.com"},
+		Outcomes:           []llm.ReviewOutcome{{Label: "No", Condition: "Team A does not win"}, {Label: "Yes", Condition: "Team A wins"}},
+	})
+	server := llmtest.NewServer(string(review))
+	defer server.Close()
+
+	result := lint(t, NewLinter(server.Pipeline()), llm.QuestionDraft{Question: "Will Team A win the final on 2025-05-31?"})
+	if !result.Reviewed || result.OK {
+		t.Errorf("expected a reviewed, failing result: %+v", result)
+	}
+	want := []string{CodeImplicitTime, CodeMissingSource, "undefined_event"}
+	if got := codes(result); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	spec := result.Spec
+	if !strings.HasPrefix(spec.Question, "Will Team A win the 2025 final") || spec.Outcomes[1].Condition != "Team A wins" ||
+		!slices.Equal(spec.Sources, []string{"uefa.com"}) {
+		t.Errorf("expected the review in the spec: %+v", spec)
+	}
+	if prompt := server.Requests()[0].Body["messages"]; !strings.Contains(jsonString(prompt), "No | Yes") {
+		t.Errorf("expected the outcomes in the prompt: %v", prompt)
+	}
+
+	// A failed review leaves the rules' result
+	result = lint(t, NewLinter(server.Pipeline()), llm.QuestionDraft{Question: "Will Team A win the final on 2025-05-31?"})
+	if result.Reviewed || !slices.Contains(codes(result), CodeReviewFailed) || result.Spec.Question != "Will Team A win the final on 2025-05-31?" {
+		t.Errorf("expected a rules-only result: %+v", result)
+	}
+}
+
+func jsonString(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
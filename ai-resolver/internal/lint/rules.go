@@ -0,0 +1,460 @@
+package lint
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/llm"
+	"github.com/project-gamma/ai-resolver/internal/metadata"
+)
+
+// rules runs the deterministic checks over one draft
+type rules struct {
+	draft llm.QuestionDraft
+	now   time.Time
+
+	issues  []Issue
+	window  *metadata.Window // From the dates in the text
+	sources []string         // Named in the text
+}
+
+func (r *rules) run() {
+	text := r.draft.Question + "\n" + r.draft.Description
+	r.checkDates(text)
+	r.checkSubjective(r.draft.Question)
+	r.checkSources(text)
+	r.checkOutcomes()
+}
+
+func (r *rules) add(code string, severity Severity, message, suggestion string) {
+	r.issues = append(r.issues, Issue{Code: code, Severity: severity, Message: message, Suggestion: suggestion, Source: "rules"})
+}
+
+// Timezone abbreviations with a fixed UTC offset in hours. ET, PT and the
+// like switch with daylight saving time and are rejected as ambiguous.
+var zoneOffsets = map[string]float64{
+	"utc": 0, "gmt": 0, "z": 0,
+	"est": -5, "edt": -4, "cst": -6, "cdt": -5, "mst": -7, "mdt": -6, "pst": -8, "pdt": -7,
+	"cet": 1, "cest": 2, "eet": 2, "eest": 3, "wet": 0, "west": 1,
+	"jst": 9, "kst": 9, "sgt": 8, "hkt": 8, "aest": 10, "aedt": 11,
+}
+
+var ambiguousZones = map[string]bool{"et": true, "pt": true, "ct": true, "mt": true, "ist": true, "bst": true}
+
+var months = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+const (
+	monthPattern = `(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?`
+	clockPattern = `(?:T|,?\s+(?:at\s+)?)(\d{1,2})(?::(\d{2}))?(?::(\d{2}))?\s*(am|pm|a\.m\.|p\.m\.)?` +
+		`(?:\s*\(?((?:utc|gmt)\s*[+-]\s*\d{1,2}(?::?\d{2})?|[a-z]{1,4}\b)\)?)?`
+)
+
+var (
+	isoDateRe    = regexp.MustCompile(`(?i)\b(\d{4})-(\d{2})-(\d{2})(?:` + clockPattern + `)?`)
+	monthFirstRe = regexp.MustCompile(`(?i)\b` + monthPattern + `\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})(?:` + clockPattern + `)?`)
+	dayFirstRe   = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?` + monthPattern + `,?\s+(\d{4})(?:` + clockPattern + `)?`)
+	vagueDateRe  = regexp.MustCompile(`(?i)\b(?:` + monthPattern + `\s+\d{4}|q[1-4]\s+\d{4}|(?:end of|by|in|before|during)\s+(?:the\s+)?(?:year\s+)?\d{4}|(?:early|mid|late)[- ]\d{4})\b`)
+	relativeRe   = regexp.MustCompile(`(?i)\b(today|tomorrow|tonight|yesterday|this (?:week|month|year|weekend|season)|next (?:week|month|year|weekend|season)|soon|in the near future|upcoming)\b`)
+	looseClockRe = regexp.MustCompile(`(?i)\b\d{1,2}(?::\d{2})\s*(?:am|pm|a\.m\.|p\.m\.)?|\b\d{1,2}\s*(?:am|pm|a\.m\.|p\.m\.)`)
+	zoneRe       = regexp.MustCompile(`(?i)^(utc|gmt)\s*([+-])\s*(\d{1,2})(?::?(\d{2}))?$`)
+)
+
+// dateRef is an absolute date found in the text
+type dateRef struct {
+	text string
+	unix int64
+}
+
+// checkDates finds the dates in the text, normalizes them to UTC and derives
+// the event window
+func (r *rules) checkDates(text string) {
+	var refs []dateRef
+	covered := make([][2]int, 0) // Spans already parsed, so day-first and month-first don't double count
+
+	parse := func(re *regexp.Regexp, order string) {
+		for _, loc := range re.FindAllStringSubmatchIndex(text, -1) {
+			if overlaps(covered, loc[0], loc[1]) {
+				continue
+			}
+			m := submatches(text, loc)
+			ref, ok := r.parseDate(m, order)
+			covered = append(covered, [2]int{loc[0], loc[1]})
+			if ok {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	parse(isoDateRe, "ymd")
+	parse(monthFirstRe, "mdy")
+	parse(dayFirstRe, "dmy")
+
+	// Clock times outside a date still need a timezone
+	for _, loc := range looseClockRe.FindAllStringIndex(text, -1) {
+		if overlaps(covered, loc[0], loc[1]) {
+			continue
+		}
+		rest := strings.ToLower(strings.TrimSpace(text[loc[1]:]))
+		if !hasZonePrefix(rest) {
+			r.add(CodeMissingTimezone, SeverityError,
+				fmt.Sprintf("time %q has no timezone", strings.TrimSpace(text[loc[0]:loc[1]])),
+				"State times in UTC, e.g. \"12:00 UTC\"")
+		}
+	}
+
+	if m := relativeRe.FindString(text); m != "" {
+		r.add(CodeRelativeDate, SeverityError,
+			fmt.Sprintf("%q depends on when the question is read", m),
+			"Replace relative dates with calendar dates, e.g. \"2025-03-01\"")
+	}
+
+	if len(refs) == 0 {
+		if len(covered) > 0 {
+			return // Dates were found but could not be normalized, as reported above
+		}
+		if loc := vagueDateRe.FindStringIndex(text); loc != nil {
+			r.add(CodeVagueDate, SeverityWarning,
+				fmt.Sprintf("%q does not name a day; the market close time is used", text[loc[0]:loc[1]]),
+				"Give the exact deadline, e.g. \"by 2025-12-31 23:59 UTC\"")
+			return
+		}
+		severity, message := SeverityWarning, "question has no date; the market close time is used as the deadline"
+		if r.draft.CloseTime <= 0 {
+			severity, message = SeverityError, "question has no date and no close time is set"
+		}
+		r.add(CodeMissingDate, severity, message, "Add the deadline or observation time, e.g. \"on 2025-03-01 at 12:00 UTC\"")
+		return
+	}
+
+	sort.Slice(refs, func(i, j int) bool { return refs[i].unix < refs[j].unix })
+	r.window = &metadata.Window{End: refs[len(refs)-1].unix}
+	if len(refs) > 1 {
+		r.window.Start = refs[0].unix
+	}
+
+	if r.draft.CloseTime > r.window.End {
+		r.add(CodeClosesAfterEvent, SeverityWarning,
+			fmt.Sprintf("trading closes at %s, after the outcome is known at %s",
+				metadata.FormatTime(r.draft.CloseTime), metadata.FormatTime(r.window.End)),
+			"Close the market at or before the deadline")
+	}
+}
+
+// parseDate converts a date match to UTC, reporting timezone problems
+func (r *rules) parseDate(m []string, order string) (dateRef, bool) {
+	var year, day int
+	var month time.Month
+	switch order {
+	case "ymd":
+		year, _ = strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		month = time.Month(mo)
+		day, _ = strconv.Atoi(m[3])
+	case "mdy":
+		month = months[strings.ToLower(m[1][:3])]
+		day, _ = strconv.Atoi(m[2])
+		year, _ = strconv.Atoi(m[3])
+	case "dmy":
+		day, _ = strconv.Atoi(m[1])
+		month = months[strings.ToLower(m[2][:3])]
+		year, _ = strconv.Atoi(m[3])
+	}
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day || date.Month() != month {
+		r.add(CodeVagueDate, SeverityError, fmt.Sprintf("%q is not a valid date", m[0]), "")
+		return dateRef{}, false
+	}
+
+	hour, minute, second, ampm, zone := m[4], m[5], m[6], strings.ToLower(strings.ReplaceAll(m[7], ".", "")), strings.ToLower(m[8])
+	if hour == "" || (minute == "" && ampm == "") {
+		// A bare number after the date is not a time
+		r.add(CodeImplicitTime, SeverityWarning,
+			fmt.Sprintf("%q has no time; read as the end of the day, 23:59:59 UTC", strings.TrimSpace(m[0])),
+			"Give the exact time in UTC")
+		return dateRef{text: m[0], unix: date.Add(24*time.Hour - time.Second).Unix()}, true
+	}
+
+	h, _ := strconv.Atoi(hour)
+	mi, _ := strconv.Atoi(minute)
+	s, _ := strconv.Atoi(second)
+	switch ampm {
+	case "am":
+		if h == 12 {
+			h = 0
+		}
+	case "pm":
+		if h < 12 {
+			h += 12
+		}
+	}
+	if h > 23 || mi > 59 || s > 59 {
+		r.add(CodeVagueDate, SeverityError, fmt.Sprintf("%q is not a valid time", strings.TrimSpace(m[0])), "")
+		return dateRef{}, false
+	}
+
+	offset, ok := r.zoneOffset(zone, m[0])
+	if !ok {
+		return dateRef{}, false
+	}
+	local := date.Add(time.Duration(h)*time.Hour + time.Duration(mi)*time.Minute + time.Duration(s)*time.Second)
+	return dateRef{text: m[0], unix: local.Add(-offset).Unix()}, true
+}
+
+// zoneOffset resolves a timezone name, reporting missing or ambiguous ones
+func (r *rules) zoneOffset(zone, text string) (time.Duration, bool) {
+	text = strings.TrimSpace(text)
+	if zone == "" {
+		r.add(CodeMissingTimezone, SeverityError, fmt.Sprintf("%q has no timezone", text), "State times in UTC, e.g. \"12:00 UTC\"")
+		return 0, false
+	}
+	if m := zoneRe.FindStringSubmatch(zone); m != nil {
+		h, _ := strconv.Atoi(m[3])
+		mi, _ := strconv.Atoi(m[4])
+		offset := time.Duration(h)*time.Hour + time.Duration(mi)*time.Minute
+		if m[2] == "-" {
+			offset = -offset
+		}
+		return offset, true
+	}
+	if hours, ok := zoneOffsets[zone]; ok {
+		return time.Duration(hours * float64(time.Hour)), true
+	}
+	if ambiguousZones[zone] {
+		r.add(CodeAmbiguousTimezone, SeverityError,
+			fmt.Sprintf("%q: %s changes with daylight saving time or names several zones", text, strings.ToUpper(zone)),
+			"Use UTC or a fixed offset such as EST or UTC-5")
+		return 0, false
+	}
+	// Not a zone after all (e.g. "at 12:00 on"), so the time has none
+	r.add(CodeMissingTimezone, SeverityError, fmt.Sprintf("%q has no timezone", text), "State times in UTC, e.g. \"12:00 UTC\"")
+	return 0, false
+}
+
+func hasZonePrefix(s string) bool {
+	s = strings.TrimLeft(s, "( ")
+	word := strings.FieldsFunc(s, func(r rune) bool { return !(r >= 'a' && r <= 'z') })
+	if len(word) == 0 {
+		return false
+	}
+	_, fixed := zoneOffsets[word[0]]
+	return fixed || ambiguousZones[word[0]]
+}
+
+// subjectiveTerms have no agreed threshold and need a measurable definition
+var subjectiveTerms = []string{
+	"significant", "significantly", "substantial", "substantially", "major", "massive", "huge",
+	"dramatic", "dramatically", "considerable", "notable", "popular", "successful", "widely",
+	"strong", "strongly", "weak", "good", "bad", "best", "worst", "reasonable",
+	"approximately", "roughly", "around", "nearly", "almost", "sharply", "mainstream", "viral",
+}
+
+var subjectiveRe = regexp.MustCompile(`(?i)\b(` + strings.Join(subjectiveTerms, "|") + `)\b`)
+
+func (r *rules) checkSubjective(question string) {
+	seen := make(map[string]bool)
+	for _, term := range subjectiveRe.FindAllString(question, -1) {
+		term = strings.ToLower(term)
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		r.add(CodeSubjectiveTerm, SeverityWarning,
+			fmt.Sprintf("%q is subjective", term),
+			"Replace it with a measurable threshold, e.g. \"by at least 10%\"")
+	}
+}
+
+var (
+	urlRe         = regexp.MustCompile(`https?://[^\s)"'<>]+`)
+	domainRe      = regexp.MustCompile(`(?i)\b[a-z0-9][a-z0-9-]*(?:\.[a-z0-9-]+)*\.(?:com|org|gov|net|io|int|edu|co\.uk|gov\.uk|eu|finance)\b`)
+	namedSourceRe = regexp.MustCompile(`\b(?:[Aa]ccording to|[Aa]s reported by|[Aa]s published by|[Pp]er|[Rr]esolves? (?:using|based on|by)|[Ss]ource:)\s+(?:the\s+)?((?:[A-Z][\w.&'-]*)(?:\s+(?:[A-Z][\w.&'-]*|of|for|and))*)`)
+)
+
+func (r *rules) checkSources(text string) {
+	for _, u := range urlRe.FindAllString(text, -1) {
+		r.sources = appendUnique(r.sources, strings.TrimRight(u, ".,;"))
+	}
+	if len(r.sources) == 0 {
+		for _, d := range domainRe.FindAllString(text, -1) {
+			r.sources = appendUnique(r.sources, strings.ToLower(d))
+		}
+	}
+	for _, m := range namedSourceRe.FindAllStringSubmatch(text, -1) {
+		r.sources = appendUnique(r.sources, strings.TrimSpace(m[1]))
+	}
+
+	if len(r.sources) == 0 && len(r.draft.Sources) == 0 {
+		r.add(CodeMissingSource, SeverityWarning,
+			"no resolution source is named",
+			"Name the source that settles the question, e.g. \"according to the official results on fec.gov\"")
+	}
+}
+
+// interval is a numeric outcome range
+type interval struct {
+	label            string
+	lo, hi           float64
+	loOpen, hiOpen   bool
+	loBound, hiBound bool // False for an unbounded side
+}
+
+var (
+	numberPattern = `\$?\s*(-?[0-9][0-9,]*(?:\.[0-9]+)?)\s*([kmb])?\s*%?`
+	belowRe       = regexp.MustCompile(`(?i)^(?:<|under|below|less than|fewer than)\s*` + numberPattern + `$`)
+	atMostRe      = regexp.MustCompile(`(?i)^(?:<=|≤|at most|up to|no more than)\s*` + numberPattern + `$|^` + numberPattern + `\s*or (?:less|fewer|below|lower)$`)
+	aboveRe       = regexp.MustCompile(`(?i)^(?:>|over|above|more than|greater than)\s*` + numberPattern + `$`)
+	atLeastRe     = regexp.MustCompile(`(?i)^(?:>=|≥|at least)\s*` + numberPattern + `$|^` + numberPattern + `\s*(?:\+|or (?:more|higher|above|greater))$`)
+	betweenRe     = regexp.MustCompile(`(?i)^(?:between\s+)?` + numberPattern + `\s*(?:-|–|to|and)\s*` + numberPattern + `$`)
+	exactRe       = regexp.MustCompile(`(?i)^` + numberPattern + `$`)
+)
+
+// parseInterval reads an outcome label such as "<100", "100-200" or "200+"
+func parseInterval(label string) (interval, bool) {
+	s := strings.TrimSpace(label)
+	num := func(digits, suffix string) float64 {
+		r, ok := new(big.Rat).SetString(strings.ReplaceAll(digits, ",", ""))
+		if !ok {
+			return math.NaN()
+		}
+		f, _ := r.Float64()
+		switch strings.ToLower(suffix) {
+		case "k":
+			f *= 1e3
+		case "m":
+			f *= 1e6
+		case "b":
+			f *= 1e9
+		}
+		return f
+	}
+	// first returns the first matched number of a two-alternative pattern
+	first := func(m []string) float64 {
+		if m[1] != "" {
+			return num(m[1], m[2])
+		}
+		return num(m[3], m[4])
+	}
+
+	iv := interval{label: s}
+	if m := belowRe.FindStringSubmatch(s); m != nil {
+		iv.hi, iv.hiOpen, iv.hiBound = num(m[1], m[2]), true, true
+	} else if m := atMostRe.FindStringSubmatch(s); m != nil {
+		iv.hi, iv.hiBound = first(m), true
+	} else if m := aboveRe.FindStringSubmatch(s); m != nil {
+		iv.lo, iv.loOpen, iv.loBound = num(m[1], m[2]), true, true
+	} else if m := atLeastRe.FindStringSubmatch(s); m != nil {
+		iv.lo, iv.loBound = first(m), true
+	} else if m := betweenRe.FindStringSubmatch(s); m != nil {
+		iv.lo, iv.hi, iv.loBound, iv.hiBound = num(m[1], m[2]), num(m[3], m[4]), true, true
+	} else if m := exactRe.FindStringSubmatch(s); m != nil {
+		iv.lo = num(m[1], m[2])
+		iv.hi, iv.loBound, iv.hiBound = iv.lo, true, true
+	} else {
+		return interval{}, false
+	}
+	if math.IsNaN(iv.lo) || math.IsNaN(iv.hi) || (iv.loBound && iv.hiBound && iv.lo > iv.hi) {
+		return interval{}, false
+	}
+	return iv, true
+}
+
+func (r *rules) checkOutcomes() {
+	outcomes := r.draft.Outcomes
+	if len(outcomes) == 0 {
+		return
+	}
+	if len(outcomes) < 2 {
+		r.add(CodeTooFewOutcomes, SeverityError, "a market needs at least 2 outcomes", "")
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, label := range outcomes {
+		key := strings.ToLower(strings.Join(strings.Fields(label), " "))
+		if seen[key] {
+			r.add(CodeDuplicateOutcomes, SeverityError, fmt.Sprintf("outcome %q is listed twice", strings.TrimSpace(label)), "")
+		}
+		seen[key] = true
+	}
+
+	intervals := make([]interval, 0, len(outcomes))
+	integral := true
+	for _, label := range outcomes {
+		iv, ok := parseInterval(label)
+		if !ok {
+			return // Not a numeric bracket market
+		}
+		intervals = append(intervals, iv)
+		integral = integral && iv.lo == math.Trunc(iv.lo) && iv.hi == math.Trunc(iv.hi)
+	}
+	if integral {
+		// Whole-number brackets count discrete values: "1-2" and "3-4" are
+		// contiguous, as are "0" and "1"
+		for i := range intervals {
+			iv := &intervals[i]
+			if iv.hiBound && !iv.hiOpen {
+				iv.hi, iv.hiOpen = iv.hi+1, true
+			}
+			if iv.loBound && iv.loOpen {
+				iv.lo, iv.loOpen = iv.lo+1, false
+			}
+		}
+	}
+
+	sort.SliceStable(intervals, func(i, j int) bool {
+		a, b := intervals[i], intervals[j]
+		if !a.loBound || !b.loBound {
+			return !a.loBound && b.loBound
+		}
+		return a.lo < b.lo
+	})
+
+	for i := 0; i+1 < len(intervals); i++ {
+		a, b := intervals[i], intervals[i+1]
+		switch {
+		case !a.hiBound || !b.loBound || a.hi > b.lo || (a.hi == b.lo && !a.hiOpen && !b.loOpen):
+			r.add(CodeOverlappingOutcomes, SeverityError,
+				fmt.Sprintf("outcomes %q and %q overlap", a.label, b.label),
+				"Make brackets half-open, e.g. \"100 to under 200\" and \"200 or more\"")
+		case a.hi < b.lo || (a.hi == b.lo && a.hiOpen && b.loOpen):
+			r.add(CodeOutcomeGap, SeverityWarning,
+				fmt.Sprintf("no outcome covers values between %q and %q", a.label, b.label),
+				"Make the brackets contiguous")
+		}
+	}
+	if first := intervals[0]; first.loBound && !(first.lo == 0 && !first.loOpen) {
+		r.add(CodeOutcomeGap, SeverityWarning, fmt.Sprintf("no outcome covers values below %q", first.label), "")
+	}
+	if last := intervals[len(intervals)-1]; last.hiBound {
+		r.add(CodeOutcomeGap, SeverityWarning, fmt.Sprintf("no outcome covers values above %q", last.label), "")
+	}
+}
+
+// submatches returns the strings of a FindStringSubmatchIndex result
+func submatches(s string, loc []int) []string {
+	m := make([]string, len(loc)/2)
+	for i := range m {
+		if loc[2*i] >= 0 {
+			m[i] = s[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return m
+}
+
+func overlaps(spans [][2]int, start, end int) bool {
+	for _, s := range spans {
+		if start < s[1] && end > s[0] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+)
+
+// urlCitation is a url_citation annotation: a web search result the API
+// attached to a span of the output text. Unlike the sources the model writes
+// into its JSON, these are pages the search tool actually returned.
+type urlCitation struct {
+	URL        string
+	Title      string
+	Start, End int // Byte offsets of the cited span in the output text
+
+	// Retrieved marks a page a function tool returned rather than an
+	// annotation: it backs a source the model declares but spans no text
+	Retrieved bool
+}
+
+// toolCitations reads the pages a function tool returned from the
+// "citations" list of its result, e.g. the documents of search_documents
+func toolCitations(result []byte) []urlCitation {
+	var listed struct {
+		Citations []struct {
+			URL   string `json:"url"`
+			Title string `json:"title"`
+		} `json:"citations"`
+	}
+	if json.Unmarshal(result, &listed) != nil {
+		return nil
+	}
+	var cites []urlCitation
+	for _, c := range listed.Citations {
+		if c.URL != "" {
+			cites = append(cites, urlCitation{URL: c.URL, Title: c.Title, Retrieved: true})
+		}
+	}
+	return cites
+}
+
+// urlCitations reads the url_citation annotations of an output text. The API
+// counts offsets in characters; they are converted to byte offsets here.
+func urlCitations(content openAIResponsesAPIContent) []urlCitation {
+	var cites []urlCitation
+	for _, a := range content.Annotations {
+		if a.Type != "url_citation" || a.URL == "" {
+			continue
+		}
+		start, end := byteOffset(content.Text, a.StartIndex), byteOffset(content.Text, a.EndIndex)
+		if end < start {
+			start, end = end, start
+		}
+		cites = append(cites, urlCitation{URL: searchURL(a.URL), Title: a.Title, Start: start, End: end})
+	}
+	return cites
+}
+
+// byteOffset converts a character offset in s to a byte offset, clamped to s
+func byteOffset(s string, chars int) int {
+	if chars <= 0 {
+		return 0
+	}
+	for i := range s {
+		if chars == 0 {
+			return i
+		}
+		chars--
+	}
+	return len(s)
+}
+
+// searchURL removes the utm_source=openai marker the search tool appends, so
+// that evidence URLs are the pages as published
+func searchURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Query().Get("utm_source") != "openai" {
+		return rawURL
+	}
+	query := u.Query()
+	query.Del("utm_source")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// groundSources reconciles the facts and sources the model wrote with the
+// url_citation annotations of its output. Annotated pages become sources,
+// and each fact gains the pages annotated inside its JSON object. Sources
+// the model declared that neither an annotation nor a tool result backs are
+// flagged Unannotated.
+func groundSources(text string, cites []urlCitation, facts []Fact, sources []WebSource) ([]Fact, []WebSource) {
+	annotated := make(map[string]bool, len(cites))
+	for _, c := range cites {
+		if key := credibility.CanonicalURL(c.URL); key != "" {
+			annotated[key] = true
+		}
+	}
+
+	// Map annotations inside a fact's object to that fact
+	grounded := make([]Fact, len(facts))
+	copy(grounded, facts)
+	if spans := factSpans(text); len(spans) == len(facts) {
+		for _, c := range cites {
+			if c.Retrieved {
+				continue
+			}
+			for i, span := range spans {
+				if c.Start >= span[0] && c.End <= span[1] {
+					grounded[i].Sources = appendURL(grounded[i].Sources, c.URL)
+				}
+			}
+		}
+	}
+
+	declared := make(map[string]bool, len(sources))
+	kept := make([]WebSource, 0, len(sources)+len(cites))
+	for _, source := range sources {
+		key := credibility.CanonicalURL(source.URL)
+		declared[key] = true
+		source.Unannotated = !annotated[key]
+		kept = append(kept, source)
+	}
+	for _, c := range cites {
+		key := credibility.CanonicalURL(c.URL)
+		if declared[key] || c.Retrieved {
+			continue
+		}
+		declared[key] = true
+		kept = append(kept, WebSource{URL: c.URL, Title: c.Title, Snippet: citedText(text, c)})
+	}
+	return grounded, kept
+}
+
+// factSpans returns the byte range of each element of the top-level "facts"
+// array in a JSON output, or nil if it cannot be read
+func factSpans(text string) [][2]int {
+	dec := json.NewDecoder(strings.NewReader(text))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		if key != "facts" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil
+			}
+			continue
+		}
+
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return nil
+		}
+		var spans [][2]int
+		for dec.More() {
+			start := int(dec.InputOffset())
+			var element json.RawMessage
+			if err := dec.Decode(&element); err != nil {
+				return nil
+			}
+			end := int(dec.InputOffset())
+			// The offset before an element may include the preceding comma
+			start = end - len(strings.TrimLeft(text[start:end], ", \t\r\n"))
+			spans = append(spans, [2]int{start, end})
+		}
+		return spans
+	}
+	return nil
+}
+
+// citedText returns the span of output text an annotation covers, as plain
+// text, for use as a snippet
+func citedText(text string, c urlCitation) string {
+	if c.Start < 0 || c.End > len(text) || c.Start >= c.End {
+		return ""
+	}
+	span := text[c.Start:c.End]
+	var s string
+	if json.Unmarshal([]byte(`"`+span+`"`), &s) == nil {
+		span = s // Unescape spans inside JSON strings
+	}
+	return strings.Trim(strings.TrimSpace(span), `"`)
+}
+
+// appendURL adds u to urls unless the same page is already listed
+func appendURL(urls []string, u string) []string {
+	key := credibility.CanonicalURL(u)
+	for _, existing := range urls {
+		if credibility.CanonicalURL(existing) == key {
+			return urls
+		}
+	}
+	return append(slices.Clip(urls), u) // Never write into the caller's array
+}
@@ -0,0 +1,221 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/progress"
+)
+
+// Verdict is the judge's ruling on a challenged decision
+type Verdict string
+
+const (
+	VerdictUpheld     Verdict = "upheld"     // The decision stands
+	VerdictWeakened   Verdict = "weakened"   // It stands with lower confidence
+	VerdictOverturned Verdict = "overturned" // A counter-case is stronger; escalated for review
+)
+
+// weakenedCut is the least a weakened verdict takes off the decision's
+// confidence, whatever confidence the judge returns
+const weakenedCut = 0.1
+
+// Challenge records the adversarial review of a decision: the strongest case
+// for each other outcome and the judge's verdict on the original
+type Challenge struct {
+	Cases              []CounterCase `json:"cases"`
+	Verdict            Verdict       `json:"verdict"`
+	Reasoning          string        `json:"reasoning"`
+	OriginalConfidence float64       `json:"originalConfidence"`
+}
+
+// CounterCase is the devil's advocate's argument for an outcome other than
+// the decided one
+type CounterCase struct {
+	OutcomeID uint64   `json:"outcomeId"`
+	Argument  string   `json:"argument"`
+	Strength  float64  `json:"strength"` // The advocate's own rating, 0-1
+	Sources   []string `json:"sources"`
+}
+
+// challengeSchema is the output of the challenge_outcome step
+var challengeSchema = OutputSchema{
+	Name: "counter_cases",
+	Schema: object(map[string]any{
+		"cases": arrayOf(object(map[string]any{
+			"outcomeId": map[string]any{"type": "integer", "minimum": float64(0)},
+			"argument":  typed("string"),
+			"strength":  bounded("number", 0, 1),
+			"sources":   arrayOf(typed("string")),
+		})),
+	}),
+}
+
+// judgeSchema is the output of the judge_decision step
+var judgeSchema = OutputSchema{
+	Name: "verdict",
+	Schema: object(map[string]any{
+		"verdict":    map[string]any{"type": "string", "enum": []any{string(VerdictUpheld), string(VerdictWeakened), string(VerdictOverturned)}},
+		"confidence": bounded("number", 0, 1),
+		"reasoning":  typed("string"),
+	}),
+}
+
+// challengeDecision has a separate model call argue, with fresh searches, for
+// every outcome the decision rejected. Cases for the decided outcome, unknown
+// outcomes or an outcome already argued are dropped, as are sources the
+// strategy does not allow; every rejected outcome must keep a case.
+func (p *OpenAIPipeline) challengeDecision(ctx context.Context, prompts *PromptSet, strategy *Strategy, promptCategory string, market MarketInfo, timeline Timeline, decision *Decision) ([]CounterCase, error) {
+	prompt, err := prompts.render(StepChallengeOutcome, promptCategory, promptData{
+		Market:       market,
+		Timeline:     timeline,
+		Facts:        decision.Facts,
+		Decision:     decision,
+		Alternatives: alternatives(market, decision.OutcomeID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, _, err := p.callOpenAIWithWebSearch(ctx, strategy, prompt, 0.7, challengeSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Cases []CounterCase `json:"cases"`
+	}
+	if err := p.decodeOutput(ctx, response, challengeSchema, &result); err != nil {
+		return nil, err
+	}
+
+	wanted := alternatives(market, decision.OutcomeID)
+	argued := make(map[uint64]bool, len(wanted))
+	cases := make([]CounterCase, 0, len(wanted))
+	for _, c := range result.Cases {
+		if !slices.Contains(wanted, c.OutcomeID) || argued[c.OutcomeID] {
+			log.Printf("Warning: dropping counter-case for outcome %d, expected one of %v", c.OutcomeID, wanted)
+			continue
+		}
+		argued[c.OutcomeID] = true
+		allowed := make([]string, 0, len(c.Sources))
+		for _, source := range c.Sources {
+			if strategy.allowsSource(source) {
+				allowed = append(allowed, source)
+			}
+		}
+		c.Sources = allowed
+		cases = append(cases, c)
+	}
+
+	var missing []uint64
+	for _, id := range wanted {
+		if !argued[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no counter-case for outcomes %v", missing)
+	}
+	return cases, nil
+}
+
+// judgeDecision weighs the decision against the counter-cases
+func (p *OpenAIPipeline) judgeDecision(ctx context.Context, prompts *PromptSet, promptCategory string, market MarketInfo, timeline Timeline, decision *Decision, cases []CounterCase) (*Challenge, error) {
+	prompt, err := prompts.render(StepJudgeDecision, promptCategory, promptData{
+		Market:   market,
+		Timeline: timeline,
+		Facts:    decision.Facts,
+		Decision: decision,
+		Cases:    cases,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, _, err := p.callOpenAIChat(ctx, prompt, 0.2, judgeSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	var ruling struct {
+		Verdict    Verdict `json:"verdict"`
+		Confidence float64 `json:"confidence"`
+		Reasoning  string  `json:"reasoning"`
+	}
+	if err := p.decodeOutput(ctx, response, judgeSchema, &ruling); err != nil {
+		return nil, err
+	}
+
+	challenge := &Challenge{
+		Cases:              cases,
+		Verdict:            ruling.Verdict,
+		Reasoning:          ruling.Reasoning,
+		OriginalConfidence: decision.Confidence,
+	}
+	// The judge can only lower confidence, never raise it, and a weakened
+	// decision always loses some
+	confidence := min(decision.Confidence, ruling.Confidence)
+	if ruling.Verdict == VerdictWeakened {
+		confidence = max(min(confidence, decision.Confidence-weakenedCut), 0)
+	}
+	decision.Confidence = confidence
+	decision.Challenge = challenge
+	return challenge, nil
+}
+
+// runChallenge runs the adversarial stage on a decision and records the verdict
+// in it. An overturned decision is returned as a PolicyError for review.
+func (p *OpenAIPipeline) runChallenge(ctx context.Context, prompts *PromptSet, strategy *Strategy, promptCategory string, market MarketInfo, timeline Timeline, decision *Decision) error {
+	progress.Step(ctx, string(StepChallengeOutcome), progress.StatusStarted, nil)
+	cases, err := p.challengeDecision(audit.WithStep(ctx, string(StepChallengeOutcome)), prompts, strategy, promptCategory, market, timeline, decision)
+	if err != nil {
+		reportStep(ctx, StepChallengeOutcome, err, nil)
+		return fmt.Errorf("failed to challenge decision: %w", err)
+	}
+	reportStep(ctx, StepChallengeOutcome, nil, map[string]any{"cases": cases})
+
+	progress.Step(ctx, string(StepJudgeDecision), progress.StatusStarted, nil)
+	challenge, err := p.judgeDecision(audit.WithStep(ctx, string(StepJudgeDecision)), prompts, promptCategory, market, timeline, decision, cases)
+	if err != nil {
+		reportStep(ctx, StepJudgeDecision, err, nil)
+		return fmt.Errorf("failed to judge decision: %w", err)
+	}
+	reportStep(ctx, StepJudgeDecision, nil, map[string]any{
+		"verdict":    challenge.Verdict,
+		"confidence": decision.Confidence,
+		"reasoning":  challenge.Reasoning,
+	})
+	log.Printf("Challenge verdict: %s, confidence %.2f -> %.2f", challenge.Verdict, challenge.OriginalConfidence, decision.Confidence)
+
+	if challenge.Verdict == VerdictOverturned {
+		return &PolicyError{
+			Strategy: strategy.Name,
+			Reason:   fmt.Sprintf("decision overturned by the adversarial review, escalated: %s", challenge.Reasoning),
+			Decision: decision,
+		}
+	}
+	return nil
+}
+
+// alternatives returns the outcomes other than the decided one
+func alternatives(market MarketInfo, decided uint64) []uint64 {
+	var out []uint64
+	for id := range uint64(outcomeCount(market)) {
+		if id != decided {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// outcomeCount returns the number of outcomes, two when unset
+func outcomeCount(market MarketInfo) int {
+	if market.OutcomeCount < 2 {
+		return 2
+	}
+	return market.OutcomeCount
+}
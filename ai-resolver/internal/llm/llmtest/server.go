@@ -0,0 +1,334 @@
+// Package llmtest provides a scripted stand-in for the OpenAI API so the LLM
+// pipeline can run offline in tests.
+package llmtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/project-gamma/ai-resolver/internal/llm"
+)
+
+// Request is a request received by the fake API
+type Request struct {
+	Path string
+	Body map[string]any
+}
+
+// FunctionCall is a scripted Responses API function call
+type FunctionCall struct {
+	CallID    string
+	Name      string
+	Arguments string // JSON
+}
+
+// URLCitation is a scripted url_citation annotation on a /responses output.
+// Start and End are character offsets in the output text.
+type URLCitation struct {
+	URL        string
+	Title      string
+	Start, End int
+}
+
+// Cite returns an annotation of the first occurrence of span in text, or of
+// the whole text if span does not occur
+func Cite(text, span, url, title string) URLCitation {
+	start, end := 0, utf8.RuneCountInString(text)
+	if i := strings.Index(text, span); i >= 0 {
+		start = utf8.RuneCountInString(text[:i])
+		end = start + utf8.RuneCountInString(span)
+	}
+	return URLCitation{URL: url, Title: title, Start: start, End: end}
+}
+
+// reply is one scripted answer: output text with its annotations, function
+// calls, or an error status with an optional Retry-After header
+type reply struct {
+	text       string
+	cites      []URLCitation
+	calls      []FunctionCall
+	status     int
+	retryAfter string
+}
+
+// Token usage reported for every reply, on either endpoint
+const (
+	InputTokens  = 1000
+	OutputTokens = 200
+)
+
+// Server serves scripted replies on /responses and /chat/completions. Each
+// request consumes the next reply in order, regardless of endpoint.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	replies  []reply
+	requests []Request
+}
+
+// NewServer starts a fake OpenAI API with the given replies queued
+func NewServer(replies ...string) *Server {
+	s := &Server{}
+	s.Queue(replies...)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Queue appends replies to the script
+func (s *Server) Queue(replies ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, text := range replies {
+		s.replies = append(s.replies, reply{text: text})
+	}
+}
+
+// QueueCited appends a reply whose text carries url_citation annotations, as
+// web search results do
+func (s *Server) QueueCited(text string, cites ...URLCitation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies = append(s.replies, reply{text: text, cites: cites})
+}
+
+// QueueFunctionCalls appends a /responses reply in which the model calls the
+// given functions in one turn
+func (s *Server) QueueFunctionCalls(calls ...FunctionCall) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies = append(s.replies, reply{calls: calls})
+}
+
+// QueueStatus appends an error reply, e.g. a 429 with retryAfter "1". An
+// empty retryAfter sends no Retry-After header.
+func (s *Server) QueueStatus(status int, retryAfter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies = append(s.replies, reply{status: status, retryAfter: retryAfter})
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Remaining returns the number of replies not yet consumed
+func (s *Server) Remaining() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.replies)
+}
+
+// Resilience keeps retries fast in tests
+var Resilience = llm.Resilience{
+	MaxAttempts:      3,
+	BaseDelay:        time.Millisecond,
+	MaxDelay:         time.Second,
+	BreakerThreshold: 5,
+	BreakerCooldown:  time.Minute,
+}
+
+// Pipeline returns an OpenAIPipeline that talks to this server
+func (s *Server) Pipeline() *llm.OpenAIPipeline {
+	p := llm.NewOpenAIPipeline("test-key", "test-model")
+	p.SetBaseURL(s.URL)
+	p.SetRouter(llm.NewRouter(nil, Resilience))
+	return p
+}
+
+// Transport returns a transport that sends OpenAI API requests to this
+// server, so a pipeline built with the default base URL talks to it, and
+// everything else through next (http.DefaultTransport when nil)
+func (s *Server) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	target, err := url.Parse(s.URL)
+	if err != nil {
+		panic(err)
+	}
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Host != "api.openai.com" {
+			return next.RoundTrip(r)
+		}
+		r = r.Clone(r.Context())
+		r.URL.Scheme, r.URL.Host = target.Scheme, target.Host
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/v1")
+		r.Host = ""
+		return s.Client().Transport.RoundTrip(r)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Path: r.URL.Path, Body: body})
+	if len(s.replies) == 0 {
+		s.mu.Unlock()
+		http.Error(w, "no scripted reply left", http.StatusInternalServerError)
+		return
+	}
+	next := s.replies[0]
+	s.replies = s.replies[1:]
+	id := len(s.requests)
+	s.mu.Unlock()
+
+	if next.status != 0 {
+		if next.retryAfter != "" {
+			w.Header().Set("Retry-After", next.retryAfter)
+		}
+		http.Error(w, fmt.Sprintf(`{"error": {"message": "scripted status %d"}}`, next.status), next.status)
+		return
+	}
+
+	var resp any
+	switch r.URL.Path {
+	case "/responses":
+		annotations := make([]map[string]any, 0, len(next.cites))
+		for _, c := range next.cites {
+			annotations = append(annotations, map[string]any{
+				"type":        "url_citation",
+				"start_index": c.Start,
+				"end_index":   c.End,
+				"title":       c.Title,
+				"url":         c.URL,
+			})
+		}
+		output := []map[string]any{{
+			"type":    "message",
+			"status":  "completed",
+			"role":    "assistant",
+			"content": []map[string]any{{"type": "output_text", "text": next.text, "annotations": annotations}},
+		}}
+		if len(next.calls) > 0 {
+			output = output[:0]
+			for _, call := range next.calls {
+				output = append(output, map[string]any{
+					"type":      "function_call",
+					"status":    "completed",
+					"call_id":   call.CallID,
+					"name":      call.Name,
+					"arguments": call.Arguments,
+				})
+			}
+		}
+		resp = map[string]any{
+			"id":     fmt.Sprintf("resp_%d", id),
+			"status": "completed",
+			"model":  body["model"],
+			"output": output,
+			"usage": map[string]any{
+				"input_tokens":          InputTokens,
+				"input_tokens_details":  map[string]any{"cached_tokens": 0},
+				"output_tokens":         OutputTokens,
+				"output_tokens_details": map[string]any{"reasoning_tokens": 0},
+				"total_tokens":          InputTokens + OutputTokens,
+			},
+		}
+	case "/chat/completions":
+		resp = map[string]any{
+			"id":      fmt.Sprintf("chatcmpl_%d", id),
+			"model":   body["model"],
+			"choices": []map[string]any{{"message": map[string]any{"role": "assistant", "content": next.text}}},
+			"usage": map[string]any{
+				"prompt_tokens":     InputTokens,
+				"completion_tokens": OutputTokens,
+				"total_tokens":      InputTokens + OutputTokens,
+			},
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Script returns the three replies OpenAIPipeline.AnalyzeMarket consumes: the
+// web search facts, the contradiction check (from each fact's Contradicts flag)
+// and the final decision
+func Script(decision llm.Decision, sources []llm.WebSource) []string {
+	if sources == nil {
+		sources = []llm.WebSource{}
+	}
+
+	facts := make([]map[string]any, 0, len(decision.Facts))
+	contradictions := make([]map[string]any, 0)
+	for i, f := range decision.Facts {
+		factSources := f.Sources
+		if factSources == nil {
+			factSources = []string{}
+		}
+		facts = append(facts, map[string]any{
+			"statement":          f.Statement,
+			"sources":            factSources,
+			"confidence":         f.Confidence,
+			"supportingEvidence": f.SupportingEvidence,
+			"publishedAt":        f.PublishedAt,
+			"eventDate":          f.EventDate,
+		})
+		if f.Contradicts {
+			contradictions = append(contradictions, map[string]any{"index": i, "reason": "scripted"})
+		}
+	}
+
+	search, _ := json.Marshal(map[string]any{"facts": facts, "sources": sources})
+	check, _ := json.Marshal(map[string]any{"contradictions": contradictions})
+	final, _ := json.Marshal(map[string]any{
+		"outcomeId":  decision.OutcomeID,
+		"confidence": decision.Confidence,
+		"reasoning":  decision.Reasoning,
+	})
+	return []string{string(search), string(check), string(final)}
+}
+
+// ChallengeScript returns the two replies the challenge stage consumes: one
+// counter-case for outcome against, and the judge's verdict
+func ChallengeScript(against uint64, verdict llm.Verdict, confidence float64) []string {
+	cases, _ := json.Marshal(map[string]any{"cases": []map[string]any{{
+		"outcomeId": against,
+		"argument":  "scripted counter-case",
+		"strength":  0.5,
+		"sources":   []string{"https://example.com/counter"},
+	}}})
+	ruling, _ := json.Marshal(map[string]any{
+		"verdict":    verdict,
+		"confidence": confidence,
+		"reasoning":  "scripted verdict",
+	})
+	return []string{string(cases), string(ruling)}
+}
+
+// ValueReply returns the reply the value step of a scalar market consumes, in
+// place of the decision reply of Script
+func ValueReply(value, unit, source, observedAt string, confidence float64) string {
+	reply, _ := json.Marshal(map[string]any{
+		"value":      value,
+		"unit":       unit,
+		"source":     source,
+		"observedAt": observedAt,
+		"confidence": confidence,
+		"reasoning":  "scripted",
+	})
+	return string(reply)
+}
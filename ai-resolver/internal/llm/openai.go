@@ -6,9 +6,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+	"github.com/project-gamma/ai-resolver/internal/progress"
+	"github.com/project-gamma/ai-resolver/internal/usage"
 )
 
 // OpenAIPipeline implements the multi-pass analysis pipeline using OpenAI with web search
@@ -18,6 +28,17 @@ type OpenAIPipeline struct {
 	httpClient   *http.Client
 	baseURL      string
 	toolRegistry ToolRegistry // Optional tool registry for extensible tool support
+	prompts      atomic.Pointer[PromptSet]
+	strategies   *StrategyRegistry
+	credibility  *credibility.Model
+	router       *Router
+	challenge    bool             // Run the devil's advocate and judge on every decision
+	hostedSearch bool             // Offer the API's hosted web_search tool
+	verifier     CitationVerifier // Checks citations against the cited pages; nil skips it
+
+	stageMu sync.Mutex // Serializes changes to the stages and their layout
+	stages  map[string]Stage
+	plans   atomic.Pointer[stagePlans]
 }
 
 // ToolRegistry interface for managing tools
@@ -36,15 +57,80 @@ type Tool interface {
 
 // NewOpenAIPipeline creates a new OpenAI-based pipeline
 func NewOpenAIPipeline(apiKey, model string) *OpenAIPipeline {
-	return &OpenAIPipeline{
+	p := &OpenAIPipeline{
 		apiKey: apiKey,
 		model:  model,
 		httpClient: &http.Client{
 			Timeout: 120 * time.Second, // Increased for web search
 		},
-		baseURL:      "https://api.openai.com/v1/chat/completions",
+		baseURL:      "https://api.openai.com/v1",
 		toolRegistry: nil, // No tools by default
+		strategies:   DefaultStrategies(),
+		credibility:  credibility.DefaultModel(),
+		router:       NewRouter(nil, DefaultResilience()),
+		hostedSearch: true,
+	}
+	p.prompts.Store(DefaultPrompts())
+	p.stages = p.builtinStages()
+	if err := p.SetStages(DefaultPipelineConfig()); err != nil {
+		panic(fmt.Sprintf("invalid built-in pipeline: %v", err))
 	}
+	return p
+}
+
+// SetBaseURL points the pipeline at an OpenAI-compatible API, e.g. a local
+// stand-in in tests. Endpoint paths (/responses, /chat/completions) are appended.
+func (p *OpenAIPipeline) SetBaseURL(baseURL string) {
+	p.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// SetTransport replaces the HTTP transport used for API calls, e.g. with an
+// httprec.Recorder
+func (p *OpenAIPipeline) SetTransport(transport http.RoundTripper) {
+	p.httpClient.Transport = transport
+}
+
+// SetPrompts replaces the prompt templates. It is safe to call while analyses
+// run; each analysis uses the set that was current when it started.
+func (p *OpenAIPipeline) SetPrompts(prompts *PromptSet) {
+	p.prompts.Store(prompts)
+}
+
+// SetStrategies replaces the category strategies
+func (p *OpenAIPipeline) SetStrategies(strategies *StrategyRegistry) {
+	p.strategies = strategies
+}
+
+// SetCredibility replaces the source credibility model
+func (p *OpenAIPipeline) SetCredibility(model *credibility.Model) {
+	p.credibility = model
+}
+
+// SetRouter replaces the router that retries API calls and falls back to
+// other endpoints. Pipelines sharing a router share its limits and circuits.
+func (p *OpenAIPipeline) SetRouter(router *Router) {
+	p.router = router
+}
+
+// SetChallenge enables the adversarial stage: after the decision, a separate
+// call argues for every other outcome and a judge rules on whether the
+// decision survives
+func (p *OpenAIPipeline) SetChallenge(enabled bool) {
+	p.challenge = enabled
+}
+
+// SetHostedSearch offers or withholds the API's hosted web_search tool. With
+// it off, the model searches only through registry tools such as
+// search_documents, for deployments that must not use hosted search.
+func (p *OpenAIPipeline) SetHostedSearch(enabled bool) {
+	p.hostedSearch = enabled
+}
+
+// SetVerifier enables citation verification: every cited page is fetched,
+// citations it does not confirm are dropped, and decisions left with fewer
+// than the strategy's MinVerified citations are rejected
+func (p *OpenAIPipeline) SetVerifier(verifier CitationVerifier) {
+	p.verifier = verifier
 }
 
 // SetToolRegistry sets the tool registry for this pipeline
@@ -52,34 +138,112 @@ func (p *OpenAIPipeline) SetToolRegistry(registry ToolRegistry) {
 	p.toolRegistry = registry
 }
 
-// AnalyzeMarket performs the complete multi-pass analysis with integrated web search
+// RegisterStage adds a stage that pipeline layouts can name, or replaces the
+// stage of the same name. The current layout is checked again.
+func (p *OpenAIPipeline) RegisterStage(stage Stage) error {
+	p.stageMu.Lock()
+	p.stages[stage.Name()] = stage
+	p.stageMu.Unlock()
+	return p.SetStages(p.plans.Load().config)
+}
+
+// SetStages replaces the stage layout. Every layout in cfg is checked and
+// ordered first; an invalid one leaves the current layout in place.
+func (p *OpenAIPipeline) SetStages(cfg *PipelineConfig) error {
+	p.stageMu.Lock()
+	defer p.stageMu.Unlock()
+
+	layouts := &stagePlans{config: cfg, strategies: make(map[string][]plannedStage)}
+	build := func(name string, layout []StageConfig) ([]plannedStage, error) {
+		planned, err := plan(layout, p.stages)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pipeline: %w", name, err)
+		}
+		if !slices.ContainsFunc(planned, func(ps plannedStage) bool { return slices.Contains(ps.stage.Outputs(), ArtifactDecision) }) {
+			return nil, fmt.Errorf("invalid %s pipeline: no stage produces a decision", name)
+		}
+		return planned, nil
+	}
+
+	var err error
+	if layouts.stages, err = build("default", cfg.Stages); err != nil {
+		return err
+	}
+	for strategy, layout := range cfg.Strategies {
+		if layouts.strategies[strategy], err = build(strategy, layout); err != nil {
+			return err
+		}
+	}
+	p.plans.Store(layouts)
+	return nil
+}
+
+// stagePlans is a checked stage layout
+type stagePlans struct {
+	config     *PipelineConfig
+	stages     []plannedStage            // The default layout
+	strategies map[string][]plannedStage // Layouts by strategy name
+}
+
+// AnalyzeMarket performs the complete multi-pass analysis with integrated web
+// search, following the strategy for the market's category and the stage
+// layout for the strategy
 func (p *OpenAIPipeline) AnalyzeMarket(ctx context.Context, market MarketInfo) (*Decision, error) {
-	// Build search query from market information
-	searchQuery := p.buildSearchQuery(market)
+	strategy := p.strategies.Resolve(market.Category)
+	log.Printf("Resolving market %d with the %s strategy", market.MarketID, strategy.Name)
 
-	// Step 1: Search and extract facts using OpenAI web search
-	facts, webSources, err := p.searchAndExtractFacts(ctx, market, searchQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search and extract facts: %w", err)
+	plans := p.plans.Load()
+	stages, ok := plans.strategies[strategy.Name]
+	if !ok {
+		stages = plans.stages
 	}
 
-	// Step 2: Check for contradictions
-	facts, err = p.checkContradictions(ctx, market, facts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check contradictions: %w", err)
+	a := &Analysis{
+		Market:      market,
+		Strategy:    strategy,
+		Artifacts:   make(map[Artifact]any),
+		prompts:     p.prompts.Load(),
+		sourceModel: strategy.credibility(p.credibility),
+		now:         time.Now(),
 	}
+	if run := audit.FromContext(ctx); run != nil {
+		run.SetSettings(p.settings(a.prompts, plans.config))
+	}
+	if err := runStages(ctx, a, stages); err != nil {
+		return nil, err
+	}
+	return a.Decision, nil
+}
 
-	// Step 3: Decide outcome based on facts
-	decision, err := p.decideOutcome(ctx, market, facts)
+// reportStep emits a finished step: its details on success, the error otherwise
+func reportStep(ctx context.Context, step AnalysisStep, err error, data map[string]any) {
 	if err != nil {
-		return nil, fmt.Errorf("failed to decide outcome: %w", err)
+		progress.Step(ctx, string(step), progress.StatusFailed, map[string]any{"error": err.Error()})
+		return
 	}
+	progress.Step(ctx, string(step), progress.StatusCompleted, data)
+}
 
-	// Step 4: Build citations from web sources
-	decision.Citations = p.buildCitationsFromSources(webSources, facts)
-	decision.Timestamp = time.Now().Unix()
+// unannotated returns the URLs of the sources no search annotation backs
+func unannotated(sources []WebSource) []string {
+	urls := make([]string, 0)
+	for _, source := range sources {
+		if source.Unannotated {
+			urls = append(urls, source.URL)
+		}
+	}
+	return urls
+}
 
-	return decision, nil
+// contradicting returns the indices of the facts flagged as contradictions
+func contradicting(facts []Fact) []int {
+	indices := make([]int, 0)
+	for i, fact := range facts {
+		if fact.Contradicts {
+			indices = append(indices, i)
+		}
+	}
+	return indices
 }
 
 // buildSearchQuery creates an effective search query from market info
@@ -96,145 +260,90 @@ func (p *OpenAIPipeline) buildSearchQuery(market MarketInfo) string {
 }
 
 // searchAndExtractFacts uses OpenAI web search to find and extract facts
-func (p *OpenAIPipeline) searchAndExtractFacts(ctx context.Context, market MarketInfo, searchQuery string) ([]Fact, []WebSource, error) {
-	prompt := fmt.Sprintf(`You are analyzing evidence to resolve a prediction market question. Use web search to find current information.
-
-Question: %s
-Description: %s
-Category: %s
-
-Task: Search the web for information about this question, then extract key facts that are relevant to answering it. For each fact:
-1. State the fact clearly
-2. List the sources (URLs) that support it
-3. Rate your confidence (0-1)
-4. Provide supporting evidence (brief quote or summary)
-
-IMPORTANT: Output ONLY a valid JSON object, no other text before or after. Use this exact format:
-{
-  "facts": [
-    {
-      "statement": "clear factual statement",
-      "sources": ["url1", "url2"],
-      "confidence": 0.95,
-      "supportingEvidence": "brief quote or summary"
-    }
-  ],
-  "sources": [
-    {
-      "url": "https://example.com/article",
-      "title": "Article Title",
-      "snippet": "Relevant excerpt from the article"
-    }
-  ]
-}
-
-Focus on facts that are:
-- Verifiable and specific
-- Directly relevant to the question
-- From credible sources
-- Recent and timely
-
-Search query to use: %s`, market.Question, market.Description, market.Category, searchQuery)
-
-	response, err := p.callOpenAIWithWebSearch(ctx, prompt, 0.3)
+func (p *OpenAIPipeline) searchAndExtractFacts(ctx context.Context, prompts *PromptSet, strategy *Strategy, market MarketInfo, timeline Timeline, searchQuery string) ([]Fact, []WebSource, error) {
+	prompt, err := prompts.render(StepExtractFacts, strategy.promptCategory(market), promptData{Market: market, Timeline: timeline, SearchQuery: searchQuery})
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// Extract JSON from response (may have extra text)
-	jsonStr := extractJSON(response)
+	response, cites, err := p.callOpenAIWithWebSearch(ctx, strategy, prompt, 0.3, extractFactsSchema)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Parse JSON response
 	var result struct {
 		Facts   []Fact      `json:"facts"`
 		Sources []WebSource `json:"sources"`
 	}
-	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		return nil, nil, fmt.Errorf("failed to parse facts JSON: %w\nResponse: %s", err, response)
+	if err := p.decodeOutput(ctx, response, extractFactsSchema, &result); err != nil {
+		return nil, nil, err
 	}
 
-	return result.Facts, result.Sources, nil
+	// The search results the API annotated are the ground truth for sources
+	facts, sources := groundSources(response, cites, result.Facts, result.Sources)
+	for _, source := range sources {
+		if source.Unannotated {
+			log.Printf("Warning: source %s was not returned by the web search", source.URL)
+		}
+	}
+	return facts, sources, nil
 }
 
-// checkContradictions checks for contradictory facts using standard chat API
-func (p *OpenAIPipeline) checkContradictions(ctx context.Context, market MarketInfo, facts []Fact) ([]Fact, error) {
+// checkContradictions flags contradictory facts using standard chat API
+func (p *OpenAIPipeline) checkContradictions(ctx context.Context, prompts *PromptSet, promptCategory string, market MarketInfo, timeline Timeline, facts []Fact) ([]Fact, error) {
 	if len(facts) == 0 {
 		return facts, nil
 	}
 
-	factsJSON, _ := json.MarshalIndent(facts, "", "  ")
-	prompt := fmt.Sprintf(`You are reviewing extracted facts for contradictions.
-
-Question: %s
-
-Extracted Facts:
-%s
-
-Task: Identify any facts that contradict each other. Return the same JSON array but with "contradicts" set to true for any contradictory facts.
-
-Consider facts contradictory if they make opposing claims about the same aspect of the question.
-
-Return the JSON array with the contradicts field updated.`, market.Question, string(factsJSON))
-
-	response, err := p.callOpenAIChat(ctx, prompt, 0.2)
+	prompt, err := prompts.render(StepCheckContradictions, promptCategory, promptData{Market: market, Timeline: timeline, Facts: facts})
 	if err != nil {
 		return nil, err
 	}
 
-	jsonStr := extractJSON(response)
-	var updatedFacts []Fact
-	if err := json.Unmarshal([]byte(jsonStr), &updatedFacts); err != nil {
-		return facts, nil // Return original on parse error
+	response, _, err := p.callOpenAIChat(ctx, prompt, 0.2, contradictionsSchema)
+	if err != nil {
+		return nil, err
 	}
 
-	return updatedFacts, nil
-}
-
-// decideOutcome makes the final decision based on facts using standard chat API
-func (p *OpenAIPipeline) decideOutcome(ctx context.Context, market MarketInfo, facts []Fact) (*Decision, error) {
-	factsJSON, _ := json.MarshalIndent(facts, "", "  ")
-	prompt := fmt.Sprintf(`You are making a final decision on a prediction market question.
-
-Question: %s
-Description: %s
-
-Analyzed Facts:
-%s
-
-Task: Decide the outcome and provide reasoning.
+	var result struct {
+		Contradictions []struct {
+			Index  int    `json:"index"`
+			Reason string `json:"reason"`
+		} `json:"contradictions"`
+	}
+	if err := p.decodeOutput(ctx, response, contradictionsSchema, &result); err != nil {
+		return nil, err
+	}
 
-For binary markets:
-- outcomeId: 0 = NO (did not happen, false)
-- outcomeId: 1 = YES (did happen, true)
+	checked := append([]Fact(nil), facts...)
+	for _, c := range result.Contradictions {
+		if c.Index >= len(checked) {
+			return nil, fmt.Errorf("contradiction refers to fact %d but only %d facts exist", c.Index, len(checked))
+		}
+		checked[c.Index].Contradicts = true
+	}
 
-Return JSON in this exact format:
-{
-  "outcomeId": 0 or 1,
-  "confidence": 0.0 to 1.0,
-  "reasoning": "clear explanation of why this outcome is correct",
-  "facts": [copy the facts array here]
+	return checked, nil
 }
 
-Base your decision on:
-1. Weight of evidence
-2. Source credibility
-3. Fact confidence scores
-4. Resolution of contradictions
-5. Completeness of information
-
-Be conservative - if evidence is insufficient or contradictory, reduce confidence accordingly.`,
-		market.Question, market.Description, string(factsJSON))
+// decideOutcome makes the final decision based on facts using standard chat API
+func (p *OpenAIPipeline) decideOutcome(ctx context.Context, prompts *PromptSet, promptCategory string, market MarketInfo, timeline Timeline, facts []Fact) (*Decision, error) {
+	prompt, err := prompts.render(StepDecideOutcome, promptCategory, promptData{Market: market, Timeline: timeline, Facts: facts})
+	if err != nil {
+		return nil, err
+	}
 
-	response, err := p.callOpenAIChat(ctx, prompt, 0.4)
+	response, model, err := p.callOpenAIChat(ctx, prompt, 0.4, decisionSchema)
 	if err != nil {
 		return nil, err
 	}
 
-	jsonStr := extractJSON(response)
 	var decision Decision
-	if err := json.Unmarshal([]byte(jsonStr), &decision); err != nil {
-		return nil, fmt.Errorf("failed to parse decision JSON: %w", err)
+	if err := p.decodeOutput(ctx, response, decisionSchema, &decision); err != nil {
+		return nil, err
 	}
+	decision.Facts = facts
+	decision.Model = model
 
 	// Validation
 	if decision.OutcomeID > 1 {
@@ -247,126 +356,138 @@ Be conservative - if evidence is insufficient or contradictory, reduce confidenc
 	return &decision, nil
 }
 
-// buildCitationsFromSources creates citations from web sources and facts
-func (p *OpenAIPipeline) buildCitationsFromSources(sources []WebSource, facts []Fact) []Citation {
+// buildCitationsFromSources creates citations from web sources and facts. A
+// source's weight is the confidence of the facts it supports scaled by its
+// credibility; syndicated copies of one article become a single citation
+// from the most credible copy.
+func buildCitationsFromSources(model *credibility.Model, sources []WebSource, facts []Fact) []Citation {
 	// Build a map of URLs mentioned in facts with their weights
 	urlWeight := make(map[string]float64)
 	for _, fact := range facts {
+		if fact.Premature {
+			continue // Predictions do not support the outcome
+		}
 		weight := fact.Confidence / float64(len(fact.Sources))
 		for _, url := range fact.Sources {
 			urlWeight[url] += weight
 		}
 	}
 
-	// Create citations from sources that are referenced in facts
-	citations := make([]Citation, 0)
+	// Cite sources that are referenced in facts, or if none are, every
+	// source with a default weight
+	cited := make([]WebSource, 0, len(sources))
+	weights := make([]float64, 0, len(sources))
 	for _, source := range sources {
 		if weight, ok := urlWeight[source.URL]; ok && weight > 0 {
-			citations = append(citations, Citation{
-				URL:     source.URL,
-				Title:   source.Title,
-				Snippet: source.Snippet,
-				Weight:  weight,
-			})
+			cited = append(cited, source)
+			weights = append(weights, weight)
+		}
+	}
+	if len(cited) == 0 {
+		cited = sources
+		weights = make([]float64, len(sources))
+		for i := range weights {
+			weights[i] = 0.5
 		}
 	}
 
-	// If no citations matched, include all sources with default weight
-	if len(citations) == 0 && len(sources) > 0 {
-		for _, source := range sources {
-			citations = append(citations, Citation{
-				URL:     source.URL,
-				Title:   source.Title,
-				Snippet: source.Snippet,
-				Weight:  0.5,
-			})
+	items := make([]credibility.Item, len(cited))
+	for i, source := range cited {
+		items[i] = credibility.Item{URL: source.URL, Title: source.Title, Snippet: source.Snippet}
+	}
+	groups := credibility.Group(items)
+
+	citations := make([]Citation, 0)
+	for i, source := range cited {
+		score := model.Score(source.URL)
+		citation := Citation{
+			URL:         source.URL,
+			Title:       source.Title,
+			Snippet:     source.Snippet,
+			Weight:      weights[i] * score.Credibility,
+			Domain:      score.Domain,
+			Tier:        string(score.Tier),
+			Credibility: score.Credibility,
+			Unannotated: source.Unannotated,
+		}
+
+		group := groups[i]
+		if group == len(citations) {
+			citations = append(citations, citation)
+			continue
+		}
+
+		// A copy of an earlier article: keep the more credible URL, and the
+		// higher weight rather than the sum
+		primary := &citations[group]
+		unannotated := primary.Unannotated && citation.Unannotated // Backed if any copy is
+		copies := append(primary.Copies, source.URL)
+		if citation.Credibility > primary.Credibility {
+			copies[len(copies)-1] = primary.URL
+			citation.Weight = max(citation.Weight, primary.Weight)
+			*primary = citation
+		} else {
+			primary.Weight = max(primary.Weight, citation.Weight)
 		}
+		primary.Copies = copies
+		primary.Unannotated = unannotated
 	}
 
 	return citations
 }
 
-// callOpenAIWithWebSearch makes a request to OpenAI Responses API with web search enabled
-func (p *OpenAIPipeline) callOpenAIWithWebSearch(ctx context.Context, prompt string, temperature float64) (string, error) {
-	// Use the responses API endpoint
-	responsesURL := "https://api.openai.com/v1/responses"
-
+// callOpenAIWithWebSearch makes a request to OpenAI Responses API with web search
+// enabled. It returns the output text, the url_citation annotations on it and
+// the pages the function tools returned.
+func (p *OpenAIPipeline) callOpenAIWithWebSearch(ctx context.Context, strategy *Strategy, prompt string, temperature float64, format OutputSchema) (string, []urlCitation, error) {
 	// Build tools array with web_search and custom tools
-	var tools []map[string]any
+	tools := make([]map[string]any, 0)
 
-	// Always include web search (use "web_search" not "web_search_preview" for compatibility with function tools)
-	tools = []map[string]any{
-		{"type": "web_search"},
+	// Include hosted web search unless disabled (use "web_search" not "web_search_preview" for compatibility with function tools)
+	if p.hostedSearch {
+		tools = append(tools, webSearchTool(strategy))
 	}
 
-	// Add custom tools from registry if available
+	// Add the strategy's custom tools from the registry if available
 	if p.toolRegistry != nil {
 		for _, tool := range p.toolRegistry.List() {
+			if !strategy.allowsTool(tool.Name()) {
+				continue
+			}
 			toolFormat := tool.ToOpenAIFormat()
 			tools = append(tools, toolFormat)
 		}
 	}
 
 	reqBody := map[string]any{
-		"model": p.model,
-		"input": prompt,
-		"tools": tools,
+		"model":               p.model,
+		"input":               prompt,
+		"tools":               tools,
+		"parallel_tool_calls": true,
+		"text":                map[string]any{"format": format.textFormat()},
 	}
+	var retrieved []urlCitation
 
-	// Tool execution loop - max 10 iterations to prevent infinite loops
+	// Tool execution loop - max 10 iterations to prevent infinite loops. The
+	// conversation lives on the server that answered the first turn, so later
+	// turns are pinned to that endpoint.
 	const maxToolIterations = 10
+	pin := -1
 	for iteration := 0; iteration < maxToolIterations; iteration++ {
-		jsonData, err := json.Marshal(reqBody)
+		auditPrompt, _ := reqBody["input"].(string) // Only the first turn carries the prompt
+		resp, err := p.post(ctx, "/responses", auditPrompt, reqBody, pin)
 		if err != nil {
-			return "", fmt.Errorf("failed to marshal request: %w", err)
+			return "", nil, err
 		}
-
-		req, err := http.NewRequestWithContext(ctx, "POST", responsesURL, bytes.NewBuffer(jsonData))
-		if err != nil {
-			return "", fmt.Errorf("failed to create request: %w", err)
-		}
-
-		req.Header.Set("Authorization", "Bearer "+p.apiKey)
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := p.httpClient.Do(req)
-		if err != nil {
-			return "", fmt.Errorf("request failed: %w", err)
-		}
-
-		body, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if err != nil {
-			return "", fmt.Errorf("failed to read response: %w", err)
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-		}
-
-		// DEBUG: Log raw JSON response
-		fmt.Printf("\n=== RAW API RESPONSE (Iteration %d) ===\n", iteration+1)
-		fmt.Printf("%s\n", string(body))
-		fmt.Printf("=========================================\n\n")
+		pin = resp.Endpoint
 
 		var apiResp openAIResponsesAPIResponse
-		if err := json.Unmarshal(body, &apiResp); err != nil {
-			return "", fmt.Errorf("failed to parse response: %w", err)
-		}
-
-		// DEBUG: Log parsed response structure
-		fmt.Printf("\n=== PARSED API RESPONSE (Iteration %d) ===\n", iteration+1)
-		fmt.Printf("Response ID: %s\n", apiResp.ID)
-		fmt.Printf("Status: %s\n", apiResp.Status)
-		fmt.Printf("Output count: %d\n", len(apiResp.Output))
-		for i, output := range apiResp.Output {
-			fmt.Printf("Output[%d]: Type=%s, Status=%s, ContentCount=%d, ToolCallCount=%d\n",
-				i, output.Type, output.Status, len(output.Content), len(output.ToolCalls))
+		if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+			return "", nil, fmt.Errorf("failed to parse response: %w", err)
 		}
-		fmt.Printf("===========================================\n\n")
 
 		if len(apiResp.Output) == 0 {
-			return "", fmt.Errorf("no output in response")
+			return "", nil, fmt.Errorf("no output in response")
 		}
 
 		// Check for tool calls (handle both nested and flat structures)
@@ -392,83 +513,33 @@ func (p *OpenAIPipeline) callOpenAIWithWebSearch(ctx context.Context, prompt str
 			}
 		}
 
-		// If there are tool calls, execute them and continue the loop
+		// If there are tool calls, execute them and continue the conversation
 		if len(toolCalls) > 0 {
 			if p.toolRegistry == nil {
-				return "", fmt.Errorf("received tool calls but no tool registry is configured")
-			}
-
-			fmt.Printf("\n=== TOOL CALLS (Iteration %d) ===\n", iteration+1)
-			fmt.Printf("Found %d tool call(s)\n", len(toolCalls))
-
-			// Execute each tool call
-			toolResults := make([]map[string]any, 0, len(toolCalls))
-			for i, toolCall := range toolCalls {
-				if toolCall.Type != "function" {
-					continue // Skip non-function tool calls
-				}
-
-				fmt.Printf("\nTool Call #%d:\n", i+1)
-				fmt.Printf("  Name: %s\n", toolCall.Function.Name)
-				fmt.Printf("  Arguments: %s\n", toolCall.Function.Arguments)
-
-				// Get the tool from registry
-				tool, ok := p.toolRegistry.Get(toolCall.Function.Name)
-				if !ok {
-					return "", fmt.Errorf("tool %s not found in registry", toolCall.Function.Name)
-				}
-
-				// Parse arguments
-				var args map[string]any
-				if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
-					return "", fmt.Errorf("failed to parse tool arguments: %w", err)
-				}
-
-				// Execute the tool
-				result, err := tool.Execute(ctx, args)
-				if err != nil {
-					fmt.Printf("  Result: ERROR - %v\n", err)
-					// Return error as tool result so LLM can see it
-					result = map[string]any{
-						"error": err.Error(),
-					}
-				} else {
-					resultJSON, _ := json.MarshalIndent(result, "  ", "  ")
-					fmt.Printf("  Result: %s\n", string(resultJSON))
-				}
-
-				// Format tool result for next API call
-				resultJSON, _ := json.Marshal(result)
-				toolResults = append(toolResults, map[string]any{
-					"tool_call_id": toolCall.ID,
-					"role":         "tool",
-					"content":      string(resultJSON),
-				})
+				return "", nil, fmt.Errorf("received tool calls but no tool registry is configured")
 			}
-
-			fmt.Printf("=== END TOOL CALLS ===\n\n")
-
-			// Update request body with tool results for next iteration
-			// Responses API doesn't support output parameter - append results to input
-			var toolResultsSummary string
-			for i, tr := range toolResults {
-				toolResultsSummary += fmt.Sprintf("\n\nTool Result %d (call_id: %v):\n%v", i+1, tr["tool_call_id"], tr["content"])
+			if apiResp.ID == "" {
+				return "", nil, fmt.Errorf("response with tool calls has no ID to continue from")
 			}
 
-			// Append tool results to the input prompt
-			currentInput := reqBody["input"].(string)
-			reqBody["input"] = currentInput + "\n\n===TOOL RESULTS===" + toolResultsSummary + "\n\nBased on the above tool results, please continue your analysis."
-
+			// The next turn carries only the tool outputs; the server keeps the
+			// conversation, including the model's reasoning, under the response ID
+			reqBody["previous_response_id"] = apiResp.ID
+			outputs, cites := p.executeToolCalls(ctx, strategy, toolCalls)
+			reqBody["input"] = outputs
+			retrieved = append(retrieved, cites...)
 			continue
 		}
 
 		// No tool calls - extract text from message outputs
 		var resultText string
+		var cites []urlCitation
 		for _, output := range apiResp.Output {
 			if output.Type == "message" && len(output.Content) > 0 {
 				for _, content := range output.Content {
 					if content.Type == "output_text" {
 						resultText = content.Text
+						cites = urlCitations(content)
 						break
 					}
 				}
@@ -479,25 +550,192 @@ func (p *OpenAIPipeline) callOpenAIWithWebSearch(ctx context.Context, prompt str
 		}
 
 		if resultText == "" {
-			return "", fmt.Errorf("no text content found in response")
+			return "", nil, fmt.Errorf("no text content found in response")
+		}
+
+		return resultText, append(cites, retrieved...), nil
+	}
+
+	return "", nil, fmt.Errorf("exceeded maximum tool call iterations (%d)", maxToolIterations)
+}
+
+// webSearchTool returns the hosted web search tool, restricted to the
+// strategy's source allowlist when every entry is a domain the API accepts.
+// Suffix entries such as "gov" are enforced only when filtering the results.
+func webSearchTool(strategy *Strategy) map[string]any {
+	tool := map[string]any{"type": "web_search"}
+	if len(strategy.Sources) == 0 {
+		return tool
+	}
+	for _, domain := range strategy.Sources {
+		if !strings.Contains(domain, ".") {
+			return tool
+		}
+	}
+	tool["filters"] = map[string]any{"allowed_domains": strategy.Sources}
+	return tool
+}
+
+// executeToolCalls runs the function calls of one model turn concurrently and
+// returns their function_call_output items in call order, with the pages the
+// tools returned. Failures are reported to the model as error outputs so it
+// can adjust.
+func (p *OpenAIPipeline) executeToolCalls(ctx context.Context, strategy *Strategy, toolCalls []ToolCall) ([]map[string]any, []urlCitation) {
+	outputs := make([]map[string]any, len(toolCalls))
+	cites := make([][]urlCitation, len(toolCalls))
+
+	var wg sync.WaitGroup
+	for i, toolCall := range toolCalls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := p.executeToolCall(ctx, strategy, toolCall)
+			resultJSON, _ := json.Marshal(result)
+			outputs[i] = map[string]any{
+				"type":    "function_call_output",
+				"call_id": toolCall.ID,
+				"output":  string(resultJSON),
+			}
+			cites[i] = toolCitations(resultJSON)
+		}()
+	}
+	wg.Wait()
+
+	return outputs, slices.Concat(cites...)
+}
+
+// executeToolCall runs one function call and records it in the audit run
+func (p *OpenAIPipeline) executeToolCall(ctx context.Context, strategy *Strategy, toolCall ToolCall) map[string]any {
+	log.Printf("Calling tool %s (call %s)", toolCall.Function.Name, toolCall.ID)
+	progress.Emit(ctx, progress.Event{
+		Type:   progress.TypeToolCall,
+		Status: progress.StatusStarted,
+		Step:   audit.StepFromContext(ctx),
+		Name:   toolCall.Function.Name,
+		Data:   map[string]any{"callId": toolCall.ID, "arguments": audit.RawJSON([]byte(toolCall.Function.Arguments))},
+	})
+
+	started := time.Now()
+	event := audit.Event{
+		Time:      started.UTC(),
+		Tool:      toolCall.Function.Name,
+		CallID:    toolCall.ID,
+		Arguments: audit.RawJSON([]byte(toolCall.Function.Arguments)),
+	}
+
+	result, err := p.runTool(ctx, strategy, toolCall)
+	event.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		log.Printf("Tool %s failed: %v", toolCall.Function.Name, err)
+		event.Error = err.Error()
+		// Return error as tool result so LLM can see it
+		result = map[string]any{
+			"error": err.Error(),
+		}
+	} else {
+		event.Result = audit.RawJSON(result)
+	}
+	audit.RecordToolCall(ctx, event)
+
+	done := progress.Event{
+		Type:   progress.TypeToolCall,
+		Status: progress.StatusCompleted,
+		Step:   audit.StepFromContext(ctx),
+		Name:   toolCall.Function.Name,
+		Data:   map[string]any{"callId": toolCall.ID, "durationMs": event.DurationMs},
+	}
+	if err != nil {
+		done.Status = progress.StatusFailed
+		done.Data["error"] = err.Error()
+	}
+	progress.Emit(ctx, done)
+
+	return result
+}
+
+// runTool looks up and executes the tool named by a function call
+func (p *OpenAIPipeline) runTool(ctx context.Context, strategy *Strategy, toolCall ToolCall) (map[string]any, error) {
+	if toolCall.Type != "" && toolCall.Type != "function" {
+		return nil, fmt.Errorf("unsupported tool call type %q", toolCall.Type)
+	}
+	if !strategy.allowsTool(toolCall.Function.Name) {
+		return nil, fmt.Errorf("tool %s is not allowed for %s markets", toolCall.Function.Name, strategy.Name)
+	}
+
+	tool, ok := p.toolRegistry.Get(toolCall.Function.Name)
+	if !ok {
+		return nil, fmt.Errorf("tool %s not found in registry", toolCall.Function.Name)
+	}
+
+	var args map[string]any
+	if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
+		return nil, fmt.Errorf("failed to parse tool arguments: %w", err)
+	}
+
+	// Tools that read URLs keep to the strategy's sources
+	return tool.Execute(credibility.WithPolicy(ctx, strategy.allowsSource), args)
+}
+
+// maxRepairAttempts bounds the repair prompts sent for one invalid output
+const maxRepairAttempts = 2
+
+// decodeOutput validates a step's output against its schema and decodes it
+// into out. Invalid output is sent back to the model with the validation errors
+// up to maxRepairAttempts times before the step fails.
+func (p *OpenAIPipeline) decodeOutput(ctx context.Context, text string, format OutputSchema, out any) error {
+	err := format.Decode(text, out)
+	if err == nil {
+		return nil
+	}
+
+	repairCtx := audit.WithStep(ctx, audit.StepFromContext(ctx)+"/repair")
+	for attempt := 1; attempt <= maxRepairAttempts; attempt++ {
+		log.Printf("Output failed schema %s, repair attempt %d/%d: %v", format.Name, attempt, maxRepairAttempts, err)
+
+		text, _, err = p.callOpenAIChat(repairCtx, repairPrompt(text, err), 0, format)
+		if err != nil {
+			return fmt.Errorf("repair request failed: %w", err)
+		}
+		if err = format.Decode(text, out); err == nil {
+			return nil
 		}
+	}
 
-		return resultText, nil
+	return fmt.Errorf("invalid %s output after %d repair attempts: %w", format.Name, maxRepairAttempts, err)
+}
+
+// repairPrompt asks the model to fix an output that failed validation
+func repairPrompt(output string, validationErr error) string {
+	const maxEcho = 8000
+	if len(output) > maxEcho {
+		output = output[:maxEcho] + "..."
 	}
 
-	return "", fmt.Errorf("exceeded maximum tool call iterations (%d)", maxToolIterations)
+	return fmt.Sprintf(`Your previous response did not match the required JSON schema.
+
+Validation errors:
+%s
+
+Previous response:
+%s
+
+Return a corrected response that satisfies the schema. Keep the content unchanged except where needed to fix the errors.`, validationErr, output)
 }
 
-// callOpenAIChat makes a standard chat completion request (for non-search steps)
-func (p *OpenAIPipeline) callOpenAIChat(ctx context.Context, prompt string, temperature float64) (string, error) {
-	chatURL := strings.Replace(p.baseURL, "/responses", "/chat/completions", 1)
+// chatSystemPrompt is the system message for chat completion steps
+const chatSystemPrompt = "You are a precise, factual AI assistant analyzing evidence for prediction markets. Always respond with valid JSON."
 
+// callOpenAIChat makes a standard chat completion request (for non-search
+// steps) whose output is constrained to format. It returns the output and the
+// model that answered.
+func (p *OpenAIPipeline) callOpenAIChat(ctx context.Context, prompt string, temperature float64, format OutputSchema) (string, string, error) {
 	reqBody := map[string]any{
 		"model": p.model,
 		"messages": []map[string]string{
 			{
 				"role":    "system",
-				"content": "You are a precise, factual AI assistant analyzing evidence for prediction markets. Always respond with valid JSON.",
+				"content": chatSystemPrompt,
 			},
 			{
 				"role":    "user",
@@ -505,46 +743,199 @@ func (p *OpenAIPipeline) callOpenAIChat(ctx context.Context, prompt string, temp
 			},
 		},
 		"max_completion_tokens": 2000,
+		"response_format":       format.responseFormat(),
+	}
+
+	resp, err := p.post(ctx, "/chat/completions", prompt, reqBody, -1)
+	if err != nil {
+		return "", "", err
+	}
+
+	var apiResp openAIChatResponse
+	if err := json.Unmarshal(resp.Body, &apiResp); err != nil {
+		return "", "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(apiResp.Choices) == 0 {
+		return "", "", fmt.Errorf("no choices in response")
+	}
+
+	return apiResp.Choices[0].Message.Content, resp.Model, nil
+}
+
+// post sends a JSON request to an API path along the router's endpoint chain,
+// retrying and falling back as the router allows; pin >= 0 restricts it to one
+// endpoint. The usage meter may refuse the call when a budget is exhausted.
+func (p *OpenAIPipeline) post(ctx context.Context, path, prompt string, reqBody map[string]any, pin int) (*reply, error) {
+	meter := usage.FromContext(ctx)
+	if err := meter.Check(); err != nil {
+		return nil, err
+	}
+
+	resp, err := p.router.do(ctx, pin, func(ctx context.Context, e Endpoint) ([]byte, error) {
+		return p.send(ctx, p.resolve(e), path, prompt, reqBody)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Model, _ = parseUsage(resp.Body); resp.Model == "" {
+		resp.Model = p.resolve(p.router.endpoints[resp.Endpoint].Endpoint).Model
+	}
+	return resp, nil
+}
+
+// resolve fills an endpoint's empty fields from the pipeline's own API
+func (p *OpenAIPipeline) resolve(e Endpoint) Endpoint {
+	if e.BaseURL == "" {
+		e.BaseURL = p.baseURL
+	}
+	e.BaseURL = strings.TrimRight(e.BaseURL, "/")
+	if e.APIKey == "" {
+		e.APIKey = p.apiKey
+	}
+	if e.Model == "" {
+		e.Model = p.model
+	}
+	return e
+}
+
+// send makes one request to an endpoint and returns the response body. The
+// prompt, parameters and raw response are recorded in the audit run, and the
+// token usage is charged to the usage meter.
+func (p *OpenAIPipeline) send(ctx context.Context, e Endpoint, path, prompt string, reqBody map[string]any) ([]byte, error) {
+	meter := usage.FromContext(ctx)
+	url := e.BaseURL + path
+	if reqBody["model"] != e.Model {
+		reqBody = maps.Clone(reqBody)
+		reqBody["model"] = e.Model
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", chatURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Authorization", "Bearer "+e.APIKey)
 	req.Header.Set("Content-Type", "application/json")
 
+	started := time.Now()
+	event := audit.Event{
+		Time:     started.UTC(),
+		Endpoint: url,
+		Prompt:   prompt,
+		Params:   auditParams(reqBody),
+	}
+	defer func() {
+		event.DurationMs = time.Since(started).Milliseconds()
+		audit.RecordLLMCall(ctx, event)
+	}()
+
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		event.Error = err.Error()
+		return nil, &requestError{err: err}
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
+	event.Status = resp.StatusCode
+	event.Response = audit.RawJSON(body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		event.Error = err.Error()
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		event.Error = fmt.Sprintf("status %d", resp.StatusCode)
+		return nil, newStatusError(resp, body)
 	}
 
-	var apiResp openAIChatResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+	model, tokens := parseUsage(body)
+	if model == "" {
+		model = e.Model
+	}
+	cost, priced := meter.Record(audit.StepFromContext(ctx), model, tokens)
+	if meter != nil && !priced {
+		log.Printf("Warning: no price configured for model %s; usage is not costed", model)
 	}
+	event.Usage = &tokens
+	event.CostUSD = cost
 
-	if len(apiResp.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
+	return body, nil
+}
+
+// apiUsage is the usage block of both Responses and Chat Completions replies
+type apiUsage struct {
+	Model string `json:"model"`
+	Usage struct {
+		InputTokens        int64 `json:"input_tokens"`
+		OutputTokens       int64 `json:"output_tokens"`
+		InputTokensDetails struct {
+			CachedTokens int64 `json:"cached_tokens"`
+		} `json:"input_tokens_details"`
+		OutputTokensDetails struct {
+			ReasoningTokens int64 `json:"reasoning_tokens"`
+		} `json:"output_tokens_details"`
+
+		PromptTokens        int64 `json:"prompt_tokens"`
+		CompletionTokens    int64 `json:"completion_tokens"`
+		PromptTokensDetails struct {
+			CachedTokens int64 `json:"cached_tokens"`
+		} `json:"prompt_tokens_details"`
+		CompletionTokensDetails struct {
+			ReasoningTokens int64 `json:"reasoning_tokens"`
+		} `json:"completion_tokens_details"`
+	} `json:"usage"`
+	Output []struct {
+		Type string `json:"type"`
+	} `json:"output"`
+}
+
+// parseUsage returns the answering model and the token usage of a reply.
+// Hosted web search calls are billed per call, so they are counted too.
+func parseUsage(body []byte) (string, usage.Tokens) {
+	var r apiUsage
+	if err := json.Unmarshal(body, &r); err != nil {
+		return "", usage.Tokens{}
+	}
+	u := r.Usage
+	tokens := usage.Tokens{
+		Input:       u.InputTokens + u.PromptTokens,
+		CachedInput: u.InputTokensDetails.CachedTokens + u.PromptTokensDetails.CachedTokens,
+		Output:      u.OutputTokens + u.CompletionTokens,
+		Reasoning:   u.OutputTokensDetails.ReasoningTokens + u.CompletionTokensDetails.ReasoningTokens,
+	}
+	for _, out := range r.Output {
+		if out.Type == "web_search_call" {
+			tokens.WebSearchCalls++
+		}
 	}
+	return r.Model, tokens
+}
 
-	return apiResp.Choices[0].Message.Content, nil
+// auditParams returns the model parameters of a request: everything but the
+// prompt, which is recorded separately
+func auditParams(reqBody map[string]any) map[string]any {
+	params := make(map[string]any, len(reqBody))
+	for k, v := range reqBody {
+		switch k {
+		case "input":
+			// Continuation turns send tool outputs rather than a prompt
+			if _, isPrompt := v.(string); !isPrompt {
+				params[k] = v
+			}
+		case "messages":
+			params["system"] = chatSystemPrompt
+		default:
+			params[k] = v
+		}
+	}
+	return params
 }
 
 // openAIResponsesAPIResponse represents OpenAI Responses API response
@@ -607,29 +998,8 @@ type WebSource struct {
 	URL     string `json:"url"`
 	Title   string `json:"title"`
 	Snippet string `json:"snippet"`
-}
-
-// extractJSON attempts to extract a JSON object from text that may contain additional content
-func extractJSON(text string) string {
-	// Try to find JSON object boundaries
-	start := strings.Index(text, "{")
-	if start == -1 {
-		return text
-	}
-
-	// Find matching closing brace
-	depth := 0
-	for i := start; i < len(text); i++ {
-		switch text[i] {
-		case '{':
-			depth++
-		case '}':
-			depth--
-			if depth == 0 {
-				return text[start : i+1]
-			}
-		}
-	}
 
-	return text
+	// Unannotated is set when the model named the source but no url_citation
+	// annotation of the search backs it
+	Unannotated bool `json:"unannotated,omitempty"`
 }
@@ -0,0 +1,941 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+	"github.com/project-gamma/ai-resolver/internal/httprec"
+	"github.com/project-gamma/ai-resolver/internal/httprec/httprectest"
+	"github.com/project-gamma/ai-resolver/internal/jsontime"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+	"github.com/project-gamma/ai-resolver/internal/llm/llmtest"
+	"github.com/project-gamma/ai-resolver/internal/scalar"
+	"github.com/project-gamma/ai-resolver/internal/usage"
+	"github.com/project-gamma/ai-resolver/internal/verify"
+)
+
+// TestAnalyzeMarketScripted tests the full pipeline against the scripted API
+func TestAnalyzeMarketScripted(t *testing.T) {
+	facts := []llm.Fact{{
+		Statement:          "BTC closed above $100k on 2025-01-01",
+		Sources:            []string{"https://example.com/btc"},
+		Confidence:         0.9,
+		SupportingEvidence: "Bitcoin ended the day at $101,200",
+	}}
+	sources := []llm.WebSource{
+		{URL: "https://example.com/btc", Title: "BTC price", Snippet: "ended the day at $101,200"},
+		{URL: "https://example.com/unrelated", Title: "Other", Snippet: "not cited"},
+	}
+
+	server := llmtest.NewServer(llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.85,
+		Reasoning:  "Price closed above the threshold",
+		Facts:      facts,
+	}, sources)...)
+	defer server.Close()
+
+	decision, err := server.Pipeline().AnalyzeMarket(context.Background(), llm.MarketInfo{
+		MarketID: 1,
+		Question: "Will BTC close above $100k on 2025-01-01?",
+		Category: "general",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if decision.OutcomeID != 1 || decision.Confidence != 0.85 {
+		t.Errorf("unexpected decision: %+v", decision)
+	}
+	if len(decision.Citations) != 1 || decision.Citations[0].URL != "https://example.com/btc" {
+		t.Errorf("expected only the cited source, got %+v", decision.Citations)
+	}
+	if decision.Prompt == nil || *decision.Prompt != llm.DefaultPrompts().Info("general") {
+		t.Errorf("expected the default prompt set to be recorded, got %+v", decision.Prompt)
+	}
+
+	requests := server.Requests()
+	paths := make([]string, 0, len(requests))
+	for _, r := range requests {
+		paths = append(paths, r.Path)
+	}
+	if strings.Join(paths, ",") != "/responses,/chat/completions,/chat/completions" {
+		t.Errorf("unexpected request sequence: %v", paths)
+	}
+	if requests[0].Body["model"] != "test-model" {
+		t.Errorf("expected model to be forwarded, got %v", requests[0].Body["model"])
+	}
+	if format, _ := requests[0].Body["text"].(map[string]any)["format"].(map[string]any); format["type"] != "json_schema" || format["strict"] != true {
+		t.Errorf("expected a strict text.format on the search step, got %v", requests[0].Body["text"])
+	}
+	if format, _ := requests[2].Body["response_format"].(map[string]any); format["type"] != "json_schema" {
+		t.Errorf("expected response_format on the decision step, got %v", requests[2].Body["response_format"])
+	}
+}
+
+// TestAnalyzeMarketRepair tests that invalid output is repaired by a follow-up prompt
+func TestAnalyzeMarketRepair(t *testing.T) {
+	script := llmtest.Script(llm.Decision{
+		OutcomeID:  0,
+		Confidence: 0.7,
+		Reasoning:  "Not reported",
+		Facts:      []llm.Fact{{Statement: "No report found", Sources: []string{"https://example.com"}, Confidence: 0.6}},
+	}, nil)
+
+	// The search step wraps its JSON in prose; the repair returns it bare
+	server := llmtest.NewServer("Here are the facts: "+script[0], script[0], script[1], script[2])
+	defer server.Close()
+
+	decision, err := server.Pipeline().AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "Was it reported?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decision.Confidence != 0.7 || len(decision.Facts) != 1 {
+		t.Errorf("unexpected decision: %+v", decision)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 4 || requests[1].Path != "/chat/completions" {
+		t.Fatalf("expected a repair request after the search step, got %d requests", len(requests))
+	}
+	repair := requests[1].Body["messages"].([]any)[1].(map[string]any)["content"].(string)
+	if !strings.Contains(repair, "invalid JSON") || !strings.Contains(repair, "Here are the facts") {
+		t.Errorf("expected repair prompt to include the error and the previous output, got %q", repair)
+	}
+}
+
+// TestContradictionCheckFailsOnInvalidOutput tests that a contradiction check
+// that cannot be repaired fails the analysis instead of being skipped
+func TestContradictionCheckFailsOnInvalidOutput(t *testing.T) {
+	script := llmtest.Script(llm.Decision{
+		Facts: []llm.Fact{{Statement: "x", Sources: []string{"https://example.com"}, Confidence: 1}},
+	}, nil)
+	server := llmtest.NewServer(script[0], "[]", "[]", "[]")
+	defer server.Close()
+
+	_, err := server.Pipeline().AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "?"})
+	if err == nil || !strings.Contains(err.Error(), "check contradictions") || !strings.Contains(err.Error(), "after 2 repair attempts") {
+		t.Errorf("expected contradiction check to fail, got %v", err)
+	}
+	if server.Remaining() != 0 {
+		t.Errorf("expected 4 requests, %d replies left", server.Remaining())
+	}
+}
+
+// TestAnalyzeMarketInvalidDecision tests that out-of-range outcomes are rejected
+func TestAnalyzeMarketInvalidDecision(t *testing.T) {
+	server := llmtest.NewServer(llmtest.Script(llm.Decision{
+		OutcomeID:  3,
+		Confidence: 0.9,
+		Facts:      []llm.Fact{{Statement: "x", Sources: []string{"https://example.com"}, Confidence: 1}},
+	}, nil)...)
+	defer server.Close()
+
+	_, err := server.Pipeline().AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "?"})
+	if err == nil || !strings.Contains(err.Error(), "invalid outcome ID") {
+		t.Errorf("expected invalid outcome error, got %v", err)
+	}
+}
+
+// TestAnalyzeMarketUsage tests that every call is metered and that a spent
+// market budget aborts the analysis before the next call
+func TestAnalyzeMarketUsage(t *testing.T) {
+	server := llmtest.NewServer(llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.9,
+		Facts:      []llm.Fact{{Statement: "x", Sources: []string{"https://example.com"}, Confidence: 1}},
+	}, nil)...)
+	defer server.Close()
+
+	// $1 per call, so the decision step exceeds a $2 budget
+	prices := usage.PriceTable{"test-model": {InputPerMillion: 1_000_000.0 / llmtest.InputTokens}}
+	ledger, err := usage.NewLedger(prices, usage.Budget{PerMarketUSD: 2}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	meter := ledger.Meter(56, 1)
+	run := audit.NewRun(56, 1, "test-model")
+	ctx := audit.WithRun(usage.WithMeter(context.Background(), meter), run)
+
+	_, err = server.Pipeline().AnalyzeMarket(ctx, llm.MarketInfo{MarketID: 1, Question: "?"})
+	if !errors.Is(err, usage.ErrBudgetExceeded) || !strings.Contains(err.Error(), "decide outcome") {
+		t.Fatalf("expected the decision step to exceed the budget, got %v", err)
+	}
+	if server.Remaining() != 1 {
+		t.Errorf("expected the decision call not to be sent, %d replies left", server.Remaining())
+	}
+
+	summary := meter.Summary()
+	if summary.Calls != 2 || summary.CostUSD != 2 || summary.Tokens.Output != 2*llmtest.OutputTokens {
+		t.Errorf("unexpected usage summary: %+v", summary.Totals)
+	}
+	if summary.Steps["extract_facts"].Calls != 1 || summary.Steps["check_contradictions"].Calls != 1 {
+		t.Errorf("unexpected per-step usage: %+v", summary.Steps)
+	}
+	for _, event := range run.EventsOf(audit.EventLLMCall) {
+		if event.Usage == nil || event.Usage.Input != llmtest.InputTokens || event.CostUSD != 1 {
+			t.Errorf("expected usage on %s event, got %+v (cost %g)", event.Step, event.Usage, event.CostUSD)
+		}
+	}
+}
+
+// TestAnalyzeMarketReplay replays a recorded OpenAI session. Re-record with
+// HTTPREC_MODE=record OPENAI_API_KEY=... go test -run TestAnalyzeMarketReplay
+func TestAnalyzeMarketReplay(t *testing.T) {
+	recorder := httprectest.New(t, "testdata/cassettes/analyze_market.json")
+
+	pipeline := llm.NewOpenAIPipeline(os.Getenv("OPENAI_API_KEY"), "gpt-4o")
+	pipeline.SetTransport(recorder)
+
+	decision, err := pipeline.AnalyzeMarket(context.Background(), llm.MarketInfo{
+		MarketID:     7,
+		Question:     "Will the Fed cut interest rates at the January 2025 FOMC meeting?",
+		Category:     "economics",
+		CloseTime:    1738195200,
+		OutcomeCount: 2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if recorder.Mode() == httprec.ModeRecord {
+		t.Logf("recorded decision: %+v", decision)
+		return
+	}
+
+	if decision.OutcomeID != 0 {
+		t.Errorf("expected NO outcome, got %d", decision.OutcomeID)
+	}
+	if decision.Confidence < 0.9 {
+		t.Errorf("expected high confidence, got %f", decision.Confidence)
+	}
+	if len(decision.Citations) != 2 {
+		t.Errorf("expected 2 citations, got %d", len(decision.Citations))
+	}
+	if recorder.Unused() != 0 {
+		t.Errorf("expected every recorded call to be replayed, %d left", recorder.Unused())
+	}
+}
+
+// testTool is a function tool backed by a Go func
+type testTool struct {
+	name string
+	run  func(args map[string]any) (map[string]any, error)
+}
+
+func (t *testTool) Name() string        { return t.name }
+func (t *testTool) Description() string { return t.name }
+func (t *testTool) ToOpenAIFormat() map[string]any {
+	return map[string]any{"type": "function", "name": t.name, "parameters": map[string]any{"type": "object"}}
+}
+func (t *testTool) Execute(ctx context.Context, args map[string]any) (map[string]any, error) {
+	return t.run(args)
+}
+
+type testRegistry []llm.Tool
+
+func (r testRegistry) Get(name string) (llm.Tool, bool) {
+	for _, tool := range r {
+		if tool.Name() == name {
+			return tool, true
+		}
+	}
+	return nil, false
+}
+
+func (r testRegistry) List() []llm.Tool { return r }
+
+// TestToolCallContinuation tests that tool results are sent back as
+// function_call_output items on the previous response, and that calls from one
+// turn run concurrently
+func TestToolCallContinuation(t *testing.T) {
+	// Each tool waits for the other to start, so sequential execution times out
+	var started sync.WaitGroup
+	started.Add(2)
+	barrier := func(result map[string]any) func(map[string]any) (map[string]any, error) {
+		return func(map[string]any) (map[string]any, error) {
+			started.Done()
+			done := make(chan struct{})
+			go func() { started.Wait(); close(done) }()
+			select {
+			case <-done:
+				return result, nil
+			case <-time.After(5 * time.Second):
+				return nil, fmt.Errorf("tool calls did not run concurrently")
+			}
+		}
+	}
+
+	server := llmtest.NewServer()
+	defer server.Close()
+	server.QueueFunctionCalls(
+		llmtest.FunctionCall{CallID: "call_price", Name: "get_price", Arguments: `{"symbol":"BNB"}`},
+		llmtest.FunctionCall{CallID: "call_time", Name: "get_time", Arguments: `{}`},
+	)
+	server.Queue(llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.9,
+		Facts:      []llm.Fact{{Statement: "BNB is above $600", Sources: []string{"tool:get_price"}, Confidence: 0.9}},
+	}, nil)...)
+
+	pipeline := server.Pipeline()
+	pipeline.SetToolRegistry(testRegistry{
+		&testTool{name: "get_price", run: barrier(map[string]any{"usd": 612.5})},
+		&testTool{name: "get_time", run: barrier(map[string]any{"unix": 1735689600})},
+	})
+
+	run := audit.NewRun(56, 1, "test-model")
+	if _, err := pipeline.AnalyzeMarket(audit.WithRun(context.Background(), run), llm.MarketInfo{Question: "Is BNB above $600?"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 4 || requests[1].Path != "/responses" {
+		t.Fatalf("expected a continuation request, got %d requests", len(requests))
+	}
+	continuation := requests[1].Body
+	if continuation["previous_response_id"] != "resp_1" {
+		t.Errorf("expected previous_response_id resp_1, got %v", continuation["previous_response_id"])
+	}
+	items, _ := continuation["input"].([]any)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 function_call_output items, got %v", continuation["input"])
+	}
+	for i, want := range []struct{ callID, output string }{
+		{"call_price", `{"usd":612.5}`},
+		{"call_time", `{"unix":1735689600}`},
+	} {
+		item := items[i].(map[string]any)
+		if item["type"] != "function_call_output" || item["call_id"] != want.callID || item["output"] != want.output {
+			t.Errorf("unexpected item %d: %v", i, item)
+		}
+	}
+
+	if calls := run.EventsOf(audit.EventToolCall); len(calls) != 2 || calls[0].Error != "" || calls[1].Error != "" {
+		t.Errorf("expected 2 successful recorded tool calls, got %+v", calls)
+	}
+}
+
+// TestAnalyzeMarketStrategy tests that the category strategy restricts tools
+// and sources and applies its confidence policy
+func TestAnalyzeMarketStrategy(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+	server.QueueFunctionCalls(llmtest.FunctionCall{CallID: "call_1", Name: "get_time", Arguments: `{}`})
+	server.Queue(llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.95,
+		Reasoning:  "BTC closed above the threshold",
+		Facts: []llm.Fact{
+			{Statement: "BTC closed at $101k", Sources: []string{"https://www.coingecko.com/btc"}, Confidence: 0.9},
+			{Statement: "A blog says BTC closed at $99k", Sources: []string{"https://blog.example/btc"}, Confidence: 0.4},
+		},
+	}, []llm.WebSource{
+		{URL: "https://www.coingecko.com/btc", Title: "BTC"},
+		{URL: "https://blog.example/btc", Title: "Blog"},
+	})...)
+
+	var timeCalled bool
+	pipeline := server.Pipeline()
+	pipeline.SetToolRegistry(testRegistry{
+		&testTool{name: "pancakeswap", run: func(map[string]any) (map[string]any, error) { return nil, nil }},
+		&testTool{name: "get_time", run: func(map[string]any) (map[string]any, error) {
+			timeCalled = true
+			return nil, nil
+		}},
+	})
+
+	_, err := pipeline.AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "Will BTC close above $100k?", Category: "Crypto"})
+	var policyErr *llm.PolicyError
+	if !errors.As(err, &policyErr) || !strings.Contains(policyErr.Reason, "1 citations with credibility 0.75 or more, 2 required") {
+		t.Fatalf("expected the crypto-price policy to require 2 citations, got %v", err)
+	}
+	decision := policyErr.Decision
+	if decision.Strategy != "crypto-price" || decision.Prompt.Category != "crypto-price" {
+		t.Errorf("expected the crypto-price strategy and prompts, got %s / %+v", decision.Strategy, decision.Prompt)
+	}
+	if len(decision.Facts) != 1 || len(decision.Citations) != 1 || decision.Citations[0].URL != "https://www.coingecko.com/btc" {
+		t.Errorf("expected only allowlisted sources, got facts %+v citations %+v", decision.Facts, decision.Citations)
+	}
+
+	requests := server.Requests()
+	tools, _ := requests[0].Body["tools"].([]any)
+	var names []string
+	for _, tool := range tools {
+		tool := tool.(map[string]any)
+		if tool["type"] == "web_search" {
+			if filters, _ := tool["filters"].(map[string]any); filters["allowed_domains"] == nil {
+				t.Errorf("expected web search restricted to the allowlist, got %v", tool)
+			}
+		}
+		if name, ok := tool["name"].(string); ok {
+			names = append(names, name)
+		}
+	}
+	if strings.Join(names, ",") != "pancakeswap" {
+		t.Errorf("expected only the allowed tool to be offered, got %v", names)
+	}
+	if timeCalled {
+		t.Error("expected the disallowed tool not to run")
+	}
+	items, _ := requests[1].Body["input"].([]any)
+	if output, _ := items[0].(map[string]any)["output"].(string); !strings.Contains(output, "not allowed for crypto-price markets") {
+		t.Errorf("expected the model to be told the tool is not allowed, got %q", output)
+	}
+}
+
+// TestAnalyzeMarketCredibility tests credibility-weighted citations and the
+// merging of syndicated copies
+func TestAnalyzeMarketCredibility(t *testing.T) {
+	headline := "Team A beats Team B 3-1 to win the 2025 final"
+	server := llmtest.NewServer(llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.9,
+		Reasoning:  "Team A won",
+		Facts: []llm.Fact{
+			{Statement: "Team A won 3-1", Confidence: 0.8, Sources: []string{
+				"https://www.reuters.com/sports/final-2025", "https://dailyherald.example/sports/final-2025",
+				"https://m.reuters.com/sports/final-2025/?utm_source=x", "https://courier.example/story/123",
+			}},
+			{Statement: "Fans say Team A won", Confidence: 0.5, Sources: []string{"https://www.reddit.com/r/football/1"}},
+		},
+	}, []llm.WebSource{
+		{URL: "https://dailyherald.example/sports/final-2025", Title: headline},
+		{URL: "https://www.reuters.com/sports/final-2025", Title: headline},
+		{URL: "https://m.reuters.com/sports/final-2025/?utm_source=x", Title: "Final"},
+		{URL: "https://courier.example/story/123", Title: "Team A beats Team B 3-1 to win 2025 final"},
+		{URL: "https://www.reddit.com/r/football/1", Title: "Match thread"},
+	})...)
+	defer server.Close()
+
+	decision, err := server.Pipeline().AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "Did Team A win the final?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decision.Citations) != 2 {
+		t.Fatalf("expected the wire story and the forum post, got %+v", decision.Citations)
+	}
+
+	wire, forum := decision.Citations[0], decision.Citations[1]
+	if wire.URL != "https://www.reuters.com/sports/final-2025" || wire.Tier != "wire" || wire.Domain != "reuters.com" || len(wire.Copies) != 3 {
+		t.Errorf("expected the copies merged into the wire citation, got %+v", wire)
+	}
+	if wire.Weight < 0.179 || wire.Weight > 0.181 { // 0.8 / 4 sources × 0.9
+		t.Errorf("expected the best copy's weight, got %f", wire.Weight)
+	}
+	if forum.Tier != "low" || forum.Weight < 0.074 || forum.Weight > 0.076 { // 0.5 × 0.15
+		t.Errorf("expected a low-credibility forum citation, got %+v", forum)
+	}
+}
+
+// TestAnalyzeMarketTimeline tests that every step sees the timeline, that an
+// open event window is rejected before any call, and that a decision resting
+// on previews is rejected
+func TestAnalyzeMarketTimeline(t *testing.T) {
+	market := llm.MarketInfo{
+		Question:    "Will Team A win the final on 2025-03-01?",
+		CloseTime:   time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC).Unix(),
+		BlockTime:   time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC).Unix(),
+		EventWindow: &llm.EventWindow{End: time.Date(2025, 3, 1, 23, 59, 59, 0, time.UTC).Unix()},
+	}
+
+	server := llmtest.NewServer()
+	defer server.Close()
+	if _, err := server.Pipeline().AnalyzeMarket(context.Background(), market); !errors.Is(err, llm.ErrEventNotEnded) || len(server.Requests()) != 0 {
+		t.Fatalf("expected the open window rejected without calls, got %v", err)
+	}
+
+	market.BlockTime = time.Date(2025, 3, 2, 8, 0, 0, 0, time.UTC).Unix()
+	server.Queue(llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.7,
+		Facts: []llm.Fact{
+			{Statement: "Team A is favored", Sources: []string{"https://example.com/preview"}, Confidence: 0.6, PublishedAt: "2025-02-27", EventDate: "2025-03-01"},
+		},
+	}, []llm.WebSource{{URL: "https://example.com/preview", Title: "Preview"}})...)
+
+	_, err := server.Pipeline().AnalyzeMarket(context.Background(), market)
+	var policyErr *llm.PolicyError
+	if !errors.As(err, &policyErr) || !strings.Contains(policyErr.Reason, "published before the event") || !policyErr.Decision.Facts[0].Premature {
+		t.Fatalf("expected a decision on a preview rejected, got %v", err)
+	}
+
+	for i, request := range server.Requests() {
+		body := fmt.Sprint(request.Body)
+		for _, want := range []string{"Current block time: 2025-03-02 08:00:00 UTC", "Market close time: 2025-03-01 12:00:00 UTC", "unknown start to 2025-03-01 23:59:59 UTC"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("request %d: expected %q in the prompt", i, want)
+			}
+		}
+	}
+}
+
+// TestAnalyzeMarketRetry tests that rate limits and server errors are retried,
+// honoring Retry-After, and that each attempt is audited
+func TestAnalyzeMarketRetry(t *testing.T) {
+	script := llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.9,
+		Facts:      []llm.Fact{{Statement: "x", Sources: []string{"https://example.com"}, Confidence: 1}},
+	}, nil)
+	server := llmtest.NewServer()
+	defer server.Close()
+	server.QueueStatus(http.StatusTooManyRequests, "1")
+	server.Queue(script[0])
+	server.QueueStatus(http.StatusBadGateway, "")
+	server.Queue(script[1:]...)
+
+	run := audit.NewRun(56, 1, "test-model")
+	started := time.Now()
+	decision, err := server.Pipeline().AnalyzeMarket(audit.WithRun(context.Background(), run), llm.MarketInfo{Question: "?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Since(started) < time.Second {
+		t.Error("expected the retry to wait for Retry-After")
+	}
+	if decision.Model != "test-model" {
+		t.Errorf("expected the primary model to answer, got %q", decision.Model)
+	}
+	if calls := run.EventsOf(audit.EventLLMCall); len(calls) != 5 || calls[0].Status != http.StatusTooManyRequests || calls[1].Error != "" {
+		t.Errorf("expected 5 audited attempts, got %+v", calls)
+	}
+}
+
+// TestAnalyzeMarketFallback tests falling back to another model when the
+// primary stays unavailable, and recording which model answered
+func TestAnalyzeMarketFallback(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+	for range llmtest.Resilience.MaxAttempts {
+		server.QueueStatus(http.StatusServiceUnavailable, "")
+	}
+	server.Queue(llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.9,
+		Facts:      []llm.Fact{{Statement: "x", Sources: []string{"https://example.com"}, Confidence: 1}},
+	}, nil)...)
+
+	// The primary's circuit opens on its failures, so later steps skip it
+	resilience := llmtest.Resilience
+	resilience.BreakerThreshold = resilience.MaxAttempts
+	pipeline := server.Pipeline()
+	pipeline.SetRouter(llm.NewRouter([]llm.Endpoint{{Model: "fallback-model"}}, resilience))
+
+	decision, err := pipeline.AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decision.Model != "fallback-model" {
+		t.Errorf("expected the fallback to answer, got %q", decision.Model)
+	}
+
+	var models []string
+	for _, req := range server.Requests() {
+		models = append(models, req.Body["model"].(string))
+	}
+	want := "test-model test-model test-model fallback-model fallback-model fallback-model"
+	if strings.Join(models, " ") != want {
+		t.Errorf("expected models %s, got %v", want, models)
+	}
+
+	// With every endpoint down the analysis fails as unavailable
+	server.QueueStatus(http.StatusServiceUnavailable, "")
+	server.QueueStatus(http.StatusServiceUnavailable, "")
+	resilience.MaxAttempts = 1
+	pipeline.SetRouter(llm.NewRouter([]llm.Endpoint{{Model: "fallback-model"}}, resilience))
+	if _, err := pipeline.AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "?"}); !errors.Is(err, llm.ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+	if server.Remaining() != 0 {
+		t.Errorf("expected one attempt per endpoint, %d replies left", server.Remaining())
+	}
+}
+
+// TestAnalyzeMarketChallenge tests that the judge's verdict lowers confidence
+// or escalates the decision
+func TestAnalyzeMarketChallenge(t *testing.T) {
+	script := llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.9,
+		Reasoning:  "reported",
+		Facts:      []llm.Fact{{Statement: "x", Sources: []string{"https://example.com"}, Confidence: 1}},
+	}, nil)
+
+	tests := []struct {
+		verdict    llm.Verdict
+		confidence float64
+		want       float64 // Decision confidence; 0 when rejected
+	}{
+		{llm.VerdictUpheld, 0.95, 0.9}, // The judge never raises confidence
+		{llm.VerdictWeakened, 0.6, 0.6},
+		{llm.VerdictWeakened, 0.95, 0.8}, // Weakened always costs confidence
+		{llm.VerdictWeakened, 0.3, 0},    // Below the generic policy's 0.5
+		{llm.VerdictOverturned, 0.7, 0},  // Escalated whatever the confidence
+	}
+	for _, tt := range tests {
+		server := llmtest.NewServer(append(script, llmtest.ChallengeScript(0, tt.verdict, tt.confidence)...)...)
+		pipeline := server.Pipeline()
+		pipeline.SetChallenge(true)
+
+		decision, err := pipeline.AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "Was it reported?"})
+		var policyErr *llm.PolicyError
+		if errors.As(err, &policyErr) {
+			decision = policyErr.Decision
+		}
+		switch {
+		case tt.want == 0 && policyErr == nil:
+			t.Errorf("%s %.2f: expected the decision to be rejected, got %v", tt.verdict, tt.confidence, err)
+		case tt.want > 0 && err != nil:
+			t.Errorf("%s %.2f: unexpected error: %v", tt.verdict, tt.confidence, err)
+		case decision == nil || decision.Challenge == nil:
+			t.Errorf("%s %.2f: expected the challenge to be recorded", tt.verdict, tt.confidence)
+		case decision.Challenge.Verdict != tt.verdict || decision.Challenge.OriginalConfidence != 0.9 || len(decision.Challenge.Cases) != 1:
+			t.Errorf("%s %.2f: unexpected challenge %+v", tt.verdict, tt.confidence, decision.Challenge)
+		case tt.want > 0 && decision.Confidence != tt.want:
+			t.Errorf("%s %.2f: expected confidence %.2f, got %.2f", tt.verdict, tt.confidence, tt.want, decision.Confidence)
+		}
+
+		requests := server.Requests()
+		if len(requests) != 5 || requests[3].Path != "/responses" || requests[4].Path != "/chat/completions" {
+			t.Errorf("%s: expected a search-backed challenge and a judge call, got %d requests", tt.verdict, len(requests))
+		} else if prompt := requests[3].Body["input"].(string); !strings.Contains(prompt, "other outcomes (0)") || !strings.Contains(prompt, "reported") {
+			t.Errorf("expected the challenge prompt to name the decision and the other outcome, got %q", prompt)
+		}
+		server.Close()
+	}
+}
+
+// TestAnalyzeMarketChallengeCases tests that stray counter-cases are dropped
+// and that an outcome left unargued fails the challenge before the judge
+func TestAnalyzeMarketChallengeCases(t *testing.T) {
+	script := llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.9,
+		Reasoning:  "reported",
+		Facts:      []llm.Fact{{Statement: "x", Sources: []string{"https://example.com"}, Confidence: 1}},
+	}, nil)
+	counter := func(outcomes ...uint64) string {
+		cases := []map[string]any{}
+		for _, id := range outcomes {
+			cases = append(cases, map[string]any{"outcomeId": id, "argument": "scripted", "strength": 0.5, "sources": []string{}})
+		}
+		reply, _ := json.Marshal(map[string]any{"cases": cases})
+		return string(reply)
+	}
+	judge := llmtest.ChallengeScript(0, llm.VerdictUpheld, 0.9)[1]
+
+	// The decided outcome, an unknown outcome and a repeat are dropped
+	server := llmtest.NewServer(append(script, counter(1, 0, 7, 0), judge)...)
+	pipeline := server.Pipeline()
+	pipeline.SetChallenge(true)
+	decision, err := pipeline.AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "Was it reported?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cases := decision.Challenge.Cases; len(cases) != 1 || cases[0].OutcomeID != 0 {
+		t.Errorf("expected only the case for outcome 0, got %+v", cases)
+	}
+	server.Close()
+
+	// No case for the other outcome never reaches the judge
+	for _, reply := range []string{counter(), counter(1)} {
+		server := llmtest.NewServer(append(script, reply, judge)...)
+		pipeline := server.Pipeline()
+		pipeline.SetChallenge(true)
+		if _, err := pipeline.AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "Was it reported?"}); err == nil || !strings.Contains(err.Error(), "no counter-case for outcomes [0]") {
+			t.Errorf("%s: expected the challenge to fail, got %v", reply, err)
+		}
+		if server.Remaining() != 1 {
+			t.Errorf("%s: expected the judge not to be called, %d replies left", reply, server.Remaining())
+		}
+		server.Close()
+	}
+}
+
+// pages is a fetcher serving fixed page text
+type pages map[string]string
+
+func (p pages) Fetch(_ context.Context, url string) (*fetch.Page, error) {
+	text, ok := p[url]
+	if !ok {
+		return nil, fmt.Errorf("%s returned status 404", url)
+	}
+	return &fetch.Page{URL: url, Status: 200, Text: text, Hash: "hash-of-" + url}, nil
+}
+
+// TestAnalyzeMarketVerification tests that citations their pages do not
+// confirm are dropped, that a confirming copy replaces its original, and that
+// too few verified citations block the decision
+func TestAnalyzeMarketVerification(t *testing.T) {
+	headline := "Team A beats Team B in the final"
+	script := llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.9,
+		Reasoning:  "Team A won",
+		Facts: []llm.Fact{
+			{Statement: "Team A beat Team B 3-1 in the final", Confidence: 0.9, Sources: []string{"https://wire.example/final", "https://mirror.example/final"}},
+			{Statement: "Team B won the final on penalties", Confidence: 0.3, Sources: []string{"https://blog.example/final"}},
+		},
+	}, []llm.WebSource{
+		{URL: "https://wire.example/final", Title: headline},
+		{URL: "https://mirror.example/final", Title: headline},
+		{URL: "https://blog.example/final", Title: "Penalty drama"},
+	})
+	verifier := verify.New(pages{
+		"https://wire.example/final":   "Subscribe to read this article.",
+		"https://mirror.example/final": "Sports\nTeam A beat Team B 3-1 in the final on Sunday night.",
+	})
+
+	server := llmtest.NewServer(script...)
+	defer server.Close()
+	pipeline := server.Pipeline()
+	pipeline.SetVerifier(verifier)
+
+	run := audit.NewRun(97, 1, "test")
+	decision, err := pipeline.AnalyzeMarket(audit.WithRun(context.Background(), run), llm.MarketInfo{Question: "Did Team A win the final?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decision.Citations) != 1 {
+		t.Fatalf("expected one verified citation, got %+v", decision.Citations)
+	}
+	cited := decision.Citations[0]
+	if cited.URL != "https://mirror.example/final" || !cited.Verified || cited.PageHash != "hash-of-https://mirror.example/final" || len(cited.Copies) != 1 || cited.Copies[0] != "https://wire.example/final" {
+		t.Errorf("expected the confirming copy to be cited, got %+v", cited)
+	}
+	if len(decision.Unverified) != 1 || decision.Unverified[0].URL != "https://blog.example/final" || decision.Unverified[0].Reason == "" {
+		t.Errorf("expected the unreachable page to be dropped with a reason, got %+v", decision.Unverified)
+	}
+	if checks := run.EventsOf(audit.EventToolCall); len(checks) != 3 || checks[0].Step != string(llm.StepVerifyCitations) {
+		t.Errorf("expected three audited page checks, got %+v", checks)
+	}
+
+	// The same run under a policy requiring two verified citations
+	strict := llmtest.NewServer(script...)
+	defer strict.Close()
+	pipeline = strict.Pipeline()
+	pipeline.SetVerifier(verifier)
+	pipeline.SetStrategies(llm.NewStrategyRegistry(llm.Strategy{Confidence: llm.ConfidencePolicy{MinVerified: 2}}))
+
+	_, err = pipeline.AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "Did Team A win the final?"})
+	if !errors.Is(err, llm.ErrPolicyRejected) || !strings.Contains(err.Error(), "1 of 2 citations verified") {
+		t.Errorf("expected the decision to be blocked, got %v", err)
+	}
+}
+
+// TestAnalyzeMarketAnnotations tests that url_citation annotations become
+// sources of the facts they annotate and that sources the model made up are
+// flagged
+func TestAnalyzeMarketAnnotations(t *testing.T) {
+	script := llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.9,
+		Reasoning:  "Équipe A won",
+		Facts: []llm.Fact{
+			{Statement: "Équipe A won the final 2-0", Confidence: 0.9, Sources: []string{"https://made-up.example/final"}},
+			{Statement: "The league confirmed the result", Confidence: 0.8},
+		},
+	}, []llm.WebSource{{URL: "https://made-up.example/final", Title: "Final"}})
+
+	server := llmtest.NewServer()
+	defer server.Close()
+	search := script[0]
+	server.QueueCited(search,
+		llmtest.Cite(search, "Équipe A won the final 2-0", "https://wire.example/final?utm_source=openai", "Équipe A beat B"),
+		llmtest.Cite(search, "The league confirmed the result", "https://league.example/results", "Results"),
+	)
+	server.Queue(script[1:]...)
+
+	decision, err := server.Pipeline().AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "Did Équipe A win the final?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := decision.Facts[0].Sources; len(got) != 2 || got[1] != "https://wire.example/final" {
+		t.Errorf("expected the annotated page added to the first fact, got %v", got)
+	}
+	if got := decision.Facts[1].Sources; len(got) != 1 || got[0] != "https://league.example/results" {
+		t.Errorf("expected the second fact sourced from its annotation, got %v", got)
+	}
+
+	cited := make(map[string]llm.Citation)
+	for _, c := range decision.Citations {
+		cited[c.URL] = c
+	}
+	if len(cited) != 3 || !cited["https://made-up.example/final"].Unannotated {
+		t.Fatalf("expected the model's own source flagged, got %+v", decision.Citations)
+	}
+	wire, league := cited["https://wire.example/final"], cited["https://league.example/results"]
+	if wire.Unannotated || wire.Title != "Équipe A beat B" || wire.Snippet != "Équipe A won the final 2-0" || league.Unannotated {
+		t.Errorf("expected annotated citations, got %+v and %+v", wire, league)
+	}
+}
+
+// TestAnalyzeMarketDocumentSearch tests searching without hosted web search:
+// a document the tool returned backs the source the model cites
+func TestAnalyzeMarketDocumentSearch(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+	server.QueueFunctionCalls(llmtest.FunctionCall{CallID: "call_docs", Name: "search_documents", Arguments: `{"query":"CPI March"}`})
+	server.Queue(llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.9,
+		Facts:      []llm.Fact{{Statement: "CPI rose 3.1% in March", Sources: []string{"https://www.bls.gov/cpi/"}, Confidence: 0.9}},
+	}, []llm.WebSource{{URL: "https://www.bls.gov/cpi/", Title: "CPI"}})...)
+
+	pipeline := server.Pipeline()
+	pipeline.SetHostedSearch(false)
+	pipeline.SetToolRegistry(testRegistry{&testTool{name: "search_documents", run: func(map[string]any) (map[string]any, error) {
+		return map[string]any{"citations": []map[string]any{{"url": "https://bls.gov/cpi", "title": "CPI"}}}, nil
+	}}})
+
+	decision, err := pipeline.AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "Did CPI rise above 3% in March?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tools, _ := server.Requests()[0].Body["tools"].([]any)
+	if len(tools) != 1 || tools[0].(map[string]any)["name"] != "search_documents" {
+		t.Errorf("expected only the registry tool without hosted search, got %v", tools)
+	}
+	if len(decision.Citations) != 1 || decision.Citations[0].Unannotated {
+		t.Errorf("expected the retrieved document to back the citation, got %+v", decision.Citations)
+	}
+}
+
+// annotateStage is a custom stage adding resolution criteria to the market
+type annotateStage struct{}
+
+func (annotateStage) Name() string            { return "annotate" }
+func (annotateStage) Inputs() []llm.Artifact  { return []llm.Artifact{llm.ArtifactMarket} }
+func (annotateStage) Outputs() []llm.Artifact { return []llm.Artifact{llm.ArtifactMetadata} }
+func (annotateStage) Run(_ context.Context, a *llm.Analysis) error {
+	a.Market.Description = "Resolves YES on the official league result."
+	a.Artifacts[llm.ArtifactMetadata] = "league"
+	return nil
+}
+
+// TestAnalyzeMarketStages tests a custom stage in a per-strategy layout, and
+// that invalid layouts are refused
+func TestAnalyzeMarketStages(t *testing.T) {
+	server := llmtest.NewServer(llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.9,
+		Reasoning:  "Team A won",
+		Facts:      []llm.Fact{{Statement: "Team A won", Confidence: 0.9, Sources: []string{"https://league.example/result"}}},
+	}, []llm.WebSource{{URL: "https://league.example/result", Title: "Result"}})...)
+	defer server.Close()
+
+	pipeline := server.Pipeline()
+	if err := pipeline.RegisterStage(annotateStage{}); err != nil {
+		t.Fatal(err)
+	}
+	err := pipeline.SetStages(&llm.PipelineConfig{
+		Stages: llm.DefaultPipelineConfig().Stages,
+		Strategies: map[string][]llm.StageConfig{"generic": {
+			{Name: llm.StagePolicyGate},
+			{Name: llm.StageExtractFacts, After: []string{"annotate"}, Timeout: jsontime.Duration(time.Minute)},
+			{Name: llm.StageCheckContradictions},
+			{Name: llm.StageDecideOutcome},
+			{Name: llm.StageBuildCitations},
+			{Name: "annotate"},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decision, err := pipeline.AnalyzeMarket(context.Background(), llm.MarketInfo{Question: "Did Team A win?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var stages []string
+	for _, trace := range decision.Trace {
+		stages = append(stages, trace.Stage+":"+trace.Status)
+	}
+	if got := strings.Join(stages, ","); got != "annotate:completed,extract_facts:completed,check_contradictions:completed,decide_outcome:completed,build_citations:completed,policy_gate:completed" {
+		t.Errorf("unexpected stage order %s", got)
+	}
+	if requests := server.Requests(); len(requests) != 3 || !strings.Contains(fmt.Sprint(requests[0].Body), "official league result") {
+		t.Errorf("expected the custom stage's description in the search, got %d requests", len(requests))
+	}
+
+	// Layouts that cannot run are refused and the current one stays
+	for _, cfg := range []*llm.PipelineConfig{
+		{Stages: []llm.StageConfig{{Name: llm.StageExtractFacts}}},
+		{Stages: []llm.StageConfig{{Name: llm.StageBuildCitations}}},
+		{Stages: []llm.StageConfig{{Name: "fetch_metadata"}}},
+	} {
+		if err := pipeline.SetStages(cfg); err == nil {
+			t.Errorf("expected %+v to be refused", cfg.Stages)
+		}
+	}
+}
+
+// TestAnalyzeMarketScalar tests mapping the value read for a bracket market
+// onto its outcome, and refusing values the market cannot resolve on
+func TestAnalyzeMarketScalar(t *testing.T) {
+	one := 1
+	spec := &scalar.Spec{Quantity: "US CPI YoY", Unit: "%", Decimals: &one, Brackets: []scalar.Bracket{
+		{Outcome: 0, Max: "2.5"},
+		{Outcome: 1, Min: "2.5", Max: "3", Label: "2.5% to 3%"},
+		{Outcome: 2, Min: "3"},
+	}}
+	market := llm.MarketInfo{
+		Question:     "What will US CPI inflation be for February 2025?",
+		OutcomeCount: 3,
+		CloseTime:    1741046400,                        // 2025-03-04 00:00 UTC
+		EventWindow:  &llm.EventWindow{End: 1740787199}, // 2025-02-28 23:59:59 UTC
+		BlockTime:    1741132800,
+		Scalar:       spec,
+	}
+	script := llmtest.Script(llm.Decision{
+		Facts: []llm.Fact{{Statement: "CPI rose 2.96% year over year in February", Confidence: 0.9, Sources: []string{"https://bls.gov/cpi"}}},
+	}, []llm.WebSource{{URL: "https://bls.gov/cpi", Title: "CPI"}})
+
+	tests := []struct {
+		name   string
+		reply  string
+		want   uint64
+		reject string
+	}{
+		{"rounded onto a bound", llmtest.ValueReply("2.96", "percent", "https://bls.gov/cpi", "2025-02-28", 0.9), 2, ""},
+		{"inside a bracket", llmtest.ValueReply("2.81", "%", "https://bls.gov/cpi", "2025-02-28T12:00:00Z", 0.9), 1, ""},
+		{"local time past the cutoff", llmtest.ValueReply("2.8", "%", "https://bls.gov/cpi", "2025-02-28T20:00:00-05:00", 0.9), 0, "after the cutoff"},
+		{"other unit", llmtest.ValueReply("0.028", "ratio", "https://bls.gov/cpi", "2025-02-28", 0.9), 0, `resolves in "%"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := llmtest.NewServer(script[0], script[1], tt.reply)
+			defer server.Close()
+			pipeline := server.Pipeline()
+			pipeline.SetStrategies(llm.NewStrategyRegistry(llm.Strategy{Confidence: llm.ConfidencePolicy{MinConfidence: 0.5, MinCitations: 1}}))
+
+			decision, err := pipeline.AnalyzeMarket(context.Background(), market)
+			if tt.reject != "" {
+				var policyErr *llm.PolicyError
+				if !errors.As(err, &policyErr) || !strings.Contains(policyErr.Reason, tt.reject) || policyErr.Decision.Value == nil {
+					t.Fatalf("expected a rejection containing %q with the reading, got %v", tt.reject, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if decision.OutcomeID != tt.want || decision.Value == nil || decision.Value.Bracket == "" {
+				t.Errorf("expected outcome %d with the reading, got %d %+v", tt.want, decision.OutcomeID, decision.Value)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package llm
 
 import (
 	"context"
+
+	"github.com/project-gamma/ai-resolver/internal/scalar"
+	"github.com/project-gamma/ai-resolver/internal/verify"
 )
 
 // Pipeline defines the multi-pass LLM analysis pipeline
@@ -19,16 +22,47 @@ type MarketInfo struct {
 	CloseTime    int64  `json:"closeTime"`
 	MetadataURI  string `json:"metadataUri"`
 	OutcomeCount int    `json:"outcomeCount"` // 2 for binary (YES/NO)
+
+	// Time context: the chain time of the analysis (zero means now) and the
+	// window the question's event falls in (nil means it ends at CloseTime)
+	BlockTime   int64        `json:"blockTime,omitempty"`
+	EventWindow *EventWindow `json:"eventWindow,omitempty"`
+
+	// Brackets of a scalar market; nil for YES/NO markets
+	Scalar *scalar.Spec `json:"scalar,omitempty"`
+
+	// The result of the market this one is conditional on, once final
+	Parent *ParentResult `json:"parent,omitempty"`
+}
+
+// ParentResult is the final outcome of a conditional market's parent
+type ParentResult struct {
+	MarketID  uint64 `json:"marketId"`
+	OutcomeID uint64 `json:"outcomeId"`
+	Condition string `json:"condition,omitempty"` // The condition in words, e.g. "Team A reaches the final"
 }
 
 // Decision represents the final outcome decision with evidence
 type Decision struct {
-	OutcomeID  uint64     `json:"outcomeId"`  // 0 = NO, 1 = YES (for binary markets)
-	Confidence float64    `json:"confidence"` // 0-1 confidence score
-	Reasoning  string     `json:"reasoning"`  // Explanation of decision
-	Citations  []Citation `json:"citations"`  // Evidence citations
-	Facts      []Fact     `json:"facts"`      // Extracted facts
-	Timestamp  int64      `json:"timestamp"`  // Unix timestamp of decision
+	OutcomeID  uint64      `json:"outcomeId"`           // 0 = NO, 1 = YES (for binary markets)
+	Confidence float64     `json:"confidence"`          // 0-1 confidence score
+	Reasoning  string      `json:"reasoning"`           // Explanation of decision
+	Citations  []Citation  `json:"citations"`           // Evidence citations
+	Facts      []Fact      `json:"facts"`               // Extracted facts
+	Timestamp  int64       `json:"timestamp"`           // Unix timestamp of decision
+	Prompt     *PromptInfo `json:"prompt,omitempty"`    // Prompt templates that produced the decision
+	Strategy   string      `json:"strategy,omitempty"`  // Category strategy that resolved the market
+	Model      string      `json:"model,omitempty"`     // Model that answered the decision step, a fallback's when the primary was unavailable
+	Challenge  *Challenge  `json:"challenge,omitempty"` // Adversarial review, when enabled
+
+	// Citations dropped because the cited page did not confirm them, when
+	// citations are verified
+	Unverified []verify.Result `json:"unverified,omitempty"`
+	// The value a scalar market's outcome was mapped from
+	Value *Reading `json:"value,omitempty"`
+
+	// How each stage of the analysis ran
+	Trace []StageTrace `json:"trace,omitempty"`
 }
 
 // Citation represents a source citation
@@ -37,6 +71,22 @@ type Citation struct {
 	Title   string  `json:"title"`
 	Snippet string  `json:"snippet"`
 	Weight  float64 `json:"weight"` // Importance weight (0-1)
+
+	// Source credibility (see internal/credibility). Syndicated copies of the
+	// same article are merged into one citation and listed in Copies.
+	Domain      string   `json:"domain,omitempty"`
+	Tier        string   `json:"tier,omitempty"`
+	Credibility float64  `json:"credibility,omitempty"`
+	Copies      []string `json:"copies,omitempty"`
+
+	// Set when the cited page was fetched and confirmed the snippet or a
+	// fact citing it; PageHash is the SHA-256 of the page as fetched
+	Verified bool   `json:"verified,omitempty"`
+	PageHash string `json:"pageHash,omitempty"`
+
+	// Unannotated flags a source the model named that none of the web
+	// search's url_citation annotations returned
+	Unannotated bool `json:"unannotated,omitempty"`
 }
 
 // Fact represents an extracted fact from sources
@@ -46,6 +96,9 @@ type Fact struct {
 	Confidence         float64  `json:"confidence"`  // 0-1 confidence in fact
 	Contradicts        bool     `json:"contradicts"` // Whether this contradicts other facts
 	SupportingEvidence string   `json:"supportingEvidence"`
+	PublishedAt        string   `json:"publishedAt"`         // When the source was published, ISO 8601 UTC; empty if unknown
+	EventDate          string   `json:"eventDate"`           // When the reported event happened, ISO 8601 UTC; empty if unknown
+	Premature          bool     `json:"premature,omitempty"` // Published before the event could have happened
 }
 
 // AnalysisStep represents a step in the multi-pass pipeline
@@ -55,5 +108,10 @@ const (
 	StepExtractFacts        AnalysisStep = "extract_facts"
 	StepCheckContradictions AnalysisStep = "check_contradictions"
 	StepDecideOutcome       AnalysisStep = "decide_outcome"
+	StepExtractValue        AnalysisStep = "extract_value" // Replaces decide_outcome for scalar markets
 	StepBuildCitations      AnalysisStep = "build_citations"
+	StepVerifyCitations     AnalysisStep = "verify_citations"  // Fetches cited pages, when enabled
+	StepChallengeOutcome    AnalysisStep = "challenge_outcome" // Devil's advocate, when enabled
+	StepJudgeDecision       AnalysisStep = "judge_decision"    // Rules on the challenge
+	StepReviewQuestion      AnalysisStep = "review_question"   // Question linting, outside the analysis
 )
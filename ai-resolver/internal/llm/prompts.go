@@ -0,0 +1,230 @@
+package llm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"testing/fstest"
+	"text/template"
+)
+
+// defaultPrompts is the prompt set shipped with the binary. Operators can
+// replace it with a directory of the same layout (see LoadPrompts).
+//
+//go:embed prompts
+var defaultPrompts embed.FS
+
+// promptSteps are the pipeline steps that have a prompt template
+var promptSteps = []AnalysisStep{StepExtractFacts, StepCheckContradictions, StepDecideOutcome, StepExtractValue, StepChallengeOutcome, StepJudgeDecision}
+
+// optionalPromptSteps may be left out of a prompt set, which then uses the
+// built-in template: extract_value and the challenge steps, added after the
+// first sets were written, and review_question, which is used outside the analysis pipeline
+// and is not part of PromptSet.Info
+var optionalPromptSteps = []AnalysisStep{StepExtractValue, StepChallengeOutcome, StepJudgeDecision, StepReviewQuestion}
+
+// PromptInfo identifies the prompt templates behind a decision
+type PromptInfo struct {
+	Version  string `json:"version"`            // Version from the prompt set's manifest.json
+	Hash     string `json:"hash"`               // SHA-256 over the templates used, see PromptSet.Info
+	Category string `json:"category,omitempty"` // Category whose overrides were applied
+}
+
+// PromptSet is a versioned set of text/template prompts, one per pipeline step,
+// with optional per-category overrides:
+//
+//	manifest.json                         {"version": "..."}
+//	extract_facts.tmpl
+//	check_contradictions.tmpl
+//	decide_outcome.tmpl
+//	extract_value.tmpl                    optional, for scalar markets
+//	challenge_outcome.tmpl                optional, see optionalPromptSteps
+//	judge_decision.tmpl                   optional
+//	review_question.tmpl                  optional
+//	categories/<category>/<step>.tmpl     overrides one step for a category
+type PromptSet struct {
+	version   string
+	templates map[string]*promptTemplate // "<step>" or "<category>/<step>"
+	files     map[string]string          // Sources by file name, manifest included
+}
+
+type promptTemplate struct {
+	tmpl *template.Template
+	hash string // Hex SHA-256 of the template source
+}
+
+// promptData is what templates are rendered with
+type promptData struct {
+	Market      MarketInfo
+	SearchQuery string
+	Facts       []Fact
+	Timeline    Timeline
+	Draft       QuestionDraft // review_question only
+
+	// challenge_outcome and judge_decision only
+	Decision     *Decision
+	Alternatives []uint64      // Outcomes other than the decided one
+	Cases        []CounterCase // judge_decision only
+}
+
+var promptFuncs = template.FuncMap{
+	"join": strings.Join,
+	"utc":  formatUTC,
+	"json": func(v any) (string, error) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		return string(data), err
+	},
+}
+
+// DefaultPrompts returns the built-in prompt set
+func DefaultPrompts() *PromptSet {
+	sub, err := fs.Sub(defaultPrompts, "prompts")
+	if err != nil {
+		panic(err)
+	}
+	set, err := LoadPrompts(sub)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in prompts: %v", err))
+	}
+	return set
+}
+
+// LoadPrompts loads a prompt set from the root of fsys, e.g. os.DirFS(dir)
+func LoadPrompts(fsys fs.FS) (*PromptSet, error) {
+	manifest, err := fs.ReadFile(fsys, "manifest.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt manifest: %w", err)
+	}
+	var m struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt manifest: %w", err)
+	}
+	if m.Version == "" {
+		return nil, fmt.Errorf("prompt manifest has no version")
+	}
+
+	set := &PromptSet{
+		version:   m.Version,
+		templates: make(map[string]*promptTemplate),
+		files:     map[string]string{"manifest.json": string(manifest)},
+	}
+	for _, step := range append(slices.Clone(promptSteps), StepReviewQuestion) {
+		src, name := fsys, string(step)+".tmpl"
+		if _, err := fs.Stat(fsys, name); errors.Is(err, fs.ErrNotExist) && slices.Contains(optionalPromptSteps, step) {
+			if src, err = fs.Sub(defaultPrompts, "prompts"); err != nil {
+				return nil, err
+			}
+		}
+		if err := set.load(src, name, string(step)); err != nil {
+			return nil, err
+		}
+	}
+
+	overrides, err := fs.Glob(fsys, "categories/*/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range overrides {
+		category := path.Base(path.Dir(name))
+		step := strings.TrimSuffix(path.Base(name), ".tmpl")
+		if _, ok := set.templates[step]; !ok {
+			return nil, fmt.Errorf("%s overrides unknown step %q", name, step)
+		}
+		if category != normalizeCategory(category) {
+			return nil, fmt.Errorf("%s: category directories must be lowercase with dashes", name)
+		}
+		if err := set.load(fsys, name, category+"/"+step); err != nil {
+			return nil, err
+		}
+	}
+
+	return set, nil
+}
+
+func (s *PromptSet) load(fsys fs.FS, name, key string) error {
+	src, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fmt.Errorf("failed to read prompt template: %w", err)
+	}
+	tmpl, err := template.New(name).Funcs(promptFuncs).Option("missingkey=error").Parse(string(src))
+	if err != nil {
+		return fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+	sum := sha256.Sum256(src)
+	s.files[name] = string(src)
+	s.templates[key] = &promptTemplate{tmpl: tmpl, hash: hex.EncodeToString(sum[:])}
+	return nil
+}
+
+// Files returns the sources the set was loaded from, by file name
+func (s *PromptSet) Files() map[string]string {
+	return maps.Clone(s.files)
+}
+
+// PromptsFromFiles loads a prompt set from sources by file name, as Files
+// returns them
+func PromptsFromFiles(files map[string]string) (*PromptSet, error) {
+	fsys := make(fstest.MapFS, len(files))
+	for name, src := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(src)}
+	}
+	return LoadPrompts(fsys)
+}
+
+// Version returns the prompt set's version
+func (s *PromptSet) Version() string {
+	return s.version
+}
+
+// template returns the category's override for step, or the default
+func (s *PromptSet) template(step AnalysisStep, category string) *promptTemplate {
+	if category := normalizeCategory(category); category != "" {
+		if t, ok := s.templates[category+"/"+string(step)]; ok {
+			return t
+		}
+	}
+	return s.templates[string(step)]
+}
+
+// Info identifies the templates used for a market category. The hash covers
+// the version and each step's template, so any edit to a prompt changes it.
+func (s *PromptSet) Info(category string) PromptInfo {
+	info := PromptInfo{Version: s.version}
+	h := sha256.New()
+	fmt.Fprintf(h, "version:%s\n", s.version)
+	for _, step := range promptSteps {
+		t := s.template(step, category)
+		fmt.Fprintf(h, "%s:%s\n", step, t.hash)
+		if t != s.templates[string(step)] {
+			info.Category = normalizeCategory(category)
+		}
+	}
+	info.Hash = hex.EncodeToString(h.Sum(nil))
+	return info
+}
+
+// render executes the template of a step, with the category's override if any
+func (s *PromptSet) render(step AnalysisStep, category string, data promptData) (string, error) {
+	var buf bytes.Buffer
+	if err := s.template(step, category).tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s prompt: %w", step, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// normalizeCategory maps a market category such as "Crypto Price" to its
+// directory name, "crypto-price"
+func normalizeCategory(category string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(category, "_", " "))), "-")
+}
@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func testPromptFS() fstest.MapFS {
+	return fstest.MapFS{
+		"manifest.json":                                 {Data: []byte(`{"version":"7"}`)},
+		"extract_facts.tmpl":                            {Data: []byte("Extract: {{.Market.Question}} / {{.SearchQuery}}")},
+		"check_contradictions.tmpl":                     {Data: []byte("{{range $i, $f := .Facts}}[{{$i}}] {{$f.Statement}} ({{join $f.Sources \", \"}})\n{{end}}")},
+		"decide_outcome.tmpl":                           {Data: []byte("Decide: {{.Market.Question}}")},
+		"categories/sports/decide_outcome.tmpl":         {Data: []byte("Decide the match: {{.Market.Question}}")},
+		"categories/weather/extract_facts.tmpl":         {Data: []byte("Weather: {{.Market.Question}}")},
+		"categories/weather/decide_outcome.tmpl":        {Data: []byte("Decide the forecast: {{.Market.Question}}")},
+		"categories/politics/check_contradictions.tmpl": {Data: []byte("Politics")},
+	}
+}
+
+// TestPromptOverrides tests category overrides and the prompt hash
+func TestPromptOverrides(t *testing.T) {
+	prompts, err := LoadPrompts(testPromptFS())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	market := MarketInfo{Question: "Who wins?", Category: "Sports"}
+	got, err := prompts.render(StepDecideOutcome, market.Category, promptData{Market: market})
+	if err != nil || got != "Decide the match: Who wins?" {
+		t.Errorf("expected the sports override, got %q (%v)", got, err)
+	}
+	got, _ = prompts.render(StepExtractFacts, market.Category, promptData{Market: market, SearchQuery: "q"})
+	if got != "Extract: Who wins? / q" {
+		t.Errorf("expected the default template for a step without override, got %q", got)
+	}
+	got, _ = prompts.render(StepCheckContradictions, "", promptData{Facts: []Fact{{Statement: "a", Sources: []string{"u1", "u2"}}}})
+	if got != "[0] a (u1, u2)" {
+		t.Errorf("unexpected contradictions prompt %q", got)
+	}
+
+	generic, sports := prompts.Info("unknown"), prompts.Info("sports")
+	if generic.Version != "7" || generic.Category != "" || sports.Category != "sports" {
+		t.Errorf("unexpected prompt info: %+v, %+v", generic, sports)
+	}
+	if generic.Hash == sports.Hash || generic.Hash != prompts.Info("").Hash {
+		t.Error("expected the hash to identify the templates used")
+	}
+
+	// Any edit to a template used by a category changes its hash
+	edited := testPromptFS()
+	edited["decide_outcome.tmpl"] = &fstest.MapFile{Data: []byte("Decide now: {{.Market.Question}}")}
+	reloaded, err := LoadPrompts(edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Info("unknown").Hash == generic.Hash {
+		t.Error("expected an edited template to change the hash")
+	}
+	if reloaded.Info("weather").Hash != prompts.Info("weather").Hash {
+		t.Error("expected categories overriding the edited step to keep their hash")
+	}
+}
+
+// TestLoadPromptsErrors tests that incomplete prompt sets are rejected
+func TestLoadPromptsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(fstest.MapFS)
+		want   string
+	}{
+		{"no manifest", func(fs fstest.MapFS) { delete(fs, "manifest.json") }, "manifest"},
+		{"no version", func(fs fstest.MapFS) { fs["manifest.json"] = &fstest.MapFile{Data: []byte(`{}`)} }, "no version"},
+		{"missing step", func(fs fstest.MapFS) { delete(fs, "decide_outcome.tmpl") }, "decide_outcome.tmpl"},
+		{"unknown step", func(fs fstest.MapFS) {
+			fs["categories/sports/summarize.tmpl"] = &fstest.MapFile{Data: []byte("x")}
+		}, `unknown step "summarize"`},
+		{"bad category", func(fs fstest.MapFS) {
+			fs["categories/Crypto Price/decide_outcome.tmpl"] = &fstest.MapFile{Data: []byte("x")}
+		}, "lowercase"},
+		{"syntax error", func(fs fstest.MapFS) {
+			fs["extract_facts.tmpl"] = &fstest.MapFile{Data: []byte("{{.Market.Question")}
+		}, "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := testPromptFS()
+			tt.modify(fs)
+			if _, err := LoadPrompts(fs); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestDefaultPrompts tests that the built-in set loads and renders every step
+func TestDefaultPrompts(t *testing.T) {
+	prompts := DefaultPrompts()
+	data := promptData{
+		Market:      MarketInfo{Question: "Will BTC close above $100k?", Category: "crypto-price"},
+		SearchQuery: "BTC close",
+		Facts:       []Fact{{Statement: "BTC closed at $101k", Sources: []string{"https://example.com"}}},
+		Decision:    &Decision{OutcomeID: 1, Confidence: 0.9, Reasoning: "Closed above"},
+		Cases:       []CounterCase{{OutcomeID: 0, Argument: "Intraday low", Strength: 0.2}},
+	}
+	for _, step := range promptSteps {
+		prompt, err := prompts.render(step, data.Market.Category, data)
+		if err != nil || !strings.Contains(prompt, data.Market.Question) {
+			t.Errorf("%s: unexpected prompt %q (%v)", step, prompt, err)
+		}
+	}
+	if info := prompts.Info("Crypto Price"); info.Category != "crypto-price" {
+		t.Errorf("expected the crypto-price overrides to apply, got %+v", info)
+	}
+}
@@ -0,0 +1,362 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable is returned when no endpoint of the fallback chain answered
+var ErrUnavailable = errors.New("LLM unavailable")
+
+// Endpoint is an OpenAI-compatible API and the model asked there. Empty
+// fields inherit the pipeline's own API, key and model, so the primary
+// endpoint is the zero Endpoint and a fallback model on the same API needs
+// only Model.
+type Endpoint struct {
+	Name      string `json:"name"`      // Label in logs; defaults to the model
+	BaseURL   string `json:"baseUrl"`   // e.g. https://api.openai.com/v1
+	APIKey    string `json:"apiKey"`    // Prefer APIKeyEnv in files
+	APIKeyEnv string `json:"apiKeyEnv"` // Environment variable holding the key
+	Model     string `json:"model"`
+}
+
+// LoadFallbacks reads a JSON array of fallback endpoints, tried in order
+// when the primary stays unavailable
+func LoadFallbacks(path string) ([]Endpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fallbacks: %w", err)
+	}
+	var endpoints []Endpoint
+	if err := json.Unmarshal(data, &endpoints); err != nil {
+		return nil, fmt.Errorf("failed to parse fallbacks %s: %w", path, err)
+	}
+	for i, e := range endpoints {
+		if e.Model == "" && e.BaseURL == "" {
+			return nil, fmt.Errorf("%s: fallback %d names neither a model nor a baseUrl", path, i)
+		}
+		if e.APIKeyEnv != "" && e.APIKey == "" {
+			if endpoints[i].APIKey = os.Getenv(e.APIKeyEnv); endpoints[i].APIKey == "" {
+				return nil, fmt.Errorf("%s: fallback %d: %s is not set", path, i, e.APIKeyEnv)
+			}
+		}
+	}
+	return endpoints, nil
+}
+
+// Resilience configures how API calls survive rate limits and outages
+type Resilience struct {
+	MaxAttempts      int           // Attempts per endpoint, including the first
+	BaseDelay        time.Duration // First backoff, doubled per attempt
+	MaxDelay         time.Duration // Backoff cap; a longer Retry-After moves on to the next endpoint
+	MaxConcurrent    int           // API calls in flight at once (0 = unlimited)
+	BreakerThreshold int           // Consecutive failures that open an endpoint's circuit (0 = never)
+	BreakerCooldown  time.Duration // How long an open circuit rejects calls before one probe
+}
+
+// DefaultResilience returns the settings used when none are configured
+func DefaultResilience() Resilience {
+	return Resilience{
+		MaxAttempts:      3,
+		BaseDelay:        time.Second,
+		MaxDelay:         30 * time.Second,
+		MaxConcurrent:    8,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// backoff returns the wait before retry number attempt (from 1): exponential
+// with jitter, and never shorter than the server's Retry-After. It fails when
+// the server asks for a longer wait than MaxDelay.
+func (r Resilience) backoff(attempt int, retryAfter time.Duration) (time.Duration, bool) {
+	if retryAfter > r.MaxDelay {
+		return 0, false
+	}
+	d := r.BaseDelay << (attempt - 1)
+	if d > r.MaxDelay || d < r.BaseDelay {
+		d = r.MaxDelay
+	}
+	if d > 0 {
+		d = d/2 + rand.N(d/2+1)
+	}
+	return max(d, retryAfter), true
+}
+
+// Router sends API calls along a chain of endpoints: the primary, then each
+// fallback. Calls are retried with backoff, limited in concurrency, and kept
+// away from endpoints whose circuit is open. One router may be shared by
+// several pipelines so that they share rate limits and circuit state.
+type Router struct {
+	resilience Resilience
+	endpoints  []*endpoint
+	slots      chan struct{} // nil when concurrency is unlimited
+}
+
+// endpoint is an Endpoint with its circuit breaker
+type endpoint struct {
+	Endpoint
+	breaker *breaker
+}
+
+// NewRouter creates a router for the primary endpoint followed by fallbacks
+func NewRouter(fallbacks []Endpoint, resilience Resilience) *Router {
+	if resilience.MaxAttempts < 1 {
+		resilience.MaxAttempts = 1
+	}
+	r := &Router{resilience: resilience}
+	for _, e := range append([]Endpoint{{}}, fallbacks...) {
+		r.endpoints = append(r.endpoints, &endpoint{
+			Endpoint: e,
+			breaker:  &breaker{threshold: resilience.BreakerThreshold, cooldown: resilience.BreakerCooldown},
+		})
+	}
+	if resilience.MaxConcurrent > 0 {
+		r.slots = make(chan struct{}, resilience.MaxConcurrent)
+	}
+	return r
+}
+
+// reply is a successful API response and who gave it
+type reply struct {
+	Body     []byte
+	Endpoint int    // Index in the router's chain; 0 is the primary
+	Model    string // The model that answered, as the API reported it
+}
+
+// attemptFunc sends one request to an endpoint
+type attemptFunc func(ctx context.Context, e Endpoint) ([]byte, error)
+
+// do sends a request along the chain until an endpoint answers. Only
+// transient failures (rate limits, server errors, network errors) are
+// retried or passed on to the next endpoint. pin >= 0 restricts the call to
+// that endpoint, for conversations that live on one server.
+func (r *Router) do(ctx context.Context, pin int, send attemptFunc) (*reply, error) {
+	var lastErr error
+	for i, e := range r.endpoints {
+		if pin >= 0 && i != pin {
+			continue
+		}
+		body, err := r.try(ctx, e, send)
+		if err == nil {
+			return &reply{Body: body, Endpoint: i}, nil
+		}
+		if !transient(err) || ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+		if i < len(r.endpoints)-1 && pin < 0 {
+			log.Printf("Warning: LLM endpoint %s unavailable, trying the next fallback: %v", e.label(), err)
+		}
+	}
+	return nil, fmt.Errorf("%w: %w", ErrUnavailable, lastErr)
+}
+
+// try sends a request to one endpoint, retrying transient failures
+func (r *Router) try(ctx context.Context, e *endpoint, send attemptFunc) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		allowed, probe := e.breaker.allow()
+		if !allowed {
+			return nil, &circuitOpenError{endpoint: e.label()}
+		}
+
+		body, sent, err := r.send(ctx, e, send)
+		if !sent || (transient(err) && ctx.Err() != nil) {
+			// The request never left, or the caller gave up on it: neither
+			// says anything about the endpoint
+			if probe {
+				e.breaker.release()
+			}
+			return nil, err
+		}
+		if err == nil || !transient(err) {
+			e.breaker.success() // The endpoint answered, even if to refuse the request
+			return body, err
+		}
+		e.breaker.failure()
+
+		if attempt >= r.resilience.MaxAttempts {
+			return nil, err
+		}
+		var statusErr *StatusError
+		var retryAfter time.Duration
+		if errors.As(err, &statusErr) {
+			retryAfter = statusErr.RetryAfter
+		}
+		delay, ok := r.resilience.backoff(attempt, retryAfter)
+		if !ok {
+			return nil, fmt.Errorf("retry after %s exceeds the maximum delay: %w", retryAfter, err)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return nil, err
+		}
+		log.Printf("LLM call to %s failed (attempt %d/%d), retrying in %s: %v", e.label(), attempt, r.resilience.MaxAttempts, delay.Round(time.Millisecond), err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// send makes one attempt once a concurrency slot is free. sent is false when
+// ctx ended before a slot was.
+func (r *Router) send(ctx context.Context, e *endpoint, send attemptFunc) (body []byte, sent bool, err error) {
+	if r.slots != nil {
+		select {
+		case r.slots <- struct{}{}:
+			defer func() { <-r.slots }()
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+	body, err = send(ctx, e.Endpoint)
+	return body, true, err
+}
+
+// label names an endpoint in logs
+func (e *endpoint) label() string {
+	switch {
+	case e.Name != "":
+		return e.Name
+	case e.Model != "":
+		return e.Model
+	case e.BaseURL != "":
+		return e.BaseURL
+	}
+	return "primary"
+}
+
+// StatusError is a non-200 API response
+type StatusError struct {
+	Status     int
+	RetryAfter time.Duration // From the Retry-After or retry-after-ms header
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API returned status %d: %s", e.Status, e.Body)
+}
+
+// newStatusError reads a failed response's status and retry hint
+func newStatusError(resp *http.Response, body []byte) *StatusError {
+	return &StatusError{Status: resp.StatusCode, RetryAfter: retryAfter(resp.Header, time.Now()), Body: string(body)}
+}
+
+// retryAfter parses the wait a server asked for: OpenAI's retry-after-ms, or
+// Retry-After in seconds or as an HTTP date
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	if ms, err := strconv.ParseFloat(h.Get("Retry-After-Ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// circuitOpenError is returned for calls to an endpoint whose circuit is open
+type circuitOpenError struct {
+	endpoint string
+}
+
+func (e *circuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open for %s", e.endpoint)
+}
+
+// transient reports whether a failure may go away on retry or on another
+// endpoint. Rejected requests (400, 401, ...) would fail anywhere.
+func transient(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.Status {
+		case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+			return true
+		}
+		return statusErr.Status >= 500
+	}
+	var circuitErr *circuitOpenError
+	var netErr *requestError
+	return errors.As(err, &circuitErr) || errors.As(err, &netErr)
+}
+
+// requestError is a request that got no response
+type requestError struct {
+	err error
+}
+
+func (e *requestError) Error() string { return "request failed: " + e.err.Error() }
+func (e *requestError) Unwrap() error { return e.err }
+
+// breaker is a circuit breaker: after threshold consecutive failures it
+// rejects calls for cooldown, then lets one probe through. The probe's
+// outcome closes or reopens the circuit.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow reports whether a call may go ahead, and whether it is the probe of
+// an open circuit. A probe must end in success, failure or release.
+func (b *breaker) allow() (allowed, probe bool) {
+	if b.threshold <= 0 {
+		return true, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true, false
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return false, false
+	}
+	b.probing = true
+	return true, true
+}
+
+// release ends a probe without an outcome, letting the next call probe
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// success closes the circuit
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures, b.probing = 0, false
+}
+
+// failure counts a failed call, opening the circuit at the threshold
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,177 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestRetryAfter tests reading the server's retry hint
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 29, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header http.Header
+		want   time.Duration
+	}{
+		{http.Header{"Retry-After": {"7"}}, 7 * time.Second},
+		{http.Header{"Retry-After-Ms": {"250"}, "Retry-After": {"1"}}, 250 * time.Millisecond},
+		{http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}, time.Minute},
+		{http.Header{"Retry-After": {now.Add(-time.Minute).Format(http.TimeFormat)}}, 0},
+		{http.Header{"Retry-After": {"soon"}}, 0},
+		{http.Header{}, 0},
+	}
+	for _, tt := range tests {
+		if got := retryAfter(tt.header, now); got != tt.want {
+			t.Errorf("%v: expected %s, got %s", tt.header, tt.want, got)
+		}
+	}
+}
+
+// TestBackoff tests that backoff grows, is capped, and honors Retry-After
+func TestBackoff(t *testing.T) {
+	r := Resilience{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, ceiling := range map[int]time.Duration{1: 100 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+		d, ok := r.backoff(attempt, 0)
+		if !ok || d < ceiling/2 || d > ceiling {
+			t.Errorf("attempt %d: expected %s-%s, got %s", attempt, ceiling/2, ceiling, d)
+		}
+	}
+	if d, ok := r.backoff(1, 800*time.Millisecond); !ok || d != 800*time.Millisecond {
+		t.Errorf("expected Retry-After to set the wait, got %s", d)
+	}
+	if _, ok := r.backoff(1, 2*time.Second); ok {
+		t.Error("expected a Retry-After beyond MaxDelay to give up")
+	}
+}
+
+// TestBreaker tests opening, probing and closing a circuit
+func TestBreaker(t *testing.T) {
+	b := &breaker{threshold: 2, cooldown: 20 * time.Millisecond}
+	allow := func() bool {
+		allowed, _ := b.allow()
+		return allowed
+	}
+	b.failure()
+	if !allow() {
+		t.Fatal("expected the circuit to stay closed below the threshold")
+	}
+	b.failure()
+	if allow() {
+		t.Fatal("expected the circuit to open at the threshold")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if allowed, probe := b.allow(); !allowed || !probe || allow() {
+		t.Fatal("expected exactly one probe after the cooldown")
+	}
+	b.release()
+	if allowed, probe := b.allow(); !allowed || !probe {
+		t.Fatal("expected a released probe to let the next call probe")
+	}
+	b.failure()
+	if allow() {
+		t.Fatal("expected a failed probe to reopen the circuit")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	b.allow()
+	b.success()
+	if !allow() || !allow() {
+		t.Fatal("expected a successful probe to close the circuit")
+	}
+}
+
+// TestRouter tests retries, fallbacks and which failures end a call
+func TestRouter(t *testing.T) {
+	r := NewRouter([]Endpoint{{Model: "fallback"}}, Resilience{MaxAttempts: 2, MaxDelay: time.Second, BreakerThreshold: 3, BreakerCooldown: time.Minute})
+
+	var asked []string
+	reply, err := r.do(context.Background(), -1, func(_ context.Context, e Endpoint) ([]byte, error) {
+		asked = append(asked, e.Model)
+		if e.Model == "" {
+			return nil, &StatusError{Status: http.StatusServiceUnavailable}
+		}
+		return []byte("ok"), nil
+	})
+	if err != nil || reply.Endpoint != 1 || len(asked) != 3 {
+		t.Fatalf("expected 2 primary attempts then the fallback, got %v %+v %v", asked, reply, err)
+	}
+
+	// The primary's circuit opens after one more failure and is skipped
+	asked = nil
+	r.do(context.Background(), -1, func(_ context.Context, e Endpoint) ([]byte, error) {
+		asked = append(asked, e.Model)
+		return nil, &StatusError{Status: http.StatusTooManyRequests}
+	})
+	asked = nil
+	r.do(context.Background(), -1, func(_ context.Context, e Endpoint) ([]byte, error) {
+		asked = append(asked, e.Model)
+		return []byte("ok"), nil
+	})
+	if len(asked) != 1 || asked[0] != "fallback" {
+		t.Errorf("expected only the fallback to be asked, got %v", asked)
+	}
+
+	// Rejected requests are neither retried nor passed on
+	asked = nil
+	_, err = r.do(context.Background(), 1, func(_ context.Context, e Endpoint) ([]byte, error) {
+		asked = append(asked, e.Model)
+		return nil, &StatusError{Status: http.StatusBadRequest}
+	})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || errors.Is(err, ErrUnavailable) || len(asked) != 1 {
+		t.Errorf("expected one rejected attempt, got %v after %v", err, asked)
+	}
+
+	// Every endpoint down
+	_, err = NewRouter(nil, Resilience{MaxAttempts: 1}).do(context.Background(), -1, func(context.Context, Endpoint) ([]byte, error) {
+		return nil, &requestError{err: errors.New("connection refused")}
+	})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+}
+
+// TestRouterCancelled tests that calls the caller gave up on leave the
+// circuit as it was
+func TestRouterCancelled(t *testing.T) {
+	unavailable := func(context.Context, Endpoint) ([]byte, error) {
+		return nil, &StatusError{Status: http.StatusServiceUnavailable}
+	}
+
+	// A probe cancelled mid-request does not keep later calls from probing
+	r := NewRouter(nil, Resilience{MaxAttempts: 1, BreakerThreshold: 1, BreakerCooldown: 10 * time.Millisecond})
+	r.do(context.Background(), -1, unavailable)
+	time.Sleep(20 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	r.do(ctx, -1, func(context.Context, Endpoint) ([]byte, error) {
+		cancel()
+		return nil, &requestError{err: context.Canceled}
+	})
+	var asked bool
+	if _, err := r.do(context.Background(), -1, func(context.Context, Endpoint) ([]byte, error) {
+		asked = true
+		return []byte("ok"), nil
+	}); err != nil || !asked {
+		t.Fatalf("expected the next call to probe the endpoint, got %v", err)
+	}
+
+	// A call cancelled while waiting for a slot was never sent, so it does
+	// not reset the failure count
+	r = NewRouter(nil, Resilience{MaxAttempts: 1, MaxConcurrent: 1, BreakerThreshold: 2, BreakerCooldown: time.Minute})
+	r.do(context.Background(), -1, unavailable)
+	r.slots <- struct{}{}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.do(ctx, -1, unavailable); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the slot wait to be cancelled, got %v", err)
+	}
+	<-r.slots
+	r.do(context.Background(), -1, unavailable)
+	var circuitErr *circuitOpenError
+	if _, err := r.do(context.Background(), -1, unavailable); !errors.As(err, &circuitErr) {
+		t.Errorf("expected the second failure to open the circuit, got %v", err)
+	}
+}
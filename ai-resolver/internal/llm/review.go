@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/audit"
+)
+
+// QuestionDraft is a market question before creation
+type QuestionDraft struct {
+	Question    string   `json:"question"`
+	Description string   `json:"description,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Outcomes    []string `json:"outcomes,omitempty"` // Empty for Yes/No
+	CloseTime   int64    `json:"closeTime,omitempty"`
+	Sources     []string `json:"sources,omitempty"`
+}
+
+// CloseTimeUTC formats the close time for prompts
+func (d QuestionDraft) CloseTimeUTC() string {
+	if d.CloseTime <= 0 {
+		return "not set"
+	}
+	return time.Unix(d.CloseTime, 0).UTC().Format(time.RFC3339)
+}
+
+// QuestionReview is the model's review of a draft
+type QuestionReview struct {
+	Issues             []ReviewIssue   `json:"issues"`
+	NormalizedQuestion string          `json:"normalizedQuestion"`
+	Criteria           string          `json:"criteria"`
+	Sources            []string        `json:"sources"`
+	Outcomes           []ReviewOutcome `json:"outcomes"`
+}
+
+// ReviewIssue is one problem the model found
+type ReviewIssue struct {
+	Code       string `json:"code"`
+	Severity   string `json:"severity"` // "error" or "warning"
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+}
+
+// ReviewOutcome is an outcome with the condition under which it wins
+type ReviewOutcome struct {
+	Label     string `json:"label"`
+	Condition string `json:"condition"`
+}
+
+// questionReviewSchema is the output of the review_question step
+var questionReviewSchema = OutputSchema{
+	Name: "question_review",
+	Schema: object(map[string]any{
+		"issues": arrayOf(object(map[string]any{
+			"code":       typed("string"),
+			"severity":   map[string]any{"type": "string", "enum": []any{"error", "warning"}},
+			"message":    typed("string"),
+			"suggestion": typed("string"),
+		})),
+		"normalizedQuestion": typed("string"),
+		"criteria":           typed("string"),
+		"sources":            arrayOf(typed("string")),
+		"outcomes": arrayOf(object(map[string]any{
+			"label":     typed("string"),
+			"condition": typed("string"),
+		})),
+	}),
+}
+
+// ReviewQuestion asks the model for ambiguities in a draft question and a
+// normalized rewrite of it
+func (p *OpenAIPipeline) ReviewQuestion(ctx context.Context, draft QuestionDraft) (*QuestionReview, error) {
+	ctx = audit.WithStep(ctx, string(StepReviewQuestion))
+	prompt, err := p.prompts.Load().render(StepReviewQuestion, draft.Category, promptData{Draft: draft})
+	if err != nil {
+		return nil, err
+	}
+
+	response, _, err := p.callOpenAIChat(ctx, prompt, 0.2, questionReviewSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	var review QuestionReview
+	if err := p.decodeOutput(ctx, response, questionReviewSchema, &review); err != nil {
+		return nil, err
+	}
+
+	want := len(draft.Outcomes)
+	if want == 0 {
+		want = 2
+	}
+	if len(review.Outcomes) != 0 && len(review.Outcomes) != want {
+		return nil, fmt.Errorf("review returned %d outcomes, expected %d", len(review.Outcomes), want)
+	}
+	return &review, nil
+}
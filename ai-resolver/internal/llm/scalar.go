@@ -0,0 +1,101 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/project-gamma/ai-resolver/internal/scalar"
+)
+
+// Reading is the value a scalar market resolves on, as read from the sources,
+// and the bracket it fell in
+type Reading struct {
+	Value      string `json:"value"`   // As reported, e.g. "3.04"
+	Rounded    string `json:"rounded"` // As mapped, after the spec's rounding
+	Unit       string `json:"unit"`
+	Source     string `json:"source"`
+	ObservedAt int64  `json:"observedAt"` // Unix seconds, UTC
+	Bracket    string `json:"bracket"`    // The range that holds the value, e.g. "[2.5, 3)"
+	Label      string `json:"label,omitempty"`
+}
+
+// decideValue resolves a scalar market: the model reads the value with its
+// unit, source and time, and the outcome is the bracket that holds it. A
+// value in the wrong unit, observed outside the window or outside every
+// bracket is not proposed; the decision is returned with the PolicyError for
+// review.
+func (p *OpenAIPipeline) decideValue(ctx context.Context, prompts *PromptSet, strategy *Strategy, promptCategory string, market MarketInfo, timeline Timeline, facts []Fact) (*Decision, error) {
+	spec := market.Scalar
+	prompt, err := prompts.render(StepExtractValue, promptCategory, promptData{Market: market, Timeline: timeline, Facts: facts})
+	if err != nil {
+		return nil, err
+	}
+
+	response, model, err := p.callOpenAIChat(ctx, prompt, 0.2, valueSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	var answer struct {
+		Value      string  `json:"value"`
+		Unit       string  `json:"unit"`
+		Source     string  `json:"source"`
+		ObservedAt string  `json:"observedAt"`
+		Confidence float64 `json:"confidence"`
+		Reasoning  string  `json:"reasoning"`
+	}
+	if err := p.decodeOutput(ctx, response, valueSchema, &answer); err != nil {
+		return nil, err
+	}
+
+	reading := &Reading{Value: answer.Value, Unit: answer.Unit, Source: answer.Source}
+	decision := &Decision{Confidence: answer.Confidence, Reasoning: answer.Reasoning, Facts: facts, Model: model, Value: reading}
+	reject := func(format string, args ...any) error {
+		return &PolicyError{Strategy: strategy.Name, Reason: fmt.Sprintf(format, args...), Decision: decision}
+	}
+
+	if !scalar.SameUnit(answer.Unit, spec.Unit) {
+		return nil, reject("value is in %q, the market resolves in %q", answer.Unit, spec.Unit)
+	}
+	if reading.ObservedAt, err = scalar.ParseObservedAt(answer.ObservedAt); err != nil {
+		return nil, reject("%v", err)
+	}
+	if timeline.EventEnd > 0 && reading.ObservedAt > timeline.EventEnd {
+		return nil, reject("value was observed at %s, after the cutoff %s", formatUTC(reading.ObservedAt), formatUTC(timeline.EventEnd))
+	}
+	if timeline.EventStart > 0 && reading.ObservedAt < timeline.EventStart {
+		return nil, reject("value was observed at %s, before the window opened at %s", formatUTC(reading.ObservedAt), formatUTC(timeline.EventStart))
+	}
+
+	value, err := scalar.ParseDecimal(answer.Value)
+	if err != nil {
+		return nil, reject("%v", err)
+	}
+	if spec.Decimals != nil {
+		value = scalar.Round(value, *spec.Decimals)
+	}
+	reading.Rounded = scalar.FormatDecimal(value)
+	bracket, err := spec.Map(reading.Rounded)
+	if err != nil {
+		return nil, reject("%v", err)
+	}
+	if bracket.Outcome >= uint64(outcomeCount(market)) {
+		return nil, fmt.Errorf("bracket %s pays outcome %d, the market has %d outcomes", bracket.String(spec.Boundary), bracket.Outcome, outcomeCount(market))
+	}
+
+	reading.Bracket, reading.Label = bracket.String(spec.Boundary), bracket.Label
+	decision.OutcomeID = bracket.Outcome
+	decision.Reasoning = fmt.Sprintf("%s was %s %s per %s as of %s, in the bracket %s of outcome %d%s. %s",
+		spec.Quantity, reading.Rounded, spec.Unit, reading.Source, formatUTC(reading.ObservedAt),
+		reading.Bracket, bracket.Outcome, labelSuffix(bracket.Label), strings.TrimSpace(answer.Reasoning))
+	return decision, nil
+}
+
+// labelSuffix renders a bracket label for reasoning, e.g. ` ("2.5% to 3%")`
+func labelSuffix(label string) string {
+	if label == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%q)", label)
+}
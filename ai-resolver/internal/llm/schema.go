@@ -0,0 +1,302 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OutputSchema is the JSON schema of one pipeline step's output. It is sent to
+// the provider as a strict structured-output format and enforced again locally,
+// since providers and stand-ins do not always honor it.
+type OutputSchema struct {
+	Name   string
+	Schema map[string]any
+}
+
+// responseFormat returns the Chat Completions response_format parameter
+func (s OutputSchema) responseFormat() map[string]any {
+	return map[string]any{
+		"type": "json_schema",
+		"json_schema": map[string]any{
+			"name":   s.Name,
+			"strict": true,
+			"schema": s.Schema,
+		},
+	}
+}
+
+// textFormat returns the Responses API text.format parameter
+func (s OutputSchema) textFormat() map[string]any {
+	return map[string]any{
+		"type":   "json_schema",
+		"name":   s.Name,
+		"strict": true,
+		"schema": s.Schema,
+	}
+}
+
+// SchemaError lists every way an output violates its schema
+type SchemaError struct {
+	Schema string
+	Errors []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("output does not match schema %s: %s", e.Schema, strings.Join(e.Errors, "; "))
+}
+
+// Decode validates text against the schema and decodes it into out. The whole
+// text must be one JSON value; nothing is extracted from surrounding prose.
+func (s OutputSchema) Decode(text string, out any) error {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return &SchemaError{Schema: s.Name, Errors: []string{fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+	if dec.More() {
+		return &SchemaError{Schema: s.Name, Errors: []string{"unexpected content after the JSON value"}}
+	}
+
+	if errs := validate("$", s.Schema, value); len(errs) > 0 {
+		return &SchemaError{Schema: s.Name, Errors: errs}
+	}
+
+	strict := json.NewDecoder(bytes.NewReader([]byte(text)))
+	strict.DisallowUnknownFields()
+	if err := strict.Decode(out); err != nil {
+		return &SchemaError{Schema: s.Name, Errors: []string{err.Error()}}
+	}
+	return nil
+}
+
+// validate checks value against the subset of JSON Schema used by the
+// pipeline: type, properties, required, additionalProperties, items, enum,
+// minimum and maximum
+func validate(path string, schema map[string]any, value any) []string {
+	if !typeMatches(schema["type"], value) {
+		return []string{fmt.Sprintf("%s: expected %v, got %s", path, schema["type"], jsonType(value))}
+	}
+
+	var errs []string
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, allowed := range enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: %v is not one of %v", path, value, enum))
+		}
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		if min, ok := schema["minimum"].(float64); ok && f < min {
+			errs = append(errs, fmt.Sprintf("%s: %v is below the minimum %v", path, v, min))
+		}
+		if max, ok := schema["maximum"].(float64); ok && f > max {
+			errs = append(errs, fmt.Sprintf("%s: %v is above the maximum %v", path, v, max))
+		}
+
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		for _, name := range stringList(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				errs = append(errs, fmt.Sprintf("%s: missing required property %q", path, name))
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			propSchema, ok := props[k].(map[string]any)
+			if !ok {
+				if schema["additionalProperties"] == false {
+					errs = append(errs, fmt.Sprintf("%s: unexpected property %q", path, k))
+				}
+				continue
+			}
+			errs = append(errs, validate(path+"."+k, propSchema, v[k])...)
+		}
+
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				errs = append(errs, validate(fmt.Sprintf("%s[%d]", path, i), items, item)...)
+			}
+		}
+	}
+
+	return errs
+}
+
+// typeMatches reports whether value has the schema type (a name or a list)
+func typeMatches(want any, value any) bool {
+	switch t := want.(type) {
+	case nil:
+		return true
+	case string:
+		return typeIs(t, value)
+	case []any:
+		for _, name := range t {
+			if s, ok := name.(string); ok && typeIs(s, value) {
+				return true
+			}
+		}
+	case []string:
+		for _, name := range t {
+			if typeIs(name, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeIs(name string, value any) bool {
+	switch name {
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return jsonType(value) == name
+	}
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func stringList(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// object builds a strict object schema: every property is required and no
+// others are allowed, as structured-output strict mode demands
+func object(props map[string]any) map[string]any {
+	required := make([]string, 0, len(props))
+	for name := range props {
+		required = append(required, name)
+	}
+	sort.Strings(required)
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func arrayOf(items map[string]any) map[string]any {
+	return map[string]any{"type": "array", "items": items}
+}
+
+func typed(name string) map[string]any {
+	return map[string]any{"type": name}
+}
+
+func bounded(name string, min, max float64) map[string]any {
+	return map[string]any{"type": name, "minimum": min, "maximum": max}
+}
+
+// Step output schemas
+var (
+	// extractFactsSchema is the output of the web search step
+	extractFactsSchema = OutputSchema{
+		Name: "extracted_facts",
+		Schema: object(map[string]any{
+			"facts": arrayOf(object(map[string]any{
+				"statement":          typed("string"),
+				"sources":            arrayOf(typed("string")),
+				"confidence":         bounded("number", 0, 1),
+				"supportingEvidence": typed("string"),
+				"publishedAt":        typed("string"),
+				"eventDate":          typed("string"),
+			})),
+			"sources": arrayOf(object(map[string]any{
+				"url":     typed("string"),
+				"title":   typed("string"),
+				"snippet": typed("string"),
+			})),
+		}),
+	}
+
+	// contradictionsSchema lists the indices of contradictory facts
+	contradictionsSchema = OutputSchema{
+		Name: "contradictions",
+		Schema: object(map[string]any{
+			"contradictions": arrayOf(object(map[string]any{
+				"index":  map[string]any{"type": "integer", "minimum": float64(0)},
+				"reason": typed("string"),
+			})),
+		}),
+	}
+
+	// decisionSchema is the output of the decision step
+	decisionSchema = OutputSchema{
+		Name: "decision",
+		Schema: object(map[string]any{
+			"outcomeId":  map[string]any{"type": "integer", "minimum": float64(0)},
+			"confidence": bounded("number", 0, 1),
+			"reasoning":  typed("string"),
+		}),
+	}
+
+	// valueSchema is the output of the value step of scalar markets
+	valueSchema = OutputSchema{
+		Name: "value",
+		Schema: object(map[string]any{
+			"value":      typed("string"),
+			"unit":       typed("string"),
+			"source":     typed("string"),
+			"observedAt": typed("string"),
+			"confidence": bounded("number", 0, 1),
+			"reasoning":  typed("string"),
+		}),
+	}
+)
@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestOutputSchemaDecode tests strict validation of step outputs
+func TestOutputSchemaDecode(t *testing.T) {
+	var decision Decision
+	if err := decisionSchema.Decode(`{"outcomeId":1,"confidence":0.8,"reasoning":"a {brace} in \"text\""}`, &decision); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decision.OutcomeID != 1 || decision.Reasoning != `a {brace} in "text"` {
+		t.Errorf("unexpected decision: %+v", decision)
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"prose around JSON", `Here it is: {"outcomeId":1,"confidence":0.8,"reasoning":"x"}`, "invalid JSON"},
+		{"trailing content", `{"outcomeId":1,"confidence":0.8,"reasoning":"x"} done`, "after the JSON value"},
+		{"missing property", `{"outcomeId":1,"confidence":0.8}`, `missing required property "reasoning"`},
+		{"extra property", `{"outcomeId":1,"confidence":0.8,"reasoning":"x","facts":[]}`, `unexpected property "facts"`},
+		{"wrong type", `{"outcomeId":"1","confidence":0.8,"reasoning":"x"}`, "$.outcomeId: expected integer"},
+		{"fractional integer", `{"outcomeId":1.5,"confidence":0.8,"reasoning":"x"}`, "$.outcomeId: expected integer"},
+		{"out of range", `{"outcomeId":1,"confidence":1.2,"reasoning":"x"}`, "above the maximum"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out Decision
+			err := decisionSchema.Decode(tt.output, &out)
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected schema error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestNestedSchemaErrors tests that errors in nested items carry their path
+func TestNestedSchemaErrors(t *testing.T) {
+	var out struct {
+		Facts   []Fact      `json:"facts"`
+		Sources []WebSource `json:"sources"`
+	}
+	err := extractFactsSchema.Decode(`{"facts":[{"statement":"s","sources":["u"],"confidence":2,"supportingEvidence":"e"}],"sources":[{"url":"u","title":"t"}]}`, &out)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"$.facts[0].confidence", `$.sources[0]: missing required property "snippet"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
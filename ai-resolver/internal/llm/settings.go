@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"fmt"
+
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+)
+
+// Settings are what a pipeline resolves markets with beyond its model and
+// tools. Analyses record them in their audit run so that a replay rebuilds
+// the same pipeline.
+type Settings struct {
+	Strategies   []Strategy         `json:"strategies"`  // Generic first
+	Prompts      map[string]string  `json:"prompts"`     // Template sources by file name
+	SourceTiers  *credibility.Model `json:"sourceTiers"` // Credibility tiers
+	Stages       *PipelineConfig    `json:"stages"`      // Stage layout
+	Challenge    bool               `json:"challenge"`
+	HostedSearch bool               `json:"hostedSearch"`
+}
+
+// settings returns the pipeline's settings with the prompt set and layout an
+// analysis started with
+func (p *OpenAIPipeline) settings(prompts *PromptSet, layout *PipelineConfig) Settings {
+	return Settings{
+		Strategies:   p.strategies.List(),
+		Prompts:      prompts.Files(),
+		SourceTiers:  p.credibility,
+		Stages:       layout,
+		Challenge:    p.challenge,
+		HostedSearch: p.hostedSearch,
+	}
+}
+
+// ApplySettings replaces the pipeline's strategies, prompts, credibility
+// tiers, stage layout and review and search options with recorded ones.
+// Settings left empty keep the pipeline's current ones.
+func (p *OpenAIPipeline) ApplySettings(s Settings) error {
+	if len(s.Strategies) > 0 {
+		strategies, err := NewStrategies(s.Strategies)
+		if err != nil {
+			return fmt.Errorf("invalid recorded strategies: %w", err)
+		}
+		p.SetStrategies(strategies)
+	}
+	if len(s.Prompts) > 0 {
+		prompts, err := PromptsFromFiles(s.Prompts)
+		if err != nil {
+			return fmt.Errorf("invalid recorded prompts: %w", err)
+		}
+		p.SetPrompts(prompts)
+	}
+	if s.SourceTiers != nil {
+		p.SetCredibility(s.SourceTiers)
+	}
+	if s.Stages != nil {
+		if err := p.SetStages(s.Stages); err != nil {
+			return fmt.Errorf("invalid recorded stage layout: %w", err)
+		}
+	}
+	p.SetChallenge(s.Challenge)
+	p.SetHostedSearch(s.HostedSearch)
+	return nil
+}
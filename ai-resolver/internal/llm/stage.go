@@ -0,0 +1,288 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+	"github.com/project-gamma/ai-resolver/internal/jsontime"
+)
+
+// Artifact names a piece of analysis state that stages read and write
+type Artifact string
+
+const (
+	ArtifactMarket         Artifact = "market" // The market being resolved; always present
+	ArtifactMetadata       Artifact = "metadata"
+	ArtifactParent         Artifact = "parent" // The parent result of a conditional market
+	ArtifactFacts          Artifact = "facts"
+	ArtifactSources        Artifact = "sources"
+	ArtifactContradictions Artifact = "contradictions"
+	ArtifactDecision       Artifact = "decision"
+	ArtifactCitations      Artifact = "citations"
+	ArtifactVerified       Artifact = "verified_citations"
+	ArtifactChallenge      Artifact = "challenge"
+)
+
+// Stage is one step of an analysis. It declares the artifacts it reads and
+// the ones it produces, so that a pipeline can be checked and ordered before
+// anything runs, and works on the shared Analysis.
+type Stage interface {
+	Name() string
+	Inputs() []Artifact
+	Outputs() []Artifact
+	Run(ctx context.Context, a *Analysis) error
+}
+
+// ErrStageSkipped is returned by a stage with nothing to do, e.g. citation
+// verification without a verifier. The analysis goes on.
+var ErrStageSkipped = errors.New("stage skipped")
+
+// ErrResolved is returned by a stage that settled the decision itself, e.g.
+// a conditional market whose condition failed. The remaining stages do not run.
+var ErrResolved = errors.New("analysis resolved")
+
+// Analysis is the state the stages of one market's analysis share
+type Analysis struct {
+	Market    MarketInfo
+	Strategy  *Strategy
+	Facts     []Fact
+	Sources   []WebSource
+	Decision  *Decision
+	Artifacts map[Artifact]any // Outputs of custom stages, e.g. the market metadata
+
+	prompts     *PromptSet
+	sourceModel *credibility.Model
+	now         time.Time
+	trace       []StageTrace
+}
+
+// Timeline returns the time context of the market as it stands; a stage may
+// have refined the event window
+func (a *Analysis) Timeline() Timeline {
+	return timelineFor(a.Market, a.now)
+}
+
+// promptCategory returns the prompt overrides the market uses
+func (a *Analysis) promptCategory() string {
+	return a.Strategy.promptCategory(a.Market)
+}
+
+// StageTrace records how one stage of an analysis ran
+type StageTrace struct {
+	Stage      string    `json:"stage"`
+	Status     string    `json:"status"` // completed, skipped, failed or timed_out
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// StageConfig places a stage in a pipeline layout
+type StageConfig struct {
+	Name    string            `json:"name"`
+	After   []string          `json:"after,omitempty"`   // Stages that must run first, beyond the data they provide
+	Timeout jsontime.Duration `json:"timeout,omitempty"` // Zero: only the analysis's own deadline applies
+}
+
+// PipelineConfig is the stage layout of analyses: Stages by default, or the
+// layout listed under a strategy's name
+type PipelineConfig struct {
+	Stages     []StageConfig            `json:"stages"`
+	Strategies map[string][]StageConfig `json:"strategies,omitempty"`
+}
+
+// DefaultPipelineConfig returns the built-in layout: search, contradiction
+// check, decision, citations, verification, temporal grounding, adversarial
+// review and the confidence policy, in that order
+func DefaultPipelineConfig() *PipelineConfig {
+	var stages []StageConfig
+	for _, name := range []string{
+		StageExtractFacts, StageCheckContradictions, StageDecideOutcome, StageBuildCitations,
+		StageVerifyCitations, StageCheckGrounded, StageAdversarialReview, StagePolicyGate,
+	} {
+		stages = append(stages, StageConfig{Name: name})
+	}
+	return &PipelineConfig{Stages: stages}
+}
+
+// LoadPipelineConfig reads a stage layout from a JSON file
+func LoadPipelineConfig(path string) (*PipelineConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline config: %w", err)
+	}
+	var cfg PipelineConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline config %s: %w", path, err)
+	}
+	if len(cfg.Stages) == 0 {
+		return nil, fmt.Errorf("%s: no stages", path)
+	}
+	return &cfg, nil
+}
+
+// Without returns a copy of the layout with the named stages left out, also
+// from the After lists of the others
+func (c *PipelineConfig) Without(names ...string) *PipelineConfig {
+	drop := func(layout []StageConfig) []StageConfig {
+		var kept []StageConfig
+		for _, sc := range layout {
+			if slices.Contains(names, sc.Name) {
+				continue
+			}
+			sc.After = slices.DeleteFunc(slices.Clone(sc.After), func(name string) bool { return slices.Contains(names, name) })
+			kept = append(kept, sc)
+		}
+		return kept
+	}
+
+	out := &PipelineConfig{Stages: drop(c.Stages)}
+	if c.Strategies != nil {
+		out.Strategies = make(map[string][]StageConfig, len(c.Strategies))
+		for strategy, layout := range c.Strategies {
+			out.Strategies[strategy] = drop(layout)
+		}
+	}
+	return out
+}
+
+// plannedStage is a stage with its place in a checked layout
+type plannedStage struct {
+	stage   Stage
+	timeout time.Duration
+}
+
+// plan orders a layout's stages so that every stage runs after the stages
+// it names in After and after the producers of its inputs. Among stages free
+// to run, the one listed first goes first, so a valid layout runs as listed.
+func plan(layout []StageConfig, stages map[string]Stage) ([]plannedStage, error) {
+	index := make(map[string]int, len(layout))
+	producer := make(map[Artifact]int)
+	for i, sc := range layout {
+		stage, ok := stages[sc.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown stage %q", sc.Name)
+		}
+		if _, dup := index[sc.Name]; dup {
+			return nil, fmt.Errorf("stage %s is listed twice", sc.Name)
+		}
+		index[sc.Name] = i
+		for _, out := range stage.Outputs() {
+			if other, taken := producer[out]; taken {
+				return nil, fmt.Errorf("stages %s and %s both produce %s", layout[other].Name, sc.Name, out)
+			}
+			producer[out] = i
+		}
+	}
+
+	// Edges from each stage to the stages that must follow it
+	next := make([][]int, len(layout))
+	pending := make([]int, len(layout))
+	for i, sc := range layout {
+		var before []int
+		for _, name := range sc.After {
+			j, ok := index[name]
+			if !ok {
+				return nil, fmt.Errorf("stage %s runs after %s, which is not in the pipeline", sc.Name, name)
+			}
+			before = append(before, j)
+		}
+		for _, in := range stages[sc.Name].Inputs() {
+			if in == ArtifactMarket {
+				continue
+			}
+			j, ok := producer[in]
+			if !ok {
+				return nil, fmt.Errorf("stage %s needs %s, which no stage produces", sc.Name, in)
+			}
+			before = append(before, j)
+		}
+		for _, j := range before {
+			if j == i {
+				return nil, fmt.Errorf("stage %s depends on itself", sc.Name)
+			}
+			if !slices.Contains(next[j], i) {
+				next[j] = append(next[j], i)
+				pending[i]++
+			}
+		}
+	}
+
+	// Run stages as listed unless a dependency holds them back; done stages
+	// are marked -1
+	ordered := make([]plannedStage, 0, len(layout))
+	for len(ordered) < len(layout) {
+		ready := slices.Index(pending, 0)
+		if ready < 0 {
+			var stuck []string
+			for i, sc := range layout {
+				if pending[i] > 0 {
+					stuck = append(stuck, sc.Name)
+				}
+			}
+			return nil, fmt.Errorf("stages %v depend on each other", stuck)
+		}
+		pending[ready] = -1
+		for _, j := range next[ready] {
+			pending[j]--
+		}
+		ordered = append(ordered, plannedStage{stage: stages[layout[ready].Name], timeout: time.Duration(layout[ready].Timeout)})
+	}
+	return ordered, nil
+}
+
+// runStages runs planned stages in order, each under its own timeout, and
+// records a trace of every stage that ran
+func runStages(ctx context.Context, a *Analysis, stages []plannedStage) error {
+	for _, ps := range stages {
+		name := ps.stage.Name()
+		stageCtx, cancel := ctx, context.CancelFunc(func() {})
+		if ps.timeout > 0 {
+			stageCtx, cancel = context.WithTimeout(ctx, ps.timeout)
+		}
+
+		trace := StageTrace{Stage: name, Status: "completed", StartedAt: time.Now().UTC()}
+		err := ps.stage.Run(audit.WithStep(stageCtx, name), a)
+		trace.DurationMs = time.Since(trace.StartedAt).Milliseconds()
+		timedOut := stageCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+		cancel()
+
+		resolved := errors.Is(err, ErrResolved) && a.Decision != nil
+		switch {
+		case errors.Is(err, ErrStageSkipped):
+			trace.Status = "skipped"
+			err = nil
+		case resolved:
+			err = nil
+		case err != nil && timedOut:
+			trace.Status = "timed_out"
+			err = fmt.Errorf("stage %s timed out after %s: %w", name, ps.timeout, err)
+		case err != nil:
+			trace.Status = "failed"
+		}
+		if err != nil {
+			trace.Error = err.Error()
+		}
+		a.trace = append(a.trace, trace)
+		log.Printf("Stage %s %s in %dms", name, trace.Status, trace.DurationMs)
+
+		if a.Decision != nil {
+			a.Decision.Trace = a.trace
+		}
+		if err != nil {
+			return err
+		}
+		if resolved {
+			log.Printf("Stage %s resolved the market, skipping the rest", name)
+			return nil
+		}
+	}
+	return nil
+}
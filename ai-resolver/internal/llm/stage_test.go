@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testStage is a stage with fixed artifacts and behavior
+func testStage(name string, inputs, outputs []Artifact, run func(ctx context.Context, a *Analysis) error) *stageFunc {
+	if run == nil {
+		run = func(context.Context, *Analysis) error { return nil }
+	}
+	return &stageFunc{name, inputs, outputs, run}
+}
+
+// TestPlan tests ordering layouts by their data and After, and rejecting
+// layouts that cannot run
+func TestPlan(t *testing.T) {
+	stages := map[string]Stage{
+		"a": testStage("a", []Artifact{ArtifactMarket}, []Artifact{ArtifactFacts}, nil),
+		"b": testStage("b", []Artifact{ArtifactFacts}, []Artifact{ArtifactDecision}, nil),
+		"c": testStage("c", []Artifact{ArtifactDecision}, nil, nil),
+		"d": testStage("d", nil, []Artifact{ArtifactFacts}, nil),
+		"e": testStage("e", []Artifact{ArtifactChallenge}, nil, nil),
+	}
+	names := func(planned []plannedStage) string {
+		var out []string
+		for _, ps := range planned {
+			out = append(out, ps.stage.Name())
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		layout []StageConfig
+		want   string
+	}{
+		{[]StageConfig{{Name: "a"}, {Name: "b"}, {Name: "c"}}, "a,b,c"},
+		{[]StageConfig{{Name: "c"}, {Name: "b"}, {Name: "a"}}, "a,b,c"},
+		{[]StageConfig{{Name: "c", After: []string{"a"}}, {Name: "a"}, {Name: "b"}}, "a,b,c"},
+		{[]StageConfig{{Name: "a"}, {Name: "x"}}, `unknown stage "x"`},
+		{[]StageConfig{{Name: "a"}, {Name: "a"}}, "listed twice"},
+		{[]StageConfig{{Name: "a"}, {Name: "d"}}, "both produce facts"},
+		{[]StageConfig{{Name: "a"}, {Name: "c", After: []string{"b"}}}, "not in the pipeline"},
+		{[]StageConfig{{Name: "e"}}, "needs challenge, which no stage produces"},
+		{[]StageConfig{{Name: "a", After: []string{"c"}}, {Name: "b"}, {Name: "c"}}, "depend on each other"},
+		{[]StageConfig{{Name: "a", After: []string{"a"}}}, "depends on itself"},
+	}
+	for _, tt := range tests {
+		planned, err := plan(tt.layout, stages)
+		got := names(planned)
+		if err != nil {
+			got = err.Error()
+		}
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("%+v: expected %q, got %q", tt.layout, tt.want, got)
+		}
+	}
+
+	// The built-in layout runs as listed
+	p := NewOpenAIPipeline("key", "model")
+	planned, err := plan(DefaultPipelineConfig().Stages, p.stages)
+	if err != nil || names(planned) != "extract_facts,check_contradictions,decide_outcome,build_citations,verify_citations,check_grounded,adversarial_review,policy_gate" {
+		t.Errorf("unexpected built-in plan %s: %v", names(planned), err)
+	}
+}
+
+// TestRunStages tests skips, timeouts and the trace
+func TestRunStages(t *testing.T) {
+	slow := testStage("slow", nil, nil, func(ctx context.Context, _ *Analysis) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	skip := testStage("skip", nil, nil, func(context.Context, *Analysis) error { return ErrStageSkipped })
+	decide := testStage("decide", nil, nil, func(_ context.Context, a *Analysis) error {
+		a.Decision = &Decision{}
+		return nil
+	})
+
+	a := &Analysis{}
+	err := runStages(context.Background(), a, []plannedStage{{stage: decide}, {stage: skip}, {stage: slow, timeout: 10 * time.Millisecond}, {stage: decide}})
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "stage slow timed out after 10ms") {
+		t.Fatalf("expected the slow stage to time out, got %v", err)
+	}
+	var statuses []string
+	for _, trace := range a.Decision.Trace {
+		statuses = append(statuses, trace.Stage+":"+trace.Status)
+	}
+	if got := strings.Join(statuses, ","); got != "decide:completed,skip:skipped,slow:timed_out" {
+		t.Errorf("unexpected trace %s", got)
+	}
+
+	// A stage that settles the decision ends the analysis
+	settle := testStage("settle", nil, nil, func(_ context.Context, a *Analysis) error {
+		a.Decision = &Decision{OutcomeID: 2}
+		return ErrResolved
+	})
+	a = &Analysis{}
+	if err := runStages(context.Background(), a, []plannedStage{{stage: settle}, {stage: decide}}); err != nil || a.Decision.OutcomeID != 2 || len(a.Decision.Trace) != 1 {
+		t.Errorf("expected the analysis to end at the settling stage, got %+v: %v", a.Decision, err)
+	}
+}
+
+// TestLoadPipelineConfig tests reading layouts and their timeouts
+func TestLoadPipelineConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pipeline.json")
+	os.WriteFile(path, []byte(`{"stages": [{"name": "extract_facts", "timeout": "45s"}, {"name": "decide_outcome"}],
+		"strategies": {"sports": [{"name": "decide_outcome", "after": ["extract_facts"]}]}}`), 0o644)
+	cfg, err := LoadPipelineConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Duration(cfg.Stages[0].Timeout) != 45*time.Second || cfg.Strategies["sports"][0].After[0] != "extract_facts" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+
+	os.WriteFile(path, []byte(`{"stages": [{"name": "extract_facts", "timeout": "soon"}]}`), 0o644)
+	if _, err := LoadPipelineConfig(path); err == nil {
+		t.Error("expected an invalid timeout to fail")
+	}
+}
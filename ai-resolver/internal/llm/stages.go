@@ -0,0 +1,154 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/progress"
+)
+
+// Built-in stage names, for pipeline layouts
+const (
+	StageExtractFacts        = "extract_facts"
+	StageCheckContradictions = "check_contradictions"
+	StageDecideOutcome       = "decide_outcome"
+	StageBuildCitations      = "build_citations"
+	StageVerifyCitations     = "verify_citations"   // Skipped without a verifier
+	StageCheckGrounded       = "check_grounded"     // Rejects decisions resting on previews
+	StageAdversarialReview   = "adversarial_review" // Skipped unless SetChallenge(true)
+	StagePolicyGate          = "policy_gate"        // The strategy's confidence policy
+)
+
+// stageFunc is a stage made of a function
+type stageFunc struct {
+	name    string
+	inputs  []Artifact
+	outputs []Artifact
+	run     func(ctx context.Context, a *Analysis) error
+}
+
+func (s *stageFunc) Name() string                               { return s.name }
+func (s *stageFunc) Inputs() []Artifact                         { return s.inputs }
+func (s *stageFunc) Outputs() []Artifact                        { return s.outputs }
+func (s *stageFunc) Run(ctx context.Context, a *Analysis) error { return s.run(ctx, a) }
+
+// builtinStages returns the pipeline's built-in stages by name
+func (p *OpenAIPipeline) builtinStages() map[string]Stage {
+	stages := []*stageFunc{
+		{StageExtractFacts, []Artifact{ArtifactMarket}, []Artifact{ArtifactFacts, ArtifactSources}, p.runExtractFacts},
+		{StageCheckContradictions, []Artifact{ArtifactFacts}, []Artifact{ArtifactContradictions}, p.runCheckContradictions},
+		{StageDecideOutcome, []Artifact{ArtifactFacts}, []Artifact{ArtifactDecision}, p.runDecideOutcome},
+		{StageBuildCitations, []Artifact{ArtifactDecision, ArtifactSources}, []Artifact{ArtifactCitations}, p.runBuildCitations},
+		{StageVerifyCitations, []Artifact{ArtifactCitations}, []Artifact{ArtifactVerified}, p.runVerifyCitations},
+		{StageCheckGrounded, []Artifact{ArtifactDecision}, nil, runCheckGrounded},
+		{StageAdversarialReview, []Artifact{ArtifactDecision}, []Artifact{ArtifactChallenge}, p.runAdversarialReview},
+		{StagePolicyGate, []Artifact{ArtifactDecision, ArtifactCitations}, nil, runPolicyGate},
+	}
+	byName := make(map[string]Stage, len(stages))
+	for _, s := range stages {
+		byName[s.name] = s
+	}
+	return byName
+}
+
+// runExtractFacts searches the web and extracts facts. No source can report
+// the outcome before the event window ends, so that is checked first.
+func (p *OpenAIPipeline) runExtractFacts(ctx context.Context, a *Analysis) error {
+	timeline := a.Timeline()
+	if err := timeline.checkEnded(); err != nil {
+		return err
+	}
+
+	searchQuery := p.buildSearchQuery(a.Market)
+	progress.Step(ctx, string(StepExtractFacts), progress.StatusStarted, map[string]any{"query": searchQuery, "strategy": a.Strategy.Name})
+	facts, webSources, err := p.searchAndExtractFacts(ctx, a.prompts, a.Strategy, a.Market, timeline, searchQuery)
+	if err != nil {
+		reportStep(ctx, StepExtractFacts, err, nil)
+		return fmt.Errorf("failed to search and extract facts: %w", err)
+	}
+	facts, webSources = a.Strategy.filterSources(a.sourceModel, facts, webSources)
+	a.Facts = timeline.flagPremature(facts)
+	a.Sources = webSources
+	reportStep(ctx, StepExtractFacts, nil, map[string]any{"facts": a.Facts, "sources": len(webSources), "unannotated": unannotated(webSources)})
+	return nil
+}
+
+// runCheckContradictions flags facts that contradict each other
+func (p *OpenAIPipeline) runCheckContradictions(ctx context.Context, a *Analysis) error {
+	progress.Step(ctx, string(StepCheckContradictions), progress.StatusStarted, nil)
+	facts, err := p.checkContradictions(ctx, a.prompts, a.promptCategory(), a.Market, a.Timeline(), a.Facts)
+	if err != nil {
+		reportStep(ctx, StepCheckContradictions, err, nil)
+		return fmt.Errorf("failed to check contradictions: %w", err)
+	}
+	a.Facts = facts
+	reportStep(ctx, StepCheckContradictions, nil, map[string]any{"contradictions": contradicting(facts)})
+	return nil
+}
+
+// runDecideOutcome decides the outcome from the facts. Scalar markets read
+// the value instead and map it onto their brackets.
+func (p *OpenAIPipeline) runDecideOutcome(ctx context.Context, a *Analysis) error {
+	progress.Step(ctx, string(StepDecideOutcome), progress.StatusStarted, nil)
+	var decision *Decision
+	var err error
+	if a.Market.Scalar != nil {
+		decision, err = p.decideValue(ctx, a.prompts, a.Strategy, a.promptCategory(), a.Market, a.Timeline(), a.Facts)
+	} else {
+		decision, err = p.decideOutcome(ctx, a.prompts, a.promptCategory(), a.Market, a.Timeline(), a.Facts)
+	}
+	if err != nil {
+		reportStep(ctx, StepDecideOutcome, err, nil)
+		return fmt.Errorf("failed to decide outcome: %w", err)
+	}
+	reportStep(ctx, StepDecideOutcome, nil, map[string]any{
+		"outcomeId":  decision.OutcomeID,
+		"confidence": decision.Confidence,
+		"reasoning":  decision.Reasoning,
+		"value":      decision.Value,
+	})
+
+	decision.Timestamp = time.Now().Unix()
+	decision.Strategy = a.Strategy.Name
+	info := a.prompts.Info(a.promptCategory())
+	decision.Prompt = &info
+	a.Decision = decision
+	return nil
+}
+
+// runBuildCitations cites the sources the facts rest on
+func (p *OpenAIPipeline) runBuildCitations(ctx context.Context, a *Analysis) error {
+	a.Decision.Citations = buildCitationsFromSources(a.sourceModel, a.Sources, a.Decision.Facts)
+	reportStep(ctx, StepBuildCitations, nil, map[string]any{"citations": a.Decision.Citations})
+	return nil
+}
+
+// runVerifyCitations drops citations their pages do not confirm
+func (p *OpenAIPipeline) runVerifyCitations(ctx context.Context, a *Analysis) error {
+	if p.verifier == nil {
+		return ErrStageSkipped
+	}
+	return p.verifyCitations(ctx, a.Strategy, a.sourceModel, a.Decision)
+}
+
+// runCheckGrounded rejects decisions grounded only in sources from before
+// the event
+func runCheckGrounded(_ context.Context, a *Analysis) error {
+	return a.Timeline().checkGrounded(a.Strategy.Name, a.Decision)
+}
+
+// runAdversarialReview challenges the decision; the judge may lower its
+// confidence or escalate it
+func (p *OpenAIPipeline) runAdversarialReview(ctx context.Context, a *Analysis) error {
+	if !p.challenge {
+		return ErrStageSkipped
+	}
+	return p.runChallenge(ctx, a.prompts, a.Strategy, a.promptCategory(), a.Market, a.Timeline(), a.Decision)
+}
+
+// runPolicyGate applies the strategy's confidence policy, which has the last
+// word on whether the decision is proposed
+func runPolicyGate(_ context.Context, a *Analysis) error {
+	return a.Strategy.Confidence.apply(a.Strategy.Name, a.Decision)
+}
@@ -0,0 +1,372 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+)
+
+// GenericStrategy is the name of the fallback strategy for unknown categories
+const GenericStrategy = "generic"
+
+// Strategy configures how markets of a category are resolved
+type Strategy struct {
+	Name       string   `json:"name"`
+	Categories []string `json:"categories"` // Market categories routed here, besides Name
+	Prompts    string   `json:"prompts"`    // Prompt override directory (see PromptSet); empty uses the market's category
+
+	// Tools lists the registry tools the model may call. Nil allows every
+	// tool; an empty list allows none. Hosted web search is always available.
+	Tools []string `json:"tools"`
+
+	// Sources is the domain allowlist for web search and citations, e.g.
+	// "reuters.com" (which also matches subdomains) or "gov". Empty allows any.
+	Sources []string `json:"sources"`
+
+	// DenySources is the domain denylist; it wins over the allowlist
+	DenySources []string `json:"denySources"`
+
+	// Tiers overrides the credibility tier of domains for this strategy,
+	// e.g. a league's own site as "official"
+	Tiers map[string]credibility.Tier `json:"tiers"`
+
+	Confidence ConfidencePolicy `json:"confidence"`
+}
+
+// ConfidencePolicy decides whether a decision is strong enough to propose
+type ConfidencePolicy struct {
+	MinConfidence float64 `json:"minConfidence"` // Decisions below this are rejected
+	MinCitations  int     `json:"minCitations"`  // Independent cited sources required
+
+	// MinCredibility is the source credibility a citation needs to count
+	// toward MinCitations, e.g. 0.75 for reputable outlets and better
+	MinCredibility float64 `json:"minCredibility"`
+
+	// ContradictionPenalty scales the confidence down once per contradicting
+	// fact, e.g. 0.8 turns 0.9 with two contradictions into 0.576. Zero or one
+	// leaves the confidence as decided.
+	ContradictionPenalty float64 `json:"contradictionPenalty"`
+
+	// MinVerified is how many citations must survive verification against
+	// the cited pages, when the pipeline verifies citations
+	MinVerified int `json:"minVerified"`
+}
+
+// ErrPolicyRejected matches any *PolicyError with errors.Is
+var ErrPolicyRejected = errors.New("decision rejected by policy")
+
+// PolicyError is returned when a decision was reached but must not be
+// proposed. Decision holds it for review.
+type PolicyError struct {
+	Strategy string
+	Reason   string
+	Decision *Decision
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("decision rejected by %s policy: %s", e.Strategy, e.Reason)
+}
+
+func (e *PolicyError) Is(target error) bool {
+	return target == ErrPolicyRejected
+}
+
+// promptCategory returns the prompt overrides to use for a market
+func (s *Strategy) promptCategory(market MarketInfo) string {
+	if s.Prompts != "" {
+		return s.Prompts
+	}
+	return market.Category
+}
+
+// allowsTool reports whether the model may call a registry tool
+func (s *Strategy) allowsTool(name string) bool {
+	return s.Tools == nil || slices.Contains(s.Tools, name)
+}
+
+// allowsSource reports whether a URL's host is on the source allowlist and
+// not on the denylist
+func (s *Strategy) allowsSource(rawURL string) bool {
+	if len(s.Sources) == 0 && len(s.DenySources) == 0 {
+		return true
+	}
+	host := credibility.Host(rawURL)
+	if host == "" {
+		return false
+	}
+	if slices.ContainsFunc(s.DenySources, func(domain string) bool { return credibility.MatchesDomain(host, domain) }) {
+		return false
+	}
+	return len(s.Sources) == 0 || slices.ContainsFunc(s.Sources, func(domain string) bool { return credibility.MatchesDomain(host, domain) })
+}
+
+// credibility returns the source credibility model with the strategy's tier
+// overrides
+func (s *Strategy) credibility(base *credibility.Model) *credibility.Model {
+	return base.With(s.Tiers)
+}
+
+// filterSources drops sources and fact references outside the allowlist, on
+// the denylist, or in the blocked tier. Facts left without an allowed source
+// are dropped.
+func (s *Strategy) filterSources(model *credibility.Model, facts []Fact, sources []WebSource) ([]Fact, []WebSource) {
+	allowed := func(u string) bool {
+		return s.allowsSource(u) && model.Score(u).Tier != credibility.TierBlocked
+	}
+
+	kept := make([]WebSource, 0, len(sources))
+	for _, source := range sources {
+		if allowed(source.URL) {
+			kept = append(kept, source)
+		}
+	}
+
+	keptFacts := make([]Fact, 0, len(facts))
+	for _, fact := range facts {
+		if len(fact.Sources) == 0 {
+			if len(s.Sources) == 0 {
+				keptFacts = append(keptFacts, fact) // Only an allowlist requires facts to be sourced
+			}
+			continue
+		}
+		var urls []string
+		for _, u := range fact.Sources {
+			if allowed(u) {
+				urls = append(urls, u)
+			}
+		}
+		if len(urls) == 0 {
+			continue
+		}
+		fact.Sources = urls
+		keptFacts = append(keptFacts, fact)
+	}
+	return keptFacts, kept
+}
+
+// apply adjusts a decision's confidence and checks it against the policy
+func (p ConfidencePolicy) apply(strategy string, decision *Decision) error {
+	if p.ContradictionPenalty > 0 && p.ContradictionPenalty < 1 {
+		for _, fact := range decision.Facts {
+			if fact.Contradicts {
+				decision.Confidence *= p.ContradictionPenalty
+			}
+		}
+	}
+
+	if decision.Confidence < p.MinConfidence {
+		return &PolicyError{
+			Strategy: strategy,
+			Reason:   fmt.Sprintf("confidence %.2f is below %.2f", decision.Confidence, p.MinConfidence),
+			Decision: decision,
+		}
+	}
+	// Syndicated copies are already merged, so each citation is independent
+	credible := 0
+	for _, citation := range decision.Citations {
+		if citation.Credibility >= p.MinCredibility {
+			credible++
+		}
+	}
+	if credible < p.MinCitations {
+		reason := fmt.Sprintf("%d citations, %d required", credible, p.MinCitations)
+		if p.MinCredibility > 0 {
+			reason = fmt.Sprintf("%d citations with credibility %.2f or more, %d required", credible, p.MinCredibility, p.MinCitations)
+		}
+		return &PolicyError{Strategy: strategy, Reason: reason, Decision: decision}
+	}
+	return nil
+}
+
+// StrategyRegistry routes market categories to strategies
+type StrategyRegistry struct {
+	strategies map[string]*Strategy // Normalized category → strategy
+	names      []string
+	generic    *Strategy
+}
+
+// NewStrategyRegistry creates a registry that falls back to generic
+func NewStrategyRegistry(generic Strategy) *StrategyRegistry {
+	if generic.Name == "" {
+		generic.Name = GenericStrategy
+	}
+	return &StrategyRegistry{
+		strategies: make(map[string]*Strategy),
+		generic:    &generic,
+	}
+}
+
+// Register adds a strategy for its name and categories
+func (r *StrategyRegistry) Register(s Strategy) error {
+	if err := s.normalize(); err != nil {
+		return err
+	}
+
+	keys := append([]string{s.Name}, s.Categories...)
+	for _, key := range keys {
+		if existing, ok := r.strategies[normalizeCategory(key)]; ok {
+			return fmt.Errorf("category %q is routed to both %s and %s", key, existing.Name, s.Name)
+		}
+	}
+	for _, key := range keys {
+		r.strategies[normalizeCategory(key)] = &s
+	}
+	r.names = append(r.names, s.Name)
+	return nil
+}
+
+// normalize validates a strategy and lowercases its source domains
+func (s *Strategy) normalize() error {
+	if s.Name == "" {
+		return fmt.Errorf("strategy has no name")
+	}
+	if s.Confidence.MinConfidence < 0 || s.Confidence.MinConfidence > 1 {
+		return fmt.Errorf("strategy %s: minConfidence must be between 0 and 1", s.Name)
+	}
+	if s.Confidence.MinCredibility < 0 || s.Confidence.MinCredibility > 1 {
+		return fmt.Errorf("strategy %s: minCredibility must be between 0 and 1", s.Name)
+	}
+	if s.Confidence.MinCitations < 0 || s.Confidence.MinVerified < 0 {
+		return fmt.Errorf("strategy %s: minCitations and minVerified must not be negative", s.Name)
+	}
+	for domain, tier := range s.Tiers {
+		if !tier.Valid() {
+			return fmt.Errorf("strategy %s: domain %s has unknown tier %q", s.Name, domain, tier)
+		}
+	}
+	s.Sources = normalizeDomains(s.Sources)
+	s.DenySources = normalizeDomains(s.DenySources)
+	return nil
+}
+
+func normalizeDomains(domains []string) []string {
+	if domains == nil {
+		return nil
+	}
+	out := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		out = append(out, credibility.NormalizeDomain(domain))
+	}
+	return out
+}
+
+// Resolve returns the strategy for a market category, or the generic one
+func (r *StrategyRegistry) Resolve(category string) *Strategy {
+	if s, ok := r.strategies[normalizeCategory(category)]; ok {
+		return s
+	}
+	return r.generic
+}
+
+// Names returns the registered strategy names, generic first
+func (r *StrategyRegistry) Names() []string {
+	return append([]string{r.generic.Name}, r.names...)
+}
+
+// List returns the strategies, generic first
+func (r *StrategyRegistry) List() []Strategy {
+	strategies := []Strategy{*r.generic}
+	for _, name := range r.names {
+		strategies = append(strategies, *r.strategies[normalizeCategory(name)])
+	}
+	return strategies
+}
+
+// genericStrategy is the built-in fallback: every tool, any source
+func genericStrategy() Strategy {
+	return Strategy{
+		Name:       GenericStrategy,
+		Confidence: ConfidencePolicy{MinConfidence: 0.5, MinVerified: 1},
+	}
+}
+
+// DefaultStrategies returns the built-in strategies
+func DefaultStrategies() *StrategyRegistry {
+	r := NewStrategyRegistry(genericStrategy())
+
+	for _, s := range []Strategy{
+		{
+			Name:       "crypto-price",
+			Categories: []string{"crypto", "cryptocurrency", "defi"},
+			Prompts:    "crypto-price",
+			Tools:      []string{"pancakeswap", "bscscan", "calculate", "datetime", "get_market_data", "search_documents", "search_web", "fetch_url"},
+			Sources: []string{
+				"coingecko.com", "coinmarketcap.com", "binance.com", "coinbase.com", "kraken.com",
+				"cryptocompare.com", "chain.link", "bscscan.com", "etherscan.io", "pancakeswap.finance",
+			},
+			Confidence: ConfidencePolicy{MinConfidence: 0.8, MinCitations: 2, MinCredibility: 0.75, ContradictionPenalty: 0.7, MinVerified: 2},
+		},
+		{
+			Name:       "sports",
+			Categories: []string{"sport", "esports"},
+			Tools:      []string{"datetime", "calculate", "get_market_data", "search_documents", "search_web", "fetch_url"},
+			Sources: []string{
+				"espn.com", "reuters.com", "apnews.com", "bbc.com", "bbc.co.uk", "skysports.com",
+				"nba.com", "nfl.com", "mlb.com", "nhl.com", "fifa.com", "uefa.com", "premierleague.com",
+			},
+			Confidence: ConfidencePolicy{MinConfidence: 0.8, MinCitations: 2, MinCredibility: 0.75, ContradictionPenalty: 0.7, MinVerified: 2},
+		},
+		{
+			Name:       "politics",
+			Categories: []string{"elections", "election", "government"},
+			Tools:      []string{"datetime", "get_market_data", "search_documents", "search_web", "fetch_url"},
+			Sources: []string{
+				"reuters.com", "apnews.com", "bbc.com", "bbc.co.uk", "politico.com", "nytimes.com",
+				"washingtonpost.com", "ft.com", "gov", "europa.eu",
+			},
+			Confidence: ConfidencePolicy{MinConfidence: 0.85, MinCitations: 2, MinCredibility: 0.75, ContradictionPenalty: 0.6, MinVerified: 2},
+		},
+		{
+			Name:       "weather",
+			Categories: []string{"climate"},
+			Tools:      []string{"datetime", "calculate", "get_market_data", "search_documents", "search_web", "fetch_url"},
+			Sources: []string{
+				"weather.gov", "noaa.gov", "metoffice.gov.uk", "ecmwf.int", "bom.gov.au",
+				"weather.com", "accuweather.com", "wunderground.com", "meteoblue.com",
+			},
+			Confidence: ConfidencePolicy{MinConfidence: 0.8, MinCitations: 1, MinCredibility: 0.75, ContradictionPenalty: 0.7, MinVerified: 1},
+		},
+	} {
+		if err := r.Register(s); err != nil {
+			panic(fmt.Sprintf("invalid built-in strategy: %v", err))
+		}
+	}
+	return r
+}
+
+// LoadStrategies reads strategies from a JSON file, replacing the built-in
+// ones: an array of strategies, where one named "generic" replaces the fallback
+func LoadStrategies(path string) (*StrategyRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read strategies: %w", err)
+	}
+	var strategies []Strategy
+	if err := json.Unmarshal(data, &strategies); err != nil {
+		return nil, fmt.Errorf("failed to parse strategies %s: %w", path, err)
+	}
+	return NewStrategies(strategies)
+}
+
+// NewStrategies creates a registry of the given strategies, where one named
+// "generic" replaces the fallback
+func NewStrategies(strategies []Strategy) (*StrategyRegistry, error) {
+	r := NewStrategyRegistry(genericStrategy())
+	for _, s := range strategies {
+		if s.Name == GenericStrategy {
+			if err := s.normalize(); err != nil {
+				return nil, err
+			}
+			r.generic = &s
+			continue
+		}
+		if err := r.Register(s); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+)
+
+// TestStrategyRouting tests category routing and the generic fallback
+func TestStrategyRouting(t *testing.T) {
+	strategies := DefaultStrategies()
+
+	tests := []struct {
+		category string
+		want     string
+	}{
+		{"crypto-price", "crypto-price"},
+		{"Crypto", "crypto-price"},
+		{"Sports", "sports"},
+		{"elections", "politics"},
+		{"weather", "weather"},
+		{"economics", GenericStrategy},
+		{"", GenericStrategy},
+	}
+	for _, tt := range tests {
+		if got := strategies.Resolve(tt.category).Name; got != tt.want {
+			t.Errorf("category %q: expected %s, got %s", tt.category, tt.want, got)
+		}
+	}
+
+	if err := strategies.Register(Strategy{Name: "stocks", Categories: []string{"crypto"}}); err == nil {
+		t.Error("expected an error for a category routed twice")
+	}
+}
+
+// TestStrategySources tests the domain allowlist
+func TestStrategySources(t *testing.T) {
+	s := Strategy{Name: "test", Sources: []string{"reuters.com", "gov"}}
+	if err := s.normalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	for url, want := range map[string]bool{
+		"https://www.reuters.com/world":    true,
+		"https://reuters.com":              true,
+		"https://www.fec.gov/data":         true,
+		"https://notreuters.com/x":         false,
+		"https://reuters.com.evil.example": false,
+		"not a url":                        false,
+	} {
+		if got := s.allowsSource(url); got != want {
+			t.Errorf("%s: expected %v, got %v", url, want, got)
+		}
+	}
+
+	facts, sources := s.filterSources(credibility.DefaultModel(), []Fact{
+		{Statement: "mixed", Sources: []string{"https://blog.example/x", "https://www.reuters.com/a"}},
+		{Statement: "unlisted", Sources: []string{"https://blog.example/y"}},
+	}, []WebSource{{URL: "https://www.reuters.com/a"}, {URL: "https://blog.example/x"}})
+	if len(facts) != 1 || len(facts[0].Sources) != 1 || facts[0].Sources[0] != "https://www.reuters.com/a" {
+		t.Errorf("unexpected facts: %+v", facts)
+	}
+	if len(sources) != 1 {
+		t.Errorf("unexpected sources: %+v", sources)
+	}
+
+	// The denylist wins over the allowlist, and blocked domains are dropped
+	// even without lists
+	deny := Strategy{Name: "deny", Sources: []string{"com"}, DenySources: []string{"Tabloid.com"}}
+	if err := deny.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if deny.allowsSource("https://www.tabloid.com/story") || !deny.allowsSource("https://reuters.com/story") {
+		t.Error("expected the denylist to win over the allowlist")
+	}
+	model := credibility.DefaultModel().With(map[string]credibility.Tier{"spam.example": credibility.TierBlocked})
+	facts, sources = (&Strategy{Name: "open"}).filterSources(model, []Fact{
+		{Statement: "spam", Sources: []string{"https://spam.example/a"}},
+		{Statement: "unsourced"},
+	}, []WebSource{{URL: "https://spam.example/a"}, {URL: "https://blog.example/b"}})
+	if len(facts) != 1 || facts[0].Statement != "unsourced" || len(sources) != 1 {
+		t.Errorf("expected the blocked source dropped: %+v %+v", facts, sources)
+	}
+}
+
+// TestConfidencePolicy tests the contradiction penalty and the thresholds
+func TestConfidencePolicy(t *testing.T) {
+	policy := ConfidencePolicy{MinConfidence: 0.6, MinCitations: 1, ContradictionPenalty: 0.8}
+
+	decision := &Decision{
+		Confidence: 0.9,
+		Facts:      []Fact{{Contradicts: true}, {}},
+		Citations:  []Citation{{URL: "https://example.com"}},
+	}
+	if err := policy.apply("test", decision); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if decision.Confidence < 0.719 || decision.Confidence > 0.721 {
+		t.Errorf("expected confidence 0.72 after one contradiction, got %f", decision.Confidence)
+	}
+
+	decision.Facts = append(decision.Facts, Fact{Contradicts: true})
+	err := policy.apply("test", decision)
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) || !errors.Is(err, ErrPolicyRejected) || policyErr.Decision != decision {
+		t.Fatalf("expected a policy error carrying the decision, got %v", err)
+	}
+
+	err = policy.apply("test", &Decision{Confidence: 0.9})
+	if err == nil || !strings.Contains(err.Error(), "0 citations, 1 required") {
+		t.Errorf("expected a citation error, got %v", err)
+	}
+
+	// Only credible citations count
+	policy = ConfidencePolicy{MinCitations: 2, MinCredibility: 0.75}
+	decision = &Decision{Confidence: 0.9, Citations: []Citation{{Credibility: 1}, {Credibility: 0.4}, {Credibility: 0.15}}}
+	if err := policy.apply("test", decision); err == nil || !strings.Contains(err.Error(), "1 citations with credibility 0.75 or more, 2 required") {
+		t.Errorf("expected a credibility error, got %v", err)
+	}
+	decision.Citations[1].Credibility = 0.9
+	if err := policy.apply("test", decision); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestLoadStrategies tests that a strategies file replaces the built-ins
+func TestLoadStrategies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strategies.json")
+	os.WriteFile(path, []byte(`[
+		{"name": "generic", "confidence": {"minConfidence": 0.7}},
+		{"name": "stocks", "categories": ["equities"], "tools": [], "sources": ["SEC.gov"], "tiers": {"sec.gov": "official"}}
+	]`), 0o644)
+
+	strategies, err := LoadStrategies(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stocks := strategies.Resolve("Equities")
+	if stocks.Name != "stocks" || stocks.allowsTool("calculate") || !stocks.allowsSource("https://www.sec.gov/x") ||
+		stocks.credibility(credibility.DefaultModel()).Score("https://www.sec.gov/x").Tier != credibility.TierOfficial {
+		t.Errorf("unexpected stocks strategy: %+v", stocks)
+	}
+	generic := strategies.Resolve("crypto")
+	if generic.Name != GenericStrategy || generic.Confidence.MinConfidence != 0.7 || !generic.allowsTool("calculate") {
+		t.Errorf("expected the file's generic strategy for crypto, got %+v", generic)
+	}
+}
+
+// TestLoadStrategiesInvalidTier tests that unknown tiers are rejected
+func TestLoadStrategiesInvalidTier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strategies.json")
+	os.WriteFile(path, []byte(`[{"name": "stocks", "tiers": {"sec.gov": "gold"}}]`), 0o644)
+	if _, err := LoadStrategies(path); err == nil || !strings.Contains(err.Error(), "unknown tier") {
+		t.Errorf("expected an unknown tier error, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrEventNotEnded is returned when a market is analyzed before its event
+// window has ended, so no source can report the outcome yet
+var ErrEventNotEnded = errors.New("event window has not ended")
+
+// EventWindow is when the question's event takes place, in unix seconds.
+// Start is zero when the question only names a deadline.
+type EventWindow struct {
+	Start int64 `json:"start,omitempty"`
+	End   int64 `json:"end"`
+}
+
+// Timeline is the time context every pipeline step is grounded in
+type Timeline struct {
+	BlockTime  int64 // Chain time the analysis runs at
+	CloseTime  int64 // Market close time
+	EventStart int64 // Zero when unknown
+	EventEnd   int64 // The event window's end, or the close time
+}
+
+// timelineFor builds the timeline of a market. A market without a block
+// time is analyzed as of now.
+func timelineFor(market MarketInfo, now time.Time) Timeline {
+	t := Timeline{BlockTime: market.BlockTime, CloseTime: market.CloseTime, EventEnd: market.CloseTime}
+	if t.BlockTime <= 0 {
+		t.BlockTime = now.Unix()
+	}
+	if w := market.EventWindow; w != nil && w.End > 0 {
+		t.EventStart, t.EventEnd = w.Start, w.End
+	}
+	return t
+}
+
+// checkEnded fails with ErrEventNotEnded while the event window is open
+func (t Timeline) checkEnded() error {
+	if t.EventEnd > 0 && t.BlockTime < t.EventEnd {
+		return fmt.Errorf("%w: it ends at %s, block time is %s", ErrEventNotEnded, formatUTC(t.EventEnd), formatUTC(t.BlockTime))
+	}
+	return nil
+}
+
+// flagPremature marks facts whose source was published before the event
+// could have happened: before the event window opened, or before the event
+// the fact itself reports. Such sources are previews and predictions.
+func (t Timeline) flagPremature(facts []Fact) []Fact {
+	flagged := append([]Fact(nil), facts...)
+	for i, fact := range flagged {
+		published, ok := parseFactTime(fact.PublishedAt)
+		if !ok {
+			continue
+		}
+		if t.EventStart > 0 && published.before(factTime{unix: t.EventStart}) {
+			flagged[i].Premature = true
+		}
+		if event, ok := parseFactTime(fact.EventDate); ok && published.before(event) {
+			flagged[i].Premature = true
+		}
+	}
+	return flagged
+}
+
+// checkGrounded rejects a decision whose evidence all predates the event
+func (t Timeline) checkGrounded(strategy string, decision *Decision) error {
+	timely, premature := 0, 0
+	for _, fact := range decision.Facts {
+		switch {
+		case fact.Premature:
+			premature++
+		case !fact.Contradicts:
+			timely++
+		}
+	}
+	if premature > 0 && timely == 0 {
+		return &PolicyError{
+			Strategy: strategy,
+			Reason:   fmt.Sprintf("all %d facts come from sources published before the event could have happened", premature),
+			Decision: decision,
+		}
+	}
+	return nil
+}
+
+// factTime is a fact's timestamp; dateOnly times are compared by UTC day
+type factTime struct {
+	unix     int64
+	dateOnly bool
+}
+
+func (a factTime) before(b factTime) bool {
+	if a.dateOnly || b.dateOnly {
+		return time.Unix(a.unix, 0).UTC().Format(time.DateOnly) < time.Unix(b.unix, 0).UTC().Format(time.DateOnly)
+	}
+	return a.unix < b.unix
+}
+
+// factTimeLayouts are the ISO 8601 forms the model is asked for, and
+// near misses. Times without a zone are read as UTC.
+var factTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+// parseFactTime reads a publication or event date; empty or unparseable
+// dates are unknown
+func parseFactTime(s string) (factTime, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return factTime{}, false
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return factTime{unix: t.Unix(), dateOnly: true}, true
+	}
+	for _, layout := range factTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return factTime{unix: t.Unix()}, true
+		}
+	}
+	return factTime{}, false
+}
+
+// formatUTC renders a unix time for prompts and errors; zero is "unknown"
+func formatUTC(unix int64) string {
+	if unix <= 0 {
+		return "unknown"
+	}
+	return time.Unix(unix, 0).UTC().Format("2006-01-02 15:04:05 UTC")
+}
@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTimeline tests the window check and premature fact flags
+func TestTimeline(t *testing.T) {
+	start := time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC).Unix()
+	end := time.Date(2025, 3, 1, 21, 0, 0, 0, time.UTC).Unix()
+	now := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	timeline := timelineFor(MarketInfo{CloseTime: end, EventWindow: &EventWindow{Start: start, End: end}}, now)
+	if timeline.BlockTime != now.Unix() || timeline.EventStart != start || timeline.checkEnded() != nil {
+		t.Errorf("unexpected timeline %+v", timeline)
+	}
+	open := timelineFor(MarketInfo{CloseTime: end, BlockTime: end - 60}, now)
+	if err := open.checkEnded(); !errors.Is(err, ErrEventNotEnded) || !strings.Contains(err.Error(), "2025-03-01 21:00:00 UTC") {
+		t.Errorf("expected the open window rejected, got %v", err)
+	}
+
+	facts := timeline.flagPremature([]Fact{
+		{Statement: "report", PublishedAt: "2025-03-01T21:30:00Z", EventDate: "2025-03-01"},
+		{Statement: "preview", PublishedAt: "2025-02-28", EventDate: "2025-03-01"},
+		{Statement: "pre-game odds", PublishedAt: "2025-03-01T17:00:00Z"},
+		{Statement: "same-day recap", PublishedAt: "2025-03-01", EventDate: "2025-03-01T20:00:00Z"},
+		{Statement: "prediction for a later date", PublishedAt: "2025-03-02T01:00:00+00:00", EventDate: "2025-03-05"},
+		{Statement: "undated"},
+		{Statement: "garbled", PublishedAt: "last Tuesday"},
+	})
+	var premature []string
+	for _, f := range facts {
+		if f.Premature {
+			premature = append(premature, f.Statement)
+		}
+	}
+	if strings.Join(premature, ",") != "preview,pre-game odds,prediction for a later date" {
+		t.Errorf("unexpected premature facts %v", premature)
+	}
+
+	decision := &Decision{Facts: []Fact{{Premature: true}, {Contradicts: true}}}
+	if err := timeline.checkGrounded("test", decision); !errors.Is(err, ErrPolicyRejected) {
+		t.Errorf("expected a decision on premature facts rejected, got %v", err)
+	}
+	decision.Facts = append(decision.Facts, Fact{})
+	if err := timeline.checkGrounded("test", decision); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
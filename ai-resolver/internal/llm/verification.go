@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+	"github.com/project-gamma/ai-resolver/internal/progress"
+	"github.com/project-gamma/ai-resolver/internal/verify"
+)
+
+// verifyConcurrency is how many cited pages are fetched at once
+const verifyConcurrency = 4
+
+// CitationVerifier checks that a cited page says what is attributed to it;
+// *verify.Verifier implements it
+type CitationVerifier interface {
+	Verify(ctx context.Context, url string, claims []string) (verify.Result, error)
+}
+
+// verifyCitations fetches every cited page and drops the citations whose
+// snippet or supporting claims do not appear on it. When the cited URL fails,
+// a syndicated copy that verifies takes its place. Too few verified citations
+// block the decision.
+func (p *OpenAIPipeline) verifyCitations(ctx context.Context, strategy *Strategy, model *credibility.Model, decision *Decision) error {
+	progress.Step(ctx, string(StepVerifyCitations), progress.StatusStarted, map[string]any{"citations": len(decision.Citations)})
+	stepCtx := audit.WithStep(ctx, string(StepVerifyCitations))
+
+	results := make([]verify.Result, len(decision.Citations))
+	slots := make(chan struct{}, verifyConcurrency)
+	var wg sync.WaitGroup
+	for i, citation := range decision.Citations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			results[i] = p.verifyCitation(stepCtx, citation, claimsFor(citation, decision.Facts))
+		}()
+	}
+	wg.Wait()
+
+	verified := make([]Citation, 0, len(decision.Citations))
+	var dropped []verify.Result
+	for i, citation := range decision.Citations {
+		result := results[i]
+		if !result.Verified {
+			log.Printf("Dropping unverified citation %s: %s", citation.URL, result.Reason)
+			dropped = append(dropped, result)
+			continue
+		}
+		if result.URL != citation.URL {
+			citation = citation.citeCopy(model, result.URL)
+		}
+		citation.Verified = true
+		citation.PageHash = result.PageHash
+		verified = append(verified, citation)
+	}
+	decision.Citations = verified
+	decision.Unverified = dropped
+	reportStep(ctx, StepVerifyCitations, nil, map[string]any{"verified": len(verified), "dropped": dropped})
+
+	if required := strategy.Confidence.MinVerified; len(verified) < required {
+		return &PolicyError{
+			Strategy: strategy.Name,
+			Reason:   fmt.Sprintf("%d of %d citations verified against their pages, %d required", len(verified), len(results), required),
+			Decision: decision,
+		}
+	}
+	return nil
+}
+
+// verifyCitation checks a citation's URL, then its copies, until one
+// verifies. The result for the cited URL is returned when none does.
+func (p *OpenAIPipeline) verifyCitation(ctx context.Context, citation Citation, claims []string) verify.Result {
+	var first verify.Result
+	for i, url := range append([]string{citation.URL}, citation.Copies...) {
+		started := time.Now()
+		result, err := p.verifier.Verify(ctx, url, claims)
+		event := audit.Event{
+			Time:       started.UTC(),
+			DurationMs: time.Since(started).Milliseconds(),
+			Tool:       "verify_citation",
+			Arguments:  audit.RawJSON(map[string]any{"url": url, "claims": claims}),
+			Result:     audit.RawJSON(result),
+		}
+		if err != nil {
+			event.Error = err.Error()
+		}
+		audit.RecordToolCall(ctx, event)
+
+		if result.Verified {
+			return result
+		}
+		if i == 0 {
+			first = result
+		}
+	}
+	return first
+}
+
+// claimsFor collects what is attributed to a citation: its snippet, and the
+// statements and evidence of the facts that cite any of its URLs
+func claimsFor(citation Citation, facts []Fact) []string {
+	urls := append([]string{citation.URL}, citation.Copies...)
+	claims := []string{citation.Snippet}
+	for _, fact := range facts {
+		if !slices.ContainsFunc(fact.Sources, func(s string) bool { return slices.Contains(urls, s) }) {
+			continue
+		}
+		claims = append(claims, fact.SupportingEvidence, fact.Statement)
+	}
+	return slices.DeleteFunc(claims, func(c string) bool { return c == "" })
+}
+
+// citeCopy returns the citation moved to one of its syndicated copies, scored
+// for the copy's domain
+func (c Citation) citeCopy(model *credibility.Model, url string) Citation {
+	score := model.Score(url)
+	if c.Credibility > 0 {
+		c.Weight *= score.Credibility / c.Credibility
+	}
+	copies := slices.Clone(c.Copies)
+	copies[slices.Index(copies, url)] = c.URL
+	c.URL, c.Copies = url, copies
+	c.Domain, c.Tier, c.Credibility = score.Domain, string(score.Tier), score.Credibility
+	return c
+}
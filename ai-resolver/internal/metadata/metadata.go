@@ -0,0 +1,173 @@
+// Package metadata defines the JSON document a market's MetadataURI points to
+// and the structured resolution spec embedded in it at market creation.
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/pricefeed"
+	"github.com/project-gamma/ai-resolver/internal/scalar"
+)
+
+// SpecVersion is the version of the ResolutionSpec layout
+const SpecVersion = 1
+
+// Document is the market metadata stored at MetadataURI, as written by the
+// SDK's uploadMarketMetadata, plus the resolution spec
+type Document struct {
+	Question    string          `json:"question"`
+	Description string          `json:"description,omitempty"`
+	Category    string          `json:"category"`
+	CreatedAt   int64           `json:"createdAt,omitempty"`
+	Creator     string          `json:"creator,omitempty"`
+	Resolution  *ResolutionSpec `json:"resolution,omitempty"`
+}
+
+// PriceSpec returns the authored price spec of a price market, or nil. It
+// implements pricefeed.SpecSource.
+func (d *Document) PriceSpec() *pricefeed.Spec {
+	if d.Resolution == nil {
+		return nil
+	}
+	return d.Resolution.Price
+}
+
+// ResolutionSpec states unambiguously how a market resolves. All times are
+// Unix seconds; Timezone records that they were normalized to UTC.
+type ResolutionSpec struct {
+	Version  int       `json:"version"`
+	Question string    `json:"question"` // Normalized question text
+	Outcomes []Outcome `json:"outcomes"`
+
+	// The event must happen within Window; the outcome is determined at
+	// ObservationTime, which is the window's end or the market close time
+	Window          *Window `json:"window,omitempty"`
+	ObservationTime int64   `json:"observationTime"`
+	Timezone        string  `json:"timezone"`
+
+	Sources  []string `json:"sources"`  // Resolution sources: domains, URLs or named bodies
+	Criteria string   `json:"criteria"` // How the outcome is decided, in plain words
+
+	// Price is set for point-in-time price questions, which resolve from
+	// on-chain oracles (see internal/pricefeed)
+	Price *pricefeed.Spec `json:"price,omitempty"`
+
+	// Scalar is set for bracket markets, which resolve on a value mapped onto
+	// the outcomes' ranges (see internal/scalar)
+	Scalar *scalar.Spec `json:"scalar,omitempty"`
+
+	// Dependency is set for conditional markets, which resolve only once
+	// their parent market is final
+	Dependency *Dependency `json:"dependency,omitempty"`
+}
+
+// Dependency ties a conditional market to its parent. The market resolves as
+// usual when the parent's final outcome is one of ParentOutcomes, and to
+// VoidOutcome otherwise.
+type Dependency struct {
+	ParentMarketID uint64   `json:"parentMarketId"`
+	ParentOutcomes []uint64 `json:"parentOutcomes"`      // Parent outcomes that meet the condition
+	Condition      string   `json:"condition,omitempty"` // The condition in words, e.g. "Team A reaches the final"
+	VoidOutcome    uint64   `json:"voidOutcome"`
+}
+
+// Outcome is one resolvable outcome; ID is the outcome index proposed on-chain
+type Outcome struct {
+	ID        uint64 `json:"id"`
+	Label     string `json:"label"`
+	Condition string `json:"condition,omitempty"` // When this outcome wins
+}
+
+// Window is the period an event must happen in
+type Window struct {
+	Start int64 `json:"start,omitempty"` // Zero: any time before End
+	End   int64 `json:"end"`
+}
+
+// BinaryOutcomes are the outcomes of a YES/NO market, matching the outcome IDs
+// of llm.Decision
+func BinaryOutcomes() []Outcome {
+	return []Outcome{{ID: 0, Label: "No"}, {ID: 1, Label: "Yes"}}
+}
+
+// Validate checks that a spec is complete enough to resolve from
+func (s *ResolutionSpec) Validate() error {
+	if s.Question == "" {
+		return fmt.Errorf("resolution spec has no question")
+	}
+	if len(s.Outcomes) < 2 {
+		return fmt.Errorf("resolution spec needs at least 2 outcomes, has %d", len(s.Outcomes))
+	}
+	for i, outcome := range s.Outcomes {
+		if outcome.ID != uint64(i) {
+			return fmt.Errorf("outcome %q has id %d, expected %d", outcome.Label, outcome.ID, i)
+		}
+	}
+	if s.ObservationTime <= 0 {
+		return fmt.Errorf("resolution spec has no observation time")
+	}
+	if s.Window != nil && s.Window.Start > s.Window.End {
+		return fmt.Errorf("event window starts after it ends")
+	}
+	if s.Timezone != "UTC" {
+		return fmt.Errorf("resolution spec times must be UTC, got %q", s.Timezone)
+	}
+	if d := s.Dependency; d != nil {
+		if len(d.ParentOutcomes) == 0 {
+			return fmt.Errorf("dependency on market %d lists no parent outcomes", d.ParentMarketID)
+		}
+		if d.VoidOutcome >= uint64(len(s.Outcomes)) {
+			return fmt.Errorf("void outcome %d is not listed", d.VoidOutcome)
+		}
+	}
+	if s.Price != nil {
+		if len(s.Outcomes) != 2 {
+			return fmt.Errorf("price markets have 2 outcomes, not %d", len(s.Outcomes))
+		}
+		if err := s.Price.Validate(); err != nil {
+			return fmt.Errorf("invalid price spec: %w", err)
+		}
+	}
+	if s.Scalar != nil {
+		if err := s.Scalar.Validate(); err != nil {
+			return err
+		}
+		for _, b := range s.Scalar.Brackets {
+			if b.Outcome >= uint64(len(s.Outcomes)) {
+				return fmt.Errorf("bracket %s pays outcome %d, which is not listed", b.String(s.Scalar.Boundary), b.Outcome)
+			}
+		}
+		// Every outcome needs a bracket, except the void outcome of a
+		// conditional market
+		want := len(s.Outcomes)
+		if s.Dependency != nil && !slices.Contains(s.Scalar.Outcomes(), s.Dependency.VoidOutcome) {
+			want--
+		}
+		if len(s.Scalar.Brackets) != want {
+			return fmt.Errorf("%d outcomes but %d brackets", want, len(s.Scalar.Brackets))
+		}
+	}
+	return nil
+}
+
+// Parse decodes a metadata document
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse market metadata: %w", err)
+	}
+	if doc.Resolution != nil {
+		if err := doc.Resolution.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid market metadata: %w", err)
+		}
+	}
+	return &doc, nil
+}
+
+// FormatTime renders a spec time for people, e.g. "2025-03-01 23:59:59 UTC"
+func FormatTime(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format("2006-01-02 15:04:05 UTC")
+}
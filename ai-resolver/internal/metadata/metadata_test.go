@@ -0,0 +1,39 @@
+package metadata
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParse tests decoding metadata documents with and without a spec
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(`{"question": "Will it rain?", "category": "weather", "createdAt": 1}`))
+	if err != nil || doc.Resolution != nil || doc.Category != "weather" {
+		t.Fatalf("unexpected document %+v: %v", doc, err)
+	}
+
+	doc, err = Parse([]byte(`{"question": "Will it rain?", "resolution": {"version": 1, "question": "Will it rain?",
+		"outcomes": [{"id": 0, "label": "No"}, {"id": 1, "label": "Yes"}], "observationTime": 1740873599, "timezone": "UTC"}}`))
+	if err != nil || doc.Resolution.ObservationTime != 1740873599 {
+		t.Fatalf("unexpected document %+v: %v", doc, err)
+	}
+
+	tests := map[string]string{
+		"one outcome":    `{"resolution": {"question": "q", "outcomes": [{"id": 0}], "observationTime": 1, "timezone": "UTC"}}`,
+		"outcome ids":    `{"resolution": {"question": "q", "outcomes": [{"id": 1}, {"id": 0}], "observationTime": 1, "timezone": "UTC"}}`,
+		"local timezone": `{"resolution": {"question": "q", "outcomes": [{"id": 0}, {"id": 1}], "observationTime": 1, "timezone": "EST"}}`,
+		"no time":        `{"resolution": {"question": "q", "outcomes": [{"id": 0}, {"id": 1}], "timezone": "UTC"}}`,
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data)); err == nil || !strings.Contains(err.Error(), "invalid market metadata") {
+			t.Errorf("%s: expected a validation error, got %v", name, err)
+		}
+	}
+}
+
+// TestFormatTime tests the UTC rendering used in criteria
+func TestFormatTime(t *testing.T) {
+	if got := FormatTime(1740873599); got != "2025-03-01 23:59:59 UTC" {
+		t.Errorf("unexpected time %q", got)
+	}
+}
@@ -0,0 +1,116 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+)
+
+// StageName is the name pipeline layouts use for the metadata stage
+const StageName = "fetch_metadata"
+
+// ErrUnavailable is returned when a market's metadata cannot be fetched; the
+// market should be retried later
+var ErrUnavailable = errors.New("market metadata unavailable")
+
+// Fetcher downloads documents; *fetch.Fetcher implements it
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (*fetch.Page, error)
+}
+
+// Stage is a pipeline stage that fetches the document at the market's
+// MetadataURI and fills in what the contract does not hold: the description,
+// the resolution criteria, the event window, the number of outcomes and the
+// brackets of scalar markets
+type Stage struct {
+	fetcher Fetcher
+	gateway string
+}
+
+// NewStage creates a metadata stage that reads ipfs:// URIs through the given
+// HTTP gateway, e.g. https://ipfs.io
+func NewStage(fetcher Fetcher, ipfsGateway string) *Stage {
+	return &Stage{fetcher: fetcher, gateway: strings.TrimSuffix(ipfsGateway, "/")}
+}
+
+// Name implements llm.Stage
+func (s *Stage) Name() string { return StageName }
+
+// Inputs implements llm.Stage
+func (s *Stage) Inputs() []llm.Artifact { return []llm.Artifact{llm.ArtifactMarket} }
+
+// Outputs implements llm.Stage
+func (s *Stage) Outputs() []llm.Artifact { return []llm.Artifact{llm.ArtifactMetadata} }
+
+// Run fetches and applies the market's metadata. Markets without a
+// MetadataURI skip the stage. Any other market fails without its metadata,
+// which may name a parent or the outcomes: a failed fetch with
+// ErrUnavailable, so the market is retried, and a document that cannot be
+// read with a plain error.
+func (s *Stage) Run(ctx context.Context, a *llm.Analysis) error {
+	if a.Market.MetadataURI == "" {
+		return llm.ErrStageSkipped
+	}
+	doc, err := s.load(ctx, a.Market.MetadataURI)
+	if err != nil {
+		return err
+	}
+	a.Market = Apply(a.Market, doc)
+	a.Artifacts[llm.ArtifactMetadata] = doc
+	return nil
+}
+
+// load fetches and parses the document at uri
+func (s *Stage) load(ctx context.Context, uri string) (*Document, error) {
+	url, err := s.resolve(uri)
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.fetcher.Fetch(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return Parse([]byte(page.Text))
+}
+
+// resolve maps a metadata URI to an HTTP URL
+func (s *Stage) resolve(uri string) (string, error) {
+	switch {
+	case strings.HasPrefix(uri, "ipfs://"):
+		return s.gateway + "/ipfs/" + strings.TrimPrefix(strings.TrimPrefix(uri, "ipfs://"), "ipfs/"), nil
+	case strings.HasPrefix(uri, "https://"), strings.HasPrefix(uri, "http://"):
+		return uri, nil
+	}
+	return "", fmt.Errorf("unsupported metadata URI %q", uri)
+}
+
+// Apply returns the market with what its metadata adds. The spec's window
+// replaces one read from the question; a description already set is kept.
+func Apply(market llm.MarketInfo, doc *Document) llm.MarketInfo {
+	if market.Description == "" {
+		market.Description = doc.Description
+	}
+	spec := doc.Resolution
+	if spec == nil {
+		return market
+	}
+	if spec.Criteria != "" && !strings.Contains(market.Description, spec.Criteria) {
+		market.Description = strings.TrimSpace(market.Description + "\n\nResolution criteria: " + spec.Criteria)
+	}
+	market.OutcomeCount = len(spec.Outcomes)
+	if spec.Scalar != nil {
+		market.Scalar = spec.Scalar
+	}
+	switch {
+	case spec.Window != nil:
+		market.EventWindow = &llm.EventWindow{Start: spec.Window.Start, End: spec.Window.End}
+	case spec.Scalar != nil:
+		// The value is read as of the observation time, not the close
+		market.EventWindow = &llm.EventWindow{End: spec.ObservationTime}
+	}
+	return market
+}
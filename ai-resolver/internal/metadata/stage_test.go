@@ -0,0 +1,90 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+)
+
+// documents is a Fetcher serving fixed documents by URL
+type documents map[string]string
+
+func (d documents) Fetch(_ context.Context, url string) (*fetch.Page, error) {
+	text, ok := d[url]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &fetch.Page{URL: url, Status: 200, Text: text}, nil
+}
+
+// TestStage tests fetching metadata from IPFS and applying it to the market
+func TestStage(t *testing.T) {
+	stage := NewStage(documents{
+		"https://ipfs.io/ipfs/QmSpec": `{"question": "Will it rain?", "description": "Rain in Paris.", "resolution": {"version": 1,
+			"question": "Will it rain?", "outcomes": [{"id": 0, "label": "No"}, {"id": 1, "label": "Yes"}, {"id": 2, "label": "Void"}],
+			"window": {"start": 100, "end": 200}, "observationTime": 200, "timezone": "UTC", "criteria": "Any rain recorded by Météo-France."}}`,
+		"https://ipfs.io/ipfs/QmText": "Will it rain in Paris?",
+	}, "https://ipfs.io/")
+
+	// The spec's window replaces the one read from the question
+	a := &llm.Analysis{Market: llm.MarketInfo{MetadataURI: "ipfs://QmSpec", OutcomeCount: 2, EventWindow: &llm.EventWindow{Start: 50, End: 60}}, Artifacts: map[llm.Artifact]any{}}
+	if err := stage.Run(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+	market := a.Market
+	if !strings.HasPrefix(market.Description, "Rain in Paris.") || !strings.Contains(market.Description, "Météo-France") {
+		t.Errorf("unexpected description %q", market.Description)
+	}
+	if market.EventWindow == nil || market.EventWindow.Start != 100 || market.EventWindow.End != 200 || market.OutcomeCount != 3 {
+		t.Errorf("unexpected market %+v", market)
+	}
+	if _, ok := a.Artifacts[llm.ArtifactMetadata].(*Document); !ok {
+		t.Error("expected the document in the artifacts")
+	}
+
+	a = &llm.Analysis{Market: llm.MarketInfo{}, Artifacts: map[llm.Artifact]any{}}
+	if err := stage.Run(context.Background(), a); !errors.Is(err, llm.ErrStageSkipped) {
+		t.Errorf("expected a skip without a URI, got %v", err)
+	}
+
+	// Metadata that cannot be read fails YES/NO markets too: it may name a
+	// parent. A failed fetch is retried later.
+	for _, outcomes := range []int{2, 3} {
+		a.Market = llm.MarketInfo{MetadataURI: "ipfs://QmMissing", OutcomeCount: outcomes}
+		if err := stage.Run(context.Background(), a); !errors.Is(err, ErrUnavailable) {
+			t.Errorf("%d outcomes: expected the metadata to be unavailable, got %v", outcomes, err)
+		}
+		a.Market.MetadataURI = "ipfs://QmText"
+		if err := stage.Run(context.Background(), a); err == nil || errors.Is(err, llm.ErrStageSkipped) || errors.Is(err, ErrUnavailable) {
+			t.Errorf("%d outcomes: expected a parse error for a document that is not JSON, got %v", outcomes, err)
+		}
+		a.Market.MetadataURI = "ar://tx"
+		if err := stage.Run(context.Background(), a); err == nil || errors.Is(err, llm.ErrStageSkipped) {
+			t.Errorf("%d outcomes: expected an unsupported URI error, got %v", outcomes, err)
+		}
+	}
+}
+
+// TestApplyScalar tests that bracket specs reach the market and set the cutoff
+func TestApplyScalar(t *testing.T) {
+	doc, err := Parse([]byte(`{"question": "CPI for February?", "resolution": {"version": 1, "question": "CPI for February?",
+		"outcomes": [{"id": 0, "label": "Below 3%"}, {"id": 1, "label": "3% or more"}], "observationTime": 1740787199, "timezone": "UTC",
+		"scalar": {"quantity": "US CPI YoY", "unit": "%", "brackets": [{"outcome": 0, "max": "3"}, {"outcome": 1, "min": "3"}]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	market := Apply(llm.MarketInfo{CloseTime: 1741046400, EventWindow: &llm.EventWindow{End: 1740700800}}, doc)
+	if market.Scalar == nil || market.EventWindow == nil || market.EventWindow.End != 1740787199 || market.OutcomeCount != 2 {
+		t.Errorf("unexpected market %+v", market)
+	}
+
+	_, err = Parse([]byte(`{"resolution": {"version": 1, "question": "q", "outcomes": [{"id": 0}, {"id": 1}], "observationTime": 1, "timezone": "UTC",
+		"scalar": {"unit": "%", "brackets": [{"outcome": 0, "max": "3"}, {"outcome": 2, "min": "3"}]}}}`))
+	if err == nil || !strings.Contains(err.Error(), "not listed") {
+		t.Errorf("expected a bracket for an unknown outcome to fail, got %v", err)
+	}
+}
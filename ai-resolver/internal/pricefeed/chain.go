@@ -0,0 +1,213 @@
+package pricefeed
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Backend reads chain state at historical blocks. *ethclient.Client
+// implements it; the node must serve archive state for old blocks.
+type Backend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// Chainlink AggregatorV3Interface and PancakeSwap V2 pair view methods
+const oracleABI = `[
+	{"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"latestRoundData","outputs":[{"name":"roundId","type":"uint80"},{"name":"answer","type":"int256"},{"name":"startedAt","type":"uint256"},{"name":"updatedAt","type":"uint256"},{"name":"answeredInRound","type":"uint80"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"token0","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"token1","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"getReserves","outputs":[{"name":"_reserve0","type":"uint112"},{"name":"_reserve1","type":"uint112"},{"name":"_blockTimestampLast","type":"uint32"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"price0CumulativeLast","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"price1CumulativeLast","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+var parsedOracleABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(oracleABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// q112 is the UQ112x112 fixed-point scale of pair cumulative prices
+var q112 = new(big.Int).Lsh(big.NewInt(1), 112)
+
+// call invokes a view method of contract at a block
+func (r *Resolver) call(ctx context.Context, contract common.Address, block *big.Int, method string) ([]any, error) {
+	data, err := parsedOracleABI.Pack(method)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s: %w", method, err)
+	}
+	result, err := r.backend.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s on %s at block %s: %w", method, contract.Hex(), block, err)
+	}
+	values, err := parsedOracleABI.Unpack(method, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s from %s: %w", method, contract.Hex(), err)
+	}
+	return values, nil
+}
+
+// chainlinkPrice reads the aggregator's latest round as of the block
+func (r *Resolver) chainlinkPrice(ctx context.Context, feed Feed, header *types.Header) (*Observation, error) {
+	decimals, err := r.call(ctx, feed.Address, header.Number, "decimals")
+	if err != nil {
+		return nil, err
+	}
+	round, err := r.call(ctx, feed.Address, header.Number, "latestRoundData")
+	if err != nil {
+		return nil, err
+	}
+
+	answer := round[1].(*big.Int)
+	updatedAt := round[3].(*big.Int).Int64()
+	if answer.Sign() <= 0 {
+		return nil, fmt.Errorf("chainlink %s/%s answered %s at block %s", feed.Asset, feed.Quote, answer, header.Number)
+	}
+	if age := int64(header.Time) - updatedAt; age > feed.MaxAgeSeconds {
+		return nil, fmt.Errorf("chainlink %s/%s round is stale at block %s: updated %s ago, limit %ds",
+			feed.Asset, feed.Quote, header.Number, time.Duration(age)*time.Second, feed.MaxAgeSeconds)
+	}
+
+	price := new(big.Rat).SetInt(answer)
+	price.Quo(price, pow10(int(decimals[0].(uint8))))
+
+	return &Observation{
+		Feed:      feed,
+		Block:     header.Number.Uint64(),
+		BlockTime: int64(header.Time),
+		Price:     price,
+		Detail: fmt.Sprintf("latestRoundData: roundId=%s answer=%s decimals=%d updatedAt=%d",
+			round[0].(*big.Int), answer, decimals[0].(uint8), updatedAt),
+	}, nil
+}
+
+// pairState is a pair's cumulative prices extrapolated to a block's time, as
+// UniswapV2OracleLibrary.currentCumulativePrices does
+type pairState struct {
+	time        int64
+	cumulative0 *big.Int // token1 per token0, UQ112x112 × seconds
+	cumulative1 *big.Int
+	reserve0    *big.Int
+	reserve1    *big.Int
+}
+
+func (r *Resolver) pairStateAt(ctx context.Context, pair common.Address, header *types.Header) (*pairState, error) {
+	reserves, err := r.call(ctx, pair, header.Number, "getReserves")
+	if err != nil {
+		return nil, err
+	}
+	c0, err := r.call(ctx, pair, header.Number, "price0CumulativeLast")
+	if err != nil {
+		return nil, err
+	}
+	c1, err := r.call(ctx, pair, header.Number, "price1CumulativeLast")
+	if err != nil {
+		return nil, err
+	}
+
+	state := &pairState{
+		time:        int64(header.Time),
+		cumulative0: new(big.Int).Set(c0[0].(*big.Int)),
+		cumulative1: new(big.Int).Set(c1[0].(*big.Int)),
+		reserve0:    reserves[0].(*big.Int),
+		reserve1:    reserves[1].(*big.Int),
+	}
+	if state.reserve0.Sign() == 0 || state.reserve1.Sign() == 0 {
+		return nil, fmt.Errorf("pair %s has no liquidity at block %s", pair.Hex(), header.Number)
+	}
+
+	// Accumulate the current reserves since the pair's last update, in the
+	// pair's uint32 timestamp arithmetic
+	elapsed := big.NewInt(int64(uint32(header.Time) - reserves[2].(uint32)))
+	if elapsed.Sign() > 0 {
+		price0 := new(big.Int).Div(new(big.Int).Mul(state.reserve1, q112), state.reserve0)
+		price1 := new(big.Int).Div(new(big.Int).Mul(state.reserve0, q112), state.reserve1)
+		state.cumulative0.Add(state.cumulative0, price0.Mul(price0, elapsed))
+		state.cumulative1.Add(state.cumulative1, price1.Mul(price1, elapsed))
+	}
+	return state, nil
+}
+
+// pancakeSwapPrice reads the pair's TWAP over the feed's window ending at the
+// block, or its spot price when the window is empty
+func (r *Resolver) pancakeSwapPrice(ctx context.Context, feed Feed, header *types.Header) (*Observation, error) {
+	token0, err := r.call(ctx, feed.Address, header.Number, "token0")
+	if err != nil {
+		return nil, err
+	}
+	baseIsToken0 := token0[0].(common.Address) == feed.BaseToken
+	if !baseIsToken0 {
+		token1, err := r.call(ctx, feed.Address, header.Number, "token1")
+		if err != nil {
+			return nil, err
+		}
+		if token1[0].(common.Address) != feed.BaseToken {
+			return nil, fmt.Errorf("pair %s does not hold %s", feed.Address.Hex(), feed.BaseToken.Hex())
+		}
+	}
+
+	end, err := r.pairStateAt(ctx, feed.Address, header)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw *big.Rat
+	var detail string
+	startHeader := header
+	if feed.WindowSeconds > 0 {
+		if startHeader, err = r.blockAt(ctx, int64(header.Time)-feed.WindowSeconds); err != nil {
+			return nil, err
+		}
+	}
+
+	if startHeader.Number.Cmp(header.Number) == 0 {
+		if baseIsToken0 {
+			raw = new(big.Rat).SetFrac(end.reserve1, end.reserve0)
+		} else {
+			raw = new(big.Rat).SetFrac(end.reserve0, end.reserve1)
+		}
+		detail = fmt.Sprintf("getReserves: reserve0=%s reserve1=%s", end.reserve0, end.reserve1)
+	} else {
+		start, err := r.pairStateAt(ctx, feed.Address, startHeader)
+		if err != nil {
+			return nil, err
+		}
+		startCumulative, endCumulative := start.cumulative0, end.cumulative0
+		if !baseIsToken0 {
+			startCumulative, endCumulative = start.cumulative1, end.cumulative1
+		}
+		diff := new(big.Int).Sub(endCumulative, startCumulative)
+		if diff.Sign() < 0 {
+			// Cumulative prices overflow by design
+			diff.Add(diff, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		elapsed := big.NewInt(end.time - start.time)
+		raw = new(big.Rat).SetFrac(diff, new(big.Int).Mul(elapsed, q112))
+		detail = fmt.Sprintf("TWAP from block %s to %s: cumulative %s to %s over %ss",
+			startHeader.Number, header.Number, startCumulative, endCumulative, elapsed)
+	}
+
+	// Scale from raw token units to whole tokens
+	price := raw.Mul(raw, pow10(int(feed.BaseDecimals)))
+	price.Quo(price, pow10(int(feed.QuoteDecimals)))
+
+	return &Observation{
+		Feed:      feed,
+		Block:     header.Number.Uint64(),
+		BlockTime: int64(header.Time),
+		Price:     price,
+		Detail:    detail,
+	}, nil
+}
@@ -0,0 +1,104 @@
+package pricefeed
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Feed is an on-chain price source for one asset on one chain
+type Feed struct {
+	ChainID int64          `json:"chainId"`
+	Asset   string         `json:"asset"`   // Canonical symbol, e.g. "BNB"
+	Quote   string         `json:"quote"`   // "USD"
+	Source  Source         `json:"source"`  // "chainlink" or "pancakeswap"
+	Address common.Address `json:"address"` // Aggregator proxy or pair
+
+	// MaxAgeSeconds rejects Chainlink rounds older than this at the
+	// observation time. Zero allows one day.
+	MaxAgeSeconds int64 `json:"maxAgeSeconds,omitempty"`
+
+	// PancakeSwap pairs: the asset's token (the other one is the quote),
+	// token decimals, and the TWAP window ending at the observation time.
+	// WindowSeconds zero uses the spot price at the observation block.
+	BaseToken     common.Address `json:"baseToken,omitempty"`
+	BaseDecimals  uint8          `json:"baseDecimals,omitempty"`
+	QuoteDecimals uint8          `json:"quoteDecimals,omitempty"`
+	WindowSeconds int64          `json:"windowSeconds,omitempty"`
+}
+
+// defaultMaxAge is the Chainlink staleness limit when a feed sets none
+const defaultMaxAge = 24 * 60 * 60
+
+func (f *Feed) normalize() error {
+	f.Asset = strings.ToUpper(f.Asset)
+	f.Quote = strings.ToUpper(f.Quote)
+	if f.Quote == "" {
+		f.Quote = "USD"
+	}
+	if f.Asset == "" || f.ChainID == 0 || f.Address == (common.Address{}) {
+		return fmt.Errorf("price feed needs chainId, asset and address")
+	}
+	switch f.Source {
+	case SourceChainlink:
+		if f.MaxAgeSeconds == 0 {
+			f.MaxAgeSeconds = defaultMaxAge
+		}
+	case SourcePancakeSwap:
+		if f.BaseToken == (common.Address{}) {
+			return fmt.Errorf("pancakeswap feed %s/%s needs baseToken", f.Asset, f.Quote)
+		}
+		if f.BaseDecimals == 0 {
+			f.BaseDecimals = 18
+		}
+		if f.QuoteDecimals == 0 {
+			f.QuoteDecimals = 18
+		}
+	default:
+		return fmt.Errorf("price feed %s/%s has unknown source %q", f.Asset, f.Quote, f.Source)
+	}
+	return nil
+}
+
+// DefaultFeeds returns the built-in BSC mainnet feeds
+func DefaultFeeds() []Feed {
+	wbnb := common.HexToAddress("0xbb4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c")
+	btcb := common.HexToAddress("0x7130d2A12B9BCbFAe4f2634d864A1Ee1Ce3Ead9c")
+
+	feeds := []Feed{
+		{ChainID: 56, Asset: "BNB", Source: SourceChainlink, Address: common.HexToAddress("0x0567F2323251f0Aab15c8dFb1967E4e8A7D42aeE")},
+		{ChainID: 56, Asset: "BTC", Source: SourceChainlink, Address: common.HexToAddress("0x264990fbd0A4796A3E3d8E37C4d5F87a3aCa5Ebf")},
+		{ChainID: 56, Asset: "ETH", Source: SourceChainlink, Address: common.HexToAddress("0x9ef1B8c0E4F7dc8bF5719Ea496883DC6401d5b2e")},
+		{ChainID: 56, Asset: "CAKE", Source: SourceChainlink, Address: common.HexToAddress("0xB6064eD41d4f67e353768aA239cA86f4F73665a1")},
+		// WBNB/BUSD and BTCB/BUSD, with a 30 minute TWAP
+		{ChainID: 56, Asset: "BNB", Source: SourcePancakeSwap, Address: common.HexToAddress("0x58F876857a02D6762E0101bb5C46A8c1ED44Dc16"), BaseToken: wbnb, WindowSeconds: 1800},
+		{ChainID: 56, Asset: "BTC", Source: SourcePancakeSwap, Address: common.HexToAddress("0xF45cd219aEF8618A92BAa7aD848364a158a24F33"), BaseToken: btcb, WindowSeconds: 1800},
+	}
+	for i := range feeds {
+		if err := feeds[i].normalize(); err != nil {
+			panic(fmt.Sprintf("invalid built-in price feed: %v", err))
+		}
+	}
+	return feeds
+}
+
+// LoadFeeds reads price feeds from a JSON array, replacing the built-in ones
+func LoadFeeds(path string) ([]Feed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price feeds: %w", err)
+	}
+	var feeds []Feed
+	if err := json.Unmarshal(data, &feeds); err != nil {
+		return nil, fmt.Errorf("failed to parse price feeds %s: %w", path, err)
+	}
+	for i := range feeds {
+		if err := feeds[i].normalize(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return feeds, nil
+}
@@ -0,0 +1,231 @@
+package pricefeed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+)
+
+// StrategyName labels decisions made by the resolver
+const StrategyName = "onchain-price"
+
+// ErrNotApplicable is returned by Decide for markets the resolver cannot
+// answer (not a price question, or no feed for the asset), which should go
+// through the LLM pipeline instead
+var ErrNotApplicable = errors.New("market is not resolvable from on-chain prices")
+
+// ErrNotObservable is returned when the chain has not reached the
+// observation time yet
+var ErrNotObservable = errors.New("price is not observable yet")
+
+// Observation is a price read from the chain
+type Observation struct {
+	Feed      Feed     `json:"feed"`
+	Block     uint64   `json:"block"`
+	BlockTime int64    `json:"blockTime"`
+	Price     *big.Rat `json:"-"`
+	Detail    string   `json:"detail"` // The raw values behind Price
+}
+
+// PriceString formats the observed price with 8 decimals
+func (o *Observation) PriceString() string {
+	return o.Price.FloatString(8)
+}
+
+// EvidenceURI identifies the contract and block read, e.g.
+// "eip155:56:0x0567...aeE?block=46000000"
+func (o *Observation) EvidenceURI() string {
+	return fmt.Sprintf("eip155:%d:%s?block=%d", o.Feed.ChainID, o.Feed.Address.Hex(), o.Block)
+}
+
+// Resolver answers price questions from a chain's oracle contracts
+type Resolver struct {
+	backend Backend
+	chainID int64
+	feeds   []Feed
+}
+
+// NewResolver creates a resolver for the feeds of chainID
+func NewResolver(backend Backend, chainID int64, feeds []Feed) *Resolver {
+	r := &Resolver{backend: backend, chainID: chainID}
+	for _, feed := range feeds {
+		if feed.ChainID == chainID {
+			r.feeds = append(r.feeds, feed)
+		}
+	}
+	return r
+}
+
+// feedFor returns the feed for a spec, preferring Chainlink when the
+// question names no source
+func (r *Resolver) feedFor(spec *Spec) (Feed, bool) {
+	for _, source := range []Source{SourceChainlink, SourcePancakeSwap} {
+		if spec.Source != "" && spec.Source != source {
+			continue
+		}
+		for _, feed := range r.feeds {
+			if feed.Source == source && feed.Asset == spec.Asset && feed.Quote == spec.Quote {
+				return feed, true
+			}
+		}
+	}
+	return Feed{}, false
+}
+
+// Price observes the spec's asset price at the last block at or before its
+// timestamp
+func (r *Resolver) Price(ctx context.Context, spec *Spec) (*Observation, error) {
+	feed, ok := r.feedFor(spec)
+	if !ok {
+		source := spec.Source
+		if source == "" {
+			source = "any source"
+		}
+		return nil, fmt.Errorf("%w: no %s/%s feed from %s on chain %d", ErrNotApplicable, spec.Asset, spec.Quote, source, r.chainID)
+	}
+
+	header, err := r.blockAt(ctx, spec.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	switch feed.Source {
+	case SourceChainlink:
+		return r.chainlinkPrice(ctx, feed, header)
+	default:
+		return r.pancakeSwapPrice(ctx, feed, header)
+	}
+}
+
+// Decide resolves a binary price market from its question: YES (outcome 1) if
+// the condition held at the observation time, NO (outcome 0) otherwise
+func (r *Resolver) Decide(ctx context.Context, market llm.MarketInfo) (*llm.Decision, error) {
+	spec, err := Parse(market.Question, market.CloseTime)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotApplicable, err)
+	}
+	return r.DecideSpec(ctx, market, spec)
+}
+
+// DecideSpec resolves a binary price market on a given spec, such as the one
+// authored in the market's metadata, instead of parsing its question
+func (r *Resolver) DecideSpec(ctx context.Context, market llm.MarketInfo, spec *Spec) (*llm.Decision, error) {
+	if market.OutcomeCount != 0 && market.OutcomeCount != 2 {
+		return nil, fmt.Errorf("%w: %d outcomes", ErrNotApplicable, market.OutcomeCount)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid price spec: %w", err)
+	}
+	threshold, err := spec.ThresholdRat()
+	if err != nil {
+		return nil, fmt.Errorf("invalid price spec: %w", err)
+	}
+
+	ctx = audit.WithStep(ctx, StrategyName)
+	start := time.Now()
+	obs, err := r.Price(ctx, spec)
+	event := audit.Event{
+		Time:       start,
+		DurationMs: time.Since(start).Milliseconds(),
+		Tool:       "resolve_price",
+		Arguments:  audit.RawJSON(spec),
+	}
+	if err != nil {
+		event.Error = err.Error()
+		audit.RecordToolCall(ctx, event)
+		return nil, err
+	}
+	event.Result = audit.RawJSON(map[string]any{
+		"price":       obs.PriceString(),
+		"observation": obs,
+		"evidence":    obs.EvidenceURI(),
+	})
+	audit.RecordToolCall(ctx, event)
+
+	outcome, answer, verdict := uint64(0), "NO", "does not hold"
+	if spec.Comparator.Holds(obs.Price, threshold) {
+		outcome, answer, verdict = 1, "YES", "holds"
+	}
+
+	blockTime := time.Unix(obs.BlockTime, 0).UTC().Format(time.RFC3339)
+	statement := fmt.Sprintf("%s/%s was %s at block %d (%s) per %s %s",
+		spec.Asset, spec.Quote, obs.PriceString(), obs.Block, blockTime, obs.Feed.Source, obs.Feed.Address.Hex())
+	evidence := obs.EvidenceURI()
+
+	return &llm.Decision{
+		OutcomeID:  outcome,
+		Confidence: 1,
+		Reasoning:  fmt.Sprintf("%s. The condition %s %s, so the answer is %s.", statement, spec, verdict, answer),
+		Facts: []llm.Fact{{
+			Statement:          statement,
+			Sources:            []string{evidence},
+			Confidence:         1,
+			SupportingEvidence: obs.Detail,
+		}},
+		Citations: []llm.Citation{{
+			URL:     evidence,
+			Title:   fmt.Sprintf("%s %s/%s", obs.Feed.Source, spec.Asset, spec.Quote),
+			Snippet: obs.Detail,
+			Weight:  1,
+		}},
+		Timestamp: time.Now().Unix(),
+		Strategy:  StrategyName,
+	}, nil
+}
+
+// MarshalJSON includes the price as an exact decimal string
+func (o *Observation) MarshalJSON() ([]byte, error) {
+	type observation Observation
+	return json.Marshal(struct {
+		*observation
+		Price string `json:"price"`
+	}{(*observation)(o), o.PriceString()})
+}
+
+// blockAt returns the header of the last block at or before ts
+func (r *Resolver) blockAt(ctx context.Context, ts int64) (*types.Header, error) {
+	latest, err := r.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	if int64(latest.Time) < ts {
+		return nil, fmt.Errorf("%w: latest block %d is at %d, before %d", ErrNotObservable, latest.Number.Uint64(), latest.Time, ts)
+	}
+
+	// Binary search for the highest block with time <= ts
+	lo, hi := uint64(0), latest.Number.Uint64()
+	var found *types.Header
+	for lo <= hi {
+		mid := lo + (hi-lo)/2
+		header, err := r.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block %d: %w", mid, err)
+		}
+		if int64(header.Time) <= ts {
+			found = header
+			lo = mid + 1
+		} else {
+			if mid == 0 {
+				break
+			}
+			hi = mid - 1
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("chain starts after %d", ts)
+	}
+	return found, nil
+}
+
+// pow10 returns 10^n as a rational
+func pow10(n int) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil))
+}
@@ -0,0 +1,204 @@
+package pricefeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/project-gamma/ai-resolver/internal/llm"
+)
+
+var (
+	aggregator = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	pair       = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	wbnb       = common.HexToAddress("0x3000000000000000000000000000000000000003")
+	busd       = common.HexToAddress("0x4000000000000000000000000000000000000004")
+)
+
+// fakeChain is a Backend with a block every 3 seconds, a Chainlink
+// aggregator and a pair that has not traded since genesis
+type fakeChain struct {
+	genesis uint64
+	head    uint64
+
+	answer    func(block uint64) *big.Int // 8 decimals
+	updatedAt func(block uint64) uint64
+
+	token0, token1     common.Address
+	reserve0, reserve1 *big.Int
+}
+
+func newFakeChain() *fakeChain {
+	genesis := uint64(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC).Unix())
+	c := &fakeChain{
+		genesis:  genesis,
+		head:     40_000, // ~33 hours
+		token0:   wbnb,
+		token1:   busd,
+		reserve0: new(big.Int).Mul(big.NewInt(1_000), big.NewInt(1e18)),
+		reserve1: new(big.Int).Mul(big.NewInt(600_000), big.NewInt(1e18)),
+	}
+	c.answer = func(block uint64) *big.Int { return big.NewInt(69_000_000_000 + 1_000*int64(block)) }
+	c.updatedAt = func(block uint64) uint64 { return c.timeOf(block) - 30 }
+	return c
+}
+
+func (c *fakeChain) timeOf(block uint64) uint64 {
+	return c.genesis + 3*block
+}
+
+func (c *fakeChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	n := c.head
+	if number != nil {
+		n = number.Uint64()
+	}
+	if n > c.head {
+		return nil, fmt.Errorf("block %d not found", n)
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(n), Time: c.timeOf(n)}, nil
+}
+
+func (c *fakeChain) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method, err := parsedOracleABI.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	block := blockNumber.Uint64()
+
+	var out []any
+	switch {
+	case *msg.To == aggregator && method.Name == "decimals":
+		out = []any{uint8(8)}
+	case *msg.To == aggregator && method.Name == "latestRoundData":
+		updated := new(big.Int).SetUint64(c.updatedAt(block))
+		out = []any{big.NewInt(int64(block)), c.answer(block), updated, updated, big.NewInt(int64(block))}
+	case *msg.To == pair && method.Name == "token0":
+		out = []any{c.token0}
+	case *msg.To == pair && method.Name == "token1":
+		out = []any{c.token1}
+	case *msg.To == pair && method.Name == "getReserves":
+		out = []any{c.reserve0, c.reserve1, uint32(c.genesis)}
+	case *msg.To == pair && (method.Name == "price0CumulativeLast" || method.Name == "price1CumulativeLast"):
+		out = []any{big.NewInt(0)}
+	default:
+		return nil, fmt.Errorf("execution reverted: %s on %s", method.Name, msg.To.Hex())
+	}
+	return method.Outputs.Pack(out...)
+}
+
+func testFeeds() []Feed {
+	feeds := []Feed{
+		{ChainID: 56, Asset: "BNB", Source: SourceChainlink, Address: aggregator, MaxAgeSeconds: 3600},
+		{ChainID: 56, Asset: "BNB", Source: SourcePancakeSwap, Address: pair, BaseToken: wbnb, WindowSeconds: 1800},
+		{ChainID: 97, Asset: "ETH", Source: SourceChainlink, Address: aggregator},
+	}
+	for i := range feeds {
+		if err := feeds[i].normalize(); err != nil {
+			panic(err)
+		}
+	}
+	return feeds
+}
+
+// TestDecideChainlink tests resolving from an aggregator at the close block
+func TestDecideChainlink(t *testing.T) {
+	chain := newFakeChain()
+	resolver := NewResolver(chain, 56, testFeeds())
+
+	// 2025-03-01T23:59:59Z is 28799.67 blocks in, so block 28799 is read,
+	// where the answer is 690.28799
+	market := llm.MarketInfo{Question: "Will BNB close above $690.28 on 2025-03-01?", OutcomeCount: 2}
+	decision, err := resolver.Decide(context.Background(), market)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decision.OutcomeID != 1 || decision.Confidence != 1 || decision.Strategy != StrategyName {
+		t.Errorf("unexpected decision: %+v", decision)
+	}
+	if len(decision.Citations) != 1 || decision.Citations[0].URL != "eip155:56:"+aggregator.Hex()+"?block=28799" {
+		t.Errorf("unexpected citations: %+v", decision.Citations)
+	}
+	if !strings.Contains(decision.Reasoning, "690.28799000") {
+		t.Errorf("expected the observed price in the reasoning: %s", decision.Reasoning)
+	}
+
+	market.Question = "Will BNB close above $690.28799 on 2025-03-01?"
+	if decision, err = resolver.Decide(context.Background(), market); err != nil || decision.OutcomeID != 0 {
+		t.Errorf("expected NO for a price equal to the threshold, got %+v, %v", decision, err)
+	}
+	market.Question = "Will BNB close at or above $690.28799 on 2025-03-01?"
+	if decision, err = resolver.Decide(context.Background(), market); err != nil || decision.OutcomeID != 1 {
+		t.Errorf("expected YES for at or above, got %+v, %v", decision, err)
+	}
+}
+
+// TestDecidePancakeSwap tests the pair TWAP with either token order
+func TestDecidePancakeSwap(t *testing.T) {
+	chain := newFakeChain()
+	resolver := NewResolver(chain, 56, testFeeds())
+	market := llm.MarketInfo{Question: "Will BNB close below $600.01 on 2025-03-01 on PancakeSwap?"}
+
+	obs, err := resolver.Price(context.Background(), &Spec{
+		Asset: "BNB", Quote: "USD", Timestamp: int64(chain.timeOf(1000)), Source: SourcePancakeSwap,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if obs.Price.Cmp(big.NewRat(600, 1)) != 0 || !strings.HasPrefix(obs.Detail, "TWAP from block 400 to 1000") {
+		t.Errorf("expected a TWAP of 600 over 600 blocks, got %s (%s)", obs.PriceString(), obs.Detail)
+	}
+
+	decision, err := resolver.Decide(context.Background(), market)
+	if err != nil || decision.OutcomeID != 1 {
+		t.Fatalf("expected YES, got %+v, %v", decision, err)
+	}
+
+	// The same pair with WBNB as token1
+	chain.token0, chain.token1 = busd, wbnb
+	chain.reserve0, chain.reserve1 = chain.reserve1, chain.reserve0
+	if decision, err = resolver.Decide(context.Background(), market); err != nil || decision.OutcomeID != 1 {
+		t.Errorf("expected YES with swapped tokens, got %+v, %v", decision, err)
+	}
+
+	chain.token1 = common.HexToAddress("0x5")
+	if _, err = resolver.Decide(context.Background(), market); err == nil || !strings.Contains(err.Error(), "does not hold") {
+		t.Errorf("expected an error for a pair without the base token, got %v", err)
+	}
+}
+
+// TestDecideErrors tests which failures fall back to the LLM pipeline
+func TestDecideErrors(t *testing.T) {
+	chain := newFakeChain()
+	resolver := NewResolver(chain, 56, testFeeds())
+	decide := func(question string) error {
+		_, err := resolver.Decide(context.Background(), llm.MarketInfo{Question: question, OutcomeCount: 2})
+		return err
+	}
+
+	for _, question := range []string{
+		"Will it rain in London tomorrow?",
+		"Will ETH close above $3000 on 2025-03-01?", // ETH has a feed on chain 97 only
+	} {
+		if err := decide(question); !errors.Is(err, ErrNotApplicable) {
+			t.Errorf("%q: expected ErrNotApplicable, got %v", question, err)
+		}
+	}
+
+	if err := decide("Will BNB close above $700 on 2025-03-05?"); !errors.Is(err, ErrNotObservable) {
+		t.Errorf("expected ErrNotObservable for a time after the head, got %v", err)
+	}
+
+	chain.updatedAt = func(block uint64) uint64 { return chain.genesis }
+	err := decide("Will BNB close above $700 on 2025-03-01?")
+	if err == nil || errors.Is(err, ErrNotApplicable) || !strings.Contains(err.Error(), "stale") {
+		t.Errorf("expected a stale round error, got %v", err)
+	}
+}
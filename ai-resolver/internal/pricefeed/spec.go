@@ -0,0 +1,258 @@
+// Package pricefeed resolves crypto price markets deterministically from
+// on-chain oracle data instead of the LLM pipeline.
+//
+// A question such as "Will BNB close above $700 on 2025-03-01?" is parsed into
+// a Spec (asset, comparator, threshold, timestamp, source), which a Resolver
+// answers from a Chainlink aggregator or a PancakeSwap pair's cumulative price
+// at the last block at or before the timestamp. The same chain state always
+// yields the same decision.
+package pricefeed
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Comparator relates the observed price to the threshold
+type Comparator string
+
+const (
+	Above        Comparator = ">"
+	AboveOrEqual Comparator = ">="
+	Below        Comparator = "<"
+	BelowOrEqual Comparator = "<="
+)
+
+// Holds reports whether price compares to threshold as c requires
+func (c Comparator) Holds(price, threshold *big.Rat) bool {
+	cmp := price.Cmp(threshold)
+	switch c {
+	case Above:
+		return cmp > 0
+	case AboveOrEqual:
+		return cmp >= 0
+	case Below:
+		return cmp < 0
+	case BelowOrEqual:
+		return cmp <= 0
+	}
+	return false
+}
+
+// Source is an on-chain price source
+type Source string
+
+const (
+	SourceChainlink   Source = "chainlink"
+	SourcePancakeSwap Source = "pancakeswap"
+)
+
+// Spec is a structured price question: is the Asset/Quote price, observed at
+// Timestamp, Comparator Threshold?
+type Spec struct {
+	Asset      string     `json:"asset"`            // Canonical symbol, e.g. "BNB"
+	Quote      string     `json:"quote"`            // "USD"; USD stablecoins count as USD
+	Comparator Comparator `json:"comparator"`       // ">", ">=", "<" or "<="
+	Threshold  string     `json:"threshold"`        // Exact decimal, e.g. "100000"
+	Timestamp  int64      `json:"timestamp"`        // Unix time the price is observed at
+	Source     Source     `json:"source,omitempty"` // Empty: any configured feed, Chainlink first
+}
+
+// ThresholdRat returns the threshold as an exact rational
+func (s Spec) ThresholdRat() (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(s.Threshold)
+	if !ok {
+		return nil, fmt.Errorf("invalid threshold %q", s.Threshold)
+	}
+	return r, nil
+}
+
+// Validate checks that the spec names an asset, a known comparator and an
+// observation time, e.g. when it was authored by hand rather than parsed
+func (s Spec) Validate() error {
+	switch {
+	case s.Asset == "" || s.Quote == "":
+		return fmt.Errorf("asset and quote are required")
+	case s.Timestamp <= 0:
+		return fmt.Errorf("timestamp is required")
+	}
+	switch s.Comparator {
+	case Above, AboveOrEqual, Below, BelowOrEqual:
+	default:
+		return fmt.Errorf("unknown comparator %q", s.Comparator)
+	}
+	switch s.Source {
+	case "", SourceChainlink, SourcePancakeSwap:
+	default:
+		return fmt.Errorf("unknown source %q", s.Source)
+	}
+	return nil
+}
+
+// String renders the spec as a condition, e.g. "BNB/USD > 700 at 2025-03-01T23:59:59Z"
+func (s Spec) String() string {
+	return fmt.Sprintf("%s/%s %s %s at %s", s.Asset, s.Quote, s.Comparator, s.Threshold,
+		time.Unix(s.Timestamp, 0).UTC().Format(time.RFC3339))
+}
+
+// ErrNotPriceQuestion is returned by Parse for questions that are not
+// point-in-time price thresholds, which are left to the LLM pipeline
+var ErrNotPriceQuestion = errors.New("not a price threshold question")
+
+// assetAliases maps names used in questions to canonical symbols
+var assetAliases = map[string]string{
+	"bnb": "BNB", "wbnb": "BNB", "binance coin": "BNB",
+	"btc": "BTC", "btcb": "BTC", "bitcoin": "BTC", "xbt": "BTC",
+	"eth": "ETH", "weth": "ETH", "ether": "ETH", "ethereum": "ETH",
+	"cake": "CAKE", "pancakeswap": "CAKE",
+}
+
+var comparators = map[string]Comparator{
+	"above": Above, "over": Above, "higher than": Above, "greater than": Above, "more than": Above,
+	"below": Below, "under": Below, "lower than": Below, "less than": Below,
+	"at or above": AboveOrEqual, "at least": AboveOrEqual,
+	"at or below": BelowOrEqual, "at most": BelowOrEqual,
+}
+
+var (
+	// Point-in-time questions only: "reach" or "trade above" are about any
+	// moment in a period and are left to the LLM
+	questionPattern = regexp.MustCompile(`(?i)^\s*will\s+(?:the\s+)?(?:price\s+of\s+(?:one\s+|1\s+)?)?` +
+		`(binance coin|[a-z][a-z0-9]{1,9})(?:\s*/\s*(?:usdt?|busd))?(?:'s\s+price|\s+price)?\s+` +
+		`(?:(close|settle|end|finish)(?:\s+the\s+day)?|be(?:\s+trading)?)\s+` +
+		`(at or above|at or below|at least|at most|above|over|higher than|greater than|more than|below|under|lower than|less than)\s+` +
+		`(\$)?\s*([0-9][0-9,]*(?:\.[0-9]+)?)\s*([km])?\b\s*(usdt?|busd|dollars)?`)
+
+	// Zones are matched broadly so that non-UTC times are rejected, not misread
+	zonePattern      = `(utc|gmt|z|[ecmp][sd]?t|cest?|bst|ist|jst|sgt|hkt|kst|aest)\b`
+	isoDatePattern   = regexp.MustCompile(`(?i)\b(\d{4}-\d{2}-\d{2})(?:(?:T|,?\s+(?:at\s+)?)(\d{1,2}:\d{2})(?::(\d{2}))?)?(?:\s*` + zonePattern + `)?`)
+	namedDatePattern = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})(?:,?\s+(?:at\s+)?(\d{1,2}:\d{2})(?:\s*` + zonePattern + `)?)?`)
+	sourcePattern    = regexp.MustCompile(`(?i)\b(chainlink|pancakeswap)\b`)
+)
+
+// Parse turns a price question into a Spec. Without a date in the question
+// the price is observed at closeTime; a date without a time means the UTC
+// daily close, 23:59:59. Questions that name a time must give it in UTC.
+func Parse(question string, closeTime int64) (*Spec, error) {
+	m := questionPattern.FindStringSubmatch(question)
+	if m == nil {
+		return nil, ErrNotPriceQuestion
+	}
+	asset, ok := assetAliases[strings.ToLower(m[1])]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown asset %q", ErrNotPriceQuestion, m[1])
+	}
+	if m[4] == "" && m[7] == "" {
+		return nil, fmt.Errorf("%w: threshold has no currency", ErrNotPriceQuestion)
+	}
+
+	threshold, err := parseAmount(m[5], m[6])
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &Spec{
+		Asset:      asset,
+		Quote:      "USD",
+		Comparator: comparators[strings.ToLower(m[3])],
+		Threshold:  threshold,
+		Timestamp:  closeTime,
+	}
+
+	// The date must come after the threshold, e.g. "... above $700 on 2025-03-01"
+	rest := question[len(m[0]):]
+	ts, found, err := parseTime(rest)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		spec.Timestamp = ts
+	}
+	if spec.Timestamp <= 0 {
+		return nil, fmt.Errorf("question has no date and the market has no close time")
+	}
+
+	if s := sourcePattern.FindStringSubmatch(rest); s != nil {
+		spec.Source = Source(strings.ToLower(s[1]))
+	}
+
+	return spec, nil
+}
+
+// parseAmount normalizes "1,234.5" with an optional k/m suffix to a decimal
+func parseAmount(digits, suffix string) (string, error) {
+	r, ok := new(big.Rat).SetString(strings.ReplaceAll(digits, ",", ""))
+	if !ok {
+		return "", fmt.Errorf("invalid threshold %q", digits)
+	}
+	switch strings.ToLower(suffix) {
+	case "k":
+		r.Mul(r, big.NewRat(1_000, 1))
+	case "m":
+		r.Mul(r, big.NewRat(1_000_000, 1))
+	}
+	return r.FloatString(decimalsOf(r)), nil
+}
+
+// decimalsOf returns the digits after the point needed to print r exactly
+func decimalsOf(r *big.Rat) int {
+	for n := 0; n < 30; n++ {
+		scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)))
+		if scaled.IsInt() {
+			return n
+		}
+	}
+	return 30
+}
+
+// parseTime finds the observation time in the text after the threshold
+func parseTime(text string) (int64, bool, error) {
+	if m := isoDatePattern.FindStringSubmatch(text); m != nil {
+		day, err := time.Parse(time.DateOnly, m[1])
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid date %q: %w", m[1], err)
+		}
+		seconds := m[3]
+		if seconds == "" {
+			seconds = "00"
+		}
+		return observationTime(day, m[2], seconds, m[4])
+	}
+
+	if m := namedDatePattern.FindStringSubmatch(text); m != nil {
+		day, err := time.Parse("Jan 2 2006", fmt.Sprintf("%s%s %s %s", strings.ToUpper(m[1][:1]), strings.ToLower(m[1][1:3]), m[2], m[3]))
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid date %q: %w", m[0], err)
+		}
+		return observationTime(day, m[4], "00", m[5])
+	}
+
+	return 0, false, nil
+}
+
+// observationTime combines a UTC day with an optional "15:04" time
+func observationTime(day time.Time, clock, seconds, zone string) (int64, bool, error) {
+	if clock == "" {
+		// The daily close
+		return day.Add(24*time.Hour - time.Second).Unix(), true, nil
+	}
+
+	switch strings.ToLower(zone) {
+	case "utc", "gmt", "z":
+	case "":
+		return 0, false, fmt.Errorf("time %s has no timezone; price questions must use UTC", clock)
+	default:
+		return 0, false, fmt.Errorf("unsupported timezone %q; price questions must use UTC", zone)
+	}
+
+	t, err := time.Parse("15:04:05", fmt.Sprintf("%05s:%s", clock, seconds))
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid time %q: %w", clock, err)
+	}
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	return day.Add(offset).Unix(), true, nil
+}
@@ -0,0 +1,76 @@
+package pricefeed
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParse tests parsing price questions into specs
+func TestParse(t *testing.T) {
+	closeTime := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC).Unix()
+	utc := func(s string) int64 {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts.Unix()
+	}
+
+	tests := []struct {
+		question string
+		want     Spec
+	}{
+		{
+			"Will BNB close above $700 on 2025-03-01?",
+			Spec{Asset: "BNB", Quote: "USD", Comparator: Above, Threshold: "700", Timestamp: utc("2025-03-01T23:59:59Z")},
+		},
+		{
+			"Will the price of Bitcoin be at or above $100k on March 1st, 2025 at 12:00 UTC?",
+			Spec{Asset: "BTC", Quote: "USD", Comparator: AboveOrEqual, Threshold: "100000", Timestamp: utc("2025-03-01T12:00:00Z")},
+		},
+		{
+			"Will ETH/USD settle below 1,234.50 USDT at 2025-03-01T08:30Z according to Chainlink?",
+			Spec{Asset: "ETH", Quote: "USD", Comparator: Below, Threshold: "1234.5", Timestamp: utc("2025-03-01T08:30:00Z"), Source: SourceChainlink},
+		},
+		{
+			"Will CAKE be trading under $2.5 at market close on PancakeSwap?",
+			Spec{Asset: "CAKE", Quote: "USD", Comparator: Below, Threshold: "2.5", Timestamp: closeTime, Source: SourcePancakeSwap},
+		},
+		{
+			"Will BTC price end at most $1.2m on Dec 31, 2025?",
+			Spec{Asset: "BTC", Quote: "USD", Comparator: BelowOrEqual, Threshold: "1200000", Timestamp: utc("2025-12-31T23:59:59Z")},
+		},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.question, closeTime)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.question, err)
+			continue
+		}
+		if *got != tt.want {
+			t.Errorf("%q:\nexpected %+v\ngot      %+v", tt.question, tt.want, *got)
+		}
+	}
+
+	for _, question := range []string{
+		"Will Team A win the championship?",
+		"Will BNB reach $1000 before July?",
+		"Will SOL close above $200 on 2025-03-01?",
+		"Will BNB close above 700 on 2025-03-01?",
+	} {
+		if _, err := Parse(question, closeTime); !errors.Is(err, ErrNotPriceQuestion) {
+			t.Errorf("%q: expected ErrNotPriceQuestion, got %v", question, err)
+		}
+	}
+
+	for question, want := range map[string]string{
+		"Will BNB close above $700 on 2025-03-01 at 12:00?":  "no timezone",
+		"Will BNB be above $700 on March 1, 2025 at 9:00 ET": "unsupported timezone",
+	} {
+		if _, err := Parse(question, closeTime); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected %q error, got %v", question, want, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package pricefeed
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/project-gamma/ai-resolver/internal/llm"
+)
+
+// StageName is the name pipeline layouts use for on-chain price resolution
+const StageName = "resolve_price"
+
+// SpecSource is market metadata that may hold an authored price spec;
+// *metadata.Document implements it
+type SpecSource interface {
+	PriceSpec() *Spec
+}
+
+// Stage is a pipeline stage that answers price markets from the chain before
+// any model call. It reads the metadata and the parent check, so it runs after
+// both: a conditional price market is only priced once its condition holds.
+type Stage struct {
+	resolver *Resolver
+}
+
+// NewStage creates a price stage around a chain's resolver
+func NewStage(resolver *Resolver) *Stage {
+	return &Stage{resolver: resolver}
+}
+
+// Name implements llm.Stage
+func (s *Stage) Name() string { return StageName }
+
+// Inputs implements llm.Stage
+func (s *Stage) Inputs() []llm.Artifact {
+	return []llm.Artifact{llm.ArtifactMarket, llm.ArtifactMetadata, llm.ArtifactParent}
+}
+
+// Outputs implements llm.Stage
+func (s *Stage) Outputs() []llm.Artifact { return nil }
+
+// Run resolves the market from the price spec in its metadata or, without
+// one, from its question. Markets that are not price questions, or have no
+// feed on the chain, skip the stage and go on to the model.
+func (s *Stage) Run(ctx context.Context, a *llm.Analysis) error {
+	var decision *llm.Decision
+	var err error
+	if spec := authoredSpec(a); spec != nil {
+		decision, err = s.resolver.DecideSpec(ctx, a.Market, spec)
+	} else {
+		decision, err = s.resolver.Decide(ctx, a.Market)
+	}
+	if errors.Is(err, ErrNotApplicable) {
+		return llm.ErrStageSkipped
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("On-chain price decision: outcomeId=%d (%s)", decision.OutcomeID, decision.Reasoning)
+	a.Decision = decision
+	return llm.ErrResolved
+}
+
+// authoredSpec returns the price spec from the market's metadata, if any
+func authoredSpec(a *llm.Analysis) *Spec {
+	if doc, ok := a.Artifacts[llm.ArtifactMetadata].(SpecSource); ok {
+		return doc.PriceSpec()
+	}
+	return nil
+}
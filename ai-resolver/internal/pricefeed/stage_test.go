@@ -0,0 +1,66 @@
+package pricefeed
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/project-gamma/ai-resolver/internal/llm"
+)
+
+// authored is metadata holding a price spec
+type authored struct{ spec *Spec }
+
+func (d authored) PriceSpec() *Spec { return d.spec }
+
+// TestStage tests that the authored spec resolves the market, that the
+// question is parsed without one, and that other markets go on to the model
+func TestStage(t *testing.T) {
+	stage := NewStage(NewResolver(newFakeChain(), 56, testFeeds()))
+	if inputs := stage.Inputs(); !slices.Contains(inputs, llm.ArtifactMetadata) || !slices.Contains(inputs, llm.ArtifactParent) {
+		t.Fatalf("expected the stage to run after the metadata and the parent check, got inputs %v", inputs)
+	}
+
+	run := func(question string, metadata any) (*llm.Decision, error) {
+		a := &llm.Analysis{
+			Market:    llm.MarketInfo{Question: question, OutcomeCount: 2},
+			Artifacts: map[llm.Artifact]any{},
+		}
+		if metadata != nil {
+			a.Artifacts[llm.ArtifactMetadata] = metadata
+		}
+		err := stage.Run(context.Background(), a)
+		return a.Decision, err
+	}
+
+	// The price at the 2025-03-01 close is 690.28799: NO by the question's
+	// $700, YES by the authored $690
+	question := "Will BNB close above $700 on 2025-03-01?"
+	decision, err := run(question, nil)
+	if !errors.Is(err, llm.ErrResolved) || decision.OutcomeID != 0 {
+		t.Errorf("expected the question to resolve NO, got %+v, %v", decision, err)
+	}
+	spec := &Spec{
+		Asset:      "BNB",
+		Quote:      "USD",
+		Comparator: Above,
+		Threshold:  "690",
+		Timestamp:  time.Date(2025, 3, 1, 23, 59, 59, 0, time.UTC).Unix(),
+	}
+	decision, err = run(question, authored{spec})
+	if !errors.Is(err, llm.ErrResolved) || decision.OutcomeID != 1 || decision.Strategy != StrategyName {
+		t.Errorf("expected the authored spec to resolve YES, got %+v, %v", decision, err)
+	}
+
+	if _, err := run("Will it rain in London tomorrow?", authored{}); !errors.Is(err, llm.ErrStageSkipped) {
+		t.Errorf("expected a non-price market to skip the stage, got %v", err)
+	}
+
+	bad := *spec
+	bad.Comparator = "~"
+	if _, err := run(question, authored{&bad}); err == nil || errors.Is(err, llm.ErrStageSkipped) {
+		t.Errorf("expected an invalid authored spec to fail the stage, got %v", err)
+	}
+}
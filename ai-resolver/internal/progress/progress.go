@@ -0,0 +1,101 @@
+// Package progress reports what a resolution is doing while it runs: pipeline
+// steps, tool calls, the signature, the bond approval and the proposal
+// transaction. Reporters travel in the context like audit runs; code emits
+// events without checking whether anyone listens.
+package progress
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Type is the kind of an event
+type Type string
+
+const (
+	TypeRun         Type = "run"         // The run started; Data has runId, chainId, marketId
+	TypeMarket      Type = "market"      // Market details fetched
+	TypeStep        Type = "step"        // A pipeline step (llm.AnalysisStep) started or finished
+	TypeToolCall    Type = "tool_call"   // The model called a tool
+	TypeDecision    Type = "decision"    // The outcome was decided
+	TypeSignature   Type = "signature"   // The proposal was signed
+	TypeApproval    Type = "approval"    // The bond allowance was checked or approved
+	TypeTransaction Type = "transaction" // The proposal transaction was broadcast or confirmed
+	TypeResult      Type = "result"      // Final response, as the non-streaming endpoint returns it
+	TypeError       Type = "error"       // The run failed
+)
+
+// Status is where an event's subject stands
+type Status string
+
+const (
+	StatusStarted   Status = "started"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusSkipped   Status = "skipped"
+	StatusBroadcast Status = "broadcast"
+	StatusConfirmed Status = "confirmed"
+)
+
+// Event is one progress update
+type Event struct {
+	Seq    int            `json:"seq"`  // Position in the run, from 1
+	Time   int64          `json:"time"` // Unix milliseconds
+	Type   Type           `json:"type"`
+	Status Status         `json:"status,omitempty"`
+	Step   string         `json:"step,omitempty"` // Pipeline step the event belongs to
+	Name   string         `json:"name,omitempty"` // Tool name for tool calls
+	Data   map[string]any `json:"data,omitempty"`
+}
+
+// Reporter numbers events and hands them to a sink. It is safe for
+// concurrent use; the sink is called with one event at a time.
+type Reporter struct {
+	mu   sync.Mutex
+	seq  int
+	sink func(Event)
+}
+
+// NewReporter creates a reporter that passes every event to sink
+func NewReporter(sink func(Event)) *Reporter {
+	return &Reporter{sink: sink}
+}
+
+// Emit stamps and delivers an event. It is a no-op on a nil reporter.
+func (r *Reporter) Emit(e Event) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	e.Seq = r.seq
+	if e.Time == 0 {
+		e.Time = time.Now().UnixMilli()
+	}
+	r.sink(e)
+}
+
+type reporterKey struct{}
+
+// WithReporter attaches a reporter to ctx
+func WithReporter(ctx context.Context, r *Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, r)
+}
+
+// FromContext returns the reporter attached to ctx, or nil
+func FromContext(ctx context.Context) *Reporter {
+	r, _ := ctx.Value(reporterKey{}).(*Reporter)
+	return r
+}
+
+// Emit delivers an event to the reporter attached to ctx, if any
+func Emit(ctx context.Context, e Event) {
+	FromContext(ctx).Emit(e)
+}
+
+// Step reports a pipeline step's status with optional details
+func Step(ctx context.Context, step string, status Status, data map[string]any) {
+	Emit(ctx, Event{Type: TypeStep, Status: status, Step: step, Data: data})
+}
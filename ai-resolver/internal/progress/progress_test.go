@@ -0,0 +1,67 @@
+package progress
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestReporter tests numbering events and the nil-safe context helpers
+func TestReporter(t *testing.T) {
+	// Nothing listens: emitting is a no-op
+	Step(context.Background(), "extract_facts", StatusStarted, nil)
+
+	var events []Event
+	ctx := WithReporter(context.Background(), NewReporter(func(e Event) { events = append(events, e) }))
+	Step(ctx, "extract_facts", StatusStarted, nil)
+	Emit(ctx, Event{Type: TypeToolCall, Status: StatusCompleted, Name: "get_market_info"})
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	for i, e := range events {
+		if e.Seq != i+1 || e.Time == 0 {
+			t.Errorf("event %d: unexpected seq %d or time %d", i, e.Seq, e.Time)
+		}
+	}
+	if events[0].Type != TypeStep || events[0].Step != "extract_facts" || events[1].Name != "get_market_info" {
+		t.Errorf("unexpected events %+v", events)
+	}
+}
+
+// TestSSEWriter tests the event stream framing
+func TestSSEWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sse := NewSSEWriter(rec)
+	if err := sse.Write(Event{Seq: 3, Time: 1, Type: TypeStep, Status: StatusStarted, Step: "decide_outcome"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Header().Get("Content-Type") != "text/event-stream" || !rec.Flushed {
+		t.Errorf("expected a flushed event stream, got %v", rec.Header())
+	}
+	want := "id: 3\nevent: step\ndata: {\"seq\":3,\"time\":1,\"type\":\"step\",\"status\":\"started\",\"step\":\"decide_outcome\"}\n\n"
+	if rec.Body.String() != want {
+		t.Errorf("unexpected frame %q", rec.Body.String())
+	}
+}
+
+// TestAccepts tests detecting a request for an event stream
+func TestAccepts(t *testing.T) {
+	for accept, want := range map[string]bool{
+		"text/event-stream":                         true,
+		"application/json, text/event-stream;q=0.9": true,
+		"application/json":                          false,
+		"":                                          false,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/v1/propose", strings.NewReader("{}"))
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		if got := Accepts(r); got != want {
+			t.Errorf("%q: expected %v, got %v", accept, want, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSEWriter streams events to an HTTP client as server-sent events:
+//
+//	id: 3
+//	event: step
+//	data: {"seq":3,"time":...,"type":"step","status":"started","step":"extract_facts"}
+type SSEWriter struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// NewSSEWriter starts an event stream response. It lifts the server's write
+// deadline, since a proposal runs for longer than a normal response may take.
+func NewSSEWriter(w http.ResponseWriter) *SSEWriter {
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{}) // Not every writer supports deadlines
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // Keep nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	return &SSEWriter{w: w, rc: rc}
+}
+
+// Write sends one event and flushes it to the client
+func (s *SSEWriter) Write(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprintf(s.w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Type, data); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// KeepAlive sends a comment line every interval until ctx is done, so
+// proxies do not close the connection during long model calls
+func (s *SSEWriter) KeepAlive(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			_, err := fmt.Fprint(s.w, ": keep-alive\n\n")
+			if err == nil {
+				err = s.rc.Flush()
+			}
+			s.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Accepts reports whether a request asked for an event stream
+func Accepts(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		if containsMediaType(accept, "text/event-stream") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsMediaType(header, mediaType string) bool {
+	for len(header) > 0 {
+		var part string
+		part, header, _ = strings.Cut(header, ",")
+		if t, _, _ := strings.Cut(part, ";"); strings.TrimSpace(t) == mediaType {
+			return true
+		}
+	}
+	return false
+}
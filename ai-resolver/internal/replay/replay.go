@@ -0,0 +1,244 @@
+// Package replay re-runs a recorded resolution from its audit record and
+// compares the new decision with the original.
+//
+// By default the recorded API responses and tool results are served back to the
+// pipeline, so the replay is deterministic and checks that the current code
+// reaches the same decision from the same evidence. In live mode the model is
+// queried again while tool calls are still answered from the record.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/dependent"
+	"github.com/project-gamma/ai-resolver/internal/httprec"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+	"github.com/project-gamma/ai-resolver/internal/metadata"
+	"github.com/project-gamma/ai-resolver/internal/pricefeed"
+)
+
+// Options control how a run is replayed
+type Options struct {
+	Live    bool   // Query the model instead of serving recorded responses
+	APIKey  string // Required in live mode
+	Model   string // Overrides the recorded model in live mode
+	BaseURL string // Overrides the OpenAI API base URL in live mode
+}
+
+// Result is the outcome of a replay
+type Result struct {
+	Original *llm.Decision // Nil if the original run failed
+	Replayed *llm.Decision // Nil if the replay failed
+	Err      error         // Why the replay failed, if it did
+	Run      *audit.Run    // Audit record of the replay itself
+	Diffs    []Diff
+}
+
+// Diff is a decision field that differs between the original and the replay
+type Diff struct {
+	Field    string `json:"field"`
+	Original string `json:"original"`
+	Replayed string `json:"replayed"`
+}
+
+// Replay re-runs the analysis recorded in original
+func Replay(ctx context.Context, original *audit.Run, opts Options) (*Result, error) {
+	if len(original.Market) == 0 {
+		return nil, fmt.Errorf("run %s has no market record", original.ID)
+	}
+	var market llm.MarketInfo
+	if err := json.Unmarshal(original.Market, &market); err != nil {
+		return nil, fmt.Errorf("failed to parse recorded market: %w", err)
+	}
+
+	var originalDecision *llm.Decision
+	if len(original.Decision) > 0 {
+		originalDecision = new(llm.Decision)
+		if err := json.Unmarshal(original.Decision, originalDecision); err != nil {
+			return nil, fmt.Errorf("failed to parse recorded decision: %w", err)
+		}
+		if originalDecision.Strategy == pricefeed.StrategyName {
+			// No LLM traffic to replay; the chain state read is immutable
+			return nil, fmt.Errorf("run %s was resolved from on-chain prices, not by the model", original.ID)
+		}
+	}
+
+	model := original.Model
+	if opts.Live && opts.Model != "" {
+		model = opts.Model
+	}
+
+	pipeline := llm.NewOpenAIPipeline(opts.APIKey, model)
+	if opts.Live {
+		if opts.BaseURL != "" {
+			pipeline.SetBaseURL(opts.BaseURL)
+		}
+	} else {
+		// Serve the recorded responses from the endpoints they came from
+		if base := recordedBaseURL(original); base != "" {
+			pipeline.SetBaseURL(base)
+		}
+		pipeline.SetTransport(httprec.NewReplayer(Cassette(original), "run "+original.ID))
+	}
+	if err := applySettings(pipeline, original); err != nil {
+		return nil, err
+	}
+	if tools := newRecordedTools(original); len(tools.names) > 0 {
+		pipeline.SetToolRegistry(tools)
+	}
+	if checks := newRecordedVerifier(original); checks != nil {
+		pipeline.SetVerifier(checks)
+	}
+
+	run := audit.NewRun(original.ChainID, original.MarketID, model)
+	run.DryRun = true
+	run.ReplayOf = original.ID
+	run.SetMarket(market)
+
+	decision, err := pipeline.AnalyzeMarket(audit.WithRun(ctx, run), market)
+	if err == nil {
+		run.SetDecision(decision)
+	}
+	run.Finish(err)
+
+	result := &Result{
+		Original: originalDecision,
+		Replayed: decision,
+		Err:      err,
+		Run:      run,
+		Diffs:    Compare(originalDecision, decision),
+	}
+
+	replayedErr := ""
+	if err != nil {
+		replayedErr = err.Error()
+	}
+	if original.Error != replayedErr {
+		result.Diffs = append(result.Diffs, Diff{Field: "error", Original: original.Error, Replayed: replayedErr})
+	}
+
+	return result, nil
+}
+
+// Cassette rebuilds the API traffic of a run as an HTTP cassette. Calls that
+// failed before a response arrived are left out.
+func Cassette(run *audit.Run) httprec.Cassette {
+	var cassette httprec.Cassette
+	for _, e := range run.EventsOf(audit.EventLLMCall) {
+		if e.Status == 0 {
+			continue
+		}
+
+		// Non-JSON bodies are stored as JSON strings
+		body := string(e.Response)
+		var s string
+		if json.Unmarshal(e.Response, &s) == nil {
+			body = s
+		}
+
+		cassette.Interactions = append(cassette.Interactions, httprec.Interaction{
+			Request: httprec.Request{Method: http.MethodPost, URL: e.Endpoint},
+			Response: httprec.Response{
+				Status:  e.Status,
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    body,
+			},
+		})
+	}
+	return cassette
+}
+
+// applySettings gives the pipeline the strategies, prompts, credibility tiers,
+// stage layout and options the run was analyzed with. The stages that fetch
+// market metadata, read parent markets and read on-chain prices are left out
+// of the layout, since they are registered by the server and reach outside
+// the recorded traffic.
+// Runs recorded without settings only have the adversarial review to go by.
+func applySettings(pipeline *llm.OpenAIPipeline, run *audit.Run) error {
+	if len(run.Settings) == 0 {
+		pipeline.SetChallenge(challenged(run))
+		return nil
+	}
+	var settings llm.Settings
+	if err := json.Unmarshal(run.Settings, &settings); err != nil {
+		return fmt.Errorf("failed to parse recorded settings: %w", err)
+	}
+	if settings.Stages != nil {
+		settings.Stages = settings.Stages.Without(metadata.StageName, dependent.StageName, pricefeed.StageName)
+	}
+	return pipeline.ApplySettings(settings)
+}
+
+// challenged reports whether the run went through the adversarial stage
+func challenged(run *audit.Run) bool {
+	for _, e := range run.EventsOf(audit.EventLLMCall) {
+		if e.Step == string(llm.StepChallengeOutcome) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordedBaseURL returns the API base URL the run talked to
+func recordedBaseURL(run *audit.Run) string {
+	for _, e := range run.EventsOf(audit.EventLLMCall) {
+		for _, path := range []string{"/responses", "/chat/completions"} {
+			if base, ok := strings.CutSuffix(e.Endpoint, path); ok {
+				return base
+			}
+		}
+	}
+	return ""
+}
+
+// Compare lists the decision fields that differ. The timestamp is ignored.
+func Compare(original, replayed *llm.Decision) []Diff {
+	a, b := decisionFields(original), decisionFields(replayed)
+
+	var diffs []Diff
+	for i := range a {
+		if a[i][1] != b[i][1] {
+			diffs = append(diffs, Diff{Field: a[i][0], Original: a[i][1], Replayed: b[i][1]})
+		}
+	}
+	return diffs
+}
+
+// decisionFields renders the compared fields of a decision in a fixed order
+func decisionFields(d *llm.Decision) [][2]string {
+	if d == nil {
+		return [][2]string{{"outcomeId", ""}, {"confidence", ""}, {"reasoning", ""}, {"facts", ""}, {"citations", ""}, {"prompt", ""}, {"challenge", ""}}
+	}
+
+	facts := make([]string, 0, len(d.Facts))
+	for _, f := range d.Facts {
+		facts = append(facts, f.Statement)
+	}
+	citations := make([]string, 0, len(d.Citations))
+	for _, c := range d.Citations {
+		citations = append(citations, fmt.Sprintf("%s (%.4f)", c.URL, c.Weight))
+	}
+	prompt := ""
+	if d.Prompt != nil {
+		prompt = d.Prompt.Version + " " + d.Prompt.Hash
+	}
+	challenge := ""
+	if d.Challenge != nil {
+		challenge = fmt.Sprintf("%s (%.4f)", d.Challenge.Verdict, d.Challenge.OriginalConfidence)
+	}
+
+	return [][2]string{
+		{"outcomeId", fmt.Sprint(d.OutcomeID)},
+		{"confidence", fmt.Sprintf("%.4f", d.Confidence)},
+		{"reasoning", d.Reasoning},
+		{"facts", strings.Join(facts, "\n")},
+		{"citations", strings.Join(citations, "\n")},
+		{"prompt", prompt},
+		{"challenge", challenge},
+	}
+}
@@ -0,0 +1,181 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+	"github.com/project-gamma/ai-resolver/internal/llm/llmtest"
+)
+
+// recordRun runs the scripted pipeline under an audit run
+func recordRun(t *testing.T) *audit.Run {
+	t.Helper()
+
+	server := llmtest.NewServer(llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.8,
+		Reasoning:  "Reported by the source",
+		Facts: []llm.Fact{{
+			Statement:  "The event happened",
+			Sources:    []string{"https://example.com/a"},
+			Confidence: 0.9,
+		}},
+	}, []llm.WebSource{{URL: "https://example.com/a", Title: "A"}})...)
+	defer server.Close()
+
+	market := llm.MarketInfo{MarketID: 3, Question: "Did it happen?", OutcomeCount: 2}
+	run := audit.NewRun(56, market.MarketID, "test-model")
+	run.SetMarket(market)
+
+	decision, err := server.Pipeline().AnalyzeMarket(audit.WithRun(context.Background(), run), market)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run.SetDecision(decision)
+	run.Finish(nil)
+	return run
+}
+
+// TestReplayMatches tests that an offline replay reproduces the decision
+func TestReplayMatches(t *testing.T) {
+	run := recordRun(t)
+	if calls := run.EventsOf(audit.EventLLMCall); len(calls) != 3 || calls[0].Prompt == "" || calls[0].Step != string(llm.StepExtractFacts) {
+		t.Fatalf("expected 3 recorded LLM calls with prompts, got %+v", calls)
+	}
+
+	result, err := Replay(context.Background(), run, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Err != nil {
+		t.Fatalf("replay failed: %v", result.Err)
+	}
+	if len(result.Diffs) != 0 {
+		t.Errorf("expected no diffs, got %+v", result.Diffs)
+	}
+	if result.Run.ReplayOf != run.ID || len(result.Run.EventsOf(audit.EventLLMCall)) != 3 {
+		t.Errorf("expected the replay to be recorded, got %+v", result.Run)
+	}
+}
+
+// TestReplayStrategy tests that a replay resolves with the recorded strategy,
+// not the built-in one for the market's category
+func TestReplayStrategy(t *testing.T) {
+	server := llmtest.NewServer(llmtest.Script(llm.Decision{
+		OutcomeID:  1,
+		Confidence: 0.8,
+		Reasoning:  "Reported by the source",
+	}, []llm.WebSource{{URL: "https://example.com/a", Title: "A"}})...)
+	defer server.Close()
+
+	strategies, err := llm.NewStrategies([]llm.Strategy{{
+		Name:       "weather",
+		Categories: []string{"rain"},
+		Confidence: llm.ConfidencePolicy{MinConfidence: 0.9},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline := server.Pipeline()
+	pipeline.SetStrategies(strategies)
+	pipeline.SetHostedSearch(false)
+
+	market := llm.MarketInfo{MarketID: 4, Question: "Will it rain?", Category: "rain", OutcomeCount: 2}
+	run := audit.NewRun(56, market.MarketID, "test-model")
+	run.SetMarket(market)
+	_, err = pipeline.AnalyzeMarket(audit.WithRun(context.Background(), run), market)
+	if !errors.Is(err, llm.ErrPolicyRejected) {
+		t.Fatalf("expected the weather policy to reject the decision, got %v", err)
+	}
+	run.Finish(err)
+
+	var settings llm.Settings
+	if err := json.Unmarshal(run.Settings, &settings); err != nil || len(settings.Strategies) != 2 || settings.HostedSearch {
+		t.Fatalf("expected the settings to be recorded, got %s (%v)", run.Settings, err)
+	}
+
+	// The generic strategy would accept the decision
+	result, err := Replay(context.Background(), run, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(result.Err, llm.ErrPolicyRejected) || len(result.Diffs) != 0 {
+		t.Errorf("expected the replay to be rejected the same way, got %v with diffs %+v", result.Err, result.Diffs)
+	}
+}
+
+// TestReplayDetectsDrift tests that a changed response shows up as a diff
+func TestReplayDetectsDrift(t *testing.T) {
+	run := recordRun(t)
+
+	// Pretend the final decision call originally answered NO
+	last := &run.Events[len(run.Events)-1]
+	var resp map[string]any
+	json.Unmarshal(last.Response, &resp)
+	message := resp["choices"].([]any)[0].(map[string]any)["message"].(map[string]any)
+	message["content"] = strings.Replace(message["content"].(string), `"outcomeId":1`, `"outcomeId":0`, 1)
+	last.Response = audit.RawJSON(resp)
+
+	result, err := Replay(context.Background(), run, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Diffs) != 1 || result.Diffs[0].Field != "outcomeId" || result.Diffs[0].Original != "1" || result.Diffs[0].Replayed != "0" {
+		t.Errorf("expected an outcomeId diff, got %+v", result.Diffs)
+	}
+}
+
+// TestRecordedTools tests that tool calls are answered from the record
+func TestRecordedTools(t *testing.T) {
+	run := audit.NewRun(56, 1, "m")
+	ctx := audit.WithRun(context.Background(), run)
+	audit.RecordToolCall(ctx, audit.Event{Tool: "get_price", Arguments: json.RawMessage(`{"symbol":"BNB"}`), Result: json.RawMessage(`{"usd":600}`)})
+	audit.RecordToolCall(ctx, audit.Event{Tool: "get_price", Arguments: json.RawMessage(`{"symbol":"BNB"}`), Error: "rate limited"})
+
+	tools := newRecordedTools(run)
+	tool, ok := tools.Get("get_price")
+	if !ok {
+		t.Fatal("expected recorded tool")
+	}
+
+	result, err := tool.Execute(ctx, map[string]any{"symbol": "BNB"})
+	if err != nil || result["usd"] != float64(600) {
+		t.Errorf("expected recorded result, got %v (%v)", result, err)
+	}
+	if _, err := tool.Execute(ctx, map[string]any{"symbol": "BNB"}); err == nil || err.Error() != "rate limited" {
+		t.Errorf("expected recorded error, got %v", err)
+	}
+	if _, err := tool.Execute(ctx, map[string]any{"symbol": "ETH"}); err == nil {
+		t.Error("expected error for unrecorded arguments")
+	}
+}
+
+// TestRecordedVerifier tests that page checks are answered from the record
+// and kept out of the model's tools
+func TestRecordedVerifier(t *testing.T) {
+	run := audit.NewRun(56, 1, "m")
+	ctx := audit.WithStep(audit.WithRun(context.Background(), run), string(llm.StepVerifyCitations))
+	audit.RecordToolCall(ctx, audit.Event{
+		Tool:      "verify_citation",
+		Arguments: json.RawMessage(`{"url":"https://example.com/a","claims":["the event happened on Monday"]}`),
+		Result:    json.RawMessage(`{"url":"https://example.com/a","verified":true,"score":1,"pageHash":"abc"}`),
+	})
+
+	if tools := newRecordedTools(run); len(tools.names) != 0 {
+		t.Errorf("expected no model tools, got %v", tools.names)
+	}
+
+	v := newRecordedVerifier(run)
+	result, err := v.Verify(ctx, "https://example.com/a", []string{"the event happened on Monday"})
+	if err != nil || !result.Verified || result.PageHash != "abc" {
+		t.Errorf("expected the recorded check, got %+v (%v)", result, err)
+	}
+	if _, err := v.Verify(ctx, "https://example.com/a", []string{"the event happened on Monday"}); err == nil {
+		t.Error("expected each recorded check to be used once")
+	}
+}
@@ -0,0 +1,144 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+)
+
+// recordedTools is an llm.ToolRegistry that answers tool calls from a run's
+// audit record instead of executing them
+type recordedTools struct {
+	names []string                  // In first-seen order
+	defs  map[string]map[string]any // OpenAI definitions sent to the model
+
+	mu    sync.Mutex
+	calls []audit.Event
+	used  []bool
+}
+
+// newRecordedTools collects the tool definitions and calls of a run
+func newRecordedTools(run *audit.Run) *recordedTools {
+	r := &recordedTools{defs: make(map[string]map[string]any)}
+
+	add := func(name string, def map[string]any) {
+		if _, ok := r.defs[name]; !ok {
+			r.names = append(r.names, name)
+		}
+		if def != nil || r.defs[name] == nil {
+			r.defs[name] = def
+		}
+	}
+
+	for _, e := range run.EventsOf(audit.EventLLMCall) {
+		defs, _ := e.Params["tools"].([]any)
+		for _, d := range defs {
+			def, _ := d.(map[string]any)
+			if name, _ := def["name"].(string); def["type"] == "function" && name != "" {
+				add(name, def)
+			}
+		}
+	}
+
+	for _, e := range run.EventsOf(audit.EventToolCall) {
+		if e.Step != string(llm.StepVerifyCitations) { // Page checks, not model tools
+			r.calls = append(r.calls, e)
+		}
+	}
+	r.used = make([]bool, len(r.calls))
+	for _, e := range r.calls {
+		add(e.Tool, nil)
+	}
+
+	return r
+}
+
+func (r *recordedTools) Get(name string) (llm.Tool, bool) {
+	if _, ok := r.defs[name]; !ok {
+		return nil, false
+	}
+	return &recordedTool{registry: r, name: name}, true
+}
+
+func (r *recordedTools) List() []llm.Tool {
+	tools := make([]llm.Tool, 0, len(r.names))
+	for _, name := range r.names {
+		tools = append(tools, &recordedTool{registry: r, name: name})
+	}
+	return tools
+}
+
+// next returns the first unused recorded call to name with the same arguments
+func (r *recordedTools) next(name string, args map[string]any) (audit.Event, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, e := range r.calls {
+		if r.used[i] || e.Tool != name {
+			continue
+		}
+		var recorded map[string]any
+		if err := json.Unmarshal(e.Arguments, &recorded); err != nil || !reflect.DeepEqual(recorded, normalize(args)) {
+			continue
+		}
+		r.used[i] = true
+		return e, true
+	}
+	return audit.Event{}, false
+}
+
+// normalize round-trips v through JSON so it compares equal to decoded records
+func normalize(v map[string]any) map[string]any {
+	data, _ := json.Marshal(v)
+	var out map[string]any
+	json.Unmarshal(data, &out)
+	return out
+}
+
+// recordedTool is one tool of a recordedTools registry
+type recordedTool struct {
+	registry *recordedTools
+	name     string
+}
+
+func (t *recordedTool) Name() string {
+	return t.name
+}
+
+func (t *recordedTool) Description() string {
+	if def := t.registry.defs[t.name]; def != nil {
+		desc, _ := def["description"].(string)
+		return desc
+	}
+	return ""
+}
+
+func (t *recordedTool) ToOpenAIFormat() map[string]any {
+	if def := t.registry.defs[t.name]; def != nil {
+		return def
+	}
+	return map[string]any{"type": "function", "name": t.name, "parameters": map[string]any{"type": "object"}}
+}
+
+func (t *recordedTool) Execute(ctx context.Context, arguments map[string]any) (map[string]any, error) {
+	e, ok := t.registry.next(t.name, arguments)
+	if !ok {
+		args, _ := json.Marshal(arguments)
+		return nil, fmt.Errorf("no recorded result for %s(%s)", t.name, args)
+	}
+	if e.Error != "" {
+		return nil, errors.New(e.Error)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(e.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse recorded result of %s: %w", t.name, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/project-gamma/ai-resolver/internal/audit"
+	"github.com/project-gamma/ai-resolver/internal/llm"
+	"github.com/project-gamma/ai-resolver/internal/verify"
+)
+
+// recordedVerifier is an llm.CitationVerifier that answers page checks from a
+// run's audit record. Pages change, so a replay never fetches them again.
+type recordedVerifier struct {
+	mu     sync.Mutex
+	checks []audit.Event
+	used   []bool
+}
+
+// newRecordedVerifier collects the citation checks of a run, or returns nil
+// if it verified none
+func newRecordedVerifier(run *audit.Run) *recordedVerifier {
+	v := &recordedVerifier{}
+	for _, e := range run.EventsOf(audit.EventToolCall) {
+		if e.Step == string(llm.StepVerifyCitations) {
+			v.checks = append(v.checks, e)
+		}
+	}
+	if len(v.checks) == 0 {
+		return nil
+	}
+	v.used = make([]bool, len(v.checks))
+	return v
+}
+
+func (v *recordedVerifier) Verify(_ context.Context, url string, claims []string) (verify.Result, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for i, e := range v.checks {
+		var args struct {
+			URL    string   `json:"url"`
+			Claims []string `json:"claims"`
+		}
+		if v.used[i] || json.Unmarshal(e.Arguments, &args) != nil || args.URL != url || !slices.Equal(args.Claims, claims) {
+			continue
+		}
+		v.used[i] = true
+
+		var result verify.Result
+		if err := json.Unmarshal(e.Result, &result); err != nil {
+			return verify.Result{URL: url}, fmt.Errorf("failed to parse recorded check of %s: %w", url, err)
+		}
+		if e.Error != "" {
+			return result, errors.New(e.Error)
+		}
+		return result, nil
+	}
+	return verify.Result{URL: url, Reason: "no recorded check"}, fmt.Errorf("no recorded check of %s", url)
+}
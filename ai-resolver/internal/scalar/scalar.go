@@ -0,0 +1,254 @@
+// Package scalar maps numeric answers onto the outcomes of bracket markets.
+//
+// A bracket market asks for a quantity ("What will the CPI be in March?") and
+// pays the outcome whose range holds the observed value. The LLM pipeline only
+// reads the value, its unit, source and time; the outcome follows from the
+// Spec's brackets with exact decimal arithmetic, so the same value always maps
+// to the same outcome.
+package scalar
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Boundary says which bracket a value exactly on a shared bound belongs to
+type Boundary string
+
+const (
+	BoundaryLower Boundary = "lower" // [min, max): a bound belongs to the bracket it starts
+	BoundaryUpper Boundary = "upper" // (min, max]: a bound belongs to the bracket it ends
+)
+
+// Spec describes the quantity a bracket market resolves on and its brackets
+type Spec struct {
+	Quantity string    `json:"quantity"`           // What is measured, e.g. "US CPI year over year"
+	Unit     string    `json:"unit"`               // e.g. "%", "USD", "°C"
+	Decimals *int      `json:"decimals,omitempty"` // The value is rounded to this many decimals first; nil: exact
+	Boundary Boundary  `json:"boundary,omitempty"` // Empty means BoundaryLower
+	Brackets []Bracket `json:"brackets"`
+}
+
+// Bracket is the range of values one outcome wins on. Min and Max are exact
+// decimals; an empty Min or Max leaves that side open.
+type Bracket struct {
+	Outcome uint64 `json:"outcome"`
+	Label   string `json:"label,omitempty"`
+	Min     string `json:"min,omitempty"`
+	Max     string `json:"max,omitempty"`
+}
+
+// String renders the bracket's range, e.g. "[2.5, 3)"
+func (b Bracket) String(boundary Boundary) string {
+	left, right := "[", ")"
+	if boundary == BoundaryUpper {
+		left, right = "(", "]"
+	}
+	lo, hi := b.Min, b.Max
+	if lo == "" {
+		left, lo = "(", "-∞"
+	}
+	if hi == "" {
+		right, hi = ")", "∞"
+	}
+	return fmt.Sprintf("%s%s, %s%s", left, lo, hi, right)
+}
+
+// ErrOutOfRange is returned by Map for values no bracket holds
+var ErrOutOfRange = errors.New("value is outside every bracket")
+
+// Validate checks that the brackets are ordered, contiguous and do not
+// overlap, so that every value maps to at most one outcome
+func (s *Spec) Validate() error {
+	if s.Unit == "" {
+		return fmt.Errorf("scalar spec has no unit")
+	}
+	if s.Boundary != "" && s.Boundary != BoundaryLower && s.Boundary != BoundaryUpper {
+		return fmt.Errorf("unknown boundary %q", s.Boundary)
+	}
+	if s.Decimals != nil && (*s.Decimals < 0 || *s.Decimals > 18) {
+		return fmt.Errorf("decimals must be 0-18, got %d", *s.Decimals)
+	}
+	if len(s.Brackets) < 2 {
+		return fmt.Errorf("scalar spec needs at least 2 brackets, has %d", len(s.Brackets))
+	}
+
+	seen := make(map[uint64]bool, len(s.Brackets))
+	var prevMax *big.Rat
+	for i, b := range s.Brackets {
+		if seen[b.Outcome] {
+			return fmt.Errorf("outcome %d has two brackets", b.Outcome)
+		}
+		seen[b.Outcome] = true
+
+		lo, hi, err := b.bounds()
+		if err != nil {
+			return err
+		}
+		switch {
+		case lo == nil && i > 0:
+			return fmt.Errorf("bracket %d: only the first bracket may have no minimum", i)
+		case hi == nil && i < len(s.Brackets)-1:
+			return fmt.Errorf("bracket %d: only the last bracket may have no maximum", i)
+		case lo != nil && hi != nil && lo.Cmp(hi) >= 0:
+			return fmt.Errorf("bracket %d: minimum %s is not below maximum %s", i, b.Min, b.Max)
+		case prevMax != nil && lo.Cmp(prevMax) != 0:
+			return fmt.Errorf("bracket %d starts at %s, not where bracket %d ends (%s)", i, b.Min, i-1, s.Brackets[i-1].Max)
+		}
+		prevMax = hi
+	}
+	return nil
+}
+
+// Map returns the bracket holding value, an exact decimal such as "3.05" or
+// "-1,250". The value is rounded to the spec's decimals first, half away from
+// zero.
+func (s *Spec) Map(value string) (Bracket, error) {
+	v, err := ParseDecimal(value)
+	if err != nil {
+		return Bracket{}, err
+	}
+	if s.Decimals != nil {
+		v = Round(v, *s.Decimals)
+	}
+	for _, b := range s.Brackets {
+		lo, hi, err := b.bounds()
+		if err != nil {
+			return Bracket{}, err
+		}
+		if s.above(v, lo) && s.below(v, hi) {
+			return b, nil
+		}
+	}
+	return Bracket{}, fmt.Errorf("%w: %s %s", ErrOutOfRange, FormatDecimal(v), s.Unit)
+}
+
+// above reports whether v is inside the bracket's lower bound
+func (s *Spec) above(v, lo *big.Rat) bool {
+	if lo == nil {
+		return true
+	}
+	if s.Boundary == BoundaryUpper {
+		return v.Cmp(lo) > 0
+	}
+	return v.Cmp(lo) >= 0
+}
+
+// below reports whether v is inside the bracket's upper bound
+func (s *Spec) below(v, hi *big.Rat) bool {
+	if hi == nil {
+		return true
+	}
+	if s.Boundary == BoundaryUpper {
+		return v.Cmp(hi) <= 0
+	}
+	return v.Cmp(hi) < 0
+}
+
+// bounds parses the bracket's bounds; nil means open
+func (b Bracket) bounds() (lo, hi *big.Rat, err error) {
+	if b.Min != "" {
+		if lo, err = ParseDecimal(b.Min); err != nil {
+			return nil, nil, fmt.Errorf("bracket for outcome %d: %w", b.Outcome, err)
+		}
+	}
+	if b.Max != "" {
+		if hi, err = ParseDecimal(b.Max); err != nil {
+			return nil, nil, fmt.Errorf("bracket for outcome %d: %w", b.Outcome, err)
+		}
+	}
+	return lo, hi, nil
+}
+
+// ParseDecimal parses an exact decimal. Thousands separators are allowed;
+// fractions, exponents and non-finite values are not.
+func ParseDecimal(s string) (*big.Rat, error) {
+	clean := strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	if clean == "" || strings.ContainsAny(clean, "/eE") {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+	r, ok := new(big.Rat).SetString(clean)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+	return r, nil
+}
+
+// Round rounds v to the given decimals, half away from zero
+func Round(v *big.Rat, decimals int) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	scaled := new(big.Rat).Mul(v, new(big.Rat).SetInt(scale))
+
+	// Truncate toward zero, then round the remainder
+	q, r := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(scaled.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(scaled.Sign())))
+	}
+	return new(big.Rat).SetFrac(q, scale)
+}
+
+// decimalsOf returns the decimals needed to print v exactly, up to 18
+func decimalsOf(v *big.Rat) int {
+	for d := 0; d < 18; d++ {
+		if Round(v, d).Cmp(v) == 0 {
+			return d
+		}
+	}
+	return 18
+}
+
+// FormatDecimal prints v with as many decimals as it needs
+func FormatDecimal(v *big.Rat) string {
+	return v.FloatString(decimalsOf(v))
+}
+
+// SameUnit reports whether two unit spellings are the same unit. Case,
+// spacing and a few common synonyms are ignored.
+func SameUnit(a, b string) bool {
+	return canonicalUnit(a) == canonicalUnit(b)
+}
+
+var unitSynonyms = map[string]string{
+	"percent": "%", "pct": "%", "percentage": "%",
+	"$": "usd", "us$": "usd", "dollars": "usd", "us dollars": "usd",
+	"celsius": "°c", "degrees celsius": "°c", "c": "°c",
+	"fahrenheit": "°f", "degrees fahrenheit": "°f", "f": "°f",
+}
+
+func canonicalUnit(u string) string {
+	u = strings.Join(strings.Fields(strings.ToLower(u)), " ")
+	if synonym, ok := unitSynonyms[u]; ok {
+		return synonym
+	}
+	return u
+}
+
+// ParseObservedAt parses when a value was observed. Times must carry a zone
+// offset; a bare date means the end of that UTC day, 23:59:59, so that a
+// daily figure counts only once the whole day has passed.
+func ParseObservedAt(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Unix(), nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t.Add(24*time.Hour - time.Second).Unix(), nil
+	}
+	if _, err := time.Parse("2006-01-02T15:04:05", s); err == nil {
+		return 0, fmt.Errorf("observation time %q has no zone offset", s)
+	}
+	return 0, fmt.Errorf("invalid observation time %q", s)
+}
+
+// Outcomes returns the outcome IDs the brackets pay, in bracket order
+func (s *Spec) Outcomes() []uint64 {
+	outcomes := make([]uint64, 0, len(s.Brackets))
+	for _, b := range s.Brackets {
+		outcomes = append(outcomes, b.Outcome)
+	}
+	return slices.Clip(outcomes)
+}
@@ -0,0 +1,113 @@
+package scalar
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func cpiSpec() *Spec {
+	one := 1
+	return &Spec{Quantity: "US CPI YoY", Unit: "%", Decimals: &one, Brackets: []Bracket{
+		{Outcome: 0, Label: "Below 2.5%", Max: "2.5"},
+		{Outcome: 1, Label: "2.5% to 3%", Min: "2.5", Max: "3"},
+		{Outcome: 2, Label: "3% or more", Min: "3"},
+	}}
+}
+
+// TestMap tests mapping values onto brackets, at and near the bounds
+func TestMap(t *testing.T) {
+	spec := cpiSpec()
+	if err := spec.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value    string
+		boundary Boundary
+		want     uint64
+	}{
+		{"1.9", "", 0},
+		{"-0.4", "", 0},
+		{"2.5", "", 1},  // A bound belongs to the bracket it starts
+		{"2.49", "", 1}, // Rounded to 2.5 first
+		{"2.44", "", 0},
+		{"2.96", "", 2}, // Rounded to 3.0
+		{"3", "", 2},
+		{"1,204.0", "", 2},
+		{"2.5", BoundaryUpper, 0}, // A bound belongs to the bracket it ends
+		{"3.0", BoundaryUpper, 1},
+		{"3.01", BoundaryUpper, 1}, // Rounded to 3.0
+	}
+	for _, tt := range tests {
+		spec.Boundary = tt.boundary
+		got, err := spec.Map(tt.value)
+		if err != nil || got.Outcome != tt.want {
+			t.Errorf("%s (%s boundary): expected outcome %d, got %d: %v", tt.value, tt.boundary, tt.want, got.Outcome, err)
+		}
+	}
+
+	if _, err := spec.Map("2.5e1"); err == nil {
+		t.Error("expected exponents to be refused")
+	}
+	closed := &Spec{Unit: "°C", Brackets: []Bracket{{Outcome: 0, Min: "0", Max: "10"}, {Outcome: 1, Min: "10", Max: "20"}}}
+	if _, err := closed.Map("20"); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("expected a value past the last bracket to be out of range, got %v", err)
+	}
+}
+
+// TestValidate tests rejecting brackets that overlap, leave gaps or are open
+// in the middle
+func TestValidate(t *testing.T) {
+	tests := map[string][]Bracket{
+		"one bracket":  {{Outcome: 0, Min: "0"}},
+		"gap":          {{Outcome: 0, Max: "1"}, {Outcome: 1, Min: "2"}},
+		"overlap":      {{Outcome: 0, Max: "2"}, {Outcome: 1, Min: "1"}},
+		"open middle":  {{Outcome: 0, Max: "1"}, {Outcome: 1, Min: "1"}, {Outcome: 2, Min: "2"}},
+		"empty range":  {{Outcome: 0, Min: "1", Max: "1"}, {Outcome: 1, Min: "1"}},
+		"same outcome": {{Outcome: 0, Max: "1"}, {Outcome: 0, Min: "1"}},
+		"bad bound":    {{Outcome: 0, Max: "one"}, {Outcome: 1, Min: "one"}},
+	}
+	for name, brackets := range tests {
+		spec := &Spec{Unit: "%", Brackets: brackets}
+		if err := spec.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+// TestRound tests rounding half away from zero
+func TestRound(t *testing.T) {
+	for value, want := range map[string]string{"2.45": "2.5", "-2.45": "-2.5", "2.449": "2.4", "0.05": "0.1", "7": "7"} {
+		v, _ := ParseDecimal(value)
+		if got := FormatDecimal(Round(v, 1)); got != want {
+			t.Errorf("Round(%s, 1) = %s, expected %s", value, got, want)
+		}
+	}
+}
+
+// TestParseObservedAt tests zone handling of observation times
+func TestParseObservedAt(t *testing.T) {
+	tests := map[string]string{
+		"2025-03-01T20:00:00-05:00": "2025-03-02T01:00:00Z", // After midnight UTC
+		"2025-03-01T23:59:59Z":      "2025-03-01T23:59:59Z",
+		"2025-03-01":                "2025-03-01T23:59:59Z", // End of the UTC day
+	}
+	for in, want := range tests {
+		got, err := ParseObservedAt(in)
+		if err != nil || time.Unix(got, 0).UTC().Format(time.RFC3339) != want {
+			t.Errorf("%s: expected %s, got %s: %v", in, want, time.Unix(got, 0).UTC().Format(time.RFC3339), err)
+		}
+	}
+	if _, err := ParseObservedAt("2025-03-01T20:00:00"); err == nil || !strings.Contains(err.Error(), "no zone offset") {
+		t.Errorf("expected a local time to be refused, got %v", err)
+	}
+}
+
+// TestSameUnit tests unit spellings
+func TestSameUnit(t *testing.T) {
+	if !SameUnit("Percent", "%") || !SameUnit("US dollars", "USD") || SameUnit("°C", "°F") {
+		t.Error("unexpected unit comparison")
+	}
+}
@@ -0,0 +1,237 @@
+package simchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/project-gamma/ai-resolver/pkg/abi"
+)
+
+// Deployment holds the addresses and bindings of a deployed protocol
+type Deployment struct {
+	Token            common.Address
+	OutcomeToken     common.Address
+	HorizonPerks     common.Address
+	FeeSplitter      common.Address
+	ResolutionModule common.Address
+	AIOracleAdapter  common.Address
+	MarketFactory    common.Address
+
+	TokenContract      *abi.Token
+	ResolutionContract *abi.ResolutionModule
+	AdapterContract    *abi.AIOracleAdapter
+	FactoryContract    *abi.MarketFactory
+}
+
+// ProtocolOptions configures DeployProtocol
+type ProtocolOptions struct {
+	TokenSupply     *big.Int
+	MinCreatorStake *big.Int
+	MinBond         *big.Int
+	DisputeWindow   time.Duration
+
+	// ArtifactsDir is a Foundry output directory (contracts/out) used for
+	// contracts whose pkg/abi binding carries no bytecode. Defaults to
+	// $SIMCHAIN_ARTIFACTS.
+	ArtifactsDir string
+}
+
+// DeployProtocol deploys the bond token and core contracts and wires them
+// together the same way contracts/script/Deploy.s.sol does. The owner acts as
+// treasury and arbitrator and the resolver account is the initial AI signer.
+//
+// Bytecode comes from pkg/abi when the binding includes it, otherwise from
+// opts.ArtifactsDir. Errors wrap ErrNoBytecode when neither is available.
+func (c *Chain) DeployProtocol(opts ProtocolOptions) (*Deployment, error) {
+	if opts.TokenSupply == nil {
+		opts.TokenSupply = new(big.Int).Mul(big.NewInt(1_000_000_000), big.NewInt(1e18))
+	}
+	if opts.ArtifactsDir == "" {
+		opts.ArtifactsDir = os.Getenv("SIMCHAIN_ARTIFACTS")
+	}
+
+	// Resolve all bytecode up front so a missing artifact fails before any
+	// transaction is sent
+	names := []struct {
+		name string
+		meta *bind.MetaData
+	}{
+		{"OutcomeToken", nil},
+		{"HorizonPerks", nil},
+		{"FeeSplitter", nil},
+		{"ResolutionModule", abi.ResolutionModuleMetaData},
+		{"AIOracleAdapter", abi.AIOracleAdapterMetaData},
+		{"MarketFactory", abi.MarketFactoryMetaData},
+	}
+	code := make(map[string]*contractCode, len(names))
+	for _, n := range names {
+		cc, err := loadContract(n.name, n.meta, opts.ArtifactsDir)
+		if err != nil {
+			return nil, err
+		}
+		code[n.name] = cc
+	}
+
+	d := &Deployment{}
+	var err error
+
+	d.Token, d.TokenContract, err = c.DeployToken(opts.TokenSupply)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomeToken, err := c.deploy(code["OutcomeToken"], &d.OutcomeToken, "https://horizon.test/{id}.json")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.deploy(code["HorizonPerks"], &d.HorizonPerks, d.Token); err != nil {
+		return nil, err
+	}
+	feeSplitter, err := c.deploy(code["FeeSplitter"], &d.FeeSplitter, c.Owner.Address)
+	if err != nil {
+		return nil, err
+	}
+	resolution, err := c.deploy(code["ResolutionModule"], &d.ResolutionModule, d.OutcomeToken, d.Token, c.Owner.Address)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.deploy(code["AIOracleAdapter"], &d.AIOracleAdapter, d.ResolutionModule, d.Token, c.Resolver.Address); err != nil {
+		return nil, err
+	}
+	factory, err := c.deploy(code["MarketFactory"], &d.MarketFactory, d.OutcomeToken, d.FeeSplitter, d.HorizonPerks, d.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.MinBond != nil {
+		if err := c.transact(resolution, "setMinBond", opts.MinBond); err != nil {
+			return nil, err
+		}
+	}
+	if opts.DisputeWindow > 0 {
+		if err := c.transact(resolution, "setDisputeWindow", big.NewInt(int64(opts.DisputeWindow.Seconds()))); err != nil {
+			return nil, err
+		}
+	}
+	if opts.MinCreatorStake != nil {
+		if err := c.transact(factory, "setMinCreatorStake", opts.MinCreatorStake); err != nil {
+			return nil, err
+		}
+	}
+
+	// Authorizations, mirroring Deploy.s.sol configureAuthorizations
+	if err := c.transact(outcomeToken, "setResolutionAuthorization", d.ResolutionModule, true); err != nil {
+		return nil, err
+	}
+	if err := c.transact(outcomeToken, "transferOwnership", d.MarketFactory); err != nil {
+		return nil, err
+	}
+	if err := c.transact(feeSplitter, "transferOwnership", d.MarketFactory); err != nil {
+		return nil, err
+	}
+
+	if d.ResolutionContract, err = abi.NewResolutionModule(d.ResolutionModule, c.Client); err != nil {
+		return nil, fmt.Errorf("failed to bind resolution module: %w", err)
+	}
+	if d.AdapterContract, err = abi.NewAIOracleAdapter(d.AIOracleAdapter, c.Client); err != nil {
+		return nil, fmt.Errorf("failed to bind adapter: %w", err)
+	}
+	if d.FactoryContract, err = abi.NewMarketFactory(d.MarketFactory, c.Client); err != nil {
+		return nil, fmt.Errorf("failed to bind market factory: %w", err)
+	}
+
+	return d, nil
+}
+
+// contractCode is a contract's ABI and creation bytecode
+type contractCode struct {
+	name string
+	abi  ethabi.ABI
+	bin  []byte
+}
+
+// loadContract returns the bytecode from the pkg/abi binding when present,
+// falling back to the Foundry artifact <dir>/<Name>.sol/<Name>.json
+func loadContract(name string, meta *bind.MetaData, artifactsDir string) (*contractCode, error) {
+	if meta != nil && meta.Bin != "" {
+		parsed, err := meta.GetAbi()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s ABI: %w", name, err)
+		}
+		return &contractCode{name: name, abi: *parsed, bin: common.FromHex(meta.Bin)}, nil
+	}
+
+	if artifactsDir == "" {
+		return nil, fmt.Errorf("%w for %s: regenerate pkg/abi with abigen --bin or set SIMCHAIN_ARTIFACTS to contracts/out", ErrNoBytecode, name)
+	}
+
+	path := filepath.Join(artifactsDir, name+".sol", name+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w for %s: %s not found (run forge build)", ErrNoBytecode, name, path)
+		}
+		return nil, fmt.Errorf("failed to read %s artifact: %w", name, err)
+	}
+
+	var artifact struct {
+		ABI      json.RawMessage `json:"abi"`
+		Bytecode struct {
+			Object string `json:"object"`
+		} `json:"bytecode"`
+	}
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		return nil, fmt.Errorf("failed to parse %s artifact: %w", name, err)
+	}
+	if artifact.Bytecode.Object == "" || artifact.Bytecode.Object == "0x" {
+		return nil, fmt.Errorf("%w for %s: artifact has empty bytecode", ErrNoBytecode, name)
+	}
+
+	parsed, err := ethabi.JSON(strings.NewReader(string(artifact.ABI)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s ABI: %w", name, err)
+	}
+	return &contractCode{name: name, abi: parsed, bin: common.FromHex(artifact.Bytecode.Object)}, nil
+}
+
+// deploy deploys a contract from the owner account and stores its address
+func (c *Chain) deploy(code *contractCode, addr *common.Address, args ...any) (*boundContract, error) {
+	auth, err := c.Transactor(c.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	deployed, tx, contract, err := bind.DeployContract(auth, code.abi, code.bin, c.Client, args...)
+	if err := c.Mined(tx, err); err != nil {
+		return nil, fmt.Errorf("failed to deploy %s: %w", code.name, err)
+	}
+
+	*addr = deployed
+	return &boundContract{name: code.name, BoundContract: contract}, nil
+}
+
+// boundContract is a deployed contract addressed by name in error messages
+type boundContract struct {
+	name string
+	*bind.BoundContract
+}
+
+// transact calls a contract method from the owner account
+func (c *Chain) transact(contract *boundContract, method string, args ...any) error {
+	auth, err := c.Transactor(c.Owner)
+	if err != nil {
+		return err
+	}
+	if err := c.Mined(contract.Transact(auth, method, args...)); err != nil {
+		return fmt.Errorf("failed to call %s.%s: %w", contract.name, method, err)
+	}
+	return nil
+}
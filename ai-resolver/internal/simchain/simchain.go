@@ -0,0 +1,204 @@
+// Package simchain runs the resolver's contracts on go-ethereum's simulated
+// backend so the create → close → propose → dispute/finalize flow can be
+// exercised without an RPC node.
+package simchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/project-gamma/ai-resolver/internal/adapter"
+	"github.com/project-gamma/ai-resolver/pkg/abi"
+)
+
+// Account is a funded externally owned account on the simulated chain
+type Account struct {
+	Key     *ecdsa.PrivateKey
+	Address common.Address
+}
+
+// KeyHex returns the private key in the hex form expected by adapter.Config
+func (a Account) KeyHex() string {
+	return hex.EncodeToString(crypto.FromECDSA(a.Key))
+}
+
+// Chain is a simulated chain with funded accounts. Every transaction sent
+// through Client is mined immediately.
+type Chain struct {
+	Backend *simulated.Backend
+	Client  adapter.Backend
+	ChainID *big.Int
+
+	Owner    Account // deploys and owns the contracts, acts as arbitrator
+	Resolver Account // AI signer and proposer
+	Disputer Account // challenges proposals
+	Creator  Account // creates markets
+}
+
+// New starts a simulated chain with four funded accounts
+func New() (*Chain, error) {
+	accounts := make([]Account, 4)
+	alloc := types.GenesisAlloc{}
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	for i := range accounts {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate key: %w", err)
+		}
+		accounts[i] = Account{Key: key, Address: crypto.PubkeyToAddress(key.PublicKey)}
+		alloc[accounts[i].Address] = types.Account{Balance: balance}
+	}
+
+	backend := simulated.NewBackend(alloc)
+	client := &autoCommitClient{Client: backend.Client(), backend: backend}
+
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		backend.Close()
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	return &Chain{
+		Backend:  backend,
+		Client:   client,
+		ChainID:  chainID,
+		Owner:    accounts[0],
+		Resolver: accounts[1],
+		Disputer: accounts[2],
+		Creator:  accounts[3],
+	}, nil
+}
+
+// Close stops the simulated chain
+func (c *Chain) Close() error {
+	return c.Backend.Close()
+}
+
+// Transactor returns transaction options signed by the given account
+func (c *Chain) Transactor(from Account) (*bind.TransactOpts, error) {
+	auth, err := bind.NewKeyedTransactorWithChainID(from.Key, c.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transactor: %w", err)
+	}
+	auth.Context = context.Background()
+	return auth, nil
+}
+
+// AdvanceTime moves the chain clock forward and mines a block
+func (c *Chain) AdvanceTime(d time.Duration) error {
+	if err := c.Backend.AdjustTime(d); err != nil {
+		return fmt.Errorf("failed to adjust time: %w", err)
+	}
+	c.Backend.Commit()
+	return nil
+}
+
+// BlockTime returns the timestamp of the latest block
+func (c *Chain) BlockTime(ctx context.Context) (uint64, error) {
+	header, err := c.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block header: %w", err)
+	}
+	return header.Time, nil
+}
+
+// Mined returns an error if tx failed. Transactions are mined on send, so the
+// receipt is always available.
+func (c *Chain) Mined(tx *types.Transaction, err error) error {
+	if err != nil {
+		return err
+	}
+	receipt, err := c.Client.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to get receipt for %s: %w", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction reverted: %s", tx.Hash().Hex())
+	}
+	return nil
+}
+
+// DeployToken deploys the bond token from pkg/abi, mints supply to the owner
+// and enables transfers
+func (c *Chain) DeployToken(supply *big.Int) (common.Address, *abi.Token, error) {
+	auth, err := c.Transactor(c.Owner)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	addr, tx, token, err := abi.DeployToken(auth, c.Client)
+	if err := c.Mined(tx, err); err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to deploy token: %w", err)
+	}
+
+	if err := c.Mined(token.Init(auth, "Horizon", "HZN", supply)); err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to initialize token: %w", err)
+	}
+
+	// init leaves the token in restricted mode; switch to normal transfers
+	if err := c.Mined(token.SetMode(auth, big.NewInt(0))); err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to enable token transfers: %w", err)
+	}
+
+	return addr, token, nil
+}
+
+// Fund transfers tokens from the owner to each recipient
+func (c *Chain) Fund(token *abi.Token, amount *big.Int, recipients ...common.Address) error {
+	auth, err := c.Transactor(c.Owner)
+	if err != nil {
+		return err
+	}
+	for _, to := range recipients {
+		if err := c.Mined(token.Transfer(auth, to, amount)); err != nil {
+			return fmt.Errorf("failed to fund %s: %w", to.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// NewAdapterClient creates an adapter.Client on the simulated chain that signs
+// with the given account
+func (c *Chain) NewAdapterClient(d *Deployment, signer Account) (*adapter.Client, error) {
+	return adapter.NewClientWithBackend(c.Client, c.AdapterConfig(d, signer))
+}
+
+// AdapterConfig returns the adapter configuration for a deployment
+func (c *Chain) AdapterConfig(d *Deployment, signer Account) adapter.Config {
+	return adapter.Config{
+		ChainID:           c.ChainID.Int64(),
+		SignerPrivateKey:  signer.KeyHex(),
+		AdapterAddress:    d.AIOracleAdapter.Hex(),
+		FactoryAddress:    d.MarketFactory.Hex(),
+		ResolutionAddress: d.ResolutionModule.Hex(),
+		TokenAddress:      d.Token.Hex(),
+	}
+}
+
+// autoCommitClient mines a block after every transaction so callers waiting
+// on receipts, such as adapter.Client.WaitForTransaction, return immediately
+type autoCommitClient struct {
+	simulated.Client
+	backend *simulated.Backend
+}
+
+func (c *autoCommitClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := c.Client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	c.backend.Commit()
+	return nil
+}
+
+// ErrNoBytecode is returned when a contract has no deployable bytecode
+var ErrNoBytecode = errors.New("no deployment bytecode")
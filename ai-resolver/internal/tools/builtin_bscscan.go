@@ -69,6 +69,11 @@ func NewBSCScanTool(apiKey string) *BSCScanTool {
 	return tool
 }
 
+// SetTransport replaces the HTTP transport used for API calls
+func (t *BSCScanTool) SetTransport(transport http.RoundTripper) {
+	t.httpClient.Transport = transport
+}
+
 // execute performs the BSCScan API call
 func (t *BSCScanTool) execute(ctx context.Context, input ToolInput) (ToolOutput, error) {
 	action, ok := input.Arguments["action"].(string)
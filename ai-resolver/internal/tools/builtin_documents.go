@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/project-gamma/ai-resolver/internal/corpus"
+)
+
+// DocumentSearcher searches a document corpus; *corpus.Index implements it
+type DocumentSearcher interface {
+	Search(ctx context.Context, query string, limit int) ([]corpus.Hit, error)
+}
+
+// maxDocumentResults caps the passages one search returns
+const maxDocumentResults = 10
+
+// DocumentSearchTool searches the local corpus of official documents
+type DocumentSearchTool struct {
+	*BaseTool
+	index DocumentSearcher
+}
+
+// NewDocumentSearchTool creates a new document search tool
+func NewDocumentSearchTool(index DocumentSearcher) *DocumentSearchTool {
+	schema := &ToolSchema{
+		Type: "object",
+		Properties: map[string]Property{
+			"query": {
+				Type:        "string",
+				Description: "What to look for, in keywords, e.g. \"CPI all items 12-month change March 2025\"",
+			},
+			"limit": {
+				Type:        "integer",
+				Description: fmt.Sprintf("Maximum passages to return (1-%d, default 5)", maxDocumentResults),
+			},
+		},
+		Required: []string{"query"},
+	}
+
+	base := NewBaseTool(
+		"search_documents",
+		"Search a local corpus of documents from official sources (reports, releases, results pages). Returns matching passages with their document's URL and title; cite a passage by its URL.",
+		ToolTypeFunction,
+		schema,
+	)
+
+	tool := &DocumentSearchTool{
+		BaseTool: base,
+		index:    index,
+	}
+
+	base.SetExecutor(tool.execute)
+
+	return tool
+}
+
+// execute runs the search
+func (t *DocumentSearchTool) execute(ctx context.Context, input ToolInput) (ToolOutput, error) {
+	query, ok := input.Arguments["query"].(string)
+	if !ok || query == "" {
+		return ToolOutput{
+			CallID: input.CallID,
+			Error:  fmt.Errorf("query is required"),
+		}, fmt.Errorf("query is required")
+	}
+
+	limit := 5
+	if l, ok := input.Arguments["limit"].(float64); ok && l >= 1 {
+		limit = min(int(l), maxDocumentResults)
+	}
+
+	hits, err := t.index.Search(ctx, query, limit)
+	if err != nil {
+		return ToolOutput{
+			CallID: input.CallID,
+			Error:  fmt.Errorf("document search failed: %w", err),
+		}, fmt.Errorf("document search failed: %w", err)
+	}
+
+	citations := make([]map[string]any, 0, len(hits))
+	for _, hit := range hits {
+		citations = append(citations, map[string]any{"url": hit.URL, "title": hit.Title})
+	}
+
+	return ToolOutput{
+		CallID: input.CallID,
+		Data: map[string]any{
+			"query":     query,
+			"count":     len(hits),
+			"passages":  hits,
+			"citations": citations,
+		},
+	}, nil
+}
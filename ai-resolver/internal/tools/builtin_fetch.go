@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+)
+
+const (
+	// defaultFetchChars is how much of a page's text one call returns
+	defaultFetchChars = 20000
+
+	// maxFetchChars caps the text one call returns
+	maxFetchChars = 50000
+)
+
+// FetchTool reads a web page, PDF or JSON document as text
+type FetchTool struct {
+	*BaseTool
+	fetcher fetch.PageFetcher
+}
+
+// NewFetchTool creates a new fetch tool. The fetcher decides which addresses,
+// domains and paths may be fetched; see fetch.Options.
+func NewFetchTool(fetcher fetch.PageFetcher) *FetchTool {
+	schema := &ToolSchema{
+		Type: "object",
+		Properties: map[string]Property{
+			"url": {
+				Type:        "string",
+				Description: "The http(s) URL to read, e.g. an official results page named in the market metadata",
+			},
+			"offset": {
+				Type:        "integer",
+				Description: "Character of the page text to start at, to read on after a previous call (default 0)",
+			},
+			"maxChars": {
+				Type:        "integer",
+				Description: fmt.Sprintf("Maximum characters of text to return (default %d, at most %d)", defaultFetchChars, maxFetchChars),
+			},
+		},
+		Required: []string{"url"},
+	}
+
+	base := NewBaseTool(
+		"fetch_url",
+		"Read a web page, PDF or JSON document. Returns its title and readable text with markup removed, and a SHA-256 hash of the content as served; cite the page by its url.",
+		ToolTypeFunction,
+		schema,
+	)
+
+	tool := &FetchTool{
+		BaseTool: base,
+		fetcher:  fetcher,
+	}
+
+	base.SetExecutor(tool.execute)
+
+	return tool
+}
+
+// execute fetches the page
+func (t *FetchTool) execute(ctx context.Context, input ToolInput) (ToolOutput, error) {
+	rawURL, ok := input.Arguments["url"].(string)
+	rawURL = strings.TrimSpace(rawURL)
+	if !ok || rawURL == "" {
+		return ToolOutput{
+			CallID: input.CallID,
+			Error:  fmt.Errorf("url is required"),
+		}, fmt.Errorf("url is required")
+	}
+	if !credibility.Allowed(ctx, rawURL) {
+		return ToolOutput{
+			CallID: input.CallID,
+			Error:  fmt.Errorf("%s is not an allowed source for this market", rawURL),
+		}, fmt.Errorf("%s is not an allowed source for this market", rawURL)
+	}
+
+	offset := 0
+	if o, ok := input.Arguments["offset"].(float64); ok && o > 0 {
+		offset = int(o)
+	}
+	maxChars := defaultFetchChars
+	if m, ok := input.Arguments["maxChars"].(float64); ok && m >= 1 {
+		maxChars = min(int(m), maxFetchChars)
+	}
+
+	page, err := t.fetcher.Fetch(ctx, rawURL)
+	if err != nil {
+		return ToolOutput{
+			CallID: input.CallID,
+			Error:  fmt.Errorf("fetch failed: %w", err),
+		}, fmt.Errorf("fetch failed: %w", err)
+	}
+	if page.URL != rawURL && !credibility.Allowed(ctx, page.URL) {
+		return ToolOutput{
+			CallID: input.CallID,
+			Error:  fmt.Errorf("%s redirected to %s, which is not an allowed source for this market", rawURL, page.URL),
+		}, fmt.Errorf("%s redirected to %s, which is not an allowed source for this market", rawURL, page.URL)
+	}
+
+	text := []rune(page.Text)
+	start := min(offset, len(text))
+	end := min(start+maxChars, len(text))
+
+	title := page.Title
+	if title == "" {
+		title = page.URL
+	}
+
+	data := map[string]any{
+		"url":         page.URL,
+		"status":      page.Status,
+		"contentType": page.ContentType,
+		"title":       title,
+		"text":        string(text[start:end]),
+		"offset":      start,
+		"totalChars":  len(text),
+		"hash":        page.Hash,
+		"truncated":   page.Truncated,
+		"fetchedAt":   page.FetchedAt,
+		"citations":   []map[string]any{{"url": page.URL, "title": title}},
+	}
+	if end < len(text) {
+		data["nextOffset"] = end
+	}
+
+	return ToolOutput{
+		CallID: input.CallID,
+		Data:   data,
+	}, nil
+}
@@ -78,6 +78,11 @@ func NewPancakeSwapTool(client PancakeSwapClient) *PancakeSwapTool {
 	return tool
 }
 
+// SetTransport replaces the HTTP transport used for subgraph queries
+func (t *PancakeSwapTool) SetTransport(transport http.RoundTripper) {
+	t.httpClient.Transport = transport
+}
+
 // execute performs the PancakeSwap query
 func (t *PancakeSwapTool) execute(ctx context.Context, input ToolInput) (ToolOutput, error) {
 	action, ok := input.Arguments["action"].(string)
@@ -236,6 +241,9 @@ func (t *PancakeSwapTool) getVolume(ctx context.Context, args map[string]any) (m
 // getReserves calls the pair contract to get reserves
 func (t *PancakeSwapTool) getReserves(ctx context.Context, pairAddr string) (map[string]any, error) {
 	client := t.client.GetETHClient()
+	if client == nil {
+		return nil, fmt.Errorf("no RPC client available for on-chain reads")
+	}
 
 	// PancakeSwap V2 Pair ABI (getReserves function)
 	pairABI := `[{"constant":true,"inputs":[],"name":"getReserves","outputs":[{"internalType":"uint112","name":"_reserve0","type":"uint112"},{"internalType":"uint112","name":"_reserve1","type":"uint112"},{"internalType":"uint32","name":"_blockTimestampLast","type":"uint32"}],"payable":false,"stateMutability":"view","type":"function"}]`
@@ -2,11 +2,21 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/project-gamma/ai-resolver/internal/corpus"
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+	"github.com/project-gamma/ai-resolver/internal/httprec"
+	"github.com/project-gamma/ai-resolver/internal/httprec/httprectest"
 )
 
 // Mock MarketDataClient for testing
@@ -30,32 +40,145 @@ func (m *mockMarketDataClient) GetCurrentBlockTimestamp(ctx context.Context) (in
 	return time.Now().Unix(), nil
 }
 
+// TestWebSearchTool tests paging, deduplication and normalization of results
 func TestWebSearchTool(t *testing.T) {
-	tool := NewWebSearchTool()
+	pages := []string{
+		`{"results":[
+			{"url":"https://www.bls.gov/cpi/","title":"<b>CPI</b> Home","content":"Consumer Price Index &amp;\n  <em>inflation</em>"},
+			{"url":"javascript:alert(1)","title":"Bad"},
+			{"url":"https://bls.gov/cpi?utm_source=searx","title":"CPI Home again"}]}`,
+		`{"results":[
+			{"url":"https://www.bls.gov/news.release/cpi.nr0.htm","title":"","content":"March release"},
+			{"url":"https://www.bls.gov/cpi/tables/","title":"CPI tables"}]}`,
+	}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("pageno"))
+		if page > len(pages) {
+			w.Write([]byte(`{"results":[]}`))
+			return
+		}
+		w.Write([]byte(pages[page-1]))
+	}))
+	defer server.Close()
 
-	if tool.Name() != "web_search" {
-		t.Errorf("expected name 'web_search', got %s", tool.Name())
+	tool := NewWebSearchTool(NewSearxNGProvider(server.URL))
+	if tool.Name() != "search_web" || tool.Type() != ToolTypeFunction {
+		t.Errorf("unexpected tool %s of type %s", tool.Name(), tool.Type())
 	}
 
-	if tool.Type() != ToolTypeWebSearchPreview {
-		t.Errorf("expected type %s, got %s", ToolTypeWebSearchPreview, tool.Type())
+	output, err := tool.Execute(context.Background(), ToolInput{
+		CallID:    "call_1",
+		Arguments: map[string]any{"query": "cpi march", "limit": 2.0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := output.Data.(map[string]any)
+	results := data["results"].([]SearchResult)
+	if len(results) != 2 || requests != 2 {
+		t.Fatalf("expected 2 results from 2 pages, got %+v after %d requests", results, requests)
+	}
+	if results[0].Title != "CPI Home" || results[0].Snippet != "Consumer Price Index & inflation" {
+		t.Errorf("expected markup stripped, got %+v", results[0])
+	}
+	if results[1].URL != "https://www.bls.gov/news.release/cpi.nr0.htm" || results[1].Title != results[1].URL {
+		t.Errorf("expected the duplicate skipped and the URL as title, got %+v", results[1])
+	}
+	if citations := data["citations"].([]map[string]any); len(citations) != 2 || citations[0]["url"] != results[0].URL {
+		t.Errorf("expected a citation per result, got %+v", citations)
 	}
 
-	// Execute should work but return delegated message
-	ctx := context.Background()
-	output, err := tool.Execute(ctx, ToolInput{CallID: "test_1"})
+	// Searching stops when the provider runs out of results
+	output, _ = tool.Execute(context.Background(), ToolInput{CallID: "call_2", Arguments: map[string]any{"query": "cpi"}})
+	if got := output.Data.(map[string]any)["count"]; got != 3 {
+		t.Errorf("expected all 3 distinct results, got %v", got)
+	}
+
+	if _, err := tool.Execute(context.Background(), ToolInput{CallID: "call_3", Arguments: map[string]any{}}); err == nil {
+		t.Error("expected an error without a query")
+	}
+}
+
+// TestFetchTool tests reading a page in parts and keeping to the source policy
+func TestFetchTool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<title>Official results</title><p>Candidate A received 52.4% of the vote.</p>"))
+	}))
+	defer server.Close()
+	tool := NewFetchTool(fetch.New(fetch.Options{AllowPrivate: true}))
+	if tool.Name() != "fetch_url" || tool.Type() != ToolTypeFunction {
+		t.Errorf("unexpected tool %s of type %s", tool.Name(), tool.Type())
+	}
+
+	output, err := tool.Execute(context.Background(), ToolInput{
+		CallID:    "call_1",
+		Arguments: map[string]any{"url": server.URL + "/results", "maxChars": 11.0},
+	})
 	if err != nil {
-		t.Errorf("unexpected error: %v", err)
+		t.Fatal(err)
+	}
+	data := output.Data.(map[string]any)
+	if data["title"] != "Official results" || data["text"] != "Candidate A" || data["nextOffset"] != 11 || len(data["hash"].(string)) != 64 {
+		t.Errorf("unexpected result %+v", data)
+	}
+	if citations := data["citations"].([]map[string]any); len(citations) != 1 || citations[0]["url"] != server.URL+"/results" {
+		t.Errorf("expected the page as citation, got %+v", citations)
 	}
 
-	if output.Data == nil {
-		t.Error("expected data in output")
+	output, _ = tool.Execute(context.Background(), ToolInput{
+		CallID:    "call_2",
+		Arguments: map[string]any{"url": server.URL + "/results", "offset": 12.0},
+	})
+	if data := output.Data.(map[string]any); data["text"] != "received 52.4% of the vote." || data["nextOffset"] != nil {
+		t.Errorf("expected the rest of the text, got %+v", data)
 	}
 
-	// Check OpenAI format
-	format := tool.ToOpenAIFormat()
-	if format["type"] != "web_search_preview" {
-		t.Errorf("expected type 'web_search_preview', got %v", format["type"])
+	ctx := credibility.WithPolicy(context.Background(), func(url string) bool { return false })
+	if _, err := tool.Execute(ctx, ToolInput{CallID: "call_3", Arguments: map[string]any{"url": server.URL}}); err == nil {
+		t.Error("expected a URL outside the source policy to be refused")
+	}
+	if _, err := NewFetchTool(fetch.New(fetch.Options{})).Execute(context.Background(), ToolInput{
+		CallID:    "call_4",
+		Arguments: map[string]any{"url": server.URL},
+	}); !errors.Is(err, fetch.ErrBlocked) {
+		t.Errorf("expected a loopback address to be blocked, got %v", err)
+	}
+}
+
+func TestDocumentSearchTool(t *testing.T) {
+	index, err := corpus.Open(t.TempDir() + "/corpus.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = index.Add(context.Background(), corpus.Document{URL: "https://www.bls.gov/cpi/", Title: "CPI"},
+		"Over the last 12 months, the all items index increased 3.1 percent.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := NewDocumentSearchTool(index)
+
+	if tool.Name() != "search_documents" || tool.Type() != ToolTypeFunction {
+		t.Errorf("unexpected tool %s of type %s", tool.Name(), tool.Type())
+	}
+
+	output, err := tool.Execute(context.Background(), ToolInput{Arguments: map[string]any{"query": "all items index", "limit": float64(3)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := output.Data.(map[string]any)
+	hits := data["passages"].([]corpus.Hit)
+	if data["count"] != 1 || hits[0].PassageID != corpus.DocumentID("https://www.bls.gov/cpi/")+"#p0" {
+		t.Errorf("unexpected passages %+v", data)
+	}
+	if citations := data["citations"].([]map[string]any); citations[0]["url"] != "https://www.bls.gov/cpi/" {
+		t.Errorf("expected the document cited by its URL, got %v", citations)
+	}
+
+	if _, err := tool.Execute(context.Background(), ToolInput{Arguments: map[string]any{}}); err == nil {
+		t.Error("expected an error without a query")
 	}
 }
 
@@ -145,12 +268,64 @@ func TestMarketDataTool(t *testing.T) {
 		t.Errorf("expected type 'function', got %v", format["type"])
 	}
 
-	fn, ok := format["function"].(map[string]any)
-	if !ok {
-		t.Fatal("expected function field")
+	// Responses API uses a flat function format
+	if format["name"] != "get_market_data" {
+		t.Errorf("expected name 'get_market_data', got %v", format["name"])
+	}
+}
+
+// TestBSCScanToolReplay replays a recorded BSCScan price lookup. Re-record with
+// HTTPREC_MODE=record BSCSCAN_API_KEY=...
+func TestBSCScanToolReplay(t *testing.T) {
+	recorder := httprectest.New(t, "testdata/cassettes/bscscan_price.json")
+
+	tool := NewBSCScanTool(os.Getenv("BSCSCAN_API_KEY"))
+	tool.SetTransport(recorder)
+
+	output, err := tool.Execute(context.Background(), ToolInput{
+		CallID:    "test_1",
+		Arguments: map[string]any{"action": "price"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if recorder.Mode() == httprec.ModeRecord {
+		return
+	}
+
+	data := output.Data.(map[string]any)
+	if data["bnb_usd"] != "678.41" {
+		t.Errorf("expected bnb_usd 678.41, got %v", data["bnb_usd"])
+	}
+}
+
+// TestPancakeSwapToolReplay replays a recorded subgraph volume query
+func TestPancakeSwapToolReplay(t *testing.T) {
+	recorder := httprectest.New(t, "testdata/cassettes/pancakeswap_volume.json")
+
+	tool := NewPancakeSwapTool(nil)
+	tool.SetTransport(recorder)
+
+	output, err := tool.Execute(context.Background(), ToolInput{
+		CallID: "test_1",
+		Arguments: map[string]any{
+			"action": "volume",
+			"token0": "0xbb4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c",
+			"token1": "0x55d398326f99059fF775485246999027B3197955",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if recorder.Mode() == httprec.ModeRecord {
+		return
 	}
 
-	if fn["name"] != "get_market_data" {
-		t.Errorf("expected name 'get_market_data', got %v", fn["name"])
+	data := output.Data.(map[string]any)
+	if data["period"] != 86400 {
+		t.Errorf("expected 24h period, got %v", data["period"])
+	}
+	if data["twap_data"] == nil {
+		t.Error("expected subgraph data in output")
 	}
 }
@@ -2,43 +2,175 @@ package tools
 
 import (
 	"context"
+	"fmt"
+	"strings"
+
+	"github.com/project-gamma/ai-resolver/internal/credibility"
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+)
+
+const (
+	// maxSearchResults caps the results one search returns
+	maxSearchResults = 20
+
+	// maxSearchPages caps the provider pages one search reads
+	maxSearchPages = 5
+
+	// maxSnippetLength caps a result's snippet, in characters
+	maxSnippetLength = 500
 )
 
-// WebSearchTool wraps OpenAI's web_search_preview functionality
-// This tool type doesn't require parameters - OpenAI handles it automatically
+// WebSearchTool searches the web through a SearchProvider, so that any LLM
+// API with function calling can search
 type WebSearchTool struct {
 	*BaseTool
+	provider SearchProvider
+	limit    int
 }
 
-// NewWebSearchTool creates a new web search tool
-func NewWebSearchTool() *WebSearchTool {
+// NewWebSearchTool creates a new web search tool backed by provider
+func NewWebSearchTool(provider SearchProvider) *WebSearchTool {
+	schema := &ToolSchema{
+		Type: "object",
+		Properties: map[string]Property{
+			"query": {
+				Type:        "string",
+				Description: "Search query, e.g. \"BLS CPI March 2025 release\"",
+			},
+			"limit": {
+				Type:        "integer",
+				Description: fmt.Sprintf("Maximum results to return (1-%d, default 10)", maxSearchResults),
+			},
+		},
+		Required: []string{"query"},
+	}
+
 	base := NewBaseTool(
-		"web_search",
-		"Search the web for current information using OpenAI's integrated web search. The LLM will automatically use this when it needs up-to-date information from the internet.",
-		ToolTypeWebSearchPreview,
-		nil, // No schema needed - OpenAI handles this internally
+		"search_web",
+		"Search the web. Returns result titles, URLs and snippets; fetch or cite a result by its URL.",
+		ToolTypeFunction,
+		schema,
 	)
 
 	tool := &WebSearchTool{
 		BaseTool: base,
+		provider: provider,
+		limit:    10,
 	}
 
-	// Set executor - for web_search_preview, execution is handled by OpenAI
-	// This executor is a no-op that returns metadata
 	base.SetExecutor(tool.execute)
 
 	return tool
 }
 
-// execute is a no-op executor since web search is handled by OpenAI
+// SetDefaultLimit sets how many results a search returns when the LLM does not
+// ask for a number
+func (t *WebSearchTool) SetDefaultLimit(limit int) {
+	if limit >= 1 {
+		t.limit = min(limit, maxSearchResults)
+	}
+}
+
+// execute runs the search
 func (t *WebSearchTool) execute(ctx context.Context, input ToolInput) (ToolOutput, error) {
-	// Web search is handled entirely by OpenAI's API
-	// This method shouldn't be called in normal flow
+	query, ok := input.Arguments["query"].(string)
+	if !ok || strings.TrimSpace(query) == "" {
+		return ToolOutput{
+			CallID: input.CallID,
+			Error:  fmt.Errorf("query is required"),
+		}, fmt.Errorf("query is required")
+	}
+
+	limit := t.limit
+	if l, ok := input.Arguments["limit"].(float64); ok && l >= 1 {
+		limit = min(int(l), maxSearchResults)
+	}
+
+	results, err := Search(ctx, t.provider, query, limit)
+	if err != nil {
+		return ToolOutput{
+			CallID: input.CallID,
+			Error:  fmt.Errorf("web search failed: %w", err),
+		}, fmt.Errorf("web search failed: %w", err)
+	}
+
+	citations := make([]map[string]any, 0, len(results))
+	for _, r := range results {
+		citations = append(citations, map[string]any{"url": r.URL, "title": r.Title})
+	}
+
 	return ToolOutput{
 		CallID: input.CallID,
 		Data: map[string]any{
-			"message": "Web search is handled by OpenAI API",
-			"status":  "delegated_to_openai",
+			"query":     query,
+			"provider":  t.provider.Name(),
+			"count":     len(results),
+			"results":   results,
+			"citations": citations,
 		},
 	}, nil
 }
+
+// Search reads pages from provider until it has limit distinct results or
+// runs out. Results are normalized: snippets are reduced to plain text,
+// non-http(s) URLs are dropped, and URLs that are the same page once
+// canonicalized are kept once.
+func Search(ctx context.Context, provider SearchProvider, query string, limit int) ([]SearchResult, error) {
+	seen := make(map[string]bool)
+	results := make([]SearchResult, 0, limit)
+	for page := 0; page < maxSearchPages && len(results) < limit; page++ {
+		got, err := provider.Search(ctx, SearchRequest{Query: query, Page: page, Count: limit})
+		if err != nil {
+			if page > 0 && len(results) > 0 {
+				// Keep what the earlier pages found
+				break
+			}
+			return nil, err
+		}
+
+		for _, r := range got.Results {
+			if !strings.HasPrefix(r.URL, "https://") && !strings.HasPrefix(r.URL, "http://") {
+				continue
+			}
+			key := credibility.CanonicalURL(r.URL)
+			if key == "" {
+				key = r.URL
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			results = append(results, normalizeResult(r))
+			if len(results) == limit {
+				break
+			}
+		}
+		if !got.More || len(got.Results) == 0 {
+			break
+		}
+	}
+	return results, nil
+}
+
+// normalizeResult reduces a result's title and snippet to plain, single-line
+// text
+func normalizeResult(r SearchResult) SearchResult {
+	r.Title = plainText(r.Title)
+	if r.Title == "" {
+		r.Title = r.URL
+	}
+	r.Snippet = plainText(r.Snippet)
+	if s := []rune(r.Snippet); len(s) > maxSnippetLength {
+		r.Snippet = string(s[:maxSnippetLength-1]) + "…"
+	}
+	r.Published = strings.TrimSpace(r.Published)
+	return r
+}
+
+// plainText strips the markup providers use to highlight matches
+func plainText(s string) string {
+	if strings.ContainsAny(s, "<&") {
+		_, s = fetch.ExtractText(s)
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
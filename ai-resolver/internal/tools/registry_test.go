@@ -423,13 +423,9 @@ func TestToOpenAIFormat(t *testing.T) {
 		t.Fatal("function tool not found in format")
 	}
 
-	function, ok := functionFormat["function"].(map[string]any)
-	if !ok {
-		t.Fatal("expected function object")
-	}
-
-	if function["name"] != "search" {
-		t.Errorf("expected name 'search', got %v", function["name"])
+	// Responses API uses a flat function format
+	if functionFormat["name"] != "search" {
+		t.Errorf("expected name 'search', got %v", functionFormat["name"])
 	}
 }
 
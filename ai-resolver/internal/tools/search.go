@@ -0,0 +1,259 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SearchProvider is a web search API behind the web search tool
+type SearchProvider interface {
+	// Name identifies the provider, e.g. "brave"
+	Name() string
+
+	// Search returns one page of results
+	Search(ctx context.Context, req SearchRequest) (SearchPage, error)
+
+	// SetTransport replaces the HTTP transport used for API calls
+	SetTransport(transport http.RoundTripper)
+}
+
+// SearchRequest asks for one page of results
+type SearchRequest struct {
+	Query string
+	Page  int // 0-based
+	Count int // Results per page; providers may return fewer
+}
+
+// SearchResult is one result as the provider returned it
+type SearchResult struct {
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	Snippet   string `json:"snippet"`
+	Published string `json:"published,omitempty"`
+}
+
+// SearchPage is a page of results
+type SearchPage struct {
+	Results []SearchResult
+	More    bool // Another page may have results
+}
+
+// NewSearchProvider creates the provider named kind: "searxng" for a
+// self-hosted SearxNG instance at endpoint, "brave" for the Brave Search API,
+// or "bing" for a Bing Web Search compatible API. An empty endpoint uses the
+// provider's public API.
+func NewSearchProvider(kind, endpoint, apiKey string) (SearchProvider, error) {
+	switch strings.ToLower(kind) {
+	case "searxng":
+		if endpoint == "" {
+			return nil, fmt.Errorf("searxng needs the URL of its instance")
+		}
+		return NewSearxNGProvider(endpoint), nil
+	case "brave":
+		if apiKey == "" {
+			return nil, fmt.Errorf("brave needs an API key")
+		}
+		p := NewBraveProvider(apiKey)
+		if endpoint != "" {
+			p.baseURL = endpoint
+		}
+		return p, nil
+	case "bing":
+		if apiKey == "" {
+			return nil, fmt.Errorf("bing needs an API key")
+		}
+		p := NewBingProvider(apiKey)
+		if endpoint != "" {
+			p.baseURL = endpoint
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("unknown search provider %q (use searxng, brave or bing)", kind)
+	}
+}
+
+// searchClient is the HTTP side shared by the providers
+type searchClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func newSearchClient(baseURL string) searchClient {
+	return searchClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+// SetTransport replaces the HTTP transport used for API calls
+func (c *searchClient) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
+
+// get calls path with params and decodes the JSON response into out
+func (c *searchClient) get(ctx context.Context, path string, params url.Values, headers map[string]string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call search API: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("search API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// SearxNGProvider searches a self-hosted SearxNG instance through its JSON
+// API, which must be enabled in the instance's settings (search.formats)
+type SearxNGProvider struct {
+	searchClient
+}
+
+// NewSearxNGProvider creates a provider for the instance at baseURL
+func NewSearxNGProvider(baseURL string) *SearxNGProvider {
+	return &SearxNGProvider{searchClient: newSearchClient(baseURL)}
+}
+
+// Name implements SearchProvider
+func (p *SearxNGProvider) Name() string { return "searxng" }
+
+// Search implements SearchProvider. SearxNG pages hold as many results as
+// its engines return; Count is not sent.
+func (p *SearxNGProvider) Search(ctx context.Context, req SearchRequest) (SearchPage, error) {
+	params := url.Values{"q": {req.Query}, "format": {"json"}, "pageno": {strconv.Itoa(req.Page + 1)}}
+	var resp struct {
+		Results []struct {
+			URL           string `json:"url"`
+			Title         string `json:"title"`
+			Content       string `json:"content"`
+			PublishedDate string `json:"publishedDate"`
+		} `json:"results"`
+	}
+	if err := p.get(ctx, "/search", params, nil, &resp); err != nil {
+		return SearchPage{}, err
+	}
+
+	page := SearchPage{More: len(resp.Results) > 0}
+	for _, r := range resp.Results {
+		page.Results = append(page.Results, SearchResult{Title: r.Title, URL: r.URL, Snippet: r.Content, Published: r.PublishedDate})
+	}
+	return page, nil
+}
+
+// BraveProvider searches the Brave Search API
+type BraveProvider struct {
+	searchClient
+	apiKey string
+}
+
+// NewBraveProvider creates a Brave Search provider
+func NewBraveProvider(apiKey string) *BraveProvider {
+	return &BraveProvider{searchClient: newSearchClient("https://api.search.brave.com/res/v1"), apiKey: apiKey}
+}
+
+// Name implements SearchProvider
+func (p *BraveProvider) Name() string { return "brave" }
+
+// Search implements SearchProvider. Brave counts offsets in pages, up to 9,
+// of at most 20 results.
+func (p *BraveProvider) Search(ctx context.Context, req SearchRequest) (SearchPage, error) {
+	if req.Page > 9 {
+		return SearchPage{}, nil
+	}
+	params := url.Values{
+		"q":      {req.Query},
+		"count":  {strconv.Itoa(min(max(req.Count, 1), 20))},
+		"offset": {strconv.Itoa(req.Page)},
+	}
+	var resp struct {
+		Query struct {
+			MoreResultsAvailable bool `json:"more_results_available"`
+		} `json:"query"`
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+				PageAge     string `json:"page_age"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := p.get(ctx, "/web/search", params, map[string]string{"X-Subscription-Token": p.apiKey}, &resp); err != nil {
+		return SearchPage{}, err
+	}
+
+	page := SearchPage{More: resp.Query.MoreResultsAvailable && req.Page < 9}
+	for _, r := range resp.Web.Results {
+		page.Results = append(page.Results, SearchResult{Title: r.Title, URL: r.URL, Snippet: r.Description, Published: r.PageAge})
+	}
+	return page, nil
+}
+
+// BingProvider searches a Bing Web Search v7 compatible API
+type BingProvider struct {
+	searchClient
+	apiKey string
+}
+
+// NewBingProvider creates a Bing-style provider
+func NewBingProvider(apiKey string) *BingProvider {
+	return &BingProvider{searchClient: newSearchClient("https://api.bing.microsoft.com"), apiKey: apiKey}
+}
+
+// Name implements SearchProvider
+func (p *BingProvider) Name() string { return "bing" }
+
+// Search implements SearchProvider. Bing counts offsets in results, and
+// pages hold at most 50.
+func (p *BingProvider) Search(ctx context.Context, req SearchRequest) (SearchPage, error) {
+	count := min(max(req.Count, 1), 50)
+	params := url.Values{
+		"q":              {req.Query},
+		"count":          {strconv.Itoa(count)},
+		"offset":         {strconv.Itoa(req.Page * count)},
+		"responseFilter": {"Webpages"},
+	}
+	var resp struct {
+		WebPages struct {
+			TotalEstimatedMatches int `json:"totalEstimatedMatches"`
+			Value                 []struct {
+				Name          string `json:"name"`
+				URL           string `json:"url"`
+				Snippet       string `json:"snippet"`
+				DatePublished string `json:"datePublished"`
+			} `json:"value"`
+		} `json:"webPages"`
+	}
+	if err := p.get(ctx, "/v7.0/search", params, map[string]string{"Ocp-Apim-Subscription-Key": p.apiKey}, &resp); err != nil {
+		return SearchPage{}, err
+	}
+
+	page := SearchPage{More: len(resp.WebPages.Value) > 0 && (req.Page+1)*count < resp.WebPages.TotalEstimatedMatches}
+	for _, r := range resp.WebPages.Value {
+		page.Results = append(page.Results, SearchResult{Title: r.Name, URL: r.URL, Snippet: r.Snippet, Published: r.DatePublished})
+	}
+	return page, nil
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// TestSearchProviders tests each provider's request and response mapping
+// against a fake API
+func TestSearchProviders(t *testing.T) {
+	tests := []struct {
+		kind     string
+		path     string
+		header   string
+		params   url.Values // Expected for page 1 of 3 results
+		response string
+		more     bool
+	}{
+		{
+			kind:     "searxng",
+			path:     "/search",
+			params:   url.Values{"q": {"cpi march"}, "format": {"json"}, "pageno": {"2"}},
+			response: `{"results":[{"url":"https://www.bls.gov/cpi/","title":"CPI Home","content":"Consumer Price Index","publishedDate":"2025-04-10T00:00:00"}]}`,
+			more:     true,
+		},
+		{
+			kind:     "brave",
+			path:     "/web/search",
+			header:   "X-Subscription-Token",
+			params:   url.Values{"q": {"cpi march"}, "count": {"3"}, "offset": {"1"}},
+			response: `{"query":{"more_results_available":false},"web":{"results":[{"title":"CPI Home","url":"https://www.bls.gov/cpi/","description":"Consumer Price Index","page_age":"2025-04-10T00:00:00"}]}}`,
+		},
+		{
+			kind:     "bing",
+			path:     "/v7.0/search",
+			header:   "Ocp-Apim-Subscription-Key",
+			params:   url.Values{"q": {"cpi march"}, "count": {"3"}, "offset": {"3"}, "responseFilter": {"Webpages"}},
+			response: `{"webPages":{"totalEstimatedMatches":100,"value":[{"name":"CPI Home","url":"https://www.bls.gov/cpi/","snippet":"Consumer Price Index","datePublished":"2025-04-10T00:00:00"}]}}`,
+			more:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					t.Errorf("expected path %s, got %s", tt.path, r.URL.Path)
+				}
+				if tt.header != "" && r.Header.Get(tt.header) != "key" {
+					t.Errorf("expected the API key in %s", tt.header)
+				}
+				for name, want := range tt.params {
+					if got := r.URL.Query().Get(name); got != want[0] {
+						t.Errorf("expected %s=%s, got %q", name, want[0], got)
+					}
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			provider, err := NewSearchProvider(tt.kind, server.URL, "key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			page, err := provider.Search(context.Background(), SearchRequest{Query: "cpi march", Page: 1, Count: 3})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := SearchResult{Title: "CPI Home", URL: "https://www.bls.gov/cpi/", Snippet: "Consumer Price Index", Published: "2025-04-10T00:00:00"}
+			if len(page.Results) != 1 || page.Results[0] != want || page.More != tt.more {
+				t.Errorf("unexpected page %+v", page)
+			}
+		})
+	}
+
+	if _, err := NewSearchProvider("brave", "", ""); err == nil {
+		t.Error("expected brave without an API key to be refused")
+	}
+	if _, err := NewSearchProvider("google", "", "key"); err == nil {
+		t.Error("expected an unknown provider to be refused")
+	}
+}
+
+// TestSearchProviderError tests that API errors surface with their status
+func TestSearchProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	if _, err := NewSearxNGProvider(server.URL).Search(context.Background(), SearchRequest{Query: "x"}); err == nil {
+		t.Error("expected an error for status 429")
+	}
+}
@@ -0,0 +1,350 @@
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// daysKept bounds how many days of spending the ledger remembers
+const daysKept = 90
+
+// Budget limits LLM spending in USD. Zero disables a limit.
+type Budget struct {
+	PerMarketUSD float64 `json:"perMarketUsd"`
+	PerDayUSD    float64 `json:"perDayUsd"`
+}
+
+// BudgetError is returned when a budget is exhausted
+type BudgetError struct {
+	Scope    string // "market" or "day"
+	LimitUSD float64
+	Spent    float64
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("LLM %s budget exceeded: spent $%.4f of $%.2f", e.Scope, e.Spent, e.LimitUSD)
+}
+
+// ErrBudgetExceeded matches any *BudgetError with errors.Is
+var ErrBudgetExceeded = errors.New("LLM budget exceeded")
+
+func (e *BudgetError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// Totals accumulates calls, tokens and cost
+type Totals struct {
+	Calls    int64   `json:"calls"`
+	Tokens   Tokens  `json:"tokens"`
+	CostUSD  float64 `json:"costUsd"`
+	Unpriced int64   `json:"unpriced,omitempty"` // Calls to models missing from the price table
+}
+
+func (t *Totals) add(tokens Tokens, cost float64, priced bool) {
+	t.Calls++
+	t.Tokens.Add(tokens)
+	t.CostUSD += cost
+	if !priced {
+		t.Unpriced++
+	}
+}
+
+// ledgerState is the persisted part of a Ledger
+type ledgerState struct {
+	Days    map[string]*Totals `json:"days"`    // UTC date → totals
+	Markets map[string]*Totals `json:"markets"` // "chainId/marketId" → totals
+	Models  map[string]*Totals `json:"models"`
+	Steps   map[string]*Totals `json:"steps"`
+}
+
+// Ledger tracks LLM spending across all analyses and enforces budgets. It is
+// safe for concurrent use.
+type Ledger struct {
+	prices PriceTable
+	budget Budget
+	path   string // Optional file the ledger is persisted to
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    ledgerState
+	exceeded map[string]int64 // Budget scope → aborted calls
+}
+
+// NewLedger creates a ledger. If path is set, totals are loaded from and saved
+// to that file so budgets survive restarts.
+func NewLedger(prices PriceTable, budget Budget, path string) (*Ledger, error) {
+	l := &Ledger{
+		prices: prices,
+		budget: budget,
+		path:   path,
+		now:    time.Now,
+		state: ledgerState{
+			Days:    make(map[string]*Totals),
+			Markets: make(map[string]*Totals),
+			Models:  make(map[string]*Totals),
+			Steps:   make(map[string]*Totals),
+		},
+		exceeded: make(map[string]int64),
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+		default:
+			if err := json.Unmarshal(data, &l.state); err != nil {
+				return nil, fmt.Errorf("failed to parse usage ledger %s: %w", path, err)
+			}
+		}
+	}
+	for _, m := range []*map[string]*Totals{&l.state.Days, &l.state.Markets, &l.state.Models, &l.state.Steps} {
+		if *m == nil {
+			*m = make(map[string]*Totals)
+		}
+	}
+
+	return l, nil
+}
+
+// Budget returns the configured budget
+func (l *Ledger) Budget() Budget {
+	return l.budget
+}
+
+// Meter returns a meter that charges a market
+func (l *Ledger) Meter(chainID int64, marketID uint64) *Meter {
+	return &Meter{
+		ledger: l,
+		market: marketKey(chainID, marketID),
+		steps:  make(map[string]*Totals),
+	}
+}
+
+// Market returns the totals charged to a market
+func (l *Ledger) Market(chainID int64, marketID uint64) Totals {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return valueOf(l.state.Markets[marketKey(chainID, marketID)])
+}
+
+// Report is a snapshot of the ledger
+type Report struct {
+	Date    string            `json:"date"` // Current UTC date
+	Today   Totals            `json:"today"`
+	Budget  Budget            `json:"budget"`
+	Days    map[string]Totals `json:"days"`
+	Models  map[string]Totals `json:"models"`
+	Steps   map[string]Totals `json:"steps"`
+	Markets []MarketTotals    `json:"markets"` // Most expensive first
+}
+
+// MarketTotals is the spending of one market
+type MarketTotals struct {
+	ChainID  int64  `json:"chainId"`
+	MarketID uint64 `json:"marketId"`
+	Totals
+}
+
+// Report returns a snapshot, listing at most topMarkets markets (0 for all)
+func (l *Ledger) Report(topMarkets int) Report {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	date := l.today()
+	r := Report{
+		Date:   date,
+		Today:  valueOf(l.state.Days[date]),
+		Budget: l.budget,
+		Days:   copyTotals(l.state.Days),
+		Models: copyTotals(l.state.Models),
+		Steps:  copyTotals(l.state.Steps),
+	}
+
+	for key, t := range l.state.Markets {
+		var m MarketTotals
+		fmt.Sscanf(strings.Replace(key, "/", " ", 1), "%d %d", &m.ChainID, &m.MarketID)
+		m.Totals = *t
+		r.Markets = append(r.Markets, m)
+	}
+	sort.Slice(r.Markets, func(i, j int) bool {
+		return r.Markets[i].CostUSD > r.Markets[j].CostUSD
+	})
+	if topMarkets > 0 && len(r.Markets) > topMarkets {
+		r.Markets = r.Markets[:topMarkets]
+	}
+
+	return r
+}
+
+// check returns a BudgetError if the market or today's budget is spent
+func (l *Ledger) check(market string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limit := l.budget.PerMarketUSD; limit > 0 {
+		if spent := valueOf(l.state.Markets[market]).CostUSD; spent >= limit {
+			l.exceeded["market"]++
+			return &BudgetError{Scope: "market", LimitUSD: limit, Spent: spent}
+		}
+	}
+	if limit := l.budget.PerDayUSD; limit > 0 {
+		if spent := valueOf(l.state.Days[l.today()]).CostUSD; spent >= limit {
+			l.exceeded["day"]++
+			return &BudgetError{Scope: "day", LimitUSD: limit, Spent: spent}
+		}
+	}
+	return nil
+}
+
+// record charges a call and persists the ledger
+func (l *Ledger) record(market, step, model string, tokens Tokens) (float64, bool) {
+	price, priced := l.prices.Lookup(model)
+	cost := price.Cost(tokens)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, t := range []*Totals{
+		entry(l.state.Days, l.today()),
+		entry(l.state.Markets, market),
+		entry(l.state.Models, model),
+		entry(l.state.Steps, step),
+	} {
+		t.add(tokens, cost, priced)
+	}
+	l.pruneDays()
+
+	if err := l.save(); err != nil {
+		log.Printf("Warning: failed to save usage ledger: %v", err)
+	}
+	return cost, priced
+}
+
+// save writes the ledger atomically. Callers hold l.mu.
+func (l *Ledger) save() error {
+	if l.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(l.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
+
+func (l *Ledger) pruneDays() {
+	if len(l.state.Days) <= daysKept {
+		return
+	}
+	cutoff := l.now().UTC().AddDate(0, 0, -daysKept).Format(time.DateOnly)
+	for day := range l.state.Days {
+		if day < cutoff {
+			delete(l.state.Days, day)
+		}
+	}
+}
+
+func (l *Ledger) today() string {
+	return l.now().UTC().Format(time.DateOnly)
+}
+
+// Meter charges the API calls of one analysis to its market and keeps the
+// analysis' own totals
+type Meter struct {
+	ledger *Ledger
+	market string
+
+	mu    sync.Mutex
+	total Totals
+	steps map[string]*Totals
+}
+
+// Check returns a *BudgetError if the market or daily budget is exhausted
+func (m *Meter) Check() error {
+	if m == nil {
+		return nil
+	}
+	return m.ledger.check(m.market)
+}
+
+// Record charges one API call. step is the pipeline step; sub-steps such as
+// "extract_facts/repair" are charged to their step. It returns the call's cost
+// and whether the model was priced.
+func (m *Meter) Record(step, model string, tokens Tokens) (float64, bool) {
+	if m == nil {
+		return 0, false
+	}
+	step, _, _ = strings.Cut(step, "/")
+	if step == "" {
+		step = "unknown"
+	}
+
+	cost, priced := m.ledger.record(m.market, step, model, tokens)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.total.add(tokens, cost, priced)
+	entry(m.steps, step).add(tokens, cost, priced)
+	return cost, priced
+}
+
+// Summary is the usage of one analysis
+type Summary struct {
+	Totals
+	Steps map[string]Totals `json:"steps"`
+}
+
+// Summary returns the analysis' totals, or nil for a nil meter
+func (m *Meter) Summary() *Summary {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &Summary{Totals: m.total, Steps: copyTotals(m.steps)}
+}
+
+func marketKey(chainID int64, marketID uint64) string {
+	return fmt.Sprintf("%d/%d", chainID, marketID)
+}
+
+func entry(m map[string]*Totals, key string) *Totals {
+	t, ok := m[key]
+	if !ok {
+		t = &Totals{}
+		m[key] = t
+	}
+	return t
+}
+
+func valueOf(t *Totals) Totals {
+	if t == nil {
+		return Totals{}
+	}
+	return *t
+}
+
+func copyTotals(m map[string]*Totals) map[string]Totals {
+	out := make(map[string]Totals, len(m))
+	for k, v := range m {
+		out[k] = *v
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package usage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WritePrometheus writes the ledger's counters in the Prometheus text format
+func (l *Ledger) WritePrometheus(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	type series struct {
+		label string
+		t     *Totals
+	}
+	var all []series
+	for model, t := range l.state.Models {
+		all = append(all, series{fmt.Sprintf(`by="model",model=%q`, escapeLabel(model)), t})
+	}
+	for step, t := range l.state.Steps {
+		all = append(all, series{fmt.Sprintf(`by="step",step=%q`, escapeLabel(step)), t})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].label < all[j].label })
+
+	fmt.Fprintln(w, "# HELP resolver_llm_calls_total LLM API calls.")
+	fmt.Fprintln(w, "# TYPE resolver_llm_calls_total counter")
+	for _, s := range all {
+		fmt.Fprintf(w, "resolver_llm_calls_total{%s} %d\n", s.label, s.t.Calls)
+	}
+
+	fmt.Fprintln(w, "# HELP resolver_llm_tokens_total LLM tokens by kind.")
+	fmt.Fprintln(w, "# TYPE resolver_llm_tokens_total counter")
+	for _, s := range all {
+		for _, kind := range []struct {
+			name  string
+			value int64
+		}{
+			{"input", s.t.Tokens.Input},
+			{"cached_input", s.t.Tokens.CachedInput},
+			{"output", s.t.Tokens.Output},
+			{"reasoning", s.t.Tokens.Reasoning},
+		} {
+			fmt.Fprintf(w, "resolver_llm_tokens_total{%s,kind=%q} %d\n", s.label, kind.name, kind.value)
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP resolver_llm_web_search_calls_total Web search tool calls billed by the provider.")
+	fmt.Fprintln(w, "# TYPE resolver_llm_web_search_calls_total counter")
+	for _, s := range all {
+		fmt.Fprintf(w, "resolver_llm_web_search_calls_total{%s} %d\n", s.label, s.t.Tokens.WebSearchCalls)
+	}
+
+	fmt.Fprintln(w, "# HELP resolver_llm_cost_usd_total LLM cost in USD from the price table.")
+	fmt.Fprintln(w, "# TYPE resolver_llm_cost_usd_total counter")
+	for _, s := range all {
+		fmt.Fprintf(w, "resolver_llm_cost_usd_total{%s} %g\n", s.label, s.t.CostUSD)
+	}
+
+	fmt.Fprintln(w, "# HELP resolver_llm_unpriced_calls_total LLM calls to models missing from the price table.")
+	fmt.Fprintln(w, "# TYPE resolver_llm_unpriced_calls_total counter")
+	for _, s := range all {
+		fmt.Fprintf(w, "resolver_llm_unpriced_calls_total{%s} %d\n", s.label, s.t.Unpriced)
+	}
+
+	fmt.Fprintln(w, "# HELP resolver_llm_cost_today_usd LLM cost in USD for the current UTC day.")
+	fmt.Fprintln(w, "# TYPE resolver_llm_cost_today_usd gauge")
+	fmt.Fprintf(w, "resolver_llm_cost_today_usd %g\n", valueOf(l.state.Days[l.today()]).CostUSD)
+
+	fmt.Fprintln(w, "# HELP resolver_llm_budget_usd Configured LLM budgets in USD (0 = unlimited).")
+	fmt.Fprintln(w, "# TYPE resolver_llm_budget_usd gauge")
+	fmt.Fprintf(w, "resolver_llm_budget_usd{scope=\"day\"} %g\n", l.budget.PerDayUSD)
+	fmt.Fprintf(w, "resolver_llm_budget_usd{scope=\"market\"} %g\n", l.budget.PerMarketUSD)
+
+	fmt.Fprintln(w, "# HELP resolver_llm_budget_exceeded_total LLM calls refused because a budget was spent.")
+	fmt.Fprintln(w, "# TYPE resolver_llm_budget_exceeded_total counter")
+	for _, scope := range []string{"day", "market"} {
+		fmt.Fprintf(w, "resolver_llm_budget_exceeded_total{scope=%q} %d\n", scope, l.exceeded[scope])
+	}
+}
+
+// escapeLabel keeps label values on one line; %q handles quotes and backslashes
+func escapeLabel(s string) string {
+	return strings.ReplaceAll(s, "\n", " ")
+}
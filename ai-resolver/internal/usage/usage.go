@@ -0,0 +1,133 @@
+// Package usage accounts for LLM token usage and cost per call, per pipeline
+// step and per market, and enforces per-market and per-day spending budgets.
+//
+// A process-wide Ledger holds the totals and budgets. Each analysis gets a
+// Meter from the ledger, carried in its context, which the pipeline checks
+// before every API call and credits after it.
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Tokens is the token usage reported by one or more API calls
+type Tokens struct {
+	Input          int64 `json:"input"`
+	CachedInput    int64 `json:"cachedInput"` // Subset of Input billed at the cached rate
+	Output         int64 `json:"output"`
+	Reasoning      int64 `json:"reasoning"` // Subset of Output
+	WebSearchCalls int64 `json:"webSearchCalls"`
+}
+
+// Add accumulates other into t
+func (t *Tokens) Add(other Tokens) {
+	t.Input += other.Input
+	t.CachedInput += other.CachedInput
+	t.Output += other.Output
+	t.Reasoning += other.Reasoning
+	t.WebSearchCalls += other.WebSearchCalls
+}
+
+// Total returns input plus output tokens
+func (t Tokens) Total() int64 {
+	return t.Input + t.Output
+}
+
+// Price is the cost of a model in USD
+type Price struct {
+	InputPerMillion       float64 `json:"inputPerMillion"`
+	CachedInputPerMillion float64 `json:"cachedInputPerMillion"`
+	OutputPerMillion      float64 `json:"outputPerMillion"`
+	WebSearchPerCall      float64 `json:"webSearchPerCall"`
+}
+
+// Cost prices a token usage
+func (p Price) Cost(t Tokens) float64 {
+	uncached := t.Input - t.CachedInput
+	if uncached < 0 {
+		uncached = 0
+	}
+	cachedRate := p.CachedInputPerMillion
+	if cachedRate == 0 {
+		cachedRate = p.InputPerMillion
+	}
+	return (float64(uncached)*p.InputPerMillion+
+		float64(t.CachedInput)*cachedRate+
+		float64(t.Output)*p.OutputPerMillion)/1_000_000 +
+		float64(t.WebSearchCalls)*p.WebSearchPerCall
+}
+
+// PriceTable maps model names to prices. A model without an exact entry uses
+// the longest entry that prefixes it, so "gpt-4o" also prices
+// "gpt-4o-2024-08-06".
+type PriceTable map[string]Price
+
+// Lookup returns the price of a model
+func (t PriceTable) Lookup(model string) (Price, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+	best := ""
+	for name := range t {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return t[best], true
+}
+
+// DefaultPrices returns list prices for common OpenAI models. Override them
+// with a price table file when prices change.
+func DefaultPrices() PriceTable {
+	const webSearch = 0.01 // $10 per 1k web search tool calls
+	return PriceTable{
+		"gpt-4o":              {InputPerMillion: 2.50, CachedInputPerMillion: 1.25, OutputPerMillion: 10.00, WebSearchPerCall: webSearch},
+		"gpt-4o-mini":         {InputPerMillion: 0.15, CachedInputPerMillion: 0.075, OutputPerMillion: 0.60, WebSearchPerCall: webSearch},
+		"gpt-4.1":             {InputPerMillion: 2.00, CachedInputPerMillion: 0.50, OutputPerMillion: 8.00, WebSearchPerCall: webSearch},
+		"gpt-4.1-mini":        {InputPerMillion: 0.40, CachedInputPerMillion: 0.10, OutputPerMillion: 1.60, WebSearchPerCall: webSearch},
+		"gpt-4-turbo":         {InputPerMillion: 10.00, OutputPerMillion: 30.00, WebSearchPerCall: webSearch},
+		"gpt-4-turbo-preview": {InputPerMillion: 10.00, OutputPerMillion: 30.00, WebSearchPerCall: webSearch},
+	}
+}
+
+// LoadPriceTable reads a JSON price table and layers it over the defaults
+func LoadPriceTable(path string) (PriceTable, error) {
+	table := DefaultPrices()
+	if path == "" {
+		return table, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price table: %w", err)
+	}
+	var overrides PriceTable
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse price table %s: %w", path, err)
+	}
+	for model, price := range overrides {
+		table[model] = price
+	}
+	return table, nil
+}
+
+type meterKey struct{}
+
+// WithMeter attaches a meter to ctx
+func WithMeter(ctx context.Context, m *Meter) context.Context {
+	return context.WithValue(ctx, meterKey{}, m)
+}
+
+// FromContext returns the meter attached to ctx, or nil. Meter methods are
+// no-ops on a nil meter, so unmetered callers (tests, replays) need not check.
+func FromContext(ctx context.Context) *Meter {
+	m, _ := ctx.Value(meterKey{}).(*Meter)
+	return m
+}
@@ -0,0 +1,134 @@
+package usage
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPriceLookup tests exact and prefix price lookups and costing
+func TestPriceLookup(t *testing.T) {
+	table := PriceTable{
+		"gpt-4o":      {InputPerMillion: 2.5, CachedInputPerMillion: 1.25, OutputPerMillion: 10, WebSearchPerCall: 0.01},
+		"gpt-4o-mini": {InputPerMillion: 0.15, OutputPerMillion: 0.6},
+	}
+
+	price, ok := table.Lookup("gpt-4o-2024-08-06")
+	if !ok || price.InputPerMillion != 2.5 {
+		t.Errorf("expected gpt-4o prefix price, got %+v (%v)", price, ok)
+	}
+	if price, _ := table.Lookup("gpt-4o-mini-2024-07-18"); price.InputPerMillion != 0.15 {
+		t.Errorf("expected the longest prefix to win, got %+v", price)
+	}
+	if _, ok := table.Lookup("claude"); ok {
+		t.Error("expected no price for an unknown model")
+	}
+
+	// 800 uncached + 200 cached input, 100 output, 2 searches
+	cost := price.Cost(Tokens{Input: 1000, CachedInput: 200, Output: 100, WebSearchCalls: 2})
+	want := (800*2.5+200*1.25+100*10)/1e6 + 0.02
+	if math.Abs(cost-want) > 1e-12 {
+		t.Errorf("expected cost %g, got %g", want, cost)
+	}
+}
+
+// TestBudgets tests that spent market and daily budgets refuse further calls
+func TestBudgets(t *testing.T) {
+	prices := PriceTable{"m": {InputPerMillion: 1_000_000}} // $1 per input token
+	ledger, err := NewLedger(prices, Budget{PerMarketUSD: 2, PerDayUSD: 3}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := ledger.Meter(56, 1)
+	for i := 0; i < 2; i++ {
+		if err := a.Check(); err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+		a.Record("decide_outcome", "m", Tokens{Input: 1})
+	}
+	err = a.Check()
+	var budgetErr *BudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Scope != "market" || !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected market budget error, got %v", err)
+	}
+
+	b := ledger.Meter(56, 2)
+	if err := b.Check(); err != nil {
+		t.Fatalf("unexpected error for another market: %v", err)
+	}
+	b.Record("extract_facts/repair", "m", Tokens{Input: 1})
+	if err := b.Check(); !errors.As(err, &budgetErr) || budgetErr.Scope != "day" {
+		t.Fatalf("expected day budget error, got %v", err)
+	}
+
+	// A new day resets the daily budget but not the market's
+	ledger.now = func() time.Time { return time.Now().Add(24 * time.Hour) }
+	if err := b.Check(); err != nil {
+		t.Errorf("expected the daily budget to reset, got %v", err)
+	}
+	if err := a.Check(); err == nil {
+		t.Error("expected the market budget to persist across days")
+	}
+
+	summary := b.Summary()
+	if summary.Calls != 1 || summary.Steps["extract_facts"].Calls != 1 {
+		t.Errorf("expected sub-steps charged to their step, got %+v", summary)
+	}
+
+	var metrics strings.Builder
+	ledger.WritePrometheus(&metrics)
+	for _, want := range []string{
+		`resolver_llm_calls_total{by="model",model="m"} 3`,
+		`resolver_llm_cost_usd_total{by="step",step="decide_outcome"} 2`,
+		`resolver_llm_budget_exceeded_total{scope="market"} 2`,
+	} {
+		if !strings.Contains(metrics.String(), want) {
+			t.Errorf("expected %q in metrics:\n%s", want, metrics.String())
+		}
+	}
+}
+
+// TestLedgerPersistence tests that totals survive a restart
+func TestLedgerPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	ledger, err := NewLedger(DefaultPrices(), Budget{}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ledger.Meter(97, 7).Record("decide_outcome", "gpt-4o-2024-08-06", Tokens{Input: 1000, Output: 500})
+	ledger.Meter(97, 7).Record("decide_outcome", "local-model", Tokens{Input: 10})
+
+	reloaded, err := NewLedger(DefaultPrices(), Budget{}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	totals := reloaded.Market(97, 7)
+	if totals.Calls != 2 || totals.Tokens.Input != 1010 || totals.Unpriced != 1 {
+		t.Errorf("unexpected reloaded totals: %+v", totals)
+	}
+	report := reloaded.Report(0)
+	if len(report.Markets) != 1 || report.Markets[0].ChainID != 97 || report.Markets[0].MarketID != 7 {
+		t.Errorf("unexpected report markets: %+v", report.Markets)
+	}
+	if report.Today.CostUSD != totals.CostUSD || totals.CostUSD == 0 {
+		t.Errorf("expected today's cost %g, got %g", totals.CostUSD, report.Today.CostUSD)
+	}
+}
+
+// TestNilMeter tests that an absent meter is a no-op
+func TestNilMeter(t *testing.T) {
+	var m *Meter
+	if err := m.Check(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if cost, _ := m.Record("step", "gpt-4o", Tokens{Input: 1}); cost != 0 {
+		t.Errorf("expected no cost, got %g", cost)
+	}
+	if m.Summary() != nil {
+		t.Error("expected nil summary")
+	}
+}
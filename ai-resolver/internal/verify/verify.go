@@ -0,0 +1,129 @@
+// Package verify checks citations against the pages they cite: a cited page
+// is fetched and the snippet or claim attributed to it must appear in its
+// text. Wording may differ a little (punctuation, a changed word, markup
+// between words), so matching is by word overlap, not exact substring.
+package verify
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+)
+
+// Fetcher downloads pages; *fetch.Fetcher implements it
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (*fetch.Page, error)
+}
+
+// Result is the outcome of checking one cited URL
+type Result struct {
+	URL      string  `json:"url"`
+	Verified bool    `json:"verified"`
+	Score    float64 `json:"score"`           // Best match among the claims, 0-1
+	Claim    string  `json:"claim,omitempty"` // The claim that matched best
+	PageURL  string  `json:"pageUrl,omitempty"`
+	PageHash string  `json:"pageHash,omitempty"` // SHA-256 of the page as fetched
+	Reason   string  `json:"reason,omitempty"`   // Why it was not verified
+}
+
+// Verifier checks claims against cited pages
+type Verifier struct {
+	fetcher  Fetcher
+	minScore float64
+	minWords int
+}
+
+// New creates a verifier that needs 80% of a claim's words, in one passage
+// of the page, to call it verified
+func New(fetcher Fetcher) *Verifier {
+	return &Verifier{fetcher: fetcher, minScore: 0.8, minWords: 4}
+}
+
+// SetMinScore sets the share of a claim's words that must appear together
+// on the page
+func (v *Verifier) SetMinScore(score float64) {
+	v.minScore = score
+}
+
+// Verify fetches url and checks whether any of the claims appears on it.
+// Claims shorter than a few words are ignored: they would match almost any
+// page. An error means the page could not be fetched.
+func (v *Verifier) Verify(ctx context.Context, url string, claims []string) (Result, error) {
+	result := Result{URL: url}
+
+	var checkable []string
+	for _, claim := range claims {
+		if len(words(claim)) >= v.minWords {
+			checkable = append(checkable, claim)
+		}
+	}
+	if len(checkable) == 0 {
+		result.Reason = "no claim long enough to check"
+		return result, nil
+	}
+
+	page, err := v.fetcher.Fetch(ctx, url)
+	if err != nil {
+		result.Reason = err.Error()
+		return result, err
+	}
+	result.PageURL, result.PageHash = page.URL, page.Hash
+
+	text := words(page.Title + "\n" + page.Text)
+	for _, claim := range checkable {
+		if s := score(words(claim), text); s > result.Score {
+			result.Score, result.Claim = s, claim
+		}
+	}
+	result.Verified = result.Score >= v.minScore
+	if !result.Verified {
+		result.Reason = "claim not found on the page"
+	}
+	return result, nil
+}
+
+// Score returns the largest share of claim's words found in any passage of
+// text about as long as the claim. Word order is ignored within the passage.
+func Score(claim, text string) float64 {
+	return score(words(claim), words(text))
+}
+
+// score is Score on split words
+func score(want, text []string) float64 {
+	if len(want) == 0 {
+		return 0
+	}
+	need := make(map[string]int, len(want))
+	for _, w := range want {
+		need[w]++
+	}
+
+	// Slide a window over the text, tracking how many needed words it holds
+	window := len(want) + len(want)/4 // Room for a few inserted words
+	have := make(map[string]int, len(need))
+	matched, best := 0, 0
+	for i, w := range text {
+		if have[w] < need[w] {
+			matched++
+		}
+		have[w]++
+		if i >= window {
+			old := text[i-window]
+			have[old]--
+			if have[old] < need[old] {
+				matched--
+			}
+		}
+		best = max(best, matched)
+	}
+	return float64(best) / float64(len(want))
+}
+
+// words splits s into lowercase words, dropping punctuation
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,65 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/project-gamma/ai-resolver/internal/fetch"
+)
+
+// TestScore tests fuzzy matching of claims in page text
+func TestScore(t *testing.T) {
+	text := "Home | Sports\nFinal score: Team A beat Team B 2-1 on Sunday, March 3, in front of 40,000 fans.\nMore news"
+	tests := []struct {
+		claim string
+		min   float64
+		max   float64
+	}{
+		{"Team A beat Team B 2-1 on Sunday", 1, 1},
+		{"team a BEAT team b, 2–1, on sunday!", 1, 1},       // Case and punctuation
+		{"Team A defeated Team B 2-1 on Sunday", 0.85, 0.9}, // One word changed
+		{"Team B beat Team A 3-0 on Saturday", 0.5, 0.79},   // Different result, below the default threshold
+		{"The central bank raised interest rates by half a point", 0, 0.1},
+		{"Team A beat Team B ... in front of 40,000 fans", 0.6, 0.9}, // Words far apart
+	}
+	for _, tt := range tests {
+		if got := Score(tt.claim, text); got < tt.min || got > tt.max {
+			t.Errorf("%q: expected %.2f-%.2f, got %.2f", tt.claim, tt.min, tt.max, got)
+		}
+	}
+}
+
+// pages is a Fetcher serving fixed text
+type pages map[string]string
+
+func (p pages) Fetch(_ context.Context, url string) (*fetch.Page, error) {
+	text, ok := p[url]
+	if !ok {
+		return nil, errors.New("404")
+	}
+	return &fetch.Page{URL: url, Text: text, Hash: "abc"}, nil
+}
+
+// TestVerify tests verifying claims against fetched pages
+func TestVerify(t *testing.T) {
+	v := New(pages{"https://a.example/": "Officials confirmed the bridge reopened to traffic on Monday morning."})
+	ctx := context.Background()
+
+	result, err := v.Verify(ctx, "https://a.example/", []string{"short", "the bridge reopened to traffic on Monday"})
+	if err != nil || !result.Verified || result.Claim != "the bridge reopened to traffic on Monday" || result.PageHash != "abc" {
+		t.Errorf("expected a verified claim, got %+v %v", result, err)
+	}
+
+	result, err = v.Verify(ctx, "https://a.example/", []string{"the bridge remains closed until further notice"})
+	if err != nil || result.Verified || result.Reason == "" {
+		t.Errorf("expected an unverified claim, got %+v %v", result, err)
+	}
+
+	if result, _ := v.Verify(ctx, "https://a.example/", []string{"Bridge open"}); result.Verified || result.PageHash != "" {
+		t.Errorf("expected short claims not to be checked, got %+v", result)
+	}
+	if result, err := v.Verify(ctx, "https://b.example/", []string{"the bridge reopened to traffic on Monday"}); err == nil || result.Verified {
+		t.Errorf("expected a fetch error, got %+v", result)
+	}
+}
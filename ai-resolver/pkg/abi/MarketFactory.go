@@ -33,28 +33,33 @@ var (
 type MarketFactoryMarket struct {
 	Id              *big.Int
 	Creator         common.Address
+	MarketType      uint8
 	Amm             common.Address
 	CollateralToken common.Address
 	CloseTime       *big.Int
 	Category        string
 	MetadataURI     string
 	CreatorStake    *big.Int
+	OutcomeCount    uint8
 	StakeRefunded   bool
 	Status          uint8
 }
 
 // MarketFactoryMarketParams is an auto generated low-level Go binding around an user-defined struct.
 type MarketFactoryMarketParams struct {
-	CollateralToken common.Address
-	CloseTime       *big.Int
-	Category        string
-	MetadataURI     string
-	CreatorStake    *big.Int
+	MarketType         uint8
+	CollateralToken    common.Address
+	CloseTime          *big.Int
+	Category           string
+	MetadataURI        string
+	CreatorStake       *big.Int
+	OutcomeCount       uint8
+	LiquidityParameter *big.Int
 }
 
 // MarketFactoryMetaData contains all meta data concerning the MarketFactory contract.
 var MarketFactoryMetaData = &bind.MetaData{
-	ABI: "[{\"type\":\"constructor\",\"inputs\":[{\"name\":\"_outcomeToken\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"_feeSplitter\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"_horizonPerks\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"_horizonToken\",\"type\":\"address\",\"internalType\":\"address\"}],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"allMarketIds\",\"inputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"createMarket\",\"inputs\":[{\"name\":\"params\",\"type\":\"tuple\",\"internalType\":\"structMarketFactory.MarketParams\",\"components\":[{\"name\":\"collateralToken\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"closeTime\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"category\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"metadataURI\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"creatorStake\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]}],\"outputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"feeSplitter\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"contractFeeSplitter\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getActiveMarkets\",\"inputs\":[{\"name\":\"offset\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"limit\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"tuple[]\",\"internalType\":\"structMarketFactory.Market[]\",\"components\":[{\"name\":\"id\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"creator\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"amm\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"collateralToken\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"closeTime\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"category\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"metadataURI\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"creatorStake\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"stakeRefunded\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"status\",\"type\":\"uint8\",\"internalType\":\"enumMarketFactory.MarketStatus\"}]}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getAllMarketIds\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256[]\",\"internalType\":\"uint256[]\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getMarket\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"tuple\",\"internalType\":\"structMarketFactory.Market\",\"components\":[{\"name\":\"id\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"creator\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"amm\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"collateralToken\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"closeTime\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"category\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"metadataURI\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"creatorStake\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"stakeRefunded\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"status\",\"type\":\"uint8\",\"internalType\":\"enumMarketFactory.MarketStatus\"}]}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getMarketCount\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getMarketIdsByCategory\",\"inputs\":[{\"name\":\"category\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256[]\",\"internalType\":\"uint256[]\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getMarketIdsByCreator\",\"inputs\":[{\"name\":\"creator\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256[]\",\"internalType\":\"uint256[]\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getMarkets\",\"inputs\":[{\"name\":\"offset\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"limit\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"tuple[]\",\"internalType\":\"structMarketFactory.Market[]\",\"components\":[{\"name\":\"id\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"creator\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"amm\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"collateralToken\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"closeTime\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"category\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"metadataURI\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"creatorStake\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"stakeRefunded\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"status\",\"type\":\"uint8\",\"internalType\":\"enumMarketFactory.MarketStatus\"}]}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"horizonPerks\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"contractHorizonPerks\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"horizonToken\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"contractHorizonToken\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"marketExists\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"markets\",\"inputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"id\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"creator\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"amm\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"collateralToken\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"closeTime\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"category\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"metadataURI\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"creatorStake\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"stakeRefunded\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"status\",\"type\":\"uint8\",\"internalType\":\"enumMarketFactory.MarketStatus\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"marketsByCategory\",\"inputs\":[{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"marketsByCreator\",\"inputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"minCreatorStake\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"nextMarketId\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"outcomeToken\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"contractOutcomeToken\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"owner\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"address\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"refundCreatorStake\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"renounceOwnership\",\"inputs\":[],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"setMinCreatorStake\",\"inputs\":[{\"name\":\"newMinStake\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"transferOwnership\",\"inputs\":[{\"name\":\"newOwner\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"updateMarketStatus\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"event\",\"name\":\"CreatorStakeRefunded\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"indexed\":true,\"internalType\":\"uint256\"},{\"name\":\"creator\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"MarketCreated\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"indexed\":true,\"internalType\":\"uint256\"},{\"name\":\"creator\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"ammAddress\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"collateralToken\",\"type\":\"address\",\"indexed\":false,\"internalType\":\"address\"},{\"name\":\"closeTime\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"},{\"name\":\"category\",\"type\":\"string\",\"indexed\":false,\"internalType\":\"string\"},{\"name\":\"metadataURI\",\"type\":\"string\",\"indexed\":false,\"internalType\":\"string\"},{\"name\":\"creatorStake\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"MarketStatusUpdated\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"indexed\":true,\"internalType\":\"uint256\"},{\"name\":\"oldStatus\",\"type\":\"uint8\",\"indexed\":false,\"internalType\":\"enumMarketFactory.MarketStatus\"},{\"name\":\"newStatus\",\"type\":\"uint8\",\"indexed\":false,\"internalType\":\"enumMarketFactory.MarketStatus\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"MinCreatorStakeUpdated\",\"inputs\":[{\"name\":\"oldStake\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"},{\"name\":\"newStake\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"OwnershipTransferred\",\"inputs\":[{\"name\":\"previousOwner\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"newOwner\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}],\"anonymous\":false},{\"type\":\"error\",\"name\":\"InvalidAddress\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidCategory\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidCloseTime\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidCollateral\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidCreatorStake\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"MarketDoesNotExist\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"MarketNotResolved\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"NotMarketCreator\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"OwnableInvalidOwner\",\"inputs\":[{\"name\":\"owner\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"OwnableUnauthorizedAccount\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"ReentrancyGuardReentrantCall\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"SafeERC20FailedOperation\",\"inputs\":[{\"name\":\"token\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"StakeAlreadyClaimed\",\"inputs\":[]}]",
+	ABI: "[{\"type\":\"constructor\",\"inputs\":[{\"name\":\"_outcomeToken\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"_feeSplitter\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"_horizonPerks\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"_horizonToken\",\"type\":\"address\",\"internalType\":\"address\"}],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"allMarketIds\",\"inputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"createMarket\",\"inputs\":[{\"name\":\"params\",\"type\":\"tuple\",\"internalType\":\"structMarketFactory.MarketParams\",\"components\":[{\"name\":\"marketType\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"collateralToken\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"closeTime\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"category\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"metadataURI\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"creatorStake\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"outcomeCount\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"liquidityParameter\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]}],\"outputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"feeSplitter\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"contractFeeSplitter\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getActiveMarkets\",\"inputs\":[{\"name\":\"offset\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"limit\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"tuple[]\",\"internalType\":\"structMarketFactory.Market[]\",\"components\":[{\"name\":\"id\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"creator\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"marketType\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"amm\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"collateralToken\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"closeTime\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"category\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"metadataURI\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"creatorStake\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"outcomeCount\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"stakeRefunded\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"status\",\"type\":\"uint8\",\"internalType\":\"enumMarketFactory.MarketStatus\"}]}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getAllMarketIds\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256[]\",\"internalType\":\"uint256[]\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getMarket\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"tuple\",\"internalType\":\"structMarketFactory.Market\",\"components\":[{\"name\":\"id\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"creator\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"marketType\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"amm\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"collateralToken\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"closeTime\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"category\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"metadataURI\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"creatorStake\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"outcomeCount\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"stakeRefunded\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"status\",\"type\":\"uint8\",\"internalType\":\"enumMarketFactory.MarketStatus\"}]}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getMarketCount\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getMarketIdsByCategory\",\"inputs\":[{\"name\":\"category\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256[]\",\"internalType\":\"uint256[]\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getMarketIdsByCreator\",\"inputs\":[{\"name\":\"creator\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256[]\",\"internalType\":\"uint256[]\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getMarkets\",\"inputs\":[{\"name\":\"offset\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"limit\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"tuple[]\",\"internalType\":\"structMarketFactory.Market[]\",\"components\":[{\"name\":\"id\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"creator\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"marketType\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"amm\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"collateralToken\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"closeTime\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"category\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"metadataURI\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"creatorStake\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"outcomeCount\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"stakeRefunded\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"status\",\"type\":\"uint8\",\"internalType\":\"enumMarketFactory.MarketStatus\"}]}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"horizonPerks\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"contractHorizonPerks\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"horizonToken\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"contractHorizonToken\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"marketExists\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"markets\",\"inputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"id\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"creator\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"marketType\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"amm\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"collateralToken\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"closeTime\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"category\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"metadataURI\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"creatorStake\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"outcomeCount\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"stakeRefunded\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"status\",\"type\":\"uint8\",\"internalType\":\"enumMarketFactory.MarketStatus\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"marketsByCategory\",\"inputs\":[{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"marketsByCreator\",\"inputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"minCreatorStake\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"nextMarketId\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"outcomeToken\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"contractOutcomeToken\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"owner\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"address\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"refundCreatorStake\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"renounceOwnership\",\"inputs\":[],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"setMinCreatorStake\",\"inputs\":[{\"name\":\"newMinStake\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"transferOwnership\",\"inputs\":[{\"name\":\"newOwner\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"updateMarketStatus\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"event\",\"name\":\"CreatorStakeRefunded\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"indexed\":true,\"internalType\":\"uint256\"},{\"name\":\"creator\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"MarketCreated\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"indexed\":true,\"internalType\":\"uint256\"},{\"name\":\"creator\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"ammAddress\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"collateralToken\",\"type\":\"address\",\"indexed\":false,\"internalType\":\"address\"},{\"name\":\"closeTime\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"},{\"name\":\"category\",\"type\":\"string\",\"indexed\":false,\"internalType\":\"string\"},{\"name\":\"metadataURI\",\"type\":\"string\",\"indexed\":false,\"internalType\":\"string\"},{\"name\":\"creatorStake\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"MarketStatusUpdated\",\"inputs\":[{\"name\":\"marketId\",\"type\":\"uint256\",\"indexed\":true,\"internalType\":\"uint256\"},{\"name\":\"oldStatus\",\"type\":\"uint8\",\"indexed\":false,\"internalType\":\"enumMarketFactory.MarketStatus\"},{\"name\":\"newStatus\",\"type\":\"uint8\",\"indexed\":false,\"internalType\":\"enumMarketFactory.MarketStatus\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"MinCreatorStakeUpdated\",\"inputs\":[{\"name\":\"oldStake\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"},{\"name\":\"newStake\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"OwnershipTransferred\",\"inputs\":[{\"name\":\"previousOwner\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"newOwner\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}],\"anonymous\":false},{\"type\":\"error\",\"name\":\"InvalidAddress\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidCategory\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidCloseTime\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidCollateral\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidCreatorStake\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidLiquidityParameter\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidMarketType\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InvalidOutcomeCount\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"MarketDoesNotExist\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"MarketNotResolved\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"NotMarketCreator\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"OwnableInvalidOwner\",\"inputs\":[{\"name\":\"owner\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"OwnableUnauthorizedAccount\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"ReentrancyGuardReentrantCall\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"SafeERC20FailedOperation\",\"inputs\":[{\"name\":\"token\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"StakeAlreadyClaimed\",\"inputs\":[]}]",
 }
 
 // MarketFactoryABI is the input ABI used to generate the binding from.
@@ -267,7 +272,7 @@ func (_MarketFactory *MarketFactoryCallerSession) FeeSplitter() (common.Address,
 
 // GetActiveMarkets is a free data retrieval call binding the contract method 0xa04ddcad.
 //
-// Solidity: function getActiveMarkets(uint256 offset, uint256 limit) view returns((uint256,address,address,address,uint256,string,string,uint256,bool,uint8)[])
+// Solidity: function getActiveMarkets(uint256 offset, uint256 limit) view returns((uint256,address,uint8,address,address,uint256,string,string,uint256,uint8,bool,uint8)[])
 func (_MarketFactory *MarketFactoryCaller) GetActiveMarkets(opts *bind.CallOpts, offset *big.Int, limit *big.Int) ([]MarketFactoryMarket, error) {
 	var out []interface{}
 	err := _MarketFactory.contract.Call(opts, &out, "getActiveMarkets", offset, limit)
@@ -284,14 +289,14 @@ func (_MarketFactory *MarketFactoryCaller) GetActiveMarkets(opts *bind.CallOpts,
 
 // GetActiveMarkets is a free data retrieval call binding the contract method 0xa04ddcad.
 //
-// Solidity: function getActiveMarkets(uint256 offset, uint256 limit) view returns((uint256,address,address,address,uint256,string,string,uint256,bool,uint8)[])
+// Solidity: function getActiveMarkets(uint256 offset, uint256 limit) view returns((uint256,address,uint8,address,address,uint256,string,string,uint256,uint8,bool,uint8)[])
 func (_MarketFactory *MarketFactorySession) GetActiveMarkets(offset *big.Int, limit *big.Int) ([]MarketFactoryMarket, error) {
 	return _MarketFactory.Contract.GetActiveMarkets(&_MarketFactory.CallOpts, offset, limit)
 }
 
 // GetActiveMarkets is a free data retrieval call binding the contract method 0xa04ddcad.
 //
-// Solidity: function getActiveMarkets(uint256 offset, uint256 limit) view returns((uint256,address,address,address,uint256,string,string,uint256,bool,uint8)[])
+// Solidity: function getActiveMarkets(uint256 offset, uint256 limit) view returns((uint256,address,uint8,address,address,uint256,string,string,uint256,uint8,bool,uint8)[])
 func (_MarketFactory *MarketFactoryCallerSession) GetActiveMarkets(offset *big.Int, limit *big.Int) ([]MarketFactoryMarket, error) {
 	return _MarketFactory.Contract.GetActiveMarkets(&_MarketFactory.CallOpts, offset, limit)
 }
@@ -329,7 +334,7 @@ func (_MarketFactory *MarketFactoryCallerSession) GetAllMarketIds() ([]*big.Int,
 
 // GetMarket is a free data retrieval call binding the contract method 0xeb44fdd3.
 //
-// Solidity: function getMarket(uint256 marketId) view returns((uint256,address,address,address,uint256,string,string,uint256,bool,uint8))
+// Solidity: function getMarket(uint256 marketId) view returns((uint256,address,uint8,address,address,uint256,string,string,uint256,uint8,bool,uint8))
 func (_MarketFactory *MarketFactoryCaller) GetMarket(opts *bind.CallOpts, marketId *big.Int) (MarketFactoryMarket, error) {
 	var out []interface{}
 	err := _MarketFactory.contract.Call(opts, &out, "getMarket", marketId)
@@ -346,14 +351,14 @@ func (_MarketFactory *MarketFactoryCaller) GetMarket(opts *bind.CallOpts, market
 
 // GetMarket is a free data retrieval call binding the contract method 0xeb44fdd3.
 //
-// Solidity: function getMarket(uint256 marketId) view returns((uint256,address,address,address,uint256,string,string,uint256,bool,uint8))
+// Solidity: function getMarket(uint256 marketId) view returns((uint256,address,uint8,address,address,uint256,string,string,uint256,uint8,bool,uint8))
 func (_MarketFactory *MarketFactorySession) GetMarket(marketId *big.Int) (MarketFactoryMarket, error) {
 	return _MarketFactory.Contract.GetMarket(&_MarketFactory.CallOpts, marketId)
 }
 
 // GetMarket is a free data retrieval call binding the contract method 0xeb44fdd3.
 //
-// Solidity: function getMarket(uint256 marketId) view returns((uint256,address,address,address,uint256,string,string,uint256,bool,uint8))
+// Solidity: function getMarket(uint256 marketId) view returns((uint256,address,uint8,address,address,uint256,string,string,uint256,uint8,bool,uint8))
 func (_MarketFactory *MarketFactoryCallerSession) GetMarket(marketId *big.Int) (MarketFactoryMarket, error) {
 	return _MarketFactory.Contract.GetMarket(&_MarketFactory.CallOpts, marketId)
 }
@@ -453,7 +458,7 @@ func (_MarketFactory *MarketFactoryCallerSession) GetMarketIdsByCreator(creator
 
 // GetMarkets is a free data retrieval call binding the contract method 0x80968d48.
 //
-// Solidity: function getMarkets(uint256 offset, uint256 limit) view returns((uint256,address,address,address,uint256,string,string,uint256,bool,uint8)[])
+// Solidity: function getMarkets(uint256 offset, uint256 limit) view returns((uint256,address,uint8,address,address,uint256,string,string,uint256,uint8,bool,uint8)[])
 func (_MarketFactory *MarketFactoryCaller) GetMarkets(opts *bind.CallOpts, offset *big.Int, limit *big.Int) ([]MarketFactoryMarket, error) {
 	var out []interface{}
 	err := _MarketFactory.contract.Call(opts, &out, "getMarkets", offset, limit)
@@ -470,14 +475,14 @@ func (_MarketFactory *MarketFactoryCaller) GetMarkets(opts *bind.CallOpts, offse
 
 // GetMarkets is a free data retrieval call binding the contract method 0x80968d48.
 //
-// Solidity: function getMarkets(uint256 offset, uint256 limit) view returns((uint256,address,address,address,uint256,string,string,uint256,bool,uint8)[])
+// Solidity: function getMarkets(uint256 offset, uint256 limit) view returns((uint256,address,uint8,address,address,uint256,string,string,uint256,uint8,bool,uint8)[])
 func (_MarketFactory *MarketFactorySession) GetMarkets(offset *big.Int, limit *big.Int) ([]MarketFactoryMarket, error) {
 	return _MarketFactory.Contract.GetMarkets(&_MarketFactory.CallOpts, offset, limit)
 }
 
 // GetMarkets is a free data retrieval call binding the contract method 0x80968d48.
 //
-// Solidity: function getMarkets(uint256 offset, uint256 limit) view returns((uint256,address,address,address,uint256,string,string,uint256,bool,uint8)[])
+// Solidity: function getMarkets(uint256 offset, uint256 limit) view returns((uint256,address,uint8,address,address,uint256,string,string,uint256,uint8,bool,uint8)[])
 func (_MarketFactory *MarketFactoryCallerSession) GetMarkets(offset *big.Int, limit *big.Int) ([]MarketFactoryMarket, error) {
 	return _MarketFactory.Contract.GetMarkets(&_MarketFactory.CallOpts, offset, limit)
 }
@@ -577,16 +582,18 @@ func (_MarketFactory *MarketFactoryCallerSession) MarketExists(marketId *big.Int
 
 // Markets is a free data retrieval call binding the contract method 0xb1283e77.
 //
-// Solidity: function markets(uint256 ) view returns(uint256 id, address creator, address amm, address collateralToken, uint256 closeTime, string category, string metadataURI, uint256 creatorStake, bool stakeRefunded, uint8 status)
+// Solidity: function markets(uint256 ) view returns(uint256 id, address creator, uint8 marketType, address amm, address collateralToken, uint256 closeTime, string category, string metadataURI, uint256 creatorStake, uint8 outcomeCount, bool stakeRefunded, uint8 status)
 func (_MarketFactory *MarketFactoryCaller) Markets(opts *bind.CallOpts, arg0 *big.Int) (struct {
 	Id              *big.Int
 	Creator         common.Address
+	MarketType      uint8
 	Amm             common.Address
 	CollateralToken common.Address
 	CloseTime       *big.Int
 	Category        string
 	MetadataURI     string
 	CreatorStake    *big.Int
+	OutcomeCount    uint8
 	StakeRefunded   bool
 	Status          uint8
 }, error) {
@@ -596,12 +603,14 @@ func (_MarketFactory *MarketFactoryCaller) Markets(opts *bind.CallOpts, arg0 *bi
 	outstruct := new(struct {
 		Id              *big.Int
 		Creator         common.Address
+		MarketType      uint8
 		Amm             common.Address
 		CollateralToken common.Address
 		CloseTime       *big.Int
 		Category        string
 		MetadataURI     string
 		CreatorStake    *big.Int
+		OutcomeCount    uint8
 		StakeRefunded   bool
 		Status          uint8
 	})
@@ -611,14 +620,16 @@ func (_MarketFactory *MarketFactoryCaller) Markets(opts *bind.CallOpts, arg0 *bi
 
 	outstruct.Id = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
 	outstruct.Creator = *abi.ConvertType(out[1], new(common.Address)).(*common.Address)
-	outstruct.Amm = *abi.ConvertType(out[2], new(common.Address)).(*common.Address)
-	outstruct.CollateralToken = *abi.ConvertType(out[3], new(common.Address)).(*common.Address)
-	outstruct.CloseTime = *abi.ConvertType(out[4], new(*big.Int)).(**big.Int)
-	outstruct.Category = *abi.ConvertType(out[5], new(string)).(*string)
-	outstruct.MetadataURI = *abi.ConvertType(out[6], new(string)).(*string)
-	outstruct.CreatorStake = *abi.ConvertType(out[7], new(*big.Int)).(**big.Int)
-	outstruct.StakeRefunded = *abi.ConvertType(out[8], new(bool)).(*bool)
-	outstruct.Status = *abi.ConvertType(out[9], new(uint8)).(*uint8)
+	outstruct.MarketType = *abi.ConvertType(out[2], new(uint8)).(*uint8)
+	outstruct.Amm = *abi.ConvertType(out[3], new(common.Address)).(*common.Address)
+	outstruct.CollateralToken = *abi.ConvertType(out[4], new(common.Address)).(*common.Address)
+	outstruct.CloseTime = *abi.ConvertType(out[5], new(*big.Int)).(**big.Int)
+	outstruct.Category = *abi.ConvertType(out[6], new(string)).(*string)
+	outstruct.MetadataURI = *abi.ConvertType(out[7], new(string)).(*string)
+	outstruct.CreatorStake = *abi.ConvertType(out[8], new(*big.Int)).(**big.Int)
+	outstruct.OutcomeCount = *abi.ConvertType(out[9], new(uint8)).(*uint8)
+	outstruct.StakeRefunded = *abi.ConvertType(out[10], new(bool)).(*bool)
+	outstruct.Status = *abi.ConvertType(out[11], new(uint8)).(*uint8)
 
 	return *outstruct, err
 
@@ -626,16 +637,18 @@ func (_MarketFactory *MarketFactoryCaller) Markets(opts *bind.CallOpts, arg0 *bi
 
 // Markets is a free data retrieval call binding the contract method 0xb1283e77.
 //
-// Solidity: function markets(uint256 ) view returns(uint256 id, address creator, address amm, address collateralToken, uint256 closeTime, string category, string metadataURI, uint256 creatorStake, bool stakeRefunded, uint8 status)
+// Solidity: function markets(uint256 ) view returns(uint256 id, address creator, uint8 marketType, address amm, address collateralToken, uint256 closeTime, string category, string metadataURI, uint256 creatorStake, uint8 outcomeCount, bool stakeRefunded, uint8 status)
 func (_MarketFactory *MarketFactorySession) Markets(arg0 *big.Int) (struct {
 	Id              *big.Int
 	Creator         common.Address
+	MarketType      uint8
 	Amm             common.Address
 	CollateralToken common.Address
 	CloseTime       *big.Int
 	Category        string
 	MetadataURI     string
 	CreatorStake    *big.Int
+	OutcomeCount    uint8
 	StakeRefunded   bool
 	Status          uint8
 }, error) {
@@ -644,16 +657,18 @@ func (_MarketFactory *MarketFactorySession) Markets(arg0 *big.Int) (struct {
 
 // Markets is a free data retrieval call binding the contract method 0xb1283e77.
 //
-// Solidity: function markets(uint256 ) view returns(uint256 id, address creator, address amm, address collateralToken, uint256 closeTime, string category, string metadataURI, uint256 creatorStake, bool stakeRefunded, uint8 status)
+// Solidity: function markets(uint256 ) view returns(uint256 id, address creator, uint8 marketType, address amm, address collateralToken, uint256 closeTime, string category, string metadataURI, uint256 creatorStake, uint8 outcomeCount, bool stakeRefunded, uint8 status)
 func (_MarketFactory *MarketFactoryCallerSession) Markets(arg0 *big.Int) (struct {
 	Id              *big.Int
 	Creator         common.Address
+	MarketType      uint8
 	Amm             common.Address
 	CollateralToken common.Address
 	CloseTime       *big.Int
 	Category        string
 	MetadataURI     string
 	CreatorStake    *big.Int
+	OutcomeCount    uint8
 	StakeRefunded   bool
 	Status          uint8
 }, error) {
@@ -846,23 +861,23 @@ func (_MarketFactory *MarketFactoryCallerSession) Owner() (common.Address, error
 	return _MarketFactory.Contract.Owner(&_MarketFactory.CallOpts)
 }
 
-// CreateMarket is a paid mutator transaction binding the contract method 0xf7cbc21c.
+// CreateMarket is a paid mutator transaction binding the contract method 0xe1e5bb4f.
 //
-// Solidity: function createMarket((address,uint256,string,string,uint256) params) returns(uint256 marketId)
+// Solidity: function createMarket((uint8,address,uint256,string,string,uint256,uint8,uint256) params) returns(uint256 marketId)
 func (_MarketFactory *MarketFactoryTransactor) CreateMarket(opts *bind.TransactOpts, params MarketFactoryMarketParams) (*types.Transaction, error) {
 	return _MarketFactory.contract.Transact(opts, "createMarket", params)
 }
 
-// CreateMarket is a paid mutator transaction binding the contract method 0xf7cbc21c.
+// CreateMarket is a paid mutator transaction binding the contract method 0xe1e5bb4f.
 //
-// Solidity: function createMarket((address,uint256,string,string,uint256) params) returns(uint256 marketId)
+// Solidity: function createMarket((uint8,address,uint256,string,string,uint256,uint8,uint256) params) returns(uint256 marketId)
 func (_MarketFactory *MarketFactorySession) CreateMarket(params MarketFactoryMarketParams) (*types.Transaction, error) {
 	return _MarketFactory.Contract.CreateMarket(&_MarketFactory.TransactOpts, params)
 }
 
-// CreateMarket is a paid mutator transaction binding the contract method 0xf7cbc21c.
+// CreateMarket is a paid mutator transaction binding the contract method 0xe1e5bb4f.
 //
-// Solidity: function createMarket((address,uint256,string,string,uint256) params) returns(uint256 marketId)
+// Solidity: function createMarket((uint8,address,uint256,string,string,uint256,uint8,uint256) params) returns(uint256 marketId)
 func (_MarketFactory *MarketFactoryTransactorSession) CreateMarket(params MarketFactoryMarketParams) (*types.Transaction, error) {
 	return _MarketFactory.Contract.CreateMarket(&_MarketFactory.TransactOpts, params)
 }
@@ -0,0 +1,5 @@
+package abi
+
+// The protocol bindings are generated from the Foundry build, with bytecode so
+// that internal/simchain can deploy them.
+//go:generate ./generate.sh
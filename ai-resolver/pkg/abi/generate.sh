@@ -0,0 +1,24 @@
+#!/usr/bin/env sh
+# Regenerates the protocol bindings, with deployment bytecode, from the
+# Foundry build of ../../../contracts. Needs forge and jq; abigen runs at the
+# go-ethereum version pinned in go.mod.
+set -eu
+
+cd "$(dirname "$0")"
+contracts=../../../contracts
+tmp=$(mktemp -d)
+trap 'rm -rf "$tmp"' EXIT
+
+(cd "$contracts" && forge build)
+
+for contract in OutcomeToken HorizonPerks FeeSplitter ResolutionModule AIOracleAdapter MarketFactory; do
+  artifact="$contracts/out/$contract.sol/$contract.json"
+  jq -r '.abi' "$artifact" > "$tmp/$contract.abi"
+  jq -r '.bytecode.object' "$artifact" > "$tmp/$contract.bin"
+  go run github.com/ethereum/go-ethereum/cmd/abigen \
+    --abi "$tmp/$contract.abi" \
+    --bin "$tmp/$contract.bin" \
+    --pkg abi \
+    --type "$contract" \
+    --out "$contract.go"
+done